@@ -31,10 +31,15 @@ Upload a chart to a remote registry.
 Note: the ref must already exist in the local registry cache.
 
 Must first run "helm chart save" or "helm chart pull".
+
+With --sbom, a software bill of materials is pushed as well, as an OCI
+referrer of the chart.
 `
 
 func newChartPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
-	return &cobra.Command{
+	client := action.NewChartPush(cfg)
+
+	cmd := &cobra.Command{
 		Use:    "push [ref]",
 		Short:  "push a chart to remote",
 		Long:   chartPushDesc,
@@ -42,7 +47,12 @@ func newChartPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		Hidden: !FeatureGateOCI.IsEnabled(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ref := args[0]
-			return action.NewChartPush(cfg).Run(out, ref)
+			return client.Run(out, ref)
 		},
 	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.SBOM, "sbom", "", "location of a software bill of materials (SPDX, CycloneDX) to push as a referrer of the chart")
+
+	return cmd
 }
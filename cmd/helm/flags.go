@@ -39,10 +39,13 @@ const outputFlag = "output"
 const postRenderFlag = "post-renderer"
 
 func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
-	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
+	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, `specify values in a YAML file, a URL, or on stdin with "-" (can specify multiple)`)
+	f.StringArrayVar(&v.JSONValues, "set-json", []string{}, "set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2)")
 	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
-	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
+	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line, concatenating with newlines the files matching a glob such as key1=policies/*.rego (can specify multiple or separate values with commas: key1=path1,key2=path2)")
+	f.StringArrayVar(&v.FileListValues, "set-file-list", []string{}, "set values to the list of the contents of the files matching a glob, sorted by name (can specify multiple or separate values with commas: key1=glob1,key2=glob2)")
+	f.StringArrayVar(&v.UnsetValues, "unset", []string{}, "remove values from the merged values, including chart defaults, by dotted path such as key1.key2 or list[0] (can specify multiple)")
 }
 
 func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
@@ -62,7 +65,7 @@ func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 // value to the given format pointer
 func bindOutputFlag(cmd *cobra.Command, varRef *output.Format) {
 	cmd.Flags().VarP(newOutputValue(output.Table, varRef), outputFlag, "o",
-		fmt.Sprintf("prints the output in the specified format. Allowed values: %s", strings.Join(output.Formats(), ", ")))
+		fmt.Sprintf("prints the output in the specified format. Allowed values: %s, jsonpath=<template>", strings.Join(output.Formats(), ", ")))
 
 	err := cmd.RegisterFlagCompletionFunc(outputFlag, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		var formatNames []string
@@ -25,6 +25,7 @@ import (
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/output"
 )
 
@@ -32,6 +33,12 @@ var getValuesHelp = `
 This command downloads a values file for a given release.
 `
 
+type valueAtPathWriter struct {
+	path  string
+	value interface{}
+	typ   string
+}
+
 type valuesWriter struct {
 	vals      map[string]interface{}
 	allValues bool
@@ -39,6 +46,7 @@ type valuesWriter struct {
 
 func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var outfmt output.Format
+	var path string
 	client := action.NewGetValues(cfg)
 
 	cmd := &cobra.Command{
@@ -57,6 +65,13 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if path != "" {
+				value, typ, err := chartutil.GetValueAtPath(vals, path)
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, &valueAtPathWriter{path, value, typ})
+			}
 			return outfmt.Write(out, &valuesWriter{vals, client.AllValues})
 		},
 	}
@@ -75,6 +90,7 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f.BoolVarP(&client.AllValues, "all", "a", false, "dump all (computed) values")
+	f.StringVar(&path, "path", "", "only show the value at the given path, such as 'x.y[0].z'")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -96,3 +112,18 @@ func (v valuesWriter) WriteJSON(out io.Writer) error {
 func (v valuesWriter) WriteYAML(out io.Writer) error {
 	return output.EncodeYAML(out, v.vals)
 }
+
+func (v valueAtPathWriter) WriteTable(out io.Writer) error {
+	fmt.Fprintf(out, "PATH: %s\n", v.path)
+	fmt.Fprintf(out, "TYPE: %s\n", v.typ)
+	fmt.Fprintln(out, "VALUE:")
+	return output.EncodeYAML(out, v.value)
+}
+
+func (v valueAtPathWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, v.value)
+}
+
+func (v valueAtPathWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, v.value)
+}
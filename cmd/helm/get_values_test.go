@@ -49,6 +49,22 @@ func TestGetValuesCmd(t *testing.T) {
 		cmd:    "get values thomas-guide --output yaml",
 		golden: "output/values.yaml",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:   "get values with a jsonpath",
+		cmd:    "get values thomas-guide -o jsonpath={.name}",
+		golden: "output/get-values-jsonpath.txt",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:   "get values at a path",
+		cmd:    "get values thomas-guide --path name",
+		golden: "output/get-values-path.txt",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:      "get values at a missing path",
+		cmd:       "get values thomas-guide --path missing",
+		golden:    "output/get-values-path-missing.txt",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+		wantError: true,
 	}}
 	runTestCmd(t, tests)
 }
@@ -40,6 +40,10 @@ import (
 // FeatureGateOCI is the feature gate for checking if `helm chart` and `helm registry` commands should work
 const FeatureGateOCI = gates.Gate("HELM_EXPERIMENTAL_OCI")
 
+// debugTemplateErrorContextLines is the number of template source lines shown
+// around template rendering errors with --debug
+const debugTemplateErrorContextLines = 3
+
 var settings = cli.New()
 
 func init() {
@@ -72,6 +76,9 @@ func main() {
 		if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), helmDriver, debug); err != nil {
 			log.Fatal(err)
 		}
+		if settings.Debug {
+			actionConfig.TemplateErrorContextLines = debugTemplateErrorContextLines
+		}
 		if helmDriver == "memory" {
 			loadReleasesInMemory(actionConfig)
 		}
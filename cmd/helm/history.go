@@ -39,7 +39,9 @@ var historyHelp = `
 History prints historical revisions for a given release.
 
 A default maximum of 256 revisions will be returned. Setting '--max'
-configures the maximum length of the revision list returned.
+configures the maximum length of the revision list returned. With '--prune',
+the revisions beyond '--max' are also removed from storage, apart from the
+deployed revision, and the number removed is reported on stderr.
 
 The historical release set is printed as a formatted table, e.g:
 
@@ -72,6 +74,9 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if client.Prune {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Pruned %d revision(s) of %s\n", client.Pruned, args[0])
+			}
 
 			return outfmt.Write(out, history)
 		},
@@ -79,6 +84,7 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.IntVar(&client.Max, "max", 256, "maximum number of revision to include in history")
+	f.BoolVar(&client.Prune, "prune", false, "remove the revisions beyond --max from storage, keeping the deployed revision")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
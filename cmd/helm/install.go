@@ -33,6 +33,7 @@ import (
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/release"
 )
 
@@ -107,6 +108,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewInstall(cfg)
 	valueOpts := &values.Options{}
 	var outfmt output.Format
+	var resume bool
 
 	cmd := &cobra.Command{
 		Use:   "install [NAME] [CHART]",
@@ -117,7 +119,15 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compInstall(args, toComplete, client)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
-			rel, err := runInstall(args, client, valueOpts, out)
+			var (
+				rel *release.Release
+				err error
+			)
+			if resume {
+				rel, err = client.Resume(args[0])
+			} else {
+				rel, err = runInstall(args, client, valueOpts, out)
+			}
 			if err != nil {
 				return err
 			}
@@ -127,6 +137,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	addInstallFlags(cmd, cmd.Flags(), client, valueOpts)
+	cmd.Flags().BoolVar(&resume, "resume", false, "resume the failed install of the release NAME from the resource it failed at, instead of installing a chart")
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer)
 
@@ -139,18 +150,32 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during install")
 	f.BoolVar(&client.Replace, "replace", false, "re-use the given name, only if that name is a deleted release which remains in the history. This is unsafe in production")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	f.DurationVar(&client.ApplyTimeout, "apply-timeout", 0, "time to wait for the Kubernetes API to create or update any single resource, apart from --timeout. 0 means no limit")
 	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
+	f.IntVar(&client.WaitRetries, "wait-retries", kube.DefaultWaitRetries, "number of consecutive transient Kubernetes API errors tolerated while waiting with --wait")
+	f.StringSliceVar(&client.WaitSkipKinds, "wait-skip-kinds", []string{}, "kinds of resources (e.g. Job) not waited for with --wait")
+	f.StringSliceVar(&client.AllowedKinds, "allowed-kinds", []string{}, "reject the install if the chart renders resources of other kinds, given as apiVersion/Kind (e.g. apps/v1/Deployment) or Kind")
 	f.BoolVarP(&client.GenerateName, "generate-name", "g", false, "generate the name (and omit the NAME parameter)")
+	f.Int64Var(&client.GenerateNameSeed, "generate-name-seed", 0, "seed used to generate a stable name with --generate-name and --dry-run")
+	f.IntVar(&client.GenerateNameLength, "generate-name-length", 0, "length of the random suffix of a generated name, instead of a timestamp")
+	f.StringVar(&client.GenerateNameCharset, "generate-name-charset", "", "characters of the random suffix of a generated name, instead of a timestamp")
 	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
+	f.StringToStringVar(&client.ReleaseAnnotations, "release-annotations", nil, "annotations stored with the release record, such as the commit that produced it (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "run helm dependency update before installing the chart")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the installation process will not validate rendered templates against the Kubernetes OpenAPI Schema")
 	f.BoolVar(&client.Atomic, "atomic", false, "if set, the installation process deletes the installation on failure. The --wait flag will be set automatically if --atomic is used")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed. By default, CRDs are installed if not already present")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
+	f.BoolVar(&client.StrictSchema, "strict-schema", false, "reject values not declared by the chart's values.schema.json, even where it allows additional properties")
+	f.BoolVar(&client.RenderValueTemplates, "render-value-templates", false, "render values that contain templates, such as \"{{ .Values.host }}\", before rendering the chart")
+	f.BoolVar(&client.AllowDuplicateResources, "allow-duplicate-resources", false, "allow the chart to render the same resource more than once, letting the last one win")
+	f.BoolVar(&client.CheckQuota, "check-quota", false, "warn when the rendered workloads do not fit the ResourceQuotas of the namespace")
+	f.BoolVar(&client.FailOnQuota, "fail-on-quota", false, "with --check-quota, fail instead of warning when the rendered workloads do not fit the ResourceQuotas of the namespace")
 	addValueOptionsFlags(f, valueOpts)
+	f.BoolVar(&valueOpts.WarnOverrides, "warn-overrides", false, "print a warning when a values file overrides a key set by an earlier values file")
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 
 	err := cmd.RegisterFlagCompletionFunc("version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -190,10 +215,19 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	debug("CHART PATH: %s\n", cp)
 
 	p := getter.All(settings)
+	report := &values.MergeReport{}
+	valueOpts.Report = report
 	vals, err := valueOpts.MergeValues(p)
 	if err != nil {
 		return nil, err
 	}
+	client.ValuesSources = report.Sources
+	client.UnsetValues = valueOpts.UnsetValues
+	if valueOpts.WarnOverrides {
+		for _, o := range report.Overrides {
+			warning("%s", o)
+		}
+	}
 
 	// Check chart dependencies to make sure all are present in /charts
 	chartRequested, err := loader.Load(cp)
@@ -27,8 +27,11 @@ import (
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/lint/rules"
+	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 var longLintHelp = `
@@ -38,11 +41,20 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+The messages can be printed as JSON or YAML with '--output', giving the chart,
+severity, path and message of each of them.
+
+With '--require-resources', the containers of the rendered workloads that
+declare no resource requests, limits or either are reported, as warnings unless
+'--require-resources-severity' says otherwise.
 `
 
 func newLintCmd(out io.Writer) *cobra.Command {
 	client := action.NewLint()
 	valueOpts := &values.Options{}
+	var outfmt output.Format
+	var requireResources, requireResourcesSeverity string
 
 	cmd := &cobra.Command{
 		Use:   "lint PATH",
@@ -69,50 +81,42 @@ func newLintCmd(out io.Writer) *cobra.Command {
 			}
 
 			client.Namespace = settings.Namespace()
+			client.UnsetValues = valueOpts.UnsetValues
+			if requireResources != "" {
+				var err error
+				if client.RequireResources, err = parseResourceRequirement(requireResources); err != nil {
+					return err
+				}
+				if client.RequireResourcesSeverity, err = parseSeverity(requireResourcesSeverity); err != nil {
+					return err
+				}
+			}
 			vals, err := valueOpts.MergeValues(getter.All(settings))
 			if err != nil {
 				return err
 			}
 
-			var message strings.Builder
+			var results []*action.LintResult
 			failed := 0
-
 			for _, path := range paths {
-				fmt.Fprintf(&message, "==> Linting %s\n", path)
-
 				result := client.Run([]string{path}, vals)
-
-				// All the Errors that are generated by a chart
-				// that failed a lint will be included in the
-				// results.Messages so we only need to print
-				// the Errors if there are no Messages.
-				if len(result.Messages) == 0 {
-					for _, err := range result.Errors {
-						fmt.Fprintf(&message, "Error %s\n", err)
-					}
-				}
-
-				for _, msg := range result.Messages {
-					fmt.Fprintf(&message, "%s\n", msg)
-				}
-
 				if len(result.Errors) != 0 {
 					failed++
 				}
-
-				// Adding extra new line here to break up the
-				// results, stops this from being a big wall of
-				// text and makes it easier to follow.
-				fmt.Fprint(&message, "\n")
+				results = append(results, result)
 			}
 
-			fmt.Fprint(out, message.String())
+			if err := outfmt.Write(out, &lintWriter{paths: paths, results: results, failed: failed}); err != nil {
+				return err
+			}
 
 			summary := fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), failed)
 			if failed > 0 {
 				return errors.New(summary)
 			}
-			fmt.Fprintln(out, summary)
+			if outfmt == output.Table {
+				fmt.Fprintln(out, summary)
+			}
 			return nil
 		},
 	}
@@ -120,7 +124,99 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVar(&client.Strict, "strict", false, "fail on lint warnings")
 	f.BoolVar(&client.WithSubcharts, "with-subcharts", false, "lint dependent charts")
+	f.BoolVar(&client.UnusedValues, "unused-values", false, "warn about values set in values.yaml that no template references")
+	f.StringVar(&client.KubeVersion, "kube-version", "", "warn about resources using APIs deprecated or removed in this Kubernetes version, or fail with --strict")
+	f.StringVar(&requireResources, "require-resources", "", "report containers without resource requests, limits or either (one of: requests, limits, both)")
+	f.StringVar(&requireResourcesSeverity, "require-resources-severity", "warning", "severity of the containers reported by --require-resources (one of: info, warning, error)")
 	addValueOptionsFlags(f, valueOpts)
+	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
+
+type lintWriter struct {
+	paths   []string
+	results []*action.LintResult
+	failed  int
+}
+
+// lintReport is the structured output of helm lint.
+type lintReport struct {
+	Messages []action.LintMessage `json:"messages"`
+	Linted   int                  `json:"linted"`
+	Failed   int                  `json:"failed"`
+}
+
+func (w *lintWriter) WriteTable(out io.Writer) error {
+	var message strings.Builder
+	for i, path := range w.paths {
+		result := w.results[i]
+		fmt.Fprintf(&message, "==> Linting %s\n", path)
+
+		// All the Errors that are generated by a chart
+		// that failed a lint will be included in the
+		// results.Messages so we only need to print
+		// the Errors if there are no Messages.
+		if len(result.Messages) == 0 {
+			for _, err := range result.Errors {
+				fmt.Fprintf(&message, "Error %s\n", err)
+			}
+		}
+
+		for _, msg := range result.Messages {
+			fmt.Fprintf(&message, "%s\n", msg)
+		}
+
+		// Adding extra new line here to break up the
+		// results, stops this from being a big wall of
+		// text and makes it easier to follow.
+		fmt.Fprint(&message, "\n")
+	}
+
+	_, err := fmt.Fprint(out, message.String())
+	return err
+}
+
+func (w *lintWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.report())
+}
+
+func (w *lintWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.report())
+}
+
+func (w *lintWriter) report() lintReport {
+	report := lintReport{
+		Messages: []action.LintMessage{},
+		Linted:   len(w.paths),
+		Failed:   w.failed,
+	}
+	for _, result := range w.results {
+		report.Messages = append(report.Messages, result.ChartMessages...)
+	}
+	return report
+}
+
+func parseResourceRequirement(s string) (rules.ResourceRequirement, error) {
+	switch s {
+	case "requests":
+		return rules.RequireRequests, nil
+	case "limits":
+		return rules.RequireLimits, nil
+	case "both":
+		return rules.RequireRequestsAndLimits, nil
+	}
+	return 0, errors.Errorf("invalid --require-resources %q: must be one of requests, limits, both", s)
+}
+
+func parseSeverity(s string) (int, error) {
+	switch s {
+	case "info":
+		return support.InfoSev, nil
+	case "warning":
+		return support.WarningSev, nil
+	case "error":
+		return support.ErrorSev, nil
+	}
+	return 0, errors.Errorf("invalid --require-resources-severity %q: must be one of info, warning, error", s)
+}
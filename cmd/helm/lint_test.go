@@ -33,6 +33,11 @@ func TestLintCmdWithSubchartsFlag(t *testing.T) {
 		cmd:       fmt.Sprintf("lint --with-subcharts %s", testChart),
 		golden:    "output/lint-chart-with-bad-subcharts-with-subcharts.txt",
 		wantError: true,
+	}, {
+		name:      "lint good chart with bad subcharts as JSON",
+		cmd:       fmt.Sprintf("lint %s --output json", testChart),
+		golden:    "output/lint-chart-with-bad-subcharts.json",
+		wantError: true,
 	}}
 	runTestCmd(t, tests)
 }
@@ -135,13 +135,14 @@ func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 }
 
 type releaseElement struct {
-	Name       string `json:"name"`
-	Namespace  string `json:"namespace"`
-	Revision   string `json:"revision"`
-	Updated    string `json:"updated"`
-	Status     string `json:"status"`
-	Chart      string `json:"chart"`
-	AppVersion string `json:"app_version"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Revision    string            `json:"revision"`
+	Updated     string            `json:"updated"`
+	Status      string            `json:"status"`
+	Chart       string            `json:"chart"`
+	AppVersion  string            `json:"app_version"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type releaseListWriter struct {
@@ -153,12 +154,13 @@ func newReleaseListWriter(releases []*release.Release, timeFormat string) *relea
 	elements := make([]releaseElement, 0, len(releases))
 	for _, r := range releases {
 		element := releaseElement{
-			Name:       r.Name,
-			Namespace:  r.Namespace,
-			Revision:   strconv.Itoa(r.Version),
-			Status:     r.Info.Status.String(),
-			Chart:      fmt.Sprintf("%s-%s", r.Chart.Metadata.Name, r.Chart.Metadata.Version),
-			AppVersion: r.Chart.Metadata.AppVersion,
+			Name:        r.Name,
+			Namespace:   r.Namespace,
+			Revision:    strconv.Itoa(r.Version),
+			Status:      r.Info.Status.String(),
+			Chart:       fmt.Sprintf("%s-%s", r.Chart.Metadata.Name, r.Chart.Metadata.Version),
+			AppVersion:  r.Chart.Metadata.AppVersion,
+			Annotations: r.Annotations,
 		}
 
 		t := "-"
@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -46,11 +47,18 @@ provide '--keyring path/to/secret/keys' and '--key keyname'.
 
 If '--keyring' is not specified, Helm usually defaults to the public keyring
 unless your environment is otherwise configured.
+
+A chart may declare a command that generates files, such as CRDs, before it is
+packaged in its Chart.yaml "helm.sh/pre-package" annotation. The command runs
+in the chart directory only when '--run-pre-package-hook' is set.
 `
 
 func newPackageCmd(out io.Writer) *cobra.Command {
 	client := action.NewPackage()
 	valueOpts := &values.Options{}
+	var buildMetadata bool
+	buildInfo := &action.BuildMetadata{}
+	var buildTime string
 
 	cmd := &cobra.Command{
 		Use:   "package [CHART_PATH] [...]",
@@ -68,6 +76,17 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 					return errors.New("--keyring is required for signing a package")
 				}
 			}
+			if buildTime != "" {
+				t, err := time.Parse(time.RFC3339, buildTime)
+				if err != nil {
+					return errors.Wrap(err, "invalid --build-time")
+				}
+				buildInfo.Time = t
+			}
+			if buildMetadata || buildInfo.Commit != "" || buildInfo.Builder != "" || buildTime != "" {
+				client.BuildMetadata = buildInfo
+			}
+			client.PrePackageHookOutput = out
 			client.RepositoryConfig = settings.RepositoryConfig
 			client.RepositoryCache = settings.RepositoryCache
 			p := getter.All(settings)
@@ -118,6 +137,13 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&client.Version, "version", "", "set the version on the chart to this semver version")
 	f.StringVar(&client.AppVersion, "app-version", "", "set the appVersion on the chart to this version")
 	f.StringVarP(&client.Destination, "destination", "d", ".", "location to write the chart.")
+	f.StringVar(&client.SBOM, "sbom", "", "location of a software bill of materials (SPDX, CycloneDX) to publish alongside the package")
+	f.BoolVar(&client.RunPrePackageHook, "run-pre-package-hook", false, "run the command declared in the chart's \"helm.sh/pre-package\" annotation in the chart directory before packaging")
+	f.DurationVar(&client.PrePackageHookTimeout, "pre-package-hook-timeout", action.DefaultPrePackageHookTimeout, "time to wait for the pre-package command to complete")
+	f.BoolVar(&buildMetadata, "build-metadata", false, "record the build time, and the --build-commit and --builder if set, in the Chart.yaml annotations of the package")
+	f.StringVar(&buildInfo.Commit, "build-commit", "", "source revision the chart is built from, such as a git commit, recorded in the package. Implies --build-metadata")
+	f.StringVar(&buildInfo.Builder, "builder", "", "who or what builds the chart, such as a CI job, recorded in the package. Implies --build-metadata")
+	f.StringVar(&buildTime, "build-time", "", "build time recorded in the package, in RFC 3339 format. Defaults to the current time; set it for reproducible packages. Implies --build-metadata")
 	f.BoolVarP(&client.DependencyUpdate, "dependency-update", "u", false, `update dependencies from "Chart.yaml" to dir "charts/" before packaging`)
 
 	return cmd
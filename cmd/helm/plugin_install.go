@@ -18,6 +18,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -28,12 +29,30 @@ import (
 )
 
 type pluginInstallOptions struct {
-	source  string
-	version string
+	source       string
+	version      string
+	checksum     string
+	timeout      time.Duration
+	verify       bool
+	keyring      string
+	validateOnly bool
 }
 
 const pluginInstallDesc = `
 This command allows you to install a plugin from a url to a VCS repo or a local path.
+
+Git repositories can be named explicitly with a 'git+' prefix, and pinned to a
+tag, branch or commit with a '#ref' suffix when --version is not set:
+
+    $ helm plugin install git+https://github.com/org/helm-plugin.git#v1.2.0
+
+Plugin archives downloaded over HTTP can be verified against a SHA-256 digest
+with --checksum, either given directly or read from a published .sha256 file:
+
+    $ helm plugin install https://example.com/helm-plugin-1.2.0.tgz --checksum sha256:<digest>
+
+They can also be verified with --verify against the provenance file published
+next to them, such as helm-plugin-1.2.0.tgz.prov, signed by a key of --keyring.
 `
 
 func newPluginInstallCmd(out io.Writer) *cobra.Command {
@@ -60,6 +79,11 @@ func newPluginInstallCmd(out io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&o.version, "version", "", "specify a version constraint. If this is not specified, the latest version is installed")
+	cmd.Flags().BoolVar(&o.validateOnly, "validate-only", false, "check that the plugin is installable without installing it. Only supported for local directories")
+	cmd.Flags().StringVar(&o.checksum, "checksum", "", "verify a plugin archive against a SHA-256 digest, given as sha256:<hex> or as the URL of a .sha256 file")
+	cmd.Flags().BoolVar(&o.verify, "verify", false, "verify a plugin archive against its provenance file before installing it")
+	cmd.Flags().StringVar(&o.keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 0, "time to wait for the download of a plugin archive over HTTP (e.g. 30s). No timeout by default")
 	return cmd
 }
 
@@ -71,10 +95,23 @@ func (o *pluginInstallOptions) complete(args []string) error {
 func (o *pluginInstallOptions) run(out io.Writer) error {
 	installer.Debug = settings.Debug
 
-	i, err := installer.NewForSource(o.source, o.version)
+	i, err := installer.NewForSourceWithOptions(o.source, installer.SourceOptions{
+		Version:  o.version,
+		Checksum: o.checksum,
+		Timeout:  o.timeout,
+		Verify:   o.verify,
+		Keyring:  o.keyring,
+	})
 	if err != nil {
 		return err
 	}
+	if o.validateOnly {
+		if err := installer.Validate(i); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Plugin at %s is valid\n", o.source)
+		return nil
+	}
 	if err := installer.Install(i); err != nil {
 		return err
 	}
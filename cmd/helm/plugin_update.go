@@ -30,6 +30,7 @@ import (
 
 type pluginUpdateOptions struct {
 	names []string
+	all   bool
 }
 
 func newPluginUpdateCmd(out io.Writer) *cobra.Command {
@@ -49,13 +50,23 @@ func newPluginUpdateCmd(out io.Writer) *cobra.Command {
 			return o.complete(args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if o.all {
+				return o.runAll(out)
+			}
 			return o.run(out)
 		},
 	}
+	cmd.Flags().BoolVar(&o.all, "all", false, "update every installed plugin, skipping the ones linked from a local directory")
 	return cmd
 }
 
 func (o *pluginUpdateOptions) complete(args []string) error {
+	if o.all {
+		if len(args) != 0 {
+			return errors.New("plugin names cannot be given with --all")
+		}
+		return nil
+	}
 	if len(args) == 0 {
 		return errors.New("please provide plugin name to update")
 	}
@@ -89,6 +100,42 @@ func (o *pluginUpdateOptions) run(out io.Writer) error {
 	return nil
 }
 
+func (o *pluginUpdateOptions) runAll(out io.Writer) error {
+	installer.Debug = settings.Debug
+	debug("updating installed plugins from %s", settings.PluginsDirectory)
+	results, err := installer.UpdateAll(settings.PluginsDirectory)
+	if err != nil {
+		return err
+	}
+	var errorPlugins []string
+
+	for _, r := range results {
+		name := r.Plugin.Metadata.Name
+		switch r.Status {
+		case installer.UpdateStatusUpdated:
+			updatedPlugin, err := plugin.LoadDir(r.Plugin.Dir)
+			if err == nil {
+				err = runHook(updatedPlugin, plugin.Update)
+			}
+			if err != nil {
+				errorPlugins = append(errorPlugins, fmt.Sprintf("Failed to update plugin %s, got error (%v)", name, err))
+				continue
+			}
+			fmt.Fprintf(out, "Updated plugin: %s\n", name)
+		case installer.UpdateStatusUpToDate:
+			fmt.Fprintf(out, "Plugin is up to date: %s\n", name)
+		case installer.UpdateStatusSkipped:
+			fmt.Fprintf(out, "Skipped plugin: %s (%v)\n", name, r.Err)
+		default:
+			errorPlugins = append(errorPlugins, fmt.Sprintf("Failed to update plugin %s, got error (%v)", name, r.Err))
+		}
+	}
+	if len(errorPlugins) > 0 {
+		return errors.Errorf(strings.Join(errorPlugins, "\n"))
+	}
+	return nil
+}
+
 func updatePlugin(p *plugin.Plugin) error {
 	exactLocation, err := filepath.EvalSymlinks(p.Dir)
 	if err != nil {
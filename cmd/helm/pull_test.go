@@ -37,7 +37,7 @@ func TestPullCmd(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	ociSrv.Run(t)
+	ociSrv.Run(t, repotest.WithSBOM([]byte(`{"spdxVersion": "SPDX-2.2"}`)))
 
 	if err := srv.LinkIndices(); err != nil {
 		t.Fatal(err)
@@ -151,6 +151,11 @@ func TestPullCmd(t *testing.T) {
 			args:       fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart --version 0.1.0", ociSrv.RegistryURL),
 			expectFile: "./oci-dependent-chart-0.1.0.tgz",
 		},
+		{
+			name:       "Fetch OCI Chart with SBOM",
+			args:       fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart --version 0.1.0 --sbom", ociSrv.RegistryURL),
+			expectFile: "./oci-dependent-chart-0.1.0.tgz.sbom",
+		},
 		{
 			name:       "Fetch OCI Chart with untar",
 			args:       fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart --version 0.1.0 --untar", ociSrv.RegistryURL),
@@ -28,6 +28,7 @@ import (
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 const releaseTestHelp = `
@@ -57,11 +58,20 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client.Namespace = settings.Namespace()
 			notName := regexp.MustCompile(`^!\s?name=`)
-			for _, f := range filter {
-				if strings.HasPrefix(f, "name=") {
-					client.Filters["name"] = append(client.Filters["name"], strings.TrimPrefix(f, "name="))
-				} else if notName.MatchString(f) {
-					client.Filters["!name"] = append(client.Filters["!name"], notName.ReplaceAllLiteralString(f, ""))
+			for _, value := range filter {
+				// label selectors may contain commas, so they are kept whole
+				if strings.HasPrefix(value, "label=") {
+					client.Filters["label"] = append(client.Filters["label"], strings.TrimPrefix(value, "label="))
+					continue
+				}
+				for _, f := range strings.Split(value, ",") {
+					if strings.HasPrefix(f, "name=") {
+						client.Filters["name"] = append(client.Filters["name"], strings.TrimPrefix(f, "name="))
+					} else if strings.HasPrefix(f, "label=") {
+						client.Filters["label"] = append(client.Filters["label"], strings.TrimPrefix(f, "label="))
+					} else if notName.MatchString(f) {
+						client.Filters["!name"] = append(client.Filters["!name"], notName.ReplaceAllLiteralString(f, ""))
+					}
 				}
 			}
 			rel, runErr := client.Run(args[0])
@@ -76,6 +86,12 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 				return err
 			}
 
+			if len(filter) != 0 {
+				if err := printTestSelection(out, client, rel); err != nil {
+					return err
+				}
+			}
+
 			if outputLogs {
 				// Print a newline to stdout to separate the output
 				fmt.Fprintln(out)
@@ -91,7 +107,32 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 	f := cmd.Flags()
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.BoolVar(&outputLogs, "logs", false, "dump the logs from test pods (this runs after all tests are complete, but before any cleanup)")
-	f.StringSliceVar(&filter, "filter", []string{}, "specify tests by attribute (currently \"name\") using attribute=value syntax or '!attribute=value' to exclude a test (can specify multiple or separate values with commas: name=test1,name=test2)")
+	f.BoolVar(&client.PersistResults, "persist-results", false, "store the outcome of the test run, with the tail of the test pod logs, in the release so that 'helm status' and 'helm get all' show it")
+	f.StringArrayVar(&filter, "filter", []string{}, "specify tests by attribute (\"name\", which accepts glob patterns, or \"label\", which accepts a label selector such as 'label=app=web,tier in (db,cache)') using attribute=value syntax or '!name=value' to exclude a test (can specify multiple or separate names with commas: name=test1,name=test2)")
 
 	return cmd
 }
+
+// printTestSelection prints the names of the tests selected and skipped by the filters
+func printTestSelection(out io.Writer, client *action.ReleaseTesting, rel *release.Release) error {
+	selected, skipped, err := client.FilterTests(rel.Hooks)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "SELECTED TESTS: %s\n", strings.Join(testNames(selected), ", "))
+	fmt.Fprintf(out, "SKIPPED TESTS: %s\n", strings.Join(testNames(skipped), ", "))
+	return nil
+}
+
+func testNames(hooks []*release.Hook) []string {
+	var names []string
+	for _, h := range hooks {
+		for _, e := range h.Events {
+			if e == release.HookTest {
+				names = append(names, h.Name)
+				break
+			}
+		}
+	}
+	return names
+}
@@ -17,9 +17,76 @@ limitations under the License.
 package main
 
 import (
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
+func TestReleaseTestingFilter(t *testing.T) {
+	testHook := func(name, labels string) *release.Hook {
+		return &release.Hook{
+			Name:     name,
+			Kind:     "Pod",
+			Path:     "templates/tests/" + name + ".yaml",
+			Manifest: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: " + name + "\n  labels:\n" + labels,
+			Events:   []release.HookEvent{release.HookTest},
+		}
+	}
+
+	tests := []struct {
+		name string
+		cmd  string
+		want string
+	}{{
+		name: "label selector with several requirements",
+		cmd:  "test flummoxed-chickadee --filter 'label=app=web,tier in (db,cache)'",
+		want: "SELECTED TESTS: web-db-test, web-cache-test\nSKIPPED TESTS: web-frontend-test, api-db-test\n",
+	}, {
+		name: "comma separated names",
+		cmd:  "test flummoxed-chickadee --filter name=web-db-test,name=api-*",
+		want: "SELECTED TESTS: web-db-test, api-db-test\nSKIPPED TESTS: web-cache-test, web-frontend-test\n",
+	}, {
+		name: "comma separated excluded names",
+		cmd:  "test flummoxed-chickadee --filter '!name=web-db-test,!name=api-*'",
+		want: "SELECTED TESTS: web-cache-test, web-frontend-test\nSKIPPED TESTS: web-db-test, api-db-test\n",
+	}, {
+		name: "label selector and an excluded name",
+		cmd:  "test flummoxed-chickadee --filter 'label=tier in (db,frontend)' --filter '!name=api-*'",
+		want: "SELECTED TESTS: web-db-test, web-frontend-test\nSKIPPED TESTS: web-cache-test, api-db-test\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storageFixture()
+			store.Create(&release.Release{
+				Name:      "flummoxed-chickadee",
+				Namespace: "default",
+				Version:   1,
+				Info: &release.Info{
+					Status:       release.StatusDeployed,
+					LastDeployed: helmtime.Unix(1452902400, 0).UTC(),
+				},
+				Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "foo", Version: "0.1.0"}},
+				Hooks: []*release.Hook{
+					testHook("web-db-test", "    app: web\n    tier: db\n"),
+					testHook("web-cache-test", "    app: web\n    tier: cache\n"),
+					testHook("web-frontend-test", "    app: web\n    tier: frontend\n"),
+					testHook("api-db-test", "    app: api\n    tier: db\n"),
+				},
+			})
+			_, out, err := executeActionCommandC(store, tt.cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(out, tt.want) {
+				t.Errorf("expected output to end with\n%s\ngot\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
 func TestReleaseTestingFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "test", false)
 	checkFileCompletion(t, "test myrelease", false)
@@ -56,6 +56,9 @@ type repoAddOptions struct {
 	caFile                string
 	insecureSkipTLSverify bool
 
+	proxy   string
+	noProxy string
+
 	repoFile  string
 	repoCache string
 
@@ -85,11 +88,14 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.username, "username", "", "chart repository username")
 	f.StringVar(&o.password, "password", "", "chart repository password")
 	f.BoolVar(&o.forceUpdate, "force-update", false, "replace (overwrite) the repo if it already exists")
+	f.BoolVar(&o.forceUpdate, "upsert", false, "alias of --force-update")
 	f.BoolVar(&o.deprecatedNoUpdate, "no-update", false, "Ignored. Formerly, it would disabled forced updates. It is deprecated by force-update.")
 	f.StringVar(&o.certFile, "cert-file", "", "identify HTTPS client using this SSL certificate file")
 	f.StringVar(&o.keyFile, "key-file", "", "identify HTTPS client using this SSL key file")
 	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the repository")
+	f.StringVar(&o.proxy, "proxy", "", "proxy URL used to reach the repository instead of the HTTP_PROXY and HTTPS_PROXY environment variables")
+	f.StringVar(&o.noProxy, "no-proxy", "", "comma-separated list of hosts that bypass the proxy for the repository instead of the NO_PROXY environment variable")
 	f.BoolVar(&o.allowDeprecatedRepos, "allow-deprecated-repos", false, "by default, this command will not allow adding official repos that have been permanently deleted. This disables that behavior")
 
 	return cmd
@@ -153,6 +159,8 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		KeyFile:               o.keyFile,
 		CAFile:                o.caFile,
 		InsecureSkipTLSverify: o.insecureSkipTLSverify,
+		Proxy:                 o.proxy,
+		NoProxy:               o.noProxy,
 	}
 
 	// If the repo exists do one of two things:
@@ -184,11 +192,15 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		return errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", o.url)
 	}
 
-	f.Update(&c)
+	updated := f.Upsert(&c)
 
 	if err := f.WriteFile(o.repoFile, 0644); err != nil {
 		return err
 	}
+	if updated {
+		fmt.Fprintf(out, "%q has been updated in your repositories\n", o.name)
+		return nil
+	}
 	fmt.Fprintf(out, "%q has been added to your repositories\n", o.name)
 	return nil
 }
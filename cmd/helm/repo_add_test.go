@@ -17,10 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -69,7 +71,7 @@ func TestRepoAddCmd(t *testing.T) {
 		{
 			name:   "add repository second time",
 			cmd:    fmt.Sprintf("repo add test-name %s --repository-config %s --repository-cache %s --force-update", srv2.URL(), repoFile, tmpdir),
-			golden: "output/repo-add.txt",
+			golden: "output/repo-add-updated.txt",
 		},
 	}
 
@@ -130,6 +132,64 @@ func TestRepoAdd(t *testing.T) {
 	}
 }
 
+func TestRepoAddUpsert(t *testing.T) {
+	ts, err := repotest.NewTempServerWithCleanup(t, "testdata/testserver/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Stop()
+
+	rootDir := ensure.TempDir(t)
+	repoFile := filepath.Join(rootDir, "repositories.yaml")
+	os.Setenv(xdg.CacheHomeEnvVar, rootDir)
+
+	// --upsert is an alias of --force-update
+	cmd := newRepoAddCmd(ioutil.Discard)
+	if err := cmd.ParseFlags([]string{"--upsert"}); err != nil {
+		t.Fatal(err)
+	}
+	if v := cmd.Flags().Lookup("force-update").Value.String(); v != "true" {
+		t.Errorf("expected --upsert to set --force-update, got %s", v)
+	}
+
+	o := &repoAddOptions{
+		name:        "test-name",
+		url:         ts.URL(),
+		forceUpdate: true,
+		repoFile:    repoFile,
+	}
+
+	var out bytes.Buffer
+	if err := o.run(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "has been added") {
+		t.Errorf("expected repository to be added, got %q", out.String())
+	}
+
+	// same name, different credentials
+	out.Reset()
+	o.username = "user"
+	o.password = "pass"
+	if err := o.run(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "has been updated") {
+		t.Errorf("expected repository to be updated, got %q", out.String())
+	}
+
+	f, err := repo.LoadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Repositories) != 1 {
+		t.Fatalf("expected 1 repository, got %d", len(f.Repositories))
+	}
+	if entry := f.Get("test-name"); entry.Username != "user" || entry.Password != "pass" {
+		t.Errorf("expected credentials to be updated, got %+v", entry)
+	}
+}
+
 func TestRepoAddConcurrentGoRoutines(t *testing.T) {
 	const testName = "test-name"
 	repoFile := filepath.Join(ensure.TempDir(t), "repositories.yaml")
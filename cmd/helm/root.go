@@ -53,6 +53,9 @@ Environment variables:
 | $HELM_DEBUG                        | indicate whether or not Helm is running in Debug mode                             |
 | $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, postgres   |
 | $HELM_DRIVER_SQL_CONNECTION_STRING | set the connection string the SQL storage driver should use.                      |
+| $HELM_DRIVER_SQL_MAX_OPEN_CONNS    | set the maximum number of open connections of the SQL storage driver.             |
+| $HELM_DRIVER_SQL_MAX_IDLE_CONNS    | set the maximum number of idle connections of the SQL storage driver.             |
+| $HELM_DRIVER_SQL_CONN_MAX_LIFETIME | set how long the SQL storage driver may reuse a connection, such as "30m".        |
 | $HELM_MAX_HISTORY                  | set the maximum number of helm release history.                                   |
 | $HELM_NAMESPACE                    | set the namespace used for the helm operations.                                   |
 | $HELM_NO_PLUGINS                   | disable plugins. Set HELM_NO_PLUGINS=1 to disable plugins.                        |
@@ -173,6 +176,7 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 		newLintCmd(out),
 		newPackageCmd(out),
 		newRepoCmd(out),
+		newSchemaCmd(out),
 		newSearchCmd(out),
 		newVerifyCmd(out),
 
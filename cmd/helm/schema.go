@@ -0,0 +1,87 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const schemaDesc = `
+Manage the values schema of a chart.
+
+A chart may validate the values it is given against the JSON Schema in its
+'values.schema.json' file.
+`
+
+const schemaGenerateDesc = `
+Generate a draft 'values.schema.json' from the 'values.yaml' of a chart.
+
+The type of each value is inferred from its default. Maps become nested object
+schemas and the item type of an array is inferred from its first element. The
+result is a best-effort starting point that the chart author should refine,
+for instance by adding descriptions, constraints and required properties.
+
+An existing schema is not overwritten unless '--force' is given.
+`
+
+func newSchemaCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "manage a chart's values schema",
+		Long:  schemaDesc,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newSchemaGenerateCmd(out))
+
+	return cmd
+}
+
+func newSchemaGenerateCmd(out io.Writer) *cobra.Command {
+	client := action.NewSchemaGenerate()
+
+	cmd := &cobra.Command{
+		Use:   "generate CHART",
+		Short: "generate a draft values schema for a chart",
+		Long:  schemaGenerateDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			schemaPath, err := client.Run(chartpath)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Wrote draft values schema to %s\n", schemaPath)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.Force, "force", false, "overwrite an existing values.schema.json")
+
+	return cmd
+}
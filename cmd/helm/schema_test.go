@@ -0,0 +1,36 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestSchemaUsage(t *testing.T) {
+	cmd := newSchemaCmd(ioutil.Discard)
+	if use := cmd.UseLine(); use != "schema" {
+		t.Errorf("expected the usage of schema to be %q, got %q", "schema", use)
+	}
+	generate, _, err := cmd.Find([]string{"generate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if use := generate.UseLine(); use != "schema generate CHART [flags]" {
+		t.Errorf("expected the usage of schema generate to be %q, got %q", "schema generate CHART [flags]", use)
+	}
+}
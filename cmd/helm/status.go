@@ -142,6 +142,14 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		}
 	}
 
+	if tr := s.release.Info.TestResults; tr != nil {
+		outcome := "Failed"
+		if tr.Passed {
+			outcome = "Passed"
+		}
+		fmt.Fprintf(out, "LAST TEST RUN: %s (completed %s)\n", outcome, tr.CompletedAt.Format(time.ANSIC))
+	}
+
 	if s.debug {
 		fmt.Fprintln(out, "USER-SUPPLIED VALUES:")
 		err := output.EncodeYAML(out, s.release.Config)
@@ -68,6 +68,45 @@ func TestStatusCmd(t *testing.T) {
 			Status: release.StatusDeployed,
 			Notes:  "release notes",
 		}),
+	}, {
+		name:   "get status of a deployed release with a jsonpath",
+		cmd:    "status flummoxed-chickadee -o jsonpath={.info.status}",
+		golden: "output/status-jsonpath.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: release.StatusDeployed,
+		}),
+	}, {
+		name:   "get the hooks of a deployed release with a jsonpath",
+		cmd:    "status flummoxed-chickadee -o 'jsonpath={.hooks[*].name}'",
+		golden: "output/status-jsonpath-list.txt",
+		rels: releasesMockWithStatus(
+			&release.Info{
+				Status: release.StatusDeployed,
+			},
+			&release.Hook{
+				Name:   "pre-install-job",
+				Events: []release.HookEvent{release.HookPreInstall},
+			},
+			&release.Hook{
+				Name:   "test-job",
+				Events: []release.HookEvent{release.HookTest},
+			},
+		),
+	}, {
+		name:   "get status of a deployed release with a jsonpath to a missing field",
+		cmd:    "status flummoxed-chickadee -o jsonpath={.info.missing}",
+		golden: "output/status-jsonpath-missing.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: release.StatusDeployed,
+		}),
+		wantError: true,
+	}, {
+		name: "get status of a deployed release with an invalid jsonpath",
+		cmd:  "status flummoxed-chickadee -o jsonpath={.info",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: release.StatusDeployed,
+		}),
+		wantError: true,
 	}, {
 		name:   "get status of a deployed release with test suite",
 		cmd:    "status flummoxed-chickadee",
@@ -29,6 +29,7 @@ import (
 
 	"helm.sh/helm/v3/pkg/release"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
@@ -50,10 +51,13 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var validate bool
 	var includeCrds bool
 	var skipTests bool
+	var showProvenance bool
 	client := action.NewInstall(cfg)
 	valueOpts := &values.Options{}
 	var extraAPIs []string
 	var showFiles []string
+	var releaseRevision int
+	var releaseService string
 
 	cmd := &cobra.Command{
 		Use:   "template [NAME] [CHART]",
@@ -63,13 +67,29 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return compInstall(args, toComplete, client)
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if client.HooksOnly && client.DisableHooks {
+				return errors.New("--hooks-only and --no-hooks cannot be used together")
+			}
+			if cmd.Flags().Changed("release-revision") || cmd.Flags().Changed("release-service") {
+				client.ReleaseOptions = &chartutil.ReleaseOptions{
+					Revision:  releaseRevision,
+					IsInstall: !client.IsUpgrade,
+					IsUpgrade: client.IsUpgrade,
+					Service:   releaseService,
+				}
+				if !cmd.Flags().Changed("release-revision") && client.IsUpgrade {
+					client.ReleaseOptions.Revision = 2
+				}
+			}
 			client.DryRun = true
 			client.ReleaseName = "RELEASE-NAME"
 			client.Replace = true // Skip the name check
 			client.ClientOnly = !validate
 			client.APIVersions = chartutil.VersionSet(extraAPIs)
 			client.IncludeCRDs = includeCrds
+			client.SortByName = true
+			client.ReportValuesProvenance = showProvenance
 			rel, err := runInstall(args, client, valueOpts, out)
 
 			if err != nil && !settings.Debug {
@@ -83,7 +103,9 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			// we always want to print the YAML, even if it is not valid. The error is still returned afterwards.
 			if rel != nil {
 				var manifests bytes.Buffer
-				fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
+				if !client.HooksOnly {
+					fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
+				}
 				if !client.DisableHooks {
 					fileWritten := make(map[string]bool)
 					for _, m := range rel.Hooks {
@@ -139,9 +161,9 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 							// well as macOS/linux
 							manifestPath := strings.Join(manifestPathSplit, "/")
 
-							// if the filepath provided matches a manifest path in the
-							// chart, render that manifest
-							if matched, _ := filepath.Match(f, manifestPath); !matched {
+							// if the filepath or glob provided matches a manifest path
+							// in the chart, render that manifest
+							if !releaseutil.MatchTemplatePath(f, manifestPath) {
 								continue
 							}
 							manifestsToRender = append(manifestsToRender, manifest)
@@ -157,6 +179,10 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				} else {
 					fmt.Fprintf(out, "%s", manifests.String())
 				}
+
+				if showProvenance {
+					printValuesProvenance(out, client.ValuesProvenance)
+				}
 			}
 
 			return err
@@ -165,12 +191,16 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	addInstallFlags(cmd, f, client, valueOpts)
-	f.StringArrayVarP(&showFiles, "show-only", "s", []string{}, "only show manifests rendered from the given templates")
+	f.StringArrayVarP(&showFiles, "show-only", "s", []string{}, "only show manifests rendered from the given templates, which can be glob patterns where '**' matches any number of directories")
 	f.StringVar(&client.OutputDir, "output-dir", "", "writes the executed templates to files in output-dir instead of stdout")
 	f.BoolVar(&validate, "validate", false, "validate your manifests against the Kubernetes cluster you are currently pointing at. This is the same validation performed on an install")
 	f.BoolVar(&includeCrds, "include-crds", false, "include CRDs in the templated output")
 	f.BoolVar(&skipTests, "skip-tests", false, "skip tests from templated output")
+	f.BoolVar(&client.HooksOnly, "hooks-only", false, "only output the hooks, with their annotations, leaving out the other resources")
+	f.BoolVar(&showProvenance, "show-values-provenance", false, "append a comment listing the source of each rendered value: a chart default, a values file, a --set flag, or a parent global")
 	f.BoolVar(&client.IsUpgrade, "is-upgrade", false, "set .Release.IsUpgrade instead of .Release.IsInstall")
+	f.IntVar(&releaseRevision, "release-revision", 1, "set .Release.Revision (defaults to 2 with --is-upgrade). Cannot be used with --validate")
+	f.StringVar(&releaseService, "release-service", "Helm", "set .Release.Service. Cannot be used with --validate")
 	f.StringArrayVarP(&extraAPIs, "api-versions", "a", []string{}, "Kubernetes api versions used for Capabilities.APIVersions")
 	f.BoolVar(&client.UseReleaseName, "release-name", false, "use release name in the output-dir path.")
 	bindPostRenderFlag(cmd, &client.PostRenderer)
@@ -178,6 +208,21 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	return cmd
 }
 
+// printValuesProvenance writes the source of each rendered value as a YAML
+// comment, sorted by key.
+func printValuesProvenance(out io.Writer, provenance map[string]string) {
+	keys := make([]string, 0, len(provenance))
+	for k := range provenance {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintln(out, "---\n# Values provenance")
+	for _, k := range keys {
+		fmt.Fprintf(out, "# %s: %s\n", k, provenance[k])
+	}
+}
+
 func isTestHook(h *release.Hook) bool {
 	for _, e := range h.Events {
 		if e == release.HookTest {
@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -102,7 +103,17 @@ func TestTemplateCmd(t *testing.T) {
 			repeat: 10,
 		},
 		{
-			name:   "sorted output of manifests (order of filenames, then order of objects within each YAML file)",
+			name:   "template with show-only recursive glob",
+			cmd:    fmt.Sprintf("template '%s' --show-only '**/subdir/role*'", chartPath),
+			golden: "output/template-show-only-glob.txt",
+		},
+		{
+			name:      "template with show-only glob matching nothing",
+			cmd:       fmt.Sprintf("template '%s' --show-only '**/nosuch*.yaml'", chartPath),
+			wantError: true,
+		},
+		{
+			name:   "sorted output of manifests (install order of kinds, then names)",
 			cmd:    fmt.Sprintf("template '%s'", "testdata/testcharts/object-order"),
 			golden: "output/object-order.txt",
 			// Helm previously used random file order. Repeat the test so we
@@ -121,15 +132,53 @@ func TestTemplateCmd(t *testing.T) {
 			wantError: true,
 			golden:    "output/template-with-invalid-yaml-debug.txt",
 		},
+		{
+			name:   "template with release options",
+			cmd:    fmt.Sprintf("template release-opts '%s' --namespace spaced --release-service Tiller --release-revision 3 --is-upgrade", "testdata/testcharts/alpine"),
+			golden: "output/template-release-options.txt",
+		},
+		{
+			name:      "template with an upgrade on the first revision",
+			cmd:       fmt.Sprintf("template '%s' --release-revision 1 --is-upgrade", "testdata/testcharts/alpine"),
+			wantError: true,
+		},
 		{
 			name:   "template skip-tests",
 			cmd:    fmt.Sprintf(`template '%s' --skip-tests`, chartPath),
 			golden: "output/template-skip-tests.txt",
 		},
+		{
+			name:   "template hooks-only",
+			cmd:    fmt.Sprintf(`template '%s' --hooks-only`, chartPath),
+			golden: "output/template-hooks-only.txt",
+		},
+		{
+			name:      "template hooks-only with no-hooks",
+			cmd:       fmt.Sprintf(`template '%s' --hooks-only --no-hooks`, chartPath),
+			wantError: true,
+		},
 	}
 	runTestCmd(t, tests)
 }
 
+func TestTemplateReleaseOptionsDefaultName(t *testing.T) {
+	chart := "testdata/testcharts/alpine"
+	_, plain, err := executeActionCommand(fmt.Sprintf("template '%s'", chart))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, withOptions, err := executeActionCommand(fmt.Sprintf("template '%s' --release-revision 3", chart))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(plain, `name: "RELEASE-NAME-my-alpine"`) {
+		t.Errorf("expected the default release name, got:\n%s", plain)
+	}
+	if withOptions != plain {
+		t.Errorf("expected the release options not to change the default release name, got:\n%s", withOptions)
+	}
+}
+
 func TestTemplateVersionCompletion(t *testing.T) {
 	repoFile := "testdata/helmhome/helm/repositories.yaml"
 	repoCache := "testdata/helmhome/helm/repository"
@@ -31,6 +31,7 @@ import (
 	"helm.sh/helm/v3/pkg/cli/output"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
@@ -102,15 +103,22 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					instClient.DisableHooks = client.DisableHooks
 					instClient.SkipCRDs = client.SkipCRDs
 					instClient.Timeout = client.Timeout
+					instClient.ApplyTimeout = client.ApplyTimeout
 					instClient.Wait = client.Wait
 					instClient.WaitForJobs = client.WaitForJobs
+					instClient.WaitRetries = client.WaitRetries
+					instClient.WaitSkipKinds = client.WaitSkipKinds
 					instClient.Devel = client.Devel
 					instClient.Namespace = client.Namespace
 					instClient.Atomic = client.Atomic
 					instClient.PostRenderer = client.PostRenderer
 					instClient.DisableOpenAPIValidation = client.DisableOpenAPIValidation
 					instClient.SubNotes = client.SubNotes
+					instClient.StrictSchema = client.StrictSchema
+					instClient.RenderValueTemplates = client.RenderValueTemplates
+					instClient.AllowDuplicateResources = client.AllowDuplicateResources
 					instClient.Description = client.Description
+					instClient.ReleaseAnnotations = client.ReleaseAnnotations
 
 					rel, err := runInstall(args, instClient, valueOpts, out)
 					if err != nil {
@@ -136,6 +144,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			client.UnsetValues = valueOpts.UnsetValues
 
 			// Check chart dependencies to make sure all are present in /charts
 			ch, err := loader.Load(chartPath)
@@ -175,17 +184,27 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Force, "force", false, "force resource updates through a replacement strategy")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "disable pre/post upgrade hooks")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the upgrade process will not validate rendered templates against the Kubernetes OpenAPI Schema")
+	f.BoolVar(&client.ApplySetPrune, "apply-set-prune", false, "if set, label release resources as an apply set and delete any labelled resource of the same kinds that is no longer part of the release")
+	f.BoolVar(&client.WaitForPrunedDeletion, "wait-for-pruned", false, "if set, delete the resources removed from the release and wait until they are gone, for as long as --timeout, before applying the new manifest")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed when an upgrade is performed with install flag enabled. By default, CRDs are installed if not already present, when an upgrade is performed with install flag enabled")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	f.DurationVar(&client.ApplyTimeout, "apply-timeout", 0, "time to wait for the Kubernetes API to create or update any single resource, apart from --timeout. 0 means no limit")
 	f.BoolVar(&client.ResetValues, "reset-values", false, "when upgrading, reset the values to the ones built into the chart")
 	f.BoolVar(&client.ReuseValues, "reuse-values", false, "when upgrading, reuse the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' is specified, this is ignored")
 	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
+	f.IntVar(&client.WaitRetries, "wait-retries", kube.DefaultWaitRetries, "number of consecutive transient Kubernetes API errors tolerated while waiting with --wait")
+	f.StringSliceVar(&client.WaitSkipKinds, "wait-skip-kinds", []string{}, "kinds of resources (e.g. Job) not waited for with --wait")
 	f.BoolVar(&client.Atomic, "atomic", false, "if set, upgrade process rolls back changes made in case of failed upgrade. The --wait flag will be set automatically if --atomic is used")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this upgrade when upgrade fails")
+	f.BoolVar(&client.RollbackFailedResources, "rollback-failed-resources", false, "if applying the upgrade fails, roll back only the resources that failed to their previous revision and keep the others. The release is then left running a mix of both revisions. Cannot be used with --atomic")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
+	f.BoolVar(&client.StrictSchema, "strict-schema", false, "reject values not declared by the chart's values.schema.json, even where it allows additional properties")
+	f.BoolVar(&client.RenderValueTemplates, "render-value-templates", false, "render values that contain templates, such as \"{{ .Values.host }}\", before rendering the chart")
+	f.BoolVar(&client.AllowDuplicateResources, "allow-duplicate-resources", false, "allow the chart to render the same resource more than once, letting the last one win")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
+	f.StringToStringVar(&client.ReleaseAnnotations, "release-annotations", nil, "annotations stored with the release record, such as the commit that produced it (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 	addValueOptionsFlags(f, valueOpts)
 	bindOutputFlag(cmd, &outfmt)
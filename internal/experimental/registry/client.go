@@ -19,11 +19,15 @@ package registry // import "helm.sh/helm/v3/internal/experimental/registry"
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"sort"
+	"sync/atomic"
+	"time"
 
 	auth "github.com/deislabs/oras/pkg/auth/docker"
 	"github.com/deislabs/oras/pkg/content"
@@ -41,6 +45,14 @@ const (
 	CredentialsFileBasename = "config.json"
 )
 
+// DefaultTimeouts are the timeouts of registry operations unless overridden
+// with ClientOptTimeouts.
+var DefaultTimeouts = Timeouts{
+	Connect:   30 * time.Second,
+	Read:      time.Minute,
+	Operation: 10 * time.Minute,
+}
+
 type (
 	// Client works with OCI-compliant registries and local Helm chart cache
 	Client struct {
@@ -51,13 +63,28 @@ type (
 		authorizer      *Authorizer
 		resolver        *Resolver
 		cache           *Cache
+		timeouts        Timeouts
+	}
+
+	// Timeouts bounds the time registry operations may take. A zero value
+	// disables the corresponding timeout.
+	Timeouts struct {
+		// Connect bounds establishing a connection to a registry
+		Connect time.Duration
+		// Read bounds waiting for a registry to respond to a request, and
+		// for each read of the response body
+		Read time.Duration
+		// Operation bounds a whole operation, such as pushing or pulling a
+		// chart, including all of its requests and transfers
+		Operation time.Duration
 	}
 )
 
 // NewClient returns a new registry client with config
 func NewClient(opts ...ClientOption) (*Client, error) {
 	client := &Client{
-		out: ioutil.Discard,
+		out:      ioutil.Discard,
+		timeouts: DefaultTimeouts,
 	}
 	for _, opt := range opts {
 		opt(client)
@@ -76,7 +103,7 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		}
 	}
 	if client.resolver == nil {
-		resolver, err := client.authorizer.Resolver(context.Background(), http.DefaultClient, false)
+		resolver, err := client.authorizer.Resolver(context.Background(), client.httpClient(nil), false)
 		if err != nil {
 			return nil, err
 		}
@@ -98,9 +125,91 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	return client, nil
 }
 
+// httpClient returns an HTTP client applying the connect and read timeouts,
+// and tlsConfig unless nil.
+func (c *Client) httpClient(tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   c.timeouts.Connect,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.ResponseHeaderTimeout = c.timeouts.Read
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	if c.timeouts.Read <= 0 {
+		return &http.Client{Transport: transport}
+	}
+	return &http.Client{Transport: &readTimeoutTransport{base: transport, timeout: c.timeouts.Read}}
+}
+
+// readTimeoutTransport aborts a request when a read of its response body
+// does not complete within timeout, as ResponseHeaderTimeout only bounds the
+// wait for the response headers.
+type readTimeoutTransport struct {
+	base    *http.Transport
+	timeout time.Duration
+}
+
+func (t *readTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	body := &readTimeoutBody{body: resp.Body, timeout: t.timeout, cancel: cancel}
+	body.timer = time.AfterFunc(t.timeout, body.expire)
+	body.timer.Stop()
+	resp.Body = body
+	return resp, nil
+}
+
+// readTimeoutBody is a response body cancelling its request when a read does
+// not complete within timeout.
+type readTimeoutBody struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+	expired int32
+}
+
+func (b *readTimeoutBody) expire() {
+	atomic.StoreInt32(&b.expired, 1)
+	b.cancel()
+}
+
+func (b *readTimeoutBody) Read(p []byte) (int, error) {
+	b.timer.Reset(b.timeout)
+	n, err := b.body.Read(p)
+	b.timer.Stop()
+	if err != nil && atomic.LoadInt32(&b.expired) == 1 {
+		return n, errors.Wrapf(context.DeadlineExceeded, "no data received from the registry within %s", b.timeout)
+	}
+	return n, err
+}
+
+func (b *readTimeoutBody) Close() error {
+	b.timer.Stop()
+	b.cancel()
+	return b.body.Close()
+}
+
+// context returns a fresh context for an operation, bounded by the operation
+// timeout.
+func (c *Client) context() (context.Context, context.CancelFunc) {
+	if c.timeouts.Operation <= 0 {
+		return context.WithCancel(ctx(c.out, c.debug))
+	}
+	return context.WithTimeout(ctx(c.out, c.debug), c.timeouts.Operation)
+}
+
 // Login logs into a registry
 func (c *Client) Login(hostname string, username string, password string, insecure bool) error {
-	err := c.authorizer.Login(ctx(c.out, c.debug), hostname, username, password, insecure)
+	ctx, cancel := c.context()
+	defer cancel()
+	err := c.authorizer.Login(ctx, hostname, username, password, insecure)
 	if err != nil {
 		return err
 	}
@@ -110,7 +219,9 @@ func (c *Client) Login(hostname string, username string, password string, insecu
 
 // Logout logs out of a registry
 func (c *Client) Logout(hostname string) error {
-	err := c.authorizer.Logout(ctx(c.out, c.debug), hostname)
+	ctx, cancel := c.context()
+	defer cancel()
+	err := c.authorizer.Logout(ctx, hostname)
 	if err != nil {
 		return err
 	}
@@ -130,7 +241,9 @@ func (c *Client) PushChart(ref *Reference) error {
 	fmt.Fprintf(c.out, "The push refers to repository [%s]\n", r.Repo)
 	c.printCacheRefSummary(r)
 	layers := []ocispec.Descriptor{*r.ContentLayer}
-	_, err = oras.Push(ctx(c.out, c.debug), c.resolver, r.Name, c.cache.Provider(), layers,
+	ctx, cancel := c.context()
+	defer cancel()
+	_, err = oras.Push(ctx, c.resolver, r.Name, c.cache.Provider(), layers,
 		oras.WithConfig(*r.Config), oras.WithNameValidation(nil))
 	if err != nil {
 		return err
@@ -158,7 +271,9 @@ func (c *Client) PullChart(ref *Reference) (*bytes.Buffer, error) {
 	store := content.NewMemoryStore()
 	fullname := ref.FullName()
 	_ = fullname
-	_, layerDescriptors, err := oras.Pull(ctx(c.out, c.debug), c.resolver, ref.FullName(), store,
+	ctx, cancel := c.context()
+	defer cancel()
+	_, layerDescriptors, err := oras.Pull(ctx, c.resolver, ref.FullName(), store,
 		oras.WithPullEmptyNameAllowed(),
 		oras.WithAllowedMediaTypes(KnownMediaTypes()))
 	if err != nil {
@@ -208,7 +323,9 @@ func (c *Client) PullChartToCache(ref *Reference) error {
 		return err
 	}
 	fmt.Fprintf(c.out, "%s: Pulling from %s\n", ref.Tag, ref.Repo)
-	manifest, _, err := oras.Pull(ctx(c.out, c.debug), c.resolver, ref.FullName(), c.cache.Ingester(),
+	ctx, cancel := c.context()
+	defer cancel()
+	manifest, _, err := oras.Pull(ctx, c.resolver, ref.FullName(), c.cache.Ingester(),
 		oras.WithPullEmptyNameAllowed(),
 		oras.WithAllowedMediaTypes(KnownMediaTypes()),
 		oras.WithContentProvideIngester(c.cache.ProvideIngester()))
@@ -67,3 +67,11 @@ func ClientOptCredentialsFile(credentialsFile string) ClientOption {
 		client.credentialsFile = credentialsFile
 	}
 }
+
+// ClientOptTimeouts returns a function that sets the timeouts of registry operations on a client options set.
+// The connect and read timeouts only apply when no resolver is set with ClientOptResolver.
+func ClientOptTimeouts(timeouts Timeouts) ClientOption {
+	return func(client *Client) {
+		client.timeouts = timeouts
+	}
+}
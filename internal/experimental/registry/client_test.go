@@ -94,6 +94,7 @@ func (suite *RegistryClientTestSuite) SetupSuite() {
 			Resolver: resolver,
 		}),
 		ClientOptCache(cache),
+		ClientOptCredentialsFile(credentialsFile),
 	)
 	suite.Nil(err, "no error creating registry client")
 
@@ -143,6 +144,13 @@ func (suite *RegistryClientTestSuite) Test_0_Login() {
 
 	err = suite.RegistryClient.Login(suite.DockerRegistryHost, testUsername, testPassword, true)
 	suite.Nil(err, "no error logging into registry with good credentials, insecure mode")
+
+	creds, err := suite.RegistryClient.Credentials()
+	suite.Nil(err, "no error listing credentials")
+	suite.Len(creds, 1)
+	suite.Equal(suite.DockerRegistryHost, creds[0].Hostname)
+	suite.Equal(testUsername, creds[0].Username)
+	suite.Equal(redactedSecret, creds[0].Password)
 }
 
 func (suite *RegistryClientTestSuite) Test_1_SaveChart() {
@@ -211,6 +219,30 @@ func (suite *RegistryClientTestSuite) Test_4_PullChart() {
 	suite.Nil(err)
 }
 
+func (suite *RegistryClientTestSuite) Test_4_SBOM() {
+
+	// non-existent ref
+	ref, err := ParseReference(fmt.Sprintf("%s/testrepo/whodis:9.9.9", suite.DockerRegistryHost))
+	suite.Nil(err)
+	err = suite.RegistryClient.PushSBOM(ref, []byte(`{"spdxVersion": "SPDX-2.2"}`))
+	suite.NotNil(err)
+
+	// existing ref, without an SBOM
+	ref, err = ParseReference(fmt.Sprintf("%s/testrepo/testchart:1.2.3", suite.DockerRegistryHost))
+	suite.Nil(err)
+	_, err = suite.RegistryClient.PullSBOM(ref)
+	suite.NotNil(err)
+
+	// the last SBOM pushed is pulled
+	for _, sbom := range []string{`{"spdxVersion": "SPDX-2.2"}`, `{"bomFormat": "CycloneDX"}`} {
+		err = suite.RegistryClient.PushSBOM(ref, []byte(sbom))
+		suite.Nil(err)
+		b, err := suite.RegistryClient.PullSBOM(ref)
+		suite.Nil(err)
+		suite.Equal(sbom, string(b))
+	}
+}
+
 func (suite *RegistryClientTestSuite) Test_5_PrintChartTable() {
 	err := suite.RegistryClient.PrintChartTable()
 	suite.Nil(err)
@@ -237,6 +269,10 @@ func (suite *RegistryClientTestSuite) Test_7_Logout() {
 
 	err = suite.RegistryClient.Logout(suite.DockerRegistryHost)
 	suite.Nil(err, "no error logging out of registry")
+
+	creds, err := suite.RegistryClient.Credentials()
+	suite.Nil(err, "no error listing credentials")
+	suite.Empty(creds, "no credentials after logout")
 }
 
 func (suite *RegistryClientTestSuite) Test_8_ManInTheMiddle() {
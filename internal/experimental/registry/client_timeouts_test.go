@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+func TestClientTimeoutsAbortStalledOperation(t *testing.T) {
+	stop := make(chan struct{})
+	var manifestReads int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a registry that stalls in the middle of sending the manifest
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		if r.Method == http.MethodHead {
+			return
+		}
+		atomic.AddInt32(&manifestReads, 1)
+		w.Write([]byte(`{"schemaVersion":`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(stop)
+
+	// The registry is only reached over HTTPS for hosts other than localhost,
+	// so the reference names example.com, which the certificate of the test
+	// registry is valid for, and connections to it are routed to the test
+	// registry.
+	ref, err := ParseReference("example.com/testrepo/testchart:1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	dialTestRegistry := func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+
+	tests := map[string]Timeouts{
+		"read":      {Read: 50 * time.Millisecond},
+		"operation": {Operation: 50 * time.Millisecond},
+	}
+	for name, timeouts := range tests {
+		t.Run(name, func(t *testing.T) {
+			atomic.StoreInt32(&manifestReads, 0)
+			dir, err := ioutil.TempDir("", "helm-registry-timeouts-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			cache, err := NewCache(CacheOptRoot(filepath.Join(dir, CacheRootDir)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, err := NewClient(
+				ClientOptCache(cache),
+				ClientOptCredentialsFile(filepath.Join(dir, CredentialsFileBasename)),
+				ClientOptTimeouts(timeouts),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpClient := client.httpClient(tlsConfig)
+			if rt, ok := httpClient.Transport.(*readTimeoutTransport); ok {
+				rt.base.DialContext = dialTestRegistry
+			} else {
+				httpClient.Transport.(*http.Transport).DialContext = dialTestRegistry
+			}
+			resolver, err := client.authorizer.Resolver(context.Background(), httpClient, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.resolver = &Resolver{Resolver: resolver}
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := client.PullChart(ref)
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expected pulling from a stalled registry to time out, got %v", err)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("pulling from a stalled registry was not aborted")
+			}
+			if n := atomic.LoadInt32(&manifestReads); n == 0 {
+				t.Error("expected the manifest to be requested from the registry")
+			}
+		})
+	}
+}
+
+func TestNewClientDefaultTimeouts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-registry-timeouts-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := NewCache(CacheOptRoot(filepath.Join(dir, CacheRootDir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(
+		ClientOptCache(cache),
+		ClientOptCredentialsFile(filepath.Join(dir, CredentialsFileBasename)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.timeouts != DefaultTimeouts {
+		t.Errorf("expected the default timeouts %+v, got %+v", DefaultTimeouts, client.timeouts)
+	}
+}
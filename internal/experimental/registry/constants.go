@@ -31,3 +31,14 @@ func KnownMediaTypes() []string {
 		HelmChartContentLayerMediaType,
 	}
 }
+
+const (
+	// HelmChartSBOMMediaType is the reserved media type for a software bill of
+	// materials attached to a Helm chart, both as the artifact type of the
+	// referrer manifest and as the media type of its layer
+	HelmChartSBOMMediaType = "application/vnd.cncf.helm.chart.sbom.v1"
+
+	// emptyConfigMediaType is the media type of the empty config of
+	// artifacts that are not images
+	emptyConfigMediaType = "application/vnd.oci.empty.v1+json"
+)
@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v3/internal/experimental/registry"
+
+import (
+	"os"
+	"sort"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/pkg/errors"
+)
+
+// redactedSecret replaces any stored password or identity token
+const redactedSecret = "********"
+
+type (
+	// Credential is a redacted view of the credentials stored for a registry
+	Credential struct {
+		Hostname string
+		Username string
+		// Password is redactedSecret when a password or identity token is stored, empty otherwise
+		Password string
+	}
+)
+
+// Credentials lists the registry credentials stored in the credentials file.
+//
+// Passwords and identity tokens are never returned; only whether one is stored.
+func (c *Client) Credentials() ([]*Credential, error) {
+	cfg, err := c.credentialsConfig()
+	if err != nil {
+		return nil, err
+	}
+	auths, err := cfg.GetAllCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := make([]*Credential, 0, len(auths))
+	for hostname, a := range auths {
+		cred := &Credential{
+			Hostname: hostname,
+			Username: a.Username,
+		}
+		if a.Password != "" || a.IdentityToken != "" {
+			cred.Password = redactedSecret
+		}
+		creds = append(creds, cred)
+	}
+	sort.Slice(creds, func(i, j int) bool {
+		return creds[i].Hostname < creds[j].Hostname
+	})
+	return creds, nil
+}
+
+// credentialsConfig loads the credentials file. A missing file holds no
+// credentials.
+func (c *Client) credentialsConfig() (*configfile.ConfigFile, error) {
+	cfg := configfile.New(c.credentialsFile)
+	f, err := os.Open(c.credentialsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := cfg.LoadFromReader(f); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse credentials file %s", c.credentialsFile)
+	}
+	// mirror the credentials store selection used on login
+	if !cfg.ContainsAuth() {
+		cfg.CredentialsStore = credentials.DetectDefaultStore(cfg.CredentialsStore)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	auth "github.com/deislabs/oras/pkg/auth/docker"
+)
+
+// userpass is base64("myuser:mypass")
+const testCredentialsFile = `{
+	"auths": {
+		"registry.example.com": {"auth": "bXl1c2VyOm15cGFzcw=="},
+		"other.example.com:5000": {"auth": "bXl1c2VyOm15cGFzcw=="}
+	}
+}`
+
+func TestCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-registry-credentials-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, CredentialsFileBasename)
+
+	// a missing credentials file means no credentials
+	creds, err := newTestCredentialsClient(t, dir, credentialsFile).Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(creds) != 0 {
+		t.Fatalf("expected no credentials, got %d", len(creds))
+	}
+
+	if err := ioutil.WriteFile(credentialsFile, []byte(testCredentialsFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	client := newTestCredentialsClient(t, dir, credentialsFile)
+	creds, err = client.Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(creds) != 2 {
+		t.Fatalf("expected 2 credentials, got %d", len(creds))
+	}
+	// sorted by hostname
+	if creds[0].Hostname != "other.example.com:5000" || creds[1].Hostname != "registry.example.com" {
+		t.Errorf("unexpected credential order: %s, %s", creds[0].Hostname, creds[1].Hostname)
+	}
+	for _, c := range creds {
+		if c.Username != "myuser" {
+			t.Errorf("expected username myuser for %s, got %q", c.Hostname, c.Username)
+		}
+		if c.Password != redactedSecret {
+			t.Errorf("expected redacted password for %s, got %q", c.Hostname, c.Password)
+		}
+	}
+
+	if err := client.Logout("registry.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	creds, err = client.Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(creds) != 1 || creds[0].Hostname != "other.example.com:5000" {
+		t.Errorf("expected only other.example.com:5000 after logout, got %v", creds)
+	}
+}
+
+func newTestCredentialsClient(t *testing.T, dir, credentialsFile string) *Client {
+	t.Helper()
+	authClient, err := auth.NewClient(credentialsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewCache(CacheOptRoot(filepath.Join(dir, CacheRootDir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(
+		ClientOptAuthorizer(&Authorizer{Client: authClient}),
+		ClientOptCache(cache),
+		ClientOptCredentialsFile(credentialsFile),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
@@ -0,0 +1,214 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v3/internal/experimental/registry"
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrRegistryUnreachable is matched by the errors of Ping when no
+	// connection to the registry could be established.
+	ErrRegistryUnreachable = errors.New("registry is unreachable")
+	// ErrRegistryUnauthorized is matched by the errors of Ping when the
+	// registry requires credentials and none are stored, or it rejects them.
+	ErrRegistryUnauthorized = errors.New("registry authentication failed")
+)
+
+// challengeParam matches the key="value" parameters of a WWW-Authenticate header
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// pingError is one of the Ping sentinel errors along with its cause.
+type pingError struct {
+	kind  error
+	cause string
+}
+
+func (e *pingError) Error() string {
+	return e.kind.Error() + ": " + e.cause
+}
+
+func (e *pingError) Unwrap() error {
+	return e.kind
+}
+
+// Ping checks that the registry at hostname answers on its /v2/ endpoint and,
+// when it requires authentication, that it accepts the credentials stored for
+// it. Basic and bearer token authentication are supported.
+//
+// The registry is reached over HTTPS. If insecure is set, its certificate is
+// not verified and plain HTTP is tried when HTTPS fails.
+//
+// Use errors.Is with ErrRegistryUnreachable and ErrRegistryUnauthorized to
+// tell network failures from authentication failures.
+func (c *Client) Ping(hostname string, insecure bool) error {
+	var tlsConfig *tls.Config
+	if insecure {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := c.httpClient(tlsConfig)
+	ctx, cancel := c.context()
+	defer cancel()
+
+	endpoint := "https://" + hostname + "/v2/"
+	resp, _, err := pingGet(ctx, client, endpoint, nil)
+	if err != nil && insecure {
+		endpoint = "http://" + hostname + "/v2/"
+		resp, _, err = pingGet(ctx, client, endpoint, nil)
+	}
+	if err != nil {
+		return &pingError{kind: ErrRegistryUnreachable, cause: err.Error()}
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+	default:
+		return errors.Errorf("unexpected status %q from %s", resp.Status, endpoint)
+	}
+
+	cfg, err := c.credentialsConfig()
+	if err != nil {
+		return err
+	}
+	authConfig, err := cfg.GetAuthConfig(hostname)
+	if err != nil {
+		return err
+	}
+	if authConfig.Username == "" && authConfig.Password == "" {
+		return &pingError{kind: ErrRegistryUnauthorized, cause: "no credentials stored for " + hostname}
+	}
+	basicAuth := func(req *http.Request) {
+		req.SetBasicAuth(authConfig.Username, authConfig.Password)
+	}
+
+	scheme, params := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	var authorize func(*http.Request)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		authorize = basicAuth
+	case "bearer":
+		token, err := fetchToken(ctx, client, params, basicAuth)
+		if err != nil {
+			return err
+		}
+		authorize = func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	default:
+		return errors.Errorf("unsupported authentication scheme %q required by %s", scheme, hostname)
+	}
+
+	resp, _, err = pingGet(ctx, client, endpoint, authorize)
+	if err != nil {
+		return &pingError{kind: ErrRegistryUnreachable, cause: err.Error()}
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &pingError{kind: ErrRegistryUnauthorized, cause: "credentials rejected by " + hostname}
+	default:
+		return errors.Errorf("unexpected status %q from %s", resp.Status, endpoint)
+	}
+}
+
+// fetchToken requests a bearer token from the authorization server named in
+// the challenge parameters.
+func fetchToken(ctx context.Context, client *http.Client, params map[string]string, authorize func(*http.Request)) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", errors.Errorf("invalid token realm %q", params["realm"])
+	}
+	if service := params["service"]; service != "" {
+		q := realm.Query()
+		q.Set("service", service)
+		realm.RawQuery = q.Encode()
+	}
+
+	resp, body, err := pingGet(ctx, client, realm.String(), authorize)
+	if err != nil {
+		return "", &pingError{kind: ErrRegistryUnreachable, cause: err.Error()}
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", &pingError{kind: ErrRegistryUnauthorized, cause: "credentials rejected by " + realm.Host}
+	default:
+		return "", errors.Errorf("unexpected status %q from %s", resp.Status, realm.Host)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", errors.Wrapf(err, "invalid token response from %s", realm.Host)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", errors.Errorf("no token returned by %s", realm.Host)
+}
+
+// pingGet sends a GET request, authorized by authorize when it is set, and
+// returns the response along with its body.
+func pingGet(ctx context.Context, client *http.Client, endpoint string, authorize func(*http.Request)) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if authorize != nil {
+		authorize(req)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and
+// parameters.
+func parseChallenge(header string) (string, map[string]string) {
+	scheme := header
+	if i := strings.Index(header, " "); i >= 0 {
+		scheme = header[:i]
+	}
+	params := make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(header, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	return scheme, params
+}
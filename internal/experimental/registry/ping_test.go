@@ -0,0 +1,137 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// newFakeRegistry serves the /v2/ endpoint of a registry protected by the
+// given authentication scheme ("" for none) for the user myuser:mypass.
+func newFakeRegistry(scheme string) *httptest.Server {
+	const token = "good-token"
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		validUser := ok && user == "myuser" && pass == "mypass"
+
+		switch {
+		case r.URL.Path == "/token" && scheme == "bearer":
+			if !validUser || r.URL.Query().Get("service") != "fake-registry" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token": %q}`, token)
+		case r.URL.Path != "/v2/":
+			w.WriteHeader(http.StatusNotFound)
+		case scheme == "basic" && !validUser:
+			w.Header().Set("WWW-Authenticate", `Basic realm="fake-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case scheme == "bearer" && r.Header.Get("Authorization") != "Bearer "+token:
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="https://%s/token",service="fake-registry"`, r.Host))
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+}
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name     string
+		scheme   string
+		password string
+		expect   error
+	}{
+		{name: "anonymous access", scheme: ""},
+		{name: "basic auth", scheme: "basic", password: "mypass"},
+		{name: "basic auth rejected", scheme: "basic", password: "wrong", expect: ErrRegistryUnauthorized},
+		{name: "basic auth without credentials", scheme: "basic", expect: ErrRegistryUnauthorized},
+		{name: "bearer token", scheme: "bearer", password: "mypass"},
+		{name: "bearer token rejected", scheme: "bearer", password: "wrong", expect: ErrRegistryUnauthorized},
+		{name: "bearer token without credentials", scheme: "bearer", expect: ErrRegistryUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeRegistry(tt.scheme)
+			defer srv.Close()
+			u, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir, err := ioutil.TempDir("", "helm-registry-ping-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			credentialsFile := filepath.Join(dir, CredentialsFileBasename)
+			if tt.password != "" {
+				auths := fmt.Sprintf(`{"auths": {%q: {"username": "myuser", "password": %q}}}`, u.Host, tt.password)
+				if err := ioutil.WriteFile(credentialsFile, []byte(auths), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			client := newTestCredentialsClient(t, dir, credentialsFile)
+
+			err = client.Ping(u.Host, true)
+			if tt.expect == nil {
+				if err != nil {
+					t.Errorf("expected the registry to be reachable, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.expect) {
+				t.Errorf("expected %v, got %v", tt.expect, err)
+			}
+		})
+	}
+}
+
+func TestPingUnreachable(t *testing.T) {
+	srv := newFakeRegistry("")
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nothing listens on the address anymore
+	srv.Close()
+
+	dir, err := ioutil.TempDir("", "helm-registry-ping-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	client := newTestCredentialsClient(t, dir, filepath.Join(dir, CredentialsFileBasename))
+
+	err = client.Ping(u.Host, true)
+	if !errors.Is(err, ErrRegistryUnreachable) {
+		t.Errorf("expected %v, got %v", ErrRegistryUnreachable, err)
+	}
+	if errors.Is(err, ErrRegistryUnauthorized) {
+		t.Errorf("expected an unreachable registry not to report an authentication failure")
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v3/internal/experimental/registry"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/containerd/containerd/errdefs"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+type (
+	// referrerManifest is an OCI image manifest referring to another manifest,
+	// its subject. The subject and artifactType fields are not yet part of
+	// the image-spec types.
+	referrerManifest struct {
+		specs.Versioned
+		MediaType    string               `json:"mediaType"`
+		ArtifactType string               `json:"artifactType"`
+		Config       ocispec.Descriptor   `json:"config"`
+		Layers       []ocispec.Descriptor `json:"layers"`
+		Subject      *ocispec.Descriptor  `json:"subject,omitempty"`
+	}
+
+	// referrerDescriptor describes a referrer manifest in a referrers index
+	referrerDescriptor struct {
+		ocispec.Descriptor
+		ArtifactType string `json:"artifactType,omitempty"`
+	}
+
+	// referrersIndex is the OCI image index listing the referrers of a
+	// manifest
+	referrersIndex struct {
+		specs.Versioned
+		MediaType string               `json:"mediaType"`
+		Manifests []referrerDescriptor `json:"manifests"`
+	}
+)
+
+// emptyConfig is the content of the config of artifacts that are not images
+var emptyConfig = []byte("{}")
+
+// PushSBOM uploads a software bill of materials for a chart already pushed to
+// a registry. The SBOM is pushed as a manifest whose subject is the chart
+// manifest, and listed in the referrers index of the chart manifest under the
+// referrers tag schema of the OCI distribution specification.
+func (c *Client) PushSBOM(ref *Reference, sbom []byte) error {
+	if ref.Tag == "" {
+		return errors.New("tag explicitly required")
+	}
+	ctx, cancel := c.context()
+	defer cancel()
+
+	_, subject, err := c.resolver.Resolve(ctx, ref.FullName())
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve chart %s", ref.FullName())
+	}
+
+	config := ocispec.Descriptor{
+		MediaType: emptyConfigMediaType,
+		Digest:    digest.FromBytes(emptyConfig),
+		Size:      int64(len(emptyConfig)),
+	}
+	layer := ocispec.Descriptor{
+		MediaType: HelmChartSBOMMediaType,
+		Digest:    digest.FromBytes(sbom),
+		Size:      int64(len(sbom)),
+	}
+	manifest, err := json.Marshal(referrerManifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: HelmChartSBOMMediaType,
+		Config:       config,
+		Layers:       []ocispec.Descriptor{layer},
+		Subject: &ocispec.Descriptor{
+			MediaType: subject.MediaType,
+			Digest:    subject.Digest,
+			Size:      subject.Size,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+
+	byDigest := fmt.Sprintf("%s@%s", ref.Repo, desc.Digest)
+	for _, blob := range []struct {
+		desc ocispec.Descriptor
+		data []byte
+	}{{config, emptyConfig}, {layer, sbom}, {desc, manifest}} {
+		if err := c.pushContent(ctx, byDigest, blob.desc, blob.data); err != nil {
+			return errors.Wrap(err, "failed to push SBOM")
+		}
+	}
+
+	indexRef := fmt.Sprintf("%s:%s", ref.Repo, referrersTag(subject.Digest))
+	index, err := c.fetchReferrersIndex(ctx, indexRef)
+	if err != nil {
+		return err
+	}
+	manifests := index.Manifests[:0]
+	for _, m := range index.Manifests {
+		if m.Digest != desc.Digest {
+			manifests = append(manifests, m)
+		}
+	}
+	index.Manifests = append(manifests, referrerDescriptor{Descriptor: desc, ArtifactType: HelmChartSBOMMediaType})
+	indexBytes, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(indexBytes),
+		Size:      int64(len(indexBytes)),
+	}
+	if err := c.pushContent(ctx, indexRef, indexDesc, indexBytes); err != nil {
+		return errors.Wrap(err, "failed to push referrers index")
+	}
+	fmt.Fprintf(c.out, "%s: pushed SBOM %s\n", ref.Tag, shortDigest(desc.Digest.Hex()))
+	return nil
+}
+
+// PullSBOM downloads the software bill of materials last pushed for a chart
+// with PushSBOM.
+func (c *Client) PullSBOM(ref *Reference) ([]byte, error) {
+	if ref.Tag == "" {
+		return nil, errors.New("tag explicitly required")
+	}
+	ctx, cancel := c.context()
+	defer cancel()
+
+	_, subject, err := c.resolver.Resolve(ctx, ref.FullName())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve chart %s", ref.FullName())
+	}
+	index, err := c.fetchReferrersIndex(ctx, fmt.Sprintf("%s:%s", ref.Repo, referrersTag(subject.Digest)))
+	if err != nil {
+		return nil, err
+	}
+	var sbom *referrerDescriptor
+	for i := range index.Manifests {
+		if index.Manifests[i].ArtifactType == HelmChartSBOMMediaType {
+			sbom = &index.Manifests[i]
+		}
+	}
+	if sbom == nil {
+		return nil, errors.Errorf("no SBOM found for %s", ref.FullName())
+	}
+
+	byDigest := fmt.Sprintf("%s@%s", ref.Repo, sbom.Digest)
+	b, err := c.fetchContent(ctx, byDigest, sbom.Descriptor)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch SBOM manifest")
+	}
+	var manifest referrerManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to read SBOM manifest")
+	}
+	if manifest.Subject == nil || manifest.Subject.Digest != subject.Digest {
+		return nil, errors.Errorf("SBOM manifest %s does not refer to %s", sbom.Digest, ref.FullName())
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == HelmChartSBOMMediaType {
+			return c.fetchContent(ctx, byDigest, layer)
+		}
+	}
+	return nil, errors.Errorf("SBOM manifest %s does not contain a layer with mediatype %s", sbom.Digest, HelmChartSBOMMediaType)
+}
+
+// fetchReferrersIndex retrieves the referrers index at ref, or an empty index
+// if there is none.
+func (c *Client) fetchReferrersIndex(ctx context.Context, ref string) (*referrersIndex, error) {
+	index := &referrersIndex{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+	}
+	_, desc, err := c.resolver.Resolve(ctx, ref)
+	if errdefs.IsNotFound(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve referrers index")
+	}
+	b, err := c.fetchContent(ctx, ref, desc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch referrers index")
+	}
+	if err := json.Unmarshal(b, index); err != nil {
+		return nil, errors.Wrap(err, "failed to read referrers index")
+	}
+	return index, nil
+}
+
+// pushContent uploads data, described by desc, to the repository of ref.
+// Manifests are tagged with the tag of ref, if any.
+func (c *Client) pushContent(ctx context.Context, ref string, desc ocispec.Descriptor, data []byte) error {
+	pusher, err := c.resolver.Pusher(ctx, ref)
+	if err != nil {
+		return err
+	}
+	w, err := pusher.Push(ctx, desc)
+	if errdefs.IsAlreadyExists(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Commit(ctx, desc.Size, desc.Digest); err != nil && !errdefs.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// fetchContent downloads the content described by desc from the repository of
+// ref, and verifies its digest.
+func (c *Client) fetchContent(ctx context.Context, ref string, desc ocispec.Descriptor) ([]byte, error) {
+	fetcher, err := c.resolver.Fetcher(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	if digest.FromBytes(b) != desc.Digest {
+		return nil, errors.Wrapf(errdefs.ErrFailedPrecondition, "content %s does not match its digest", desc.Digest)
+	}
+	return b, nil
+}
+
+// referrersTag is the tag of the referrers index of the manifest with digest
+// dgst, as defined by the referrers tag schema: "<alg>-<ref>".
+func referrersTag(dgst digest.Digest) string {
+	return fmt.Sprintf("%s-%s", dgst.Algorithm(), dgst.Hex())
+}
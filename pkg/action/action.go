@@ -94,6 +94,10 @@ type Configuration struct {
 	// Capabilities describes the capabilities of the Kubernetes cluster.
 	Capabilities *chartutil.Capabilities
 
+	// TemplateErrorContextLines is the number of template source lines shown
+	// around the line a template rendering error points at.
+	TemplateErrorContextLines int
+
 	Log func(string, ...interface{})
 }
 
@@ -102,7 +106,7 @@ type Configuration struct {
 // TODO: This function is badly in need of a refactor.
 // TODO: As part of the refactor the duplicate code in cmd/helm/template.go should be removed
 //       This code has to do with writing files to disk.
-func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrender.PostRenderer, dryRun bool) ([]*release.Hook, *bytes.Buffer, string, error) {
+func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds, sortByName bool, pr postrender.PostRenderer, dryRun bool) ([]*release.Hook, *bytes.Buffer, string, error) {
 	hs := []*release.Hook{}
 	b := bytes.NewBuffer(nil)
 
@@ -117,6 +121,7 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 		}
 	}
 
+	var e engine.Engine
 	var files map[string]string
 	var err2 error
 
@@ -130,10 +135,10 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 		if err != nil {
 			return hs, b, "", err
 		}
-		files, err2 = engine.RenderWithClient(ch, values, rest)
-	} else {
-		files, err2 = engine.Render(ch, values)
+		e = engine.New(rest)
 	}
+	e.ErrorContextLines = c.TemplateErrorContextLines
+	files, err2 = e.Render(ch, values)
 
 	if err2 != nil {
 		return hs, b, "", err2
@@ -177,6 +182,10 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 		}
 		return hs, b, "", err
 	}
+	if sortByName {
+		hs = releaseutil.SortHooksByKindAndName(hs, releaseutil.InstallOrder)
+		manifests = releaseutil.SortManifestsByKindAndName(manifests, releaseutil.InstallOrder)
+	}
 
 	// Aggregate all valid manifests into one big doc.
 	fileWritten := make(map[string]bool)
@@ -306,6 +315,23 @@ func (c *Configuration) releaseContent(name string, version int) (*release.Relea
 	return c.Releases.Get(name, version)
 }
 
+// checkUnknownValues returns an error listing the values of valuesToRender
+// that the chart schemas do not declare. See chartutil.UnknownValues.
+func checkUnknownValues(ch *chart.Chart, valuesToRender chartutil.Values) error {
+	vals, err := valuesToRender.Table("Values")
+	if err != nil {
+		return err
+	}
+	unknown, err := chartutil.UnknownValues(ch, vals)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return errors.Errorf("values not declared by the chart schema(s): %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
 // GetVersionSet retrieves a set of available k8s API versions
 func GetVersionSet(client discovery.ServerResourcesInterface) (chartutil.VersionSet, error) {
 	groups, resources, err := client.ServerGroupsAndResources()
@@ -366,25 +392,77 @@ func (c *Configuration) Init(getter genericclioptions.RESTClientGetter, namespac
 	kc := kube.New(getter)
 	kc.Log = log
 
+	store, err := newStorage(kc.Factory.KubernetesClientSet, c.Releases, namespace, helmDriver, log)
+	if err != nil {
+		return errors.Wrapf(err, "unable to initialize the %q storage driver", helmDriver)
+	}
+
+	c.RESTClientGetter = getter
+	c.KubeClient = kc
+	c.Releases = store
+	c.Log = log
+
+	return nil
+}
+
+// WithStorage returns a copy of the configuration that stores releases in s.
+//
+// Actions created with the returned configuration read and write releases
+// through s only, while sharing the Kubernetes and registry clients.
+func (c *Configuration) WithStorage(s *storage.Storage) *Configuration {
+	cfg := *c
+	cfg.Releases = s
+	return &cfg
+}
+
+// WithDriver returns a copy of the configuration that stores releases in
+// namespace using the named storage driver instead of the one chosen in Init.
+//
+// This allows a single process to use several storage backends, for instance
+// to migrate releases from one to another.
+func (c *Configuration) WithDriver(namespace, helmDriver string) (*Configuration, error) {
+	clientFn := func() (*kubernetes.Clientset, error) {
+		if c.RESTClientGetter == nil {
+			return nil, errors.New("no Kubernetes configuration available for the storage driver")
+		}
+		conf, err := c.RESTClientGetter.ToRESTConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to generate config for kubernetes client")
+		}
+		return kubernetes.NewForConfig(conf)
+	}
+	store, err := newStorage(clientFn, nil, namespace, helmDriver, c.Log)
+	if err != nil {
+		return nil, err
+	}
+	return c.WithStorage(store), nil
+}
+
+// newStorage creates the release storage for the named driver.
+//
+// An existing memory driver is re-used so that releases it already holds are kept.
+func newStorage(clientFn func() (*kubernetes.Clientset, error), existing *storage.Storage, namespace, helmDriver string, log DebugLog) (*storage.Storage, error) {
+	if log == nil {
+		log = func(string, ...interface{}) {}
+	}
 	lazyClient := &lazyClient{
 		namespace: namespace,
-		clientFn:  kc.Factory.KubernetesClientSet,
+		clientFn:  clientFn,
 	}
 
-	var store *storage.Storage
 	switch helmDriver {
 	case "secret", "secrets", "":
 		d := driver.NewSecrets(newSecretClient(lazyClient))
 		d.Log = log
-		store = storage.Init(d)
+		return storage.Init(d), nil
 	case "configmap", "configmaps":
 		d := driver.NewConfigMaps(newConfigMapClient(lazyClient))
 		d.Log = log
-		store = storage.Init(d)
+		return storage.Init(d), nil
 	case "memory":
 		var d *driver.Memory
-		if c.Releases != nil {
-			if mem, ok := c.Releases.Driver.(*driver.Memory); ok {
+		if existing != nil {
+			if mem, ok := existing.Driver.(*driver.Memory); ok {
 				// This function can be called more than once (e.g., helm list --all-namespaces).
 				// If a memory driver was already initialized, re-use it but set the possibly new namespace.
 				// We re-use it in case some releases where already created in the existing memory driver.
@@ -395,26 +473,23 @@ func (c *Configuration) Init(getter genericclioptions.RESTClientGetter, namespac
 			d = driver.NewMemory()
 		}
 		d.SetNamespace(namespace)
-		store = storage.Init(d)
+		return storage.Init(d), nil
 	case "sql":
-		d, err := driver.NewSQL(
+		pool, err := sqlPoolOptionsFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		d, err := driver.NewSQLWithPool(
 			os.Getenv("HELM_DRIVER_SQL_CONNECTION_STRING"),
 			log,
 			namespace,
+			pool,
 		)
 		if err != nil {
-			panic(fmt.Sprintf("Unable to instantiate SQL driver: %v", err))
+			return nil, errors.Wrap(err, "unable to instantiate SQL driver")
 		}
-		store = storage.Init(d)
+		return storage.Init(d), nil
 	default:
-		// Not sure what to do here.
-		panic("Unknown driver in HELM_DRIVER: " + helmDriver)
+		return nil, errors.Errorf("unknown driver in HELM_DRIVER: %s", helmDriver)
 	}
-
-	c.RESTClientGetter = getter
-	c.KubeClient = kc
-	c.Releases = store
-	c.Log = log
-
-	return nil
 }
@@ -22,9 +22,12 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	dockerauth "github.com/deislabs/oras/pkg/auth/docker"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
 
 	"helm.sh/helm/v3/internal/experimental/registry"
@@ -319,3 +322,86 @@ func TestGetVersionSet(t *testing.T) {
 		t.Error("Non-existent version is reported found.")
 	}
 }
+
+func TestConfigurationInitInvalidDriver(t *testing.T) {
+	cfg := &Configuration{}
+	err := cfg.Init(genericclioptions.NewConfigFlags(false), "spaced", "bogus", func(string, ...interface{}) {})
+	if err == nil {
+		t.Fatal("expected an error for an unknown storage driver")
+	}
+	if !strings.Contains(err.Error(), `"bogus" storage driver`) {
+		t.Errorf("expected the error to name the driver, got %q", err)
+	}
+}
+
+func TestConfigurationWithStorage(t *testing.T) {
+	memoryConfig := actionConfigFixture(t)
+	secrets := fakeclientset.NewSimpleClientset().CoreV1().Secrets("spaced")
+	secretsConfig := memoryConfig.WithStorage(storage.Init(driver.NewSecrets(secrets)))
+
+	install := func(cfg *Configuration, name string) {
+		t.Helper()
+		instAction := NewInstall(cfg)
+		instAction.Namespace = "spaced"
+		instAction.ReleaseName = name
+		if _, err := instAction.Run(buildChart(), map[string]interface{}{}); err != nil {
+			t.Fatalf("failed to install %s: %s", name, err)
+		}
+	}
+	install(memoryConfig, "in-memory")
+	install(secretsConfig, "in-secrets")
+
+	for cfg, expected := range map[*Configuration]string{
+		memoryConfig:  "in-memory",
+		secretsConfig: "in-secrets",
+	} {
+		releases, err := NewList(cfg).Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(releases) != 1 || releases[0].Name != expected {
+			t.Errorf("expected only %s to be listed, got %v", expected, releases)
+		}
+
+		history, err := NewHistory(cfg).Run(expected)
+		if err != nil || len(history) != 1 {
+			t.Errorf("expected one revision of %s, got %d (%v)", expected, len(history), err)
+		}
+		if _, err := NewGet(cfg).Run(expected); err != nil {
+			t.Errorf("expected to get %s: %s", expected, err)
+		}
+	}
+
+	if _, err := NewGet(memoryConfig).Run("in-secrets"); err == nil {
+		t.Error("expected in-secrets not to be found in the memory driver")
+	}
+	if list, err := secrets.List(context.Background(), metav1.ListOptions{}); err != nil || len(list.Items) != 1 {
+		t.Errorf("expected one release secret, got %v (%v)", list, err)
+	}
+}
+
+func TestConfigurationWithDriver(t *testing.T) {
+	config := actionConfigFixture(t)
+	if err := config.Releases.Create(releaseStub()); err != nil {
+		t.Fatal(err)
+	}
+
+	memoryConfig, err := config.WithDriver("spaced", "memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releases, err := memoryConfig.Releases.ListReleases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 0 {
+		t.Errorf("expected a new empty storage, got %d releases", len(releases))
+	}
+	if config.Releases == memoryConfig.Releases {
+		t.Error("expected the original configuration to keep its storage")
+	}
+
+	if _, err := config.WithDriver("spaced", "carrier-pigeon"); err == nil {
+		t.Error("expected an error for an unknown driver")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// checkAllowedKinds fails if the release renders a resource, including its
+// hooks, of a kind that AllowedKinds does not list. Every offending resource
+// is named in the error.
+func (i *Install) checkAllowedKinds(resources kube.ResourceList, hooks []*release.Hook) error {
+	if len(i.AllowedKinds) == 0 {
+		return nil
+	}
+	denied := disallowedResources(resources, i.AllowedKinds)
+	if !i.DisableHooks {
+		for _, h := range hooks {
+			res, err := i.cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+			if err != nil {
+				return errors.Wrapf(err, "unable to build kubernetes object for hook %s", h.Path)
+			}
+			denied = append(denied, disallowedResources(res, i.AllowedKinds)...)
+		}
+	}
+	return allowedKindsError(denied)
+}
+
+// checkAllowedCRDs is like checkAllowedKinds for the CRDs of the chart, which
+// are installed before anything else is rendered.
+func (i *Install) checkAllowedCRDs(crds []chart.CRD) error {
+	if len(i.AllowedKinds) == 0 {
+		return nil
+	}
+	var denied []string
+	for _, obj := range crds {
+		res, err := i.cfg.KubeClient.Build(bytes.NewBuffer(obj.File.Data), false)
+		if err != nil {
+			return errors.Wrapf(err, "failed to install CRD %s", obj.Name)
+		}
+		denied = append(denied, disallowedResources(res, i.AllowedKinds)...)
+	}
+	return allowedKindsError(denied)
+}
+
+func allowedKindsError(denied []string) error {
+	if len(denied) == 0 {
+		return nil
+	}
+	return errors.Errorf("the chart renders resources of kinds that are not allowed: %s", strings.Join(denied, ", "))
+}
+
+// disallowedResources describes the resources whose kind none of allowed
+// matches.
+func disallowedResources(resources kube.ResourceList, allowed []string) []string {
+	var denied []string
+	for _, r := range resources {
+		gvk := r.Object.GetObjectKind().GroupVersionKind()
+		if r.Mapping != nil {
+			gvk = r.Mapping.GroupVersionKind
+		}
+		apiVersion := gvk.GroupVersion().String()
+		if !isKindAllowed(apiVersion, gvk.Kind, allowed) {
+			denied = append(denied, fmt.Sprintf("%s/%s %q", apiVersion, gvk.Kind, r.Name))
+		}
+	}
+	return denied
+}
+
+// isKindAllowed reports whether an entry of allowed matches the kind. An
+// entry is either an API version and a kind, such as "apps/v1/Deployment" or
+// "v1/ConfigMap", or a kind alone, such as "Deployment", matching it in any
+// API version. Kinds are compared ignoring case.
+func isKindAllowed(apiVersion, kind string, allowed []string) bool {
+	for _, entry := range allowed {
+		idx := strings.LastIndex(entry, "/")
+		if idx < 0 {
+			if strings.EqualFold(entry, kind) {
+				return true
+			}
+			continue
+		}
+		if entry[:idx] == apiVersion && strings.EqualFold(entry[idx+1:], kind) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// manifestKubeClient builds the resources described by the manifests, and
+// counts the resources created, hooks included.
+type manifestKubeClient struct {
+	kubefake.FailingKubeClient
+	created int
+}
+
+func (c *manifestKubeClient) Build(r io.Reader, _ bool) (kube.ResourceList, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// build the resources in the order of the manifests
+	manifests := releaseutil.SplitManifests(string(b))
+	var keys []string
+	for key := range manifests {
+		keys = append(keys, key)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var resources kube.ResourceList
+	for _, key := range keys {
+		m := manifests[key]
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(m), &obj.Object); err != nil {
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		// none of the resources exists in the cluster
+		info := newNotFoundResource(obj.GetKind(), obj.GetName())
+		info.Object = obj
+		info.Mapping.GroupVersionKind = obj.GroupVersionKind()
+		resources = append(resources, info)
+	}
+	return resources, nil
+}
+
+func (c *manifestKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	c.created += len(resources)
+	return &kube.Result{Created: resources}, nil
+}
+
+func allowedKindsChart() *chart.Chart {
+	c := buildChart()
+	c.Templates = []*chart.File{
+		{Name: "templates/configmap", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n")},
+		{Name: "templates/deployment", Data: []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n")},
+		{Name: "templates/hook", Data: []byte("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n  annotations:\n    \"helm.sh/hook\": pre-install\n")},
+	}
+	return c
+}
+
+func TestInstallRelease_AllowedKinds(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowed      []string
+		disableHooks bool
+		denied       []string
+	}{
+		{
+			name:    "all kinds allowed",
+			allowed: []string{"v1/ConfigMap", "apps/v1/Deployment", "batch/v1/Job"},
+		},
+		{
+			name:    "kinds allowed in any version",
+			allowed: []string{"configmap", "Deployment", "Job"},
+		},
+		{
+			name:    "disallowed kind",
+			allowed: []string{"v1/ConfigMap", "batch/v1/Job"},
+			denied:  []string{`apps/v1/Deployment "web"`},
+		},
+		{
+			name:    "disallowed version",
+			allowed: []string{"v1/ConfigMap", "apps/v1beta1/Deployment", "Job"},
+			denied:  []string{`apps/v1/Deployment "web"`},
+		},
+		{
+			name:    "disallowed hook",
+			allowed: []string{"ConfigMap", "Deployment"},
+			denied:  []string{`batch/v1/Job "migrate"`},
+		},
+		{
+			name:         "hooks disabled",
+			allowed:      []string{"ConfigMap", "Deployment"},
+			disableHooks: true,
+		},
+		{
+			name:    "several disallowed resources",
+			allowed: []string{"Job"},
+			denied:  []string{`v1/ConfigMap "settings"`, `apps/v1/Deployment "web"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instAction := installAction(t)
+			client := &manifestKubeClient{
+				FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}},
+			}
+			instAction.cfg.KubeClient = client
+			instAction.AllowedKinds = tt.allowed
+			instAction.DisableHooks = tt.disableHooks
+
+			_, err := instAction.Run(allowedKindsChart(), map[string]interface{}{})
+			if len(tt.denied) == 0 {
+				require.NoError(t, err)
+				created := 3
+				if tt.disableHooks {
+					created = 2
+				}
+				assert.Equal(t, created, client.created)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "kinds that are not allowed")
+			for _, d := range tt.denied {
+				assert.Contains(t, err.Error(), d)
+			}
+			assert.Zero(t, client.created, "expected nothing to be applied")
+		})
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"time"
+
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+// createResources creates the resources, failing the creation of any
+// resource that takes longer than applyTimeout when it is set and the
+// Kubernetes client supports it.
+func (c *Configuration) createResources(resources kube.ResourceList, applyTimeout time.Duration) (*kube.Result, error) {
+	if kubeClient, ok := c.KubeClient.(kube.TimeoutApplier); ok && applyTimeout > 0 {
+		return kubeClient.CreateWithTimeout(resources, applyTimeout)
+	}
+	return c.KubeClient.Create(resources)
+}
+
+// updateResources updates original to target, failing the creation or update
+// of any resource that takes longer than applyTimeout when it is set and the
+// Kubernetes client supports it.
+func (c *Configuration) updateResources(original, target kube.ResourceList, force bool, applyTimeout time.Duration) (*kube.Result, error) {
+	if kubeClient, ok := c.KubeClient.(kube.TimeoutApplier); ok && applyTimeout > 0 {
+		return kubeClient.UpdateWithTimeout(original, target, force, applyTimeout)
+	}
+	return c.KubeClient.Update(original, target, force)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// applyTimeoutKubeClient records the apply timeouts it is given.
+type applyTimeoutKubeClient struct {
+	kubefake.FailingKubeClient
+	timeouts []time.Duration
+}
+
+func (c *applyTimeoutKubeClient) CreateWithTimeout(resources kube.ResourceList, applyTimeout time.Duration) (*kube.Result, error) {
+	c.timeouts = append(c.timeouts, applyTimeout)
+	return c.FailingKubeClient.CreateWithTimeout(resources, applyTimeout)
+}
+
+func (c *applyTimeoutKubeClient) UpdateWithTimeout(original, target kube.ResourceList, force bool, applyTimeout time.Duration) (*kube.Result, error) {
+	c.timeouts = append(c.timeouts, applyTimeout)
+	return c.FailingKubeClient.UpdateWithTimeout(original, target, force, applyTimeout)
+}
+
+func TestConfigurationApplyTimeout(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	kubeClient := &applyTimeoutKubeClient{FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}}
+	config.KubeClient = kubeClient
+
+	// no apply timeout goes through the plain Interface methods
+	_, err := config.createResources(kube.ResourceList{}, 0)
+	is.NoError(err)
+	_, err = config.updateResources(kube.ResourceList{}, kube.ResourceList{}, false, 0)
+	is.NoError(err)
+	is.Empty(kubeClient.timeouts)
+
+	_, err = config.createResources(kube.ResourceList{}, time.Second)
+	is.NoError(err)
+	_, err = config.updateResources(kube.ResourceList{}, kube.ResourceList{}, false, 2*time.Second)
+	is.NoError(err)
+	is.Equal([]time.Duration{time.Second, 2 * time.Second}, kubeClient.timeouts)
+}
+
+func TestUpgradeRelease_ApplyTimeout(t *testing.T) {
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Info.Status = release.StatusDeployed
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	kubeClient := &applyTimeoutKubeClient{FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}}
+	upAction.cfg.KubeClient = kubeClient
+	upAction.ApplyTimeout = 90 * time.Second
+
+	_, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	// the update, then the creation of the post-upgrade hook
+	assert.Equal(t, []time.Duration{90 * time.Second, 90 * time.Second}, kubeClient.timeouts)
+}
+
+func TestInstallRelease_HookApplyTimeout(t *testing.T) {
+	req := require.New(t)
+
+	instAction := installAction(t)
+	kubeClient := &applyTimeoutKubeClient{FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}}
+	instAction.cfg.KubeClient = kubeClient
+	instAction.ApplyTimeout = 30 * time.Second
+
+	_, err := instAction.Run(buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	// the fake client builds no release resources, so only the post-install
+	// hook is created
+	assert.Equal(t, []time.Duration{30 * time.Second}, kubeClient.timeouts)
+}
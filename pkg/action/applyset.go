@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+// applySetLabel marks the resources that belong to a release's apply set.
+const applySetLabel = "helm.sh/apply-set"
+
+// applySetID returns the apply set label value for a release. It is derived
+// from the release name and namespace so it always fits in a label value.
+func applySetID(releaseName, releaseNamespace string) string {
+	sum := sha256.Sum256([]byte(releaseNamespace + "/" + releaseName))
+	return fmt.Sprintf("helm-%x", sum[:16])
+}
+
+func setApplySetLabelVisitor(releaseName, releaseNamespace string) resource.VisitorFunc {
+	return func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := mergeLabels(info.Object, map[string]string{
+			applySetLabel: applySetID(releaseName, releaseNamespace),
+		}); err != nil {
+			return fmt.Errorf(
+				"%s labels could not be updated: %s",
+				resourceString(info), err,
+			)
+		}
+
+		return nil
+	}
+}
+
+// pruneApplySet deletes the live resources labelled as part of the release's
+// apply set that are not in exclude.
+//
+// Only the kinds and namespaces found in scope are considered. Resources that
+// are not owned by the release, or that are annotated with the keep resource
+// policy, are never deleted.
+func pruneApplySet(cfg *Configuration, scope, exclude kube.ResourceList, releaseName, releaseNamespace string) (kube.ResourceList, error) {
+	lister, ok := cfg.KubeClient.(kube.LabelLister)
+	if !ok {
+		return nil, errors.New("apply set pruning is not supported by the Kubernetes client")
+	}
+
+	selector := fmt.Sprintf("%s=%s", applySetLabel, applySetID(releaseName, releaseNamespace))
+	live, err := lister.ListByLabel(scope, selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list apply set resources")
+	}
+
+	var prune kube.ResourceList
+	for _, info := range live.Difference(exclude) {
+		if err := checkOwnership(info.Object, releaseName, releaseNamespace); err != nil {
+			cfg.Log("Skipping prune of %s: %s", resourceString(info), err)
+			continue
+		}
+		annotations, err := accessor.Annotations(info.Object)
+		if err != nil {
+			cfg.Log("Unable to get annotations on %s, err: %s", resourceString(info), err)
+			continue
+		}
+		if annotations[kube.ResourcePolicyAnno] == kube.KeepPolicy {
+			cfg.Log("Skipping prune of %s due to annotation [%s=%s]", resourceString(info), kube.ResourcePolicyAnno, kube.KeepPolicy)
+			continue
+		}
+		prune.Append(info)
+	}
+	if len(prune) == 0 {
+		return nil, nil
+	}
+
+	cfg.Log("Pruning %d resources from apply set of %s", len(prune), releaseName)
+	res, errs := cfg.KubeClient.Delete(prune)
+	if errs != nil {
+		var errorList []string
+		for _, e := range errs {
+			errorList = append(errorList, e.Error())
+		}
+		return nil, errors.Errorf("unable to prune resources: %s", strings.Join(errorList, ", "))
+	}
+	return res.Deleted, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+)
+
+// applySetKubeClient returns the live resources it was created with for any
+// label selector.
+type applySetKubeClient struct {
+	kubefake.PrintingKubeClient
+	live kube.ResourceList
+}
+
+func (c *applySetKubeClient) ListByLabel(_ kube.ResourceList, _ string) (kube.ResourceList, error) {
+	return c.live, nil
+}
+
+func newOwnedDeploymentResource(name, releaseName, releaseNamespace string) *resource.Info {
+	info := newDeploymentResource(name, releaseNamespace)
+	_ = setMetadataVisitor(releaseName, releaseNamespace, true)(info, nil)
+	_ = setApplySetLabelVisitor(releaseName, releaseNamespace)(info, nil)
+	return info
+}
+
+func TestApplySetID(t *testing.T) {
+	id := applySetID("rel-a", "ns-a")
+	assert.Equal(t, id, applySetID("rel-a", "ns-a"))
+	assert.NotEqual(t, id, applySetID("rel-a", "ns-b"))
+	assert.NotEqual(t, id, applySetID("rel-b", "ns-a"))
+	assert.LessOrEqual(t, len(id), 63)
+}
+
+func TestSetApplySetLabelVisitor(t *testing.T) {
+	resources := kube.ResourceList{newDeploymentResource("foo", "ns-a")}
+	assert.NoError(t, resources.Visit(setApplySetLabelVisitor("rel-a", "ns-a")))
+
+	labels, err := accessor.Labels(resources[0].Object)
+	assert.NoError(t, err)
+	assert.Equal(t, applySetID("rel-a", "ns-a"), labels[applySetLabel])
+}
+
+func TestPruneApplySet(t *testing.T) {
+	foo := newOwnedDeploymentResource("foo", "rel-a", "ns-a")
+	bar := newOwnedDeploymentResource("bar", "rel-a", "ns-a")
+	// labelled but owned by another release
+	baz := newOwnedDeploymentResource("baz", "rel-b", "ns-a")
+	// owned but annotated to be kept
+	qux := newOwnedDeploymentResource("qux", "rel-a", "ns-a")
+	_ = mergeAnnotations(qux.Object, map[string]string{kube.ResourcePolicyAnno: kube.KeepPolicy})
+
+	cfg := actionConfigFixture(t)
+	cfg.KubeClient = &applySetKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		live:               kube.ResourceList{foo, bar, baz, qux},
+	}
+
+	target := kube.ResourceList{foo}
+	pruned, err := pruneApplySet(cfg, target, target, "rel-a", "ns-a")
+	assert.NoError(t, err)
+	assert.Equal(t, kube.ResourceList{bar}, pruned)
+
+	// nothing left to prune once every live resource is excluded
+	pruned, err = pruneApplySet(cfg, target, kube.ResourceList{foo, bar}, "rel-a", "ns-a")
+	assert.NoError(t, err)
+	assert.Empty(t, pruned)
+}
+
+func TestPruneApplySetUnsupportedClient(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	cfg.KubeClient = struct{ kube.Interface }{cfg.KubeClient}
+
+	_, err := pruneApplySet(cfg, nil, nil, "rel-a", "ns-a")
+	assert.Error(t, err)
+
+	upAction := NewUpgrade(cfg)
+	upAction.ApplySetPrune = true
+	rel := releaseStub()
+	cfg.Releases.Create(rel)
+
+	_, err = upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "apply set pruning is not supported")
+}
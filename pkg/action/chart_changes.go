@@ -0,0 +1,313 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// ChangeRisk classifies how likely a chart change is to break an upgrade.
+type ChangeRisk string
+
+const (
+	// RiskHigh changes are likely to break existing releases or user values.
+	RiskHigh ChangeRisk = "high"
+	// RiskMedium changes alter behavior for users relying on the defaults.
+	RiskMedium ChangeRisk = "medium"
+	// RiskLow changes are additive.
+	RiskLow ChangeRisk = "low"
+)
+
+// ChangeType describes what changed between two chart versions.
+type ChangeType string
+
+const (
+	// ValueRemoved is a default value that no longer exists.
+	ValueRemoved ChangeType = "ValueRemoved"
+	// ValueTypeChanged is a default value that changed between a table and a
+	// non-table value.
+	ValueTypeChanged ChangeType = "ValueTypeChanged"
+	// ValueDefaultChanged is a default value that changed.
+	ValueDefaultChanged ChangeType = "ValueDefaultChanged"
+	// ValueAdded is a new default value.
+	ValueAdded ChangeType = "ValueAdded"
+	// ResourceRemoved is a resource that is no longer rendered.
+	ResourceRemoved ChangeType = "ResourceRemoved"
+	// ResourceAPIVersionChanged is a resource rendered with another apiVersion.
+	ResourceAPIVersionChanged ChangeType = "ResourceAPIVersionChanged"
+	// ResourceAdded is a new resource.
+	ResourceAdded ChangeType = "ResourceAdded"
+)
+
+var changeRisks = map[ChangeType]ChangeRisk{
+	ValueRemoved:              RiskHigh,
+	ValueTypeChanged:          RiskHigh,
+	ValueDefaultChanged:       RiskMedium,
+	ValueAdded:                RiskLow,
+	ResourceRemoved:           RiskHigh,
+	ResourceAPIVersionChanged: RiskMedium,
+	ResourceAdded:             RiskLow,
+}
+
+// ChartChange is a single difference between two chart versions.
+type ChartChange struct {
+	Type ChangeType
+	Risk ChangeRisk
+	// Path is the dotted values path, or "Kind namespace/name" for resources.
+	Path string
+	// Old and New are the default values, or the resource apiVersions.
+	Old interface{}
+	New interface{}
+}
+
+func (c ChartChange) String() string {
+	switch c.Type {
+	case ValueRemoved, ResourceRemoved:
+		return fmt.Sprintf("%s %s: %s removed", c.Risk, c.Type, c.Path)
+	case ValueAdded, ResourceAdded:
+		return fmt.Sprintf("%s %s: %s added", c.Risk, c.Type, c.Path)
+	default:
+		return fmt.Sprintf("%s %s: %s changed from %v to %v", c.Risk, c.Type, c.Path, c.Old, c.New)
+	}
+}
+
+// ChartChangeSummary holds the differences found between two chart versions.
+type ChartChangeSummary struct {
+	// Changes lists value changes by path, then resource changes by kind,
+	// namespace and name.
+	Changes []ChartChange
+}
+
+// ByRisk returns the changes classified with the given risk.
+func (s *ChartChangeSummary) ByRisk(risk ChangeRisk) []ChartChange {
+	var changes []ChartChange
+	for _, c := range s.Changes {
+		if c.Risk == risk {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// HasBreakingChanges reports whether any high risk change was found.
+func (s *ChartChangeSummary) HasBreakingChanges() bool {
+	return len(s.ByRisk(RiskHigh)) > 0
+}
+
+// DetectChartChanges compares the default values and the manifests rendered
+// with those defaults of two versions of a chart.
+//
+// Both charts are rendered as a fresh install with the default capabilities.
+// Hooks are not compared. Like an install, rendering processes the chart
+// dependencies, which may remove disabled subcharts from the charts.
+func DetectChartChanges(oldChart, newChart *chart.Chart) (*ChartChangeSummary, error) {
+	oldValues, oldResources, err := renderDefaults(oldChart)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to render %s", oldChart.Name())
+	}
+	newValues, newResources, err := renderDefaults(newChart)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to render %s", newChart.Name())
+	}
+
+	summary := &ChartChangeSummary{}
+	compareValues("", oldValues, newValues, summary)
+	compareResources(oldResources, newResources, summary)
+	return summary, nil
+}
+
+// renderDefaults returns the coalesced default values of a chart and the
+// rendered resources.
+func renderDefaults(ch *chart.Chart) (map[string]interface{}, map[ResourceID]bool, error) {
+	vals := map[string]interface{}{}
+	if err := chartutil.ProcessDependencies(ch, vals); err != nil {
+		return nil, nil, err
+	}
+	options := chartutil.ReleaseOptions{
+		Name:      "release-name",
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}
+	valuesToRender, err := chartutil.ToRenderValues(ch, vals, options, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := engine.Render(ch, valuesToRender)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k := range files {
+		if strings.HasSuffix(k, notesFileSuffix) {
+			delete(files, k)
+		}
+	}
+	_, manifests, err := releaseutil.SortManifests(files, chartutil.DefaultCapabilities.APIVersions, releaseutil.InstallOrder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resources := make(map[ResourceID]bool)
+	for _, m := range manifests {
+		id, ok, err := parseResourceID(m.Content, options.Namespace)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "unable to parse %s", m.Name)
+		}
+		if ok {
+			resources[id] = true
+		}
+	}
+
+	values, err := valuesToRender.Table("Values")
+	if err != nil {
+		return nil, nil, err
+	}
+	return values.AsMap(), resources, nil
+}
+
+func compareValues(prefix string, oldValues, newValues map[string]interface{}, summary *ChartChangeSummary) {
+	keys := make(map[string]bool)
+	for k := range oldValues {
+		keys[k] = true
+	}
+	for k := range newValues {
+		keys[k] = true
+	}
+	for _, k := range sortedKeys(keys) {
+		path := prefix + k
+		oldValue, inOld := oldValues[k]
+		newValue, inNew := newValues[k]
+		switch {
+		case !inNew:
+			summary.add(ValueRemoved, path, oldValue, nil)
+		case !inOld:
+			summary.add(ValueAdded, path, nil, newValue)
+		default:
+			oldTable, oldIsTable := asTable(oldValue)
+			newTable, newIsTable := asTable(newValue)
+			switch {
+			case oldIsTable && newIsTable:
+				compareValues(path+".", oldTable, newTable, summary)
+			case oldIsTable != newIsTable:
+				summary.add(ValueTypeChanged, path, oldValue, newValue)
+			case !reflect.DeepEqual(oldValue, newValue):
+				summary.add(ValueDefaultChanged, path, oldValue, newValue)
+			}
+		}
+	}
+}
+
+func asTable(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return t, true
+	case chartutil.Values:
+		return t.AsMap(), true
+	}
+	return nil, false
+}
+
+// compareResources reports the resources removed from and added to a chart.
+// A resource of the same kind, namespace and name that is only rendered with
+// another apiVersion is reported as a change of apiVersion.
+func compareResources(oldResources, newResources map[ResourceID]bool, summary *ChartChangeSummary) {
+	var removed, added []ResourceID
+	for id := range oldResources {
+		if !newResources[id] {
+			removed = append(removed, id)
+		}
+	}
+	for id := range newResources {
+		if !oldResources[id] {
+			added = append(added, id)
+		}
+	}
+	sortResourceIDs(removed)
+	sortResourceIDs(added)
+
+	var changes []ChartChange
+	for _, r := range removed {
+		i := indexOfObject(added, r)
+		if i < 0 {
+			changes = append(changes, newChartChange(ResourceRemoved, resourcePath(r), r.APIVersion, nil))
+			continue
+		}
+		changes = append(changes, newChartChange(ResourceAPIVersionChanged, resourcePath(r), r.APIVersion, added[i].APIVersion))
+		added = append(added[:i], added[i+1:]...)
+	}
+	for _, a := range added {
+		changes = append(changes, newChartChange(ResourceAdded, resourcePath(a), nil, a.APIVersion))
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	summary.Changes = append(summary.Changes, changes...)
+}
+
+// indexOfObject returns the index of the first resource of ids with the kind,
+// namespace and name of id, or -1 if there is none.
+func indexOfObject(ids []ResourceID, id ResourceID) int {
+	for i, other := range ids {
+		if other.Kind == id.Kind && other.Namespace == id.Namespace && other.Name == id.Name {
+			return i
+		}
+	}
+	return -1
+}
+
+func sortResourceIDs(ids []ResourceID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+}
+
+func resourcePath(id ResourceID) string {
+	return fmt.Sprintf("%s %s/%s", id.Kind, id.Namespace, id.Name)
+}
+
+func (s *ChartChangeSummary) add(t ChangeType, path string, oldValue, newValue interface{}) {
+	s.Changes = append(s.Changes, newChartChange(t, path, oldValue, newValue))
+}
+
+func newChartChange(t ChangeType, path string, oldValue, newValue interface{}) ChartChange {
+	return ChartChange{
+		Type: t,
+		Risk: changeRisks[t],
+		Path: path,
+		Old:  oldValue,
+		New:  newValue,
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
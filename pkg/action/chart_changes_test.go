@@ -0,0 +1,158 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const (
+	changesConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+data:
+  replicas: {{ .Values.replicas | quote }}
+`
+	changesDeploymentV1beta1 = `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-web
+`
+	changesDeploymentV1 = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-web
+`
+	changesService = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}-web
+`
+	changesIngress = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: {{ .Release.Name }}-web
+`
+)
+
+func changesChart(version string, values map[string]interface{}, templates map[string]string) *chart.Chart {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "changes",
+			Version:    version,
+		},
+		Values: values,
+	}
+	for name, data := range templates {
+		c.Templates = append(c.Templates, &chart.File{Name: "templates/" + name, Data: []byte(data)})
+	}
+	return c
+}
+
+func TestDetectChartChanges(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	oldChart := changesChart("1.0.0", map[string]interface{}{
+		"replicas": 1,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.19",
+		},
+		"service": map[string]interface{}{
+			"port": 80,
+		},
+		"legacy": true,
+	}, map[string]string{
+		"configmap.yaml":  changesConfigMap,
+		"deployment.yaml": changesDeploymentV1beta1,
+		"service.yaml":    changesService,
+		"NOTES.txt":       "old notes",
+	})
+	newChart := changesChart("2.0.0", map[string]interface{}{
+		"replicas": 2,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.19",
+			"pullPolicy": "IfNotPresent",
+		},
+		"service": "web",
+	}, map[string]string{
+		"configmap.yaml":  changesConfigMap,
+		"deployment.yaml": changesDeploymentV1,
+		"ingress.yaml":    changesIngress,
+		"NOTES.txt":       "new notes",
+	})
+
+	summary, err := DetectChartChanges(oldChart, newChart)
+	req.NoError(err)
+
+	is.Equal([]ChartChange{
+		{Type: ValueAdded, Risk: RiskLow, Path: "image.pullPolicy", New: "IfNotPresent"},
+		{Type: ValueRemoved, Risk: RiskHigh, Path: "legacy", Old: true},
+		{Type: ValueDefaultChanged, Risk: RiskMedium, Path: "replicas", Old: 1, New: 2},
+		{Type: ValueTypeChanged, Risk: RiskHigh, Path: "service", Old: map[string]interface{}{"port": 80}, New: "web"},
+		{Type: ResourceAPIVersionChanged, Risk: RiskMedium, Path: "Deployment default/release-name-web", Old: "extensions/v1beta1", New: "apps/v1"},
+		{Type: ResourceAdded, Risk: RiskLow, Path: "Ingress default/release-name-web", New: "networking.k8s.io/v1"},
+		{Type: ResourceRemoved, Risk: RiskHigh, Path: "Service default/release-name-web", Old: "v1"},
+	}, summary.Changes)
+
+	is.True(summary.HasBreakingChanges())
+	is.Len(summary.ByRisk(RiskHigh), 3)
+	is.Len(summary.ByRisk(RiskMedium), 2)
+	is.Len(summary.ByRisk(RiskLow), 2)
+	is.Equal("high ValueRemoved: legacy removed", summary.ByRisk(RiskHigh)[0].String())
+}
+
+func TestDetectChartChangesIdentical(t *testing.T) {
+	c := changesChart("1.0.0", map[string]interface{}{"replicas": 1}, map[string]string{
+		"configmap.yaml": changesConfigMap,
+	})
+
+	summary, err := DetectChartChanges(c, c)
+	require.NoError(t, err)
+	assert.Empty(t, summary.Changes)
+	assert.False(t, summary.HasBreakingChanges())
+}
+
+func TestDetectChartChangesResourceIdentity(t *testing.T) {
+	certificate := func(apiVersion, namespace string) string {
+		return "apiVersion: " + apiVersion + "\nkind: Certificate\nmetadata:\n  name: web\n  namespace: " + namespace + "\n"
+	}
+	oldChart := changesChart("1.0.0", nil, map[string]string{
+		"cert-manager.yaml": certificate("cert-manager.io/v1", "default"),
+		"other.yaml":        certificate("example.com/v1", "default"),
+	})
+	newChart := changesChart("2.0.0", nil, map[string]string{
+		"cert-manager.yaml": certificate("cert-manager.io/v1", "default"),
+		"other.yaml":        certificate("example.com/v1", "other"),
+	})
+
+	summary, err := DetectChartChanges(oldChart, newChart)
+	require.NoError(t, err)
+	assert.Equal(t, []ChartChange{
+		{Type: ResourceRemoved, Risk: RiskHigh, Path: "Certificate default/web", Old: "example.com/v1"},
+		{Type: ResourceAdded, Risk: RiskLow, Path: "Certificate other/web", New: "example.com/v1"},
+	}, summary.Changes)
+}
@@ -18,6 +18,9 @@ package action
 
 import (
 	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/internal/experimental/registry"
 )
@@ -25,6 +28,10 @@ import (
 // ChartPush performs a chart push operation.
 type ChartPush struct {
 	cfg *Configuration
+
+	// SBOM is the path to a software bill of materials (e.g. SPDX or CycloneDX)
+	// pushed as a referrer of the chart.
+	SBOM string
 }
 
 // NewChartPush creates a new ChartPush object with the given configuration.
@@ -40,5 +47,20 @@ func (a *ChartPush) Run(out io.Writer, ref string) error {
 	if err != nil {
 		return err
 	}
-	return a.cfg.RegistryClient.PushChart(r)
+	var sbom []byte
+	if a.SBOM != "" {
+		if sbom, err = ioutil.ReadFile(a.SBOM); err != nil {
+			return errors.Wrap(err, "failed to read SBOM")
+		}
+		if len(sbom) == 0 {
+			return errors.Errorf("SBOM %s is empty", a.SBOM)
+		}
+	}
+	if err := a.cfg.RegistryClient.PushChart(r); err != nil {
+		return err
+	}
+	if sbom != nil {
+		return a.cfg.RegistryClient.PushSBOM(r, sbom)
+	}
+	return nil
 }
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// checkDuplicateResources returns an error listing every resource that the
+// rendered manifest defines more than once, with the templates defining it.
+// Resources without a namespace are taken to be in namespace.
+func checkDuplicateResources(manifest, namespace string) error {
+	sources := map[string][]string{}
+	for name, content := range releaseutil.SplitManifests(manifest) {
+		id, ok, err := parseResourceID(content, namespace)
+		if err != nil || !ok {
+			// malformed manifests are reported when the resources are built
+			continue
+		}
+		key := fmt.Sprintf("%s/%s %s/%s", id.APIVersion, id.Kind, id.Namespace, id.Name)
+		sources[key] = append(sources[key], manifestSource(name, content))
+	}
+
+	var duplicates []string
+	for key, srcs := range sources {
+		if len(srcs) > 1 {
+			sort.Strings(srcs)
+			duplicates = append(duplicates, fmt.Sprintf("%s (defined in %s)", key, strings.Join(srcs, ", ")))
+		}
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	sort.Strings(duplicates)
+	return errors.Errorf("rendered manifests contain duplicate resources: %s", strings.Join(duplicates, "; "))
+}
+
+// manifestSource returns the template a rendered manifest came from, as
+// recorded in its "# Source:" comment, or name when there is none.
+func manifestSource(name, content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "# Source: ") {
+			return strings.TrimPrefix(line, "# Source: ")
+		}
+	}
+	return name
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const duplicateConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  color: blue
+`
+
+func TestCheckDuplicateResources(t *testing.T) {
+	manifest := `---
+# Source: web/templates/a.yaml
+` + duplicateConfigMap + `---
+# Source: web/templates/b.yaml
+` + duplicateConfigMap + `---
+# Source: web/templates/c.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: other
+`
+	err := checkDuplicateResources(manifest, "spaced")
+	require.Error(t, err)
+	assert.Equal(t, "rendered manifests contain duplicate resources: v1/ConfigMap spaced/settings (defined in web/templates/a.yaml, web/templates/b.yaml)", err.Error())
+
+	// an explicit release namespace is the same as none
+	manifest = `---
+# Source: web/templates/a.yaml
+` + duplicateConfigMap + `---
+# Source: web/templates/b.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: spaced
+`
+	assert.Error(t, checkDuplicateResources(manifest, "spaced"))
+
+	assert.NoError(t, checkDuplicateResources("---\n"+duplicateConfigMap, "spaced"))
+}
+
+func TestInstallRelease_DuplicateResources(t *testing.T) {
+	ch := buildChart()
+	ch.Templates = append(ch.Templates,
+		&chart.File{Name: "templates/settings.yaml", Data: []byte(duplicateConfigMap)},
+		&chart.File{Name: "templates/settings-copy.yaml", Data: []byte(duplicateConfigMap)},
+	)
+
+	instAction := installAction(t)
+	_, err := instAction.Run(ch, map[string]interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hello/templates/settings-copy.yaml, hello/templates/settings.yaml")
+
+	instAction = installAction(t)
+	instAction.AllowDuplicateResources = true
+	_, err = instAction.Run(ch, map[string]interface{}{})
+	assert.NoError(t, err)
+}
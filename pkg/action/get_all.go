@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+// GetAll is the action for collecting everything known about a release.
+//
+// It combines what 'helm get all', 'helm get values --all' and 'helm history'
+// report into a single ReleaseBundle, reading the release history once.
+type GetAll struct {
+	cfg *Configuration
+
+	// Initializing Version to 0 will get the latest revision of the release.
+	Version int
+}
+
+// ReleaseBundle is the complete view of a release revision returned by GetAll.
+type ReleaseBundle struct {
+	Name           string                 `json:"name"`
+	Namespace      string                 `json:"namespace"`
+	Revision       int                    `json:"revision"`
+	Info           *release.Info          `json:"info,omitempty"`
+	Chart          *chart.Metadata        `json:"chart,omitempty"`
+	UserValues     map[string]interface{} `json:"user_values,omitempty"`
+	ComputedValues map[string]interface{} `json:"computed_values,omitempty"`
+	Manifest       string                 `json:"manifest,omitempty"`
+	Hooks          []*release.Hook        `json:"hooks,omitempty"`
+	Notes          string                 `json:"notes,omitempty"`
+	History        []RevisionSummary      `json:"history"`
+}
+
+// RevisionSummary describes one revision in the history of a ReleaseBundle.
+type RevisionSummary struct {
+	Revision    int            `json:"revision"`
+	Updated     helmtime.Time  `json:"updated"`
+	Status      release.Status `json:"status"`
+	Chart       string         `json:"chart"`
+	AppVersion  string         `json:"app_version"`
+	Description string         `json:"description"`
+}
+
+// NewGetAll creates a new GetAll object with the given configuration.
+func NewGetAll(cfg *Configuration) *GetAll {
+	return &GetAll{
+		cfg: cfg,
+	}
+}
+
+// Run returns the bundle of the given release.
+func (g *GetAll) Run(name string) (*ReleaseBundle, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+
+	rels, err := g.cfg.Releases.History(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(rels) == 0 {
+		return nil, errors.Errorf("release: %q not found", name)
+	}
+	releaseutil.SortByRevision(rels)
+
+	rel := rels[len(rels)-1]
+	if g.Version > 0 {
+		rel = nil
+		for _, r := range rels {
+			if r.Version == g.Version {
+				rel = r
+				break
+			}
+		}
+		if rel == nil {
+			return nil, errors.Errorf("release: %q has no revision %d", name, g.Version)
+		}
+	}
+
+	computed, err := chartutil.CoalesceValues(rel.Chart, rel.Config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot compute the values of revision %d", rel.Version)
+	}
+
+	bundle := &ReleaseBundle{
+		Name:           rel.Name,
+		Namespace:      rel.Namespace,
+		Revision:       rel.Version,
+		Info:           rel.Info,
+		UserValues:     rel.Config,
+		ComputedValues: computed,
+		Manifest:       rel.Manifest,
+		Hooks:          rel.Hooks,
+		History:        make([]RevisionSummary, 0, len(rels)),
+	}
+	if rel.Chart != nil {
+		bundle.Chart = rel.Chart.Metadata
+	}
+	if rel.Info != nil {
+		bundle.Notes = rel.Info.Notes
+	}
+	for _, r := range rels {
+		bundle.History = append(bundle.History, revisionSummary(r))
+	}
+	return bundle, nil
+}
+
+func revisionSummary(rel *release.Release) RevisionSummary {
+	s := RevisionSummary{Revision: rel.Version, Chart: "MISSING"}
+	if rel.Info != nil {
+		s.Updated = rel.Info.LastDeployed
+		s.Status = rel.Info.Status
+		s.Description = rel.Info.Description
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		s.Chart = fmt.Sprintf("%s-%s", rel.Chart.Name(), rel.Chart.Metadata.Version)
+		s.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+	return s
+}
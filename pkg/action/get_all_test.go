@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestGetAll(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	config := actionConfigFixture(t)
+	for version := 1; version <= 3; version++ {
+		status := release.StatusSuperseded
+		if version == 3 {
+			status = release.StatusDeployed
+		}
+		rel := namedReleaseStub("bundled", status)
+		rel.Version = version
+		rel.Config = map[string]interface{}{"replicas": version}
+		rel.Chart.Values = map[string]interface{}{"image": "nginx"}
+		rel.Info.Notes = "thanks for installing"
+		rel.Manifest = "apiVersion: v1\nkind: ConfigMap\n"
+		req.NoError(config.Releases.Create(rel))
+	}
+
+	client := NewGetAll(config)
+	bundle, err := client.Run("bundled")
+	req.NoError(err)
+	is.Equal("bundled", bundle.Name)
+	is.Equal(3, bundle.Revision)
+	is.Equal("hello", bundle.Chart.Name)
+	is.Equal(map[string]interface{}{"replicas": 3}, bundle.UserValues)
+	is.Equal(map[string]interface{}{"replicas": 3, "image": "nginx"}, bundle.ComputedValues)
+	is.Equal("apiVersion: v1\nkind: ConfigMap\n", bundle.Manifest)
+	is.Len(bundle.Hooks, 2)
+	is.Equal("thanks for installing", bundle.Notes)
+	req.Len(bundle.History, 3)
+	for i, summary := range bundle.History {
+		is.Equal(i+1, summary.Revision)
+		is.Equal("hello-0.1.0", summary.Chart)
+	}
+	is.Equal(release.StatusDeployed, bundle.History[2].Status)
+
+	_, err = json.Marshal(bundle)
+	is.NoError(err)
+
+	client.Version = 2
+	bundle, err = client.Run("bundled")
+	req.NoError(err)
+	is.Equal(2, bundle.Revision)
+	is.Equal(map[string]interface{}{"replicas": 2}, bundle.UserValues)
+	is.Len(bundle.History, 3)
+
+	client.Version = 7
+	_, err = client.Run("bundled")
+	is.Error(err)
+
+	client.Version = 0
+	_, err = client.Run("missing")
+	is.Error(err)
+}
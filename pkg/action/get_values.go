@@ -17,7 +17,10 @@ limitations under the License.
 package action
 
 import (
+	"github.com/pkg/errors"
+
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/releaseutil"
 )
 
 // GetValues is the action for checking a given release's values.
@@ -28,6 +31,8 @@ type GetValues struct {
 
 	Version   int
 	AllValues bool
+	// MaxRevisions limits RunRevisions to the latest revisions. Zero means all of them.
+	MaxRevisions int
 }
 
 // NewGetValues creates a new GetValues object with the given configuration.
@@ -58,3 +63,38 @@ func (g *GetValues) Run(name string) (map[string]interface{}, error) {
 	}
 	return rel.Config, nil
 }
+
+// RunRevisions returns the values of every revision of the given release,
+// keyed by revision number, reading the release history once.
+func (g *GetValues) RunRevisions(name string) (map[int]map[string]interface{}, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+
+	rels, err := g.cfg.Releases.History(name)
+	if err != nil {
+		return nil, err
+	}
+	releaseutil.SortByRevision(rels)
+	if g.MaxRevisions > 0 && len(rels) > g.MaxRevisions {
+		rels = rels[len(rels)-g.MaxRevisions:]
+	}
+
+	values := make(map[int]map[string]interface{}, len(rels))
+	for _, rel := range rels {
+		if g.AllValues {
+			cfg, err := chartutil.CoalesceValues(rel.Chart, rel.Config)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot compute the values of revision %d", rel.Version)
+			}
+			values[rel.Version] = cfg
+			continue
+		}
+		values[rel.Version] = rel.Config
+	}
+	return values, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestGetValuesRunRevisions(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	config := actionConfigFixture(t)
+	for version := 1; version <= 3; version++ {
+		rel := namedReleaseStub("audited", release.StatusSuperseded)
+		rel.Version = version
+		rel.Config = map[string]interface{}{"replicas": version}
+		req.NoError(config.Releases.Create(rel))
+	}
+	other := namedReleaseStub("other", release.StatusDeployed)
+	req.NoError(config.Releases.Create(other))
+
+	client := NewGetValues(config)
+	values, err := client.RunRevisions("audited")
+	req.NoError(err)
+	is.Equal(map[int]map[string]interface{}{
+		1: {"replicas": 1},
+		2: {"replicas": 2},
+		3: {"replicas": 3},
+	}, values)
+
+	client.MaxRevisions = 2
+	values, err = client.RunRevisions("audited")
+	req.NoError(err)
+	is.Equal(map[int]map[string]interface{}{
+		2: {"replicas": 2},
+		3: {"replicas": 3},
+	}, values)
+
+	client.AllValues = true
+	values, err = client.RunRevisions("audited")
+	req.NoError(err)
+	is.Len(values, 2)
+	is.Equal(3, values[3]["replicas"])
+
+	_, err = client.RunRevisions("missing")
+	is.Error(err)
+}
@@ -34,6 +34,12 @@ type History struct {
 
 	Max     int
 	Version int
+	// Prune removes the revisions beyond Max from storage before returning
+	// the remaining history. As with PruneHistory, the latest revision and
+	// the deployed revision are always kept.
+	Prune bool
+	// Pruned is set by Run to the number of revisions removed by Prune.
+	Pruned int
 }
 
 // NewHistory creates a new History object with the given configuration.
@@ -53,6 +59,33 @@ func (h *History) Run(name string) ([]*release.Release, error) {
 		return nil, errors.Errorf("release name is invalid: %s", name)
 	}
 
+	if h.Prune && h.Max <= 0 {
+		return nil, errors.New("pruning history requires a positive maximum number of revisions")
+	}
+
 	h.cfg.Log("getting history for release %s", name)
-	return h.cfg.Releases.History(name)
+	hist, err := h.cfg.Releases.History(name)
+	if err != nil || !h.Prune {
+		return hist, err
+	}
+	return h.prune(hist)
+}
+
+// prune removes the revisions of hist beyond Max and returns the others.
+func (h *History) prune(hist []*release.Release) ([]*release.Release, error) {
+	expired := (&PruneHistory{cfg: h.cfg, MaxHistory: h.Max}).expired(hist)
+	removed, err := h.cfg.removeRevisions(expired)
+	h.Pruned = len(removed)
+
+	gone := make(map[int]bool, len(removed))
+	for _, rel := range removed {
+		gone[rel.Version] = true
+	}
+	remaining := make([]*release.Release, 0, len(hist)-len(removed))
+	for _, rel := range hist {
+		if !gone[rel.Version] {
+			remaining = append(remaining, rel)
+		}
+	}
+	return remaining, err
 }
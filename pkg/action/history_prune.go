@@ -0,0 +1,133 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// PruneHistory is the action for removing old release revisions from storage.
+//
+// It applies the retention rules to every release in the configured
+// namespace. The latest revision and the deployed revision of a release are
+// always kept.
+type PruneHistory struct {
+	cfg *Configuration
+
+	// MaxHistory is the maximum number of revisions kept per release. Zero means no limit.
+	MaxHistory int
+	// MaxAge removes revisions last deployed longer ago than this. Zero means no limit.
+	MaxAge time.Duration
+	// DryRun reports the revisions that would be removed without removing them.
+	DryRun bool
+}
+
+// NewPruneHistory creates a new PruneHistory object with the given configuration.
+func NewPruneHistory(cfg *Configuration) *PruneHistory {
+	return &PruneHistory{
+		cfg: cfg,
+	}
+}
+
+// Run removes the revisions that fall outside the retention rules and
+// returns them, ordered by release name and revision.
+func (p *PruneHistory) Run() ([]*release.Release, error) {
+	if p.MaxHistory < 0 || p.MaxAge < 0 {
+		return nil, errors.New("retention rules must not be negative")
+	}
+	if p.MaxHistory == 0 && p.MaxAge == 0 {
+		return nil, errors.New("at least one retention rule (max history or max age) is required")
+	}
+
+	all, err := p.cfg.Releases.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	histories := make(map[string][]*release.Release)
+	var names []string
+	for _, rel := range all {
+		if _, ok := histories[rel.Name]; !ok {
+			names = append(names, rel.Name)
+		}
+		histories[rel.Name] = append(histories[rel.Name], rel)
+	}
+
+	sort.Strings(names)
+
+	var pruned []*release.Release
+	for _, name := range names {
+		pruned = append(pruned, p.expired(histories[name])...)
+	}
+
+	if p.DryRun {
+		return pruned, nil
+	}
+
+	return p.cfg.removeRevisions(pruned)
+}
+
+// removeRevisions deletes the revisions from storage and returns those that
+// were removed. It deletes as many as possible and reports the first failure.
+func (cfg *Configuration) removeRevisions(revisions []*release.Release) ([]*release.Release, error) {
+	var removed []*release.Release
+	var errs []error
+	for _, rel := range revisions {
+		if _, err := cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to remove revision %d of %s", rel.Version, rel.Name))
+			continue
+		}
+		removed = append(removed, rel)
+	}
+
+	cfg.Log("Pruned %d record(s) with %d error(s)", len(removed), len(errs))
+	switch c := len(errs); c {
+	case 0:
+		return removed, nil
+	case 1:
+		return removed, errs[0]
+	default:
+		return removed, errors.Errorf("encountered %d deletion errors. First is: %s", c, errs[0])
+	}
+}
+
+// expired returns the revisions of a single release history that fall
+// outside the retention rules.
+func (p *PruneHistory) expired(history []*release.Release) []*release.Release {
+	releaseutil.SortByRevision(history)
+
+	now := p.cfg.Now()
+	var expired []*release.Release
+	for i, rel := range history {
+		newer := len(history) - 1 - i
+		if newer == 0 || rel.Info.Status == release.StatusDeployed {
+			continue
+		}
+		tooMany := p.MaxHistory > 0 && newer >= p.MaxHistory
+		tooOld := p.MaxAge > 0 && now.Sub(rel.Info.LastDeployed) > p.MaxAge
+		if tooMany || tooOld {
+			expired = append(expired, rel)
+		}
+	}
+	return expired
+}
@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func TestPruneHistory(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	config := actionConfigFixture(t)
+	store := func(name string, version int, status release.Status, age time.Duration) {
+		rel := namedReleaseStub(name, status)
+		rel.Version = version
+		rel.Info.LastDeployed = helmtime.Now().Add(-age)
+		req.NoError(config.Releases.Create(rel))
+	}
+	// the latest revision is a failed upgrade, so the deployed one is kept too
+	store("alpha", 1, release.StatusSuperseded, 0)
+	store("alpha", 2, release.StatusSuperseded, 0)
+	store("alpha", 3, release.StatusSuperseded, 0)
+	store("alpha", 4, release.StatusDeployed, 0)
+	store("alpha", 5, release.StatusFailed, 0)
+	// only old enough revisions are removed by age
+	store("beta", 1, release.StatusSuperseded, 48*time.Hour)
+	store("beta", 2, release.StatusDeployed, 48*time.Hour)
+
+	versions := func(rels []*release.Release) []string {
+		var out []string
+		for _, r := range rels {
+			out = append(out, fmt.Sprintf("%s.v%d", r.Name, r.Version))
+		}
+		return out
+	}
+	expected := []string{"alpha.v1", "alpha.v2", "alpha.v3", "beta.v1"}
+
+	prune := NewPruneHistory(config)
+	prune.MaxHistory = 2
+	prune.MaxAge = 24 * time.Hour
+	prune.DryRun = true
+	res, err := prune.Run()
+	req.NoError(err)
+	is.Equal(expected, versions(res))
+
+	all, err := config.Releases.ListReleases()
+	req.NoError(err)
+	is.Len(all, 7, "dry run must not remove anything")
+
+	prune.DryRun = false
+	res, err = prune.Run()
+	req.NoError(err)
+	is.Equal(expected, versions(res))
+
+	all, err = config.Releases.ListReleases()
+	req.NoError(err)
+	is.Len(all, 3)
+
+	// nothing left to remove
+	res, err = prune.Run()
+	req.NoError(err)
+	is.Empty(res)
+}
+
+func TestPruneHistoryRequiresRule(t *testing.T) {
+	prune := NewPruneHistory(actionConfigFixture(t))
+	_, err := prune.Run()
+	assert.Error(t, err)
+
+	prune.MaxHistory = -1
+	_, err = prune.Run()
+	assert.Error(t, err)
+}
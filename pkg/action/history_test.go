@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestHistoryPrune(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	config := actionConfigFixture(t)
+	for v, status := range []release.Status{
+		release.StatusSuperseded,
+		release.StatusDeployed,
+		release.StatusSuperseded,
+		release.StatusSuperseded,
+		release.StatusFailed,
+	} {
+		rel := namedReleaseStub("gamma", status)
+		rel.Version = v + 1
+		req.NoError(config.Releases.Create(rel))
+	}
+	other := namedReleaseStub("delta", release.StatusSuperseded)
+	req.NoError(config.Releases.Create(other))
+
+	versions := func(rels []*release.Release) []string {
+		var out []string
+		for _, r := range rels {
+			out = append(out, fmt.Sprintf("%s.v%d", r.Name, r.Version))
+		}
+		return out
+	}
+
+	client := NewHistory(config)
+	client.Max = 2
+	hist, err := client.Run("gamma")
+	req.NoError(err)
+	is.Len(hist, 5, "history must not be pruned unless asked")
+
+	client.Prune = true
+	hist, err = client.Run("gamma")
+	req.NoError(err)
+	is.Equal(2, client.Pruned)
+	// the deployed revision is kept beyond the maximum
+	is.Equal([]string{"gamma.v2", "gamma.v4", "gamma.v5"}, versions(hist))
+
+	stored, err := config.Releases.History("gamma")
+	req.NoError(err)
+	is.Len(stored, 3)
+	stored, err = config.Releases.History("delta")
+	req.NoError(err)
+	is.Len(stored, 1, "other releases must not be pruned")
+
+	client.Max = 0
+	_, err = client.Run("gamma")
+	is.Error(err)
+}
@@ -26,8 +26,9 @@ import (
 	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
-// execHook executes all of the hooks for the given hook event.
-func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent, timeout time.Duration) error {
+// execHook executes all of the hooks for the given hook event. Creating any
+// single hook resource fails after applyTimeout, when it is set.
+func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent, timeout, applyTimeout time.Duration) error {
 	executingHooks := []*release.Hook{}
 
 	for _, h := range rl.Hooks {
@@ -73,9 +74,13 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 		h.LastRun.Phase = release.HookPhaseUnknown
 
 		// Create hook resources
-		if _, err := cfg.KubeClient.Create(resources); err != nil {
+		if _, err := cfg.createResources(resources, applyTimeout); err != nil {
 			h.LastRun.CompletedAt = helmtime.Now()
 			h.LastRun.Phase = release.HookPhaseFailed
+			if h.FailurePolicy == release.HookFailurePolicyIgnore {
+				cfg.Log("warning: ignoring failed %s hook %s: %s", hook, h.Path, err)
+				continue
+			}
 			return errors.Wrapf(err, "warning: Hook %s %s failed", hook, h.Path)
 		}
 
@@ -91,14 +96,23 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 			if err := cfg.deleteHookByPolicy(h, release.HookFailed); err != nil {
 				return err
 			}
+			// A hook with the ignore failure policy keeps its failed phase in the
+			// release record, but does not fail the operation.
+			if h.FailurePolicy == release.HookFailurePolicyIgnore {
+				cfg.Log("warning: ignoring failed %s hook %s: %s", hook, h.Path, err)
+				continue
+			}
 			return err
 		}
 		h.LastRun.Phase = release.HookPhaseSucceeded
 	}
 
-	// If all hooks are successful, check the annotation of each hook to determine whether the hook should be deleted
+	// If all hooks are successful or ignored, check the annotation of each succeeded hook to determine whether the hook should be deleted
 	// under succeeded condition. If so, then clear the corresponding resource object in each hook
 	for _, h := range executingHooks {
+		if h.LastRun.Phase == release.HookPhaseFailed {
+			continue
+		}
 		if err := cfg.deleteHookByPolicy(h, release.HookSucceeded); err != nil {
 			return err
 		}
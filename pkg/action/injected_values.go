@@ -0,0 +1,63 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+)
+
+// injectValues layers injected above vals, so that they take precedence over
+// every other source of values, including the values reused from a previous
+// release. As with --set, a nil injected value removes the key: it is kept as
+// nil, whether or not vals sets the key, so that coalescing the values removes
+// the key from the chart defaults too.
+//
+// Neither map is modified.
+func injectValues(vals, injected map[string]interface{}) (map[string]interface{}, error) {
+	if len(injected) == 0 {
+		return vals, nil
+	}
+	v, err := copystructure.Copy(injected)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy injected values")
+	}
+	base, err := copystructure.Copy(vals)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy values")
+	}
+	baseVals, _ := base.(map[string]interface{})
+	if baseVals == nil {
+		baseVals = map[string]interface{}{}
+	}
+	overrideValues(baseVals, v.(map[string]interface{}))
+	return baseVals, nil
+}
+
+// overrideValues sets every key of src in dst, merging the tables both of
+// them hold.
+func overrideValues(dst, src map[string]interface{}) {
+	for k, sv := range src {
+		if st, ok := sv.(map[string]interface{}); ok {
+			if dt, ok := dst[k].(map[string]interface{}); ok {
+				overrideValues(dt, st)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+}
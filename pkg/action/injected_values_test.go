@@ -0,0 +1,157 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	cliValues "helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func withHostTemplate() chartOption {
+	return func(opts *chartOptions) {
+		opts.Templates = []*chart.File{
+			{Name: "templates/host", Data: []byte("host: {{ .Values.ingress.host }}\nport: {{ .Values.ingress.port }}")},
+		}
+	}
+}
+
+// userValues merges a values file and --set as "helm install" would.
+func userValues(t *testing.T) map[string]interface{} {
+	t.Helper()
+	file := filepath.Join(ensure.TempDir(t), "values.yaml")
+	if err := ioutil.WriteFile(file, []byte("ingress:\n  host: file.example.com\n  port: 80\n  tls: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := &cliValues.Options{
+		ValueFiles: []string{file},
+		Values:     []string{"ingress.host=set.example.com", "ingress.port=8080"},
+	}
+	vals, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vals
+}
+
+var injectedValues = map[string]interface{}{
+	"ingress": map[string]interface{}{"host": "injected.example.com"},
+}
+
+var expectedInjectedConfig = map[string]interface{}{
+	"ingress": map[string]interface{}{
+		"host": "injected.example.com",
+		"port": int64(8080),
+		"tls":  true,
+	},
+}
+
+func TestInstallRelease_InjectedValues(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.InjectedValues = injectedValues
+
+	vals := userValues(t)
+	res, err := instAction.Run(buildChart(withHostTemplate()), vals)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Equal(expectedInjectedConfig, res.Config)
+	is.Contains(res.Manifest, "host: injected.example.com\nport: 8080")
+
+	// the caller's maps are left untouched
+	is.Equal("set.example.com", vals["ingress"].(map[string]interface{})["host"])
+	is.Equal(map[string]interface{}{"host": "injected.example.com"}, injectedValues["ingress"])
+}
+
+func TestTemplate_InjectedValues(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.DryRun = true
+	instAction.ClientOnly = true
+	instAction.InjectedValues = injectedValues
+
+	res, err := instAction.Run(buildChart(withHostTemplate()), userValues(t))
+	if err != nil {
+		t.Fatalf("Failed template: %s", err)
+	}
+	is.Contains(res.Manifest, "host: injected.example.com\nport: 8080")
+}
+
+func TestUpgradeRelease_InjectedValues(t *testing.T) {
+	is := assert.New(t)
+	upAction := upgradeAction(t)
+	upAction.ReuseValues = true
+	upAction.InjectedValues = map[string]interface{}{
+		"ingress": map[string]interface{}{"host": "injected.example.com"},
+		"debug":   nil,
+	}
+
+	rel := releaseStub()
+	rel.Name = "injected"
+	rel.Info.Status = release.StatusDeployed
+	rel.Config = map[string]interface{}{
+		"ingress": map[string]interface{}{"host": "reused.example.com", "tls": true},
+		"debug":   true,
+	}
+	is.NoError(upAction.cfg.Releases.Create(rel))
+
+	res, err := upAction.Run(rel.Name, buildChart(withHostTemplate()), userValues(t))
+	if err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+	expected := map[string]interface{}{"debug": nil}
+	for k, v := range expectedInjectedConfig {
+		expected[k] = v
+	}
+	is.Equal(expected, res.Config)
+	is.Contains(res.Manifest, "host: injected.example.com\nport: 8080")
+}
+
+func TestInjectValuesRemovesKeys(t *testing.T) {
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "injected"},
+		Values:   map[string]interface{}{"debug": true, "name": "value"},
+	}
+	injected := map[string]interface{}{"debug": nil}
+
+	for name, vals := range map[string]map[string]interface{}{
+		"key set by the user":        {"debug": false},
+		"key only in chart defaults": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			merged, err := injectValues(vals, injected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			coalesced, err := chartutil.CoalesceValues(chrt, merged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, chartutil.Values{"name": "value"}, coalesced)
+		})
+	}
+}
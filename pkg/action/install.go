@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path"
 	"path/filepath"
@@ -39,6 +40,7 @@ import (
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/kube"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
@@ -50,6 +52,12 @@ import (
 	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
+// DefaultGenerateNameCharset is the charset of a random generated name suffix.
+const DefaultGenerateNameCharset = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// DefaultGenerateNameLength is the length of a random generated name suffix.
+const DefaultGenerateNameLength = 8
+
 // releaseNameMaxLen is the maximum length of a release name.
 //
 // As of Kubernetes 1.4, the max limit on a name is 63 chars. We reserve 10 for
@@ -78,6 +86,8 @@ type Install struct {
 	Replace                  bool
 	Wait                     bool
 	WaitForJobs              bool
+	WaitRetries              int
+	WaitSkipKinds            []string
 	Devel                    bool
 	DependencyUpdate         bool
 	Timeout                  time.Duration
@@ -97,10 +107,74 @@ type Install struct {
 	APIVersions chartutil.VersionSet
 	// Used by helm template to render charts with .Release.IsUpgrade. Ignored if Dry-Run is false
 	IsUpgrade bool
+	// HooksOnly leaves the resources that are not hooks out of the manifest,
+	// so that the release only holds its hooks. Hooks are detected by their
+	// annotations as during a release. Ignored if Dry-Run is false
+	HooksOnly bool
+	// AllowedKinds, when set, rejects the install before anything is applied
+	// if the chart renders a resource of another kind. Entries are an API
+	// version and a kind, such as "apps/v1/Deployment", or a kind alone.
+	AllowedKinds []string
+	// ApplyTimeout, when set, fails the creation of any single resource that
+	// takes longer, apart from the Timeout spent waiting for readiness.
+	ApplyTimeout time.Duration
+	// GenerateNameSeed, when not zero, makes the name generated for a dry-run
+	// deterministic so that it can be previewed. Real installs ignore it.
+	GenerateNameSeed int64
+	// GenerateNameLength and GenerateNameCharset, when set, replace the
+	// timestamp suffix of a generated name with a random suffix of that many
+	// characters drawn from the charset. They default to
+	// DefaultGenerateNameLength and DefaultGenerateNameCharset.
+	GenerateNameLength  int
+	GenerateNameCharset string
+	// StrictSchema rejects values that the chart schemas do not declare, even
+	// where they allow additional properties.
+	StrictSchema bool
+	// RenderValueTemplates renders the values containing templates, such as
+	// "{{ .Values.host }}", before the chart templates. See
+	// engine.RenderValueTemplates.
+	RenderValueTemplates bool
+	// UnsetValues are paths, as read by chartutil.UnsetValueAtPath, removed
+	// from the values once they are coalesced with the chart's defaults.
+	UnsetValues []string
+	// CheckQuota compares the pods and compute resources requested by the
+	// rendered workloads with the ResourceQuotas of the namespace before
+	// installing. A quota that would be exceeded is logged as a warning, or
+	// fails the install when FailOnQuota is set.
+	CheckQuota  bool
+	FailOnQuota bool
+	// AllowDuplicateResources skips the check rejecting rendered manifests
+	// that define the same resource more than once.
+	AllowDuplicateResources bool
+	// ReleaseOptions fully specifies the .Release rendered by helm template,
+	// overriding IsUpgrade. An empty Name or Namespace defaults to ReleaseName
+	// and Namespace. Only supported with ClientOnly.
+	ReleaseOptions *chartutil.ReleaseOptions
+	// SortByName orders the rendered resources and hooks of the same kind by
+	// name, instead of by the template and position they come from, so that
+	// the manifest does not depend on how the templates are laid out. It is
+	// set by helm template.
+	SortByName bool
 	// Used by helm template to add the release as part of OutputDir path
 	// OutputDir/<ReleaseName>
 	UseReleaseName bool
 	PostRenderer   postrender.PostRenderer
+	// ReportValuesProvenance makes Run record in ValuesProvenance the source
+	// that won each key of the rendered values.
+	ReportValuesProvenance bool
+	// ValuesSources maps each key supplied by the user to its source, as
+	// reported by values.Options.MergeValues in MergeReport.Sources
+	ValuesSources map[string]string
+	// ValuesProvenance is set by Run when ReportValuesProvenance is set. See
+	// chartutil.ValuesProvenance.
+	ValuesProvenance map[string]string
+	// InjectedValues are layered above all other values, including those
+	// passed to Run, as the highest-precedence overrides. They let embedders
+	// supply values computed at call time, such as from a config service.
+	InjectedValues map[string]interface{}
+	// ReleaseAnnotations are stored with the new release revision. See
+	// release.Release.Annotations.
+	ReleaseAnnotations map[string]string
 }
 
 // ChartPathOptions captures common options used for controlling chart paths
@@ -115,12 +189,14 @@ type ChartPathOptions struct {
 	Username              string // --username
 	Verify                bool   // --verify
 	Version               string // --version
+	UserAgent             string // User-Agent sent when downloading charts, defaults to the Helm user agent
 }
 
 // NewInstall creates a new Install object with the given configuration.
 func NewInstall(cfg *Configuration) *Install {
 	return &Install{
-		cfg: cfg,
+		cfg:         cfg,
+		WaitRetries: kube.DefaultWaitRetries,
 	}
 }
 
@@ -135,7 +211,7 @@ func (i *Install) installCRDs(crds []chart.CRD) error {
 		}
 
 		// Send them to Kube
-		if _, err := i.cfg.KubeClient.Create(res); err != nil {
+		if _, err := i.cfg.createResources(res, i.ApplyTimeout); err != nil {
 			// If the error is CRD already exists, continue.
 			if apierrors.IsAlreadyExists(err) {
 				crdName := res[0].Name
@@ -185,6 +261,9 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	// Pre-install anything in the crd/ directory. We do this before Helm
 	// contacts the upstream server and builds the capabilities object.
 	if crds := chrt.CRDObjects(); !i.ClientOnly && !i.SkipCRDs && len(crds) > 0 {
+		if err := i.checkAllowedCRDs(crds); err != nil {
+			return nil, err
+		}
 		// On dry run, bail here
 		if i.DryRun {
 			i.cfg.Log("WARNING: This chart or one of its subcharts contains CRDs. Rendering may fail or contain inaccuracies.")
@@ -207,6 +286,11 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		i.cfg.Log("API Version list given outside of client only mode, this list will be ignored")
 	}
 
+	vals, err := injectValues(vals, i.InjectedValues)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
 		return nil, err
 	}
@@ -229,17 +313,60 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		IsInstall: !isUpgrade,
 		IsUpgrade: isUpgrade,
 	}
+	if i.ReleaseOptions != nil {
+		if !i.ClientOnly {
+			return nil, errors.New("release options can only be specified when rendering client-side")
+		}
+		// The options are validated before the defaults are applied: like
+		// without release options, ReleaseName is used as is, such as the
+		// RELEASE-NAME placeholder of helm template.
+		options = *i.ReleaseOptions
+		if err := options.Validate(); err != nil {
+			return nil, errors.Wrap(err, "invalid release options")
+		}
+		if options.Name == "" {
+			options.Name = i.ReleaseName
+		}
+		if options.Namespace == "" {
+			options.Namespace = i.Namespace
+		}
+		isUpgrade = options.IsUpgrade
+	}
 	valuesToRender, err := chartutil.ToRenderValues(chrt, vals, options, caps)
 	if err != nil {
 		return nil, err
 	}
+	if err := chartutil.UnsetRenderValues(valuesToRender, i.UnsetValues); err != nil {
+		return nil, err
+	}
+	if i.RenderValueTemplates {
+		if err := engine.RenderValueTemplates(chrt, valuesToRender); err != nil {
+			return nil, err
+		}
+	}
+	if i.StrictSchema {
+		if err := checkUnknownValues(chrt, valuesToRender); err != nil {
+			return nil, err
+		}
+	}
+	if i.ReportValuesProvenance {
+		i.ValuesProvenance = chartutil.ValuesProvenance(chrt, valuesToRender["Values"].(chartutil.Values), i.ValuesSources)
+	}
 
 	rel := i.createRelease(chrt, vals)
+	rel.Name, rel.Namespace, rel.Version = options.Name, options.Namespace, options.Revision
+
+	hooksOnly := i.HooksOnly && i.DryRun
+	outputDir := i.OutputDir
+	if hooksOnly {
+		// the regular resources are discarded, do not write them either
+		outputDir = ""
+	}
 
 	var manifestDoc *bytes.Buffer
-	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, i.DryRun)
+	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, outputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.SortByName, i.PostRenderer, i.DryRun)
 	// Even for errors, attach this if available
-	if manifestDoc != nil {
+	if manifestDoc != nil && !hooksOnly {
 		rel.Manifest = manifestDoc.String()
 	}
 	// Check error from render
@@ -249,6 +376,12 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		return rel, err
 	}
 
+	if !i.AllowDuplicateResources {
+		if err := checkDuplicateResources(rel.Manifest, rel.Namespace); err != nil {
+			return nil, err
+		}
+	}
+
 	// Mark this release as in-progress
 	rel.SetStatus(release.StatusPendingInstall, "Initial install underway")
 
@@ -257,6 +390,9 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to build kubernetes objects from release manifest")
 	}
+	if err := i.checkAllowedKinds(resources, rel.Hooks); err != nil {
+		return nil, err
+	}
 
 	// It is safe to use "force" here because these are resources currently rendered by the chart.
 	err = resources.Visit(setMetadataVisitor(rel.Name, rel.Namespace, true))
@@ -277,6 +413,12 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		}
 	}
 
+	if i.CheckQuota && !i.ClientOnly {
+		if err := i.checkQuota(rel); err != nil {
+			return nil, err
+		}
+	}
+
 	// Bail out here if it is a dry run
 	if i.DryRun {
 		rel.Info.Description = "Dry run complete"
@@ -318,6 +460,7 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 
 	// Store the release in history before continuing (new in Helm 3). We always know
 	// that this is a create operation.
+	rel.Info.Checkpoint = new(int)
 	if err := i.cfg.Releases.Create(rel); err != nil {
 		// We could try to recover gracefully here, but since nothing has been installed
 		// yet, this is probably safer than trying to continue when we know storage is
@@ -327,7 +470,7 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 
 	// pre-install hooks
 	if !i.DisableHooks {
-		if err := i.cfg.execHook(rel, release.HookPreInstall, i.Timeout); err != nil {
+		if err := i.cfg.execHook(rel, release.HookPreInstall, i.Timeout, i.ApplyTimeout); err != nil {
 			return i.failRelease(rel, fmt.Errorf("failed pre-install: %s", err))
 		}
 	}
@@ -335,34 +478,127 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	// At this point, we can do the install. Note that before we were detecting whether to
 	// do an update, but it's not clear whether we WANT to do an update if the re-use is set
 	// to true, since that is basically an upgrade operation.
-	if len(toBeAdopted) == 0 && len(resources) > 0 {
-		if _, err := i.cfg.KubeClient.Create(resources); err != nil {
-			return i.failRelease(rel, err)
+	if err := i.applyResources(rel, resources, toBeAdopted); err != nil {
+		return i.failRelease(rel, err)
+	}
+
+	return i.completeInstall(rel, resources)
+}
+
+// Resume continues the failed install of the named release from the resource
+// it failed at, using the chart, values and manifest stored with the release.
+//
+// Resources of the release that exist already are adopted rather than
+// created, and the pre-install hooks are only run again when they did not
+// all succeed. Releases that were not installed by this version of Helm, or
+// that failed an upgrade, cannot be resumed.
+func (i *Install) Resume(name string) (*release.Release, error) {
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	// Make sure if Atomic is set, that wait is set as well. This makes it so
+	// the user doesn't have to specify both
+	i.Wait = i.Wait || i.Atomic
+	i.ReleaseName = name
+
+	rel, err := i.cfg.Releases.Last(name)
+	if err != nil {
+		return nil, err
+	}
+	if rel.Info.Status != release.StatusFailed || rel.Info.Checkpoint == nil {
+		return nil, errors.Errorf("release %s is not a failed install that can be resumed", name)
+	}
+
+	resources, err := i.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), !i.DisableOpenAPIValidation)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes objects from release manifest")
+	}
+	if err := resources.Visit(setMetadataVisitor(rel.Name, rel.Namespace, true)); err != nil {
+		return nil, err
+	}
+	if *rel.Info.Checkpoint > len(resources) {
+		return nil, errors.Errorf("release %s was checkpointed after %d resources, but has only %d", name, *rel.Info.Checkpoint, len(resources))
+	}
+
+	// the resources of the kind the install failed at may have been created
+	// in part
+	toBeAdopted, err := existingResourceConflict(resources[*rel.Info.Checkpoint:], rel.Name, rel.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "rendered manifests contain a resource that already exists. Unable to continue with install")
+	}
+
+	i.cfg.Log("resuming install of %s after %d of %d resources", name, *rel.Info.Checkpoint, len(resources))
+	rel.SetStatus(release.StatusPendingInstall, "Resuming install")
+	if err := i.cfg.Releases.Update(rel); err != nil {
+		return rel, err
+	}
+
+	if !i.DisableHooks && !hooksSucceeded(rel, release.HookPreInstall) {
+		if err := i.cfg.execHook(rel, release.HookPreInstall, i.Timeout, i.ApplyTimeout); err != nil {
+			return i.failRelease(rel, fmt.Errorf("failed pre-install: %s", err))
 		}
-	} else if len(resources) > 0 {
-		if _, err := i.cfg.KubeClient.Update(toBeAdopted, resources, false); err != nil {
-			return i.failRelease(rel, err)
+	}
+
+	if err := i.applyResources(rel, resources, toBeAdopted); err != nil {
+		return i.failRelease(rel, err)
+	}
+
+	return i.completeInstall(rel, resources)
+}
+
+// applyResources creates the resources of rel from its checkpoint on,
+// updating the ones in toBeAdopted instead when there are any.
+func (i *Install) applyResources(rel *release.Release, resources, toBeAdopted kube.ResourceList) error {
+	remaining := resources[*rel.Info.Checkpoint:]
+	if len(remaining) == 0 {
+		return nil
+	}
+	if len(toBeAdopted) == 0 {
+		return i.createResources(rel, resources)
+	}
+	if _, err := i.cfg.updateResources(toBeAdopted, remaining, false, i.ApplyTimeout); err != nil {
+		return err
+	}
+	*rel.Info.Checkpoint = len(resources)
+	return nil
+}
+
+// createResources creates the resources of rel from its checkpoint on, one
+// kind at a time as they are ordered in the manifest, advancing the
+// checkpoint after each kind.
+func (i *Install) createResources(rel *release.Release, resources kube.ResourceList) error {
+	for start := *rel.Info.Checkpoint; start < len(resources); {
+		kind := resources[start].Object.GetObjectKind().GroupVersionKind().Kind
+		end := start + 1
+		for end < len(resources) && resources[end].Object.GetObjectKind().GroupVersionKind().Kind == kind {
+			end++
 		}
+		if _, err := i.cfg.createResources(resources[start:end], i.ApplyTimeout); err != nil {
+			return err
+		}
+		*rel.Info.Checkpoint = end
+		start = end
 	}
+	return nil
+}
 
+// completeInstall waits for the created resources of rel, runs the
+// post-install hooks and records the release as deployed.
+func (i *Install) completeInstall(rel *release.Release, resources kube.ResourceList) (*release.Release, error) {
 	if i.Wait {
-		if i.WaitForJobs {
-			if err := i.cfg.KubeClient.WaitWithJobs(resources, i.Timeout); err != nil {
-				return i.failRelease(rel, err)
-			}
-		} else {
-			if err := i.cfg.KubeClient.Wait(resources, i.Timeout); err != nil {
-				return i.failRelease(rel, err)
-			}
+		if err := i.cfg.waitForResources(resources, i.Timeout, i.WaitForJobs, i.WaitRetries, i.WaitSkipKinds); err != nil {
+			return i.failRelease(rel, err)
 		}
 	}
 
 	if !i.DisableHooks {
-		if err := i.cfg.execHook(rel, release.HookPostInstall, i.Timeout); err != nil {
+		if err := i.cfg.execHook(rel, release.HookPostInstall, i.Timeout, i.ApplyTimeout); err != nil {
 			return i.failRelease(rel, fmt.Errorf("failed post-install: %s", err))
 		}
 	}
 
+	rel.Info.Checkpoint = nil
 	if len(i.Description) > 0 {
 		rel.SetStatus(release.StatusDeployed, i.Description)
 	} else {
@@ -383,6 +619,33 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	return rel, nil
 }
 
+// checkQuota checks the rendered manifest of rel against the ResourceQuotas
+// of its namespace.
+func (i *Install) checkQuota(rel *release.Release) error {
+	p := NewPreflight(i.cfg)
+	p.Namespace = rel.Namespace
+	results, err := p.CheckManifestQuota(rel.Manifest)
+	if err != nil {
+		return errors.Wrap(err, "unable to check the resource quotas")
+	}
+	var exceeded []string
+	for _, r := range results {
+		if !r.Passed {
+			exceeded = append(exceeded, r.Message)
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	if i.FailOnQuota {
+		return errors.Errorf("the release does not fit the resource quotas of namespace %s: %s", rel.Namespace, strings.Join(exceeded, "; "))
+	}
+	for _, msg := range exceeded {
+		i.cfg.Log("warning: %s", msg)
+	}
+	return nil
+}
+
 func (i *Install) failRelease(rel *release.Release, err error) (*release.Release, error) {
 	rel.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", i.ReleaseName, err.Error()))
 	if i.Atomic {
@@ -400,6 +663,19 @@ func (i *Install) failRelease(rel *release.Release, err error) (*release.Release
 	return rel, err
 }
 
+// hooksSucceeded reports whether the last run of every hook of rel for event
+// succeeded.
+func hooksSucceeded(rel *release.Release, event release.HookEvent) bool {
+	for _, h := range rel.Hooks {
+		for _, e := range h.Events {
+			if e == event && h.LastRun.Phase != release.HookPhaseSucceeded {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // availableName tests whether a name is available
 //
 // Roughly, this will return an error if name is
@@ -448,7 +724,8 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]interface{
 			LastDeployed:  ts,
 			Status:        release.StatusUnknown,
 		},
-		Version: 1,
+		Version:     1,
+		Annotations: i.ReleaseAnnotations,
 	}
 }
 
@@ -573,7 +850,62 @@ func (i *Install) NameAndChart(args []string) (string, string, error) {
 		base = base[0:idx]
 	}
 
-	return fmt.Sprintf("%s-%d", base, time.Now().Unix()), args[0], nil
+	if i.GenerateNameLength == 0 && i.GenerateNameCharset == "" {
+		return fmt.Sprintf("%s-%d", base, i.generatedNameSuffix()), args[0], nil
+	}
+
+	suffix, err := i.randomNameSuffix()
+	if err != nil {
+		return "", args[0], err
+	}
+	name := fmt.Sprintf("%s-%s", base, suffix)
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return "", args[0], errors.Wrapf(err, "generated name %q", name)
+	}
+	return name, args[0], nil
+}
+
+// randomNameSuffix returns a suffix of GenerateNameLength characters drawn
+// from GenerateNameCharset. Like generatedNameSuffix, it is stable on a
+// dry-run with a GenerateNameSeed.
+func (i *Install) randomNameSuffix() (string, error) {
+	length, charset := i.GenerateNameLength, i.GenerateNameCharset
+	if length == 0 {
+		length = DefaultGenerateNameLength
+	}
+	if charset == "" {
+		charset = DefaultGenerateNameCharset
+	}
+	if length < 0 {
+		return "", errors.Errorf("invalid generated name length %d", length)
+	}
+	// the suffix ends the release name, so separators are not allowed
+	for _, c := range charset {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return "", errors.Errorf("invalid generated name charset %q: only lowercase letters and digits are allowed", charset)
+		}
+	}
+
+	seed := time.Now().UnixNano()
+	if i.DryRun && i.GenerateNameSeed != 0 {
+		seed = i.GenerateNameSeed
+	}
+	r := rand.New(rand.NewSource(seed))
+	suffix := make([]byte, length)
+	for n := range suffix {
+		suffix[n] = charset[r.Intn(len(charset))]
+	}
+	return string(suffix), nil
+}
+
+// generatedNameSuffix returns the suffix of a generated release name: the
+// current time, or a number derived from GenerateNameSeed on a dry-run.
+func (i *Install) generatedNameSuffix() int64 {
+	if i.DryRun && i.GenerateNameSeed != 0 {
+		// keep the ten digits of a Unix timestamp
+		return 1e9 + rand.New(rand.NewSource(i.GenerateNameSeed)).Int63n(9e9)
+	}
+	return time.Now().Unix()
 }
 
 // TemplateName renders a name template, returning the name or an error.
@@ -652,6 +984,7 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 			getter.WithBasicAuth(c.Username, c.Password),
 			getter.WithTLSClientConfig(c.CertFile, c.KeyFile, c.CaFile),
 			getter.WithInsecureSkipVerifyTLS(c.InsecureSkipTLSverify),
+			getter.WithUserAgent(c.UserAgent),
 		},
 		RepositoryConfig: settings.RepositoryConfig,
 		RepositoryCache:  settings.RepositoryCache,
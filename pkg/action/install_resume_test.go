@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	restfake "k8s.io/client-go/rest/fake"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// checkpointKubeClient builds a fixed list of resources, none of which
+// exists in the cluster, and fails to create resources of failKind.
+type checkpointKubeClient struct {
+	kubefake.FailingKubeClient
+	resources kube.ResourceList
+	failKind  string
+	created   []string
+}
+
+func (c *checkpointKubeClient) Build(_ io.Reader, _ bool) (kube.ResourceList, error) {
+	return c.resources, nil
+}
+
+func (c *checkpointKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	for _, r := range resources {
+		if r.Object.GetObjectKind().GroupVersionKind().Kind == c.failKind {
+			return nil, errors.Errorf("creating %s failed", r.Name)
+		}
+	}
+	for _, r := range resources {
+		c.created = append(c.created, r.Name)
+	}
+	return &kube.Result{Created: resources}, nil
+}
+
+func newNotFoundResource(kind, name string) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace("spaced")
+	return &resource.Info{
+		Name:      name,
+		Namespace: "spaced",
+		Object:    obj,
+		Mapping: &meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Version: "v1", Resource: strings.ToLower(kind) + "s"},
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: kind},
+			Scope:            meta.RESTScopeNamespace,
+		},
+		Client: &restfake.RESTClient{
+			NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+			Client: restfake.CreateHTTPClient(func(*http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+			}),
+		},
+	}
+}
+
+func TestInstallRelease_Resume(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+	client := &checkpointKubeClient{
+		FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}},
+		resources: kube.ResourceList{
+			newNotFoundResource("ConfigMap", "settings"),
+			newNotFoundResource("ConfigMap", "scripts"),
+			newNotFoundResource("Service", "web"),
+			newNotFoundResource("Deployment", "web-app"),
+			newNotFoundResource("Ingress", "web-ingress"),
+		},
+		failKind: "Deployment",
+	}
+	cfg.KubeClient = client
+
+	instAction := NewInstall(cfg)
+	instAction.Namespace = "spaced"
+	instAction.ReleaseName = "resumable"
+	instAction.DisableHooks = true
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	require.Error(t, err)
+	is.Contains(err.Error(), "creating web-app failed")
+	is.Equal(release.StatusFailed, res.Info.Status)
+	require.NotNil(t, res.Info.Checkpoint)
+	is.Equal(3, *res.Info.Checkpoint)
+	is.Equal([]string{"settings", "scripts", "web"}, client.created)
+
+	client.failKind = ""
+	client.created = nil
+	resume := NewInstall(cfg)
+	resume.DisableHooks = true
+	res, err = resume.Resume("resumable")
+	require.NoError(t, err)
+	is.Equal(release.StatusDeployed, res.Info.Status)
+	is.Nil(res.Info.Checkpoint)
+	is.Equal([]string{"web-app", "web-ingress"}, client.created)
+
+	stored, err := cfg.Releases.Get("resumable", 1)
+	require.NoError(t, err)
+	is.Equal(release.StatusDeployed, stored.Info.Status)
+
+	// a deployed release has nothing to resume
+	_, err = resume.Resume("resumable")
+	is.Error(err)
+}
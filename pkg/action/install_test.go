@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -112,6 +113,30 @@ func TestInstallReleaseWithValues(t *testing.T) {
 	is.Equal(expectedUserValues, rel.Config)
 }
 
+func TestInstallReleaseValuesProvenance(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReportValuesProvenance = true
+	instAction.ValuesSources = map[string]string{
+		"nestedKey.simpleKey": "override.yaml",
+	}
+	userVals := map[string]interface{}{
+		"nestedKey": map[string]interface{}{
+			"simpleKey": "overridden",
+		},
+	}
+	_, err := instAction.Run(buildChart(withSampleValues()), userVals)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	is.Equal(map[string]string{
+		"someKey":             "chart default (hello)",
+		"nestedKey.simpleKey": "override.yaml",
+		"nestedKey.anotherNestedKey.yetAnotherNestedKey.youReadyForAnotherNestedKey": "chart default (hello)",
+	}, instAction.ValuesProvenance)
+}
+
 func TestInstallReleaseClientOnly(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -241,6 +266,30 @@ func TestInstallRelease_DryRun(t *testing.T) {
 	is.Equal(res.Info.Description, "Dry run complete")
 }
 
+func TestInstallRelease_DryRun_HooksOnly(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.DryRun = true
+	instAction.HooksOnly = true
+	res, err := instAction.Run(buildChart(withSampleTemplates()), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	is.Empty(res.Manifest)
+	is.Len(res.Hooks, 1)
+	is.Contains(res.Hooks[0].Manifest, "helm.sh/hook")
+
+	// a real install keeps the manifest
+	instAction = installAction(t)
+	instAction.HooksOnly = true
+	res, err = instAction.Run(buildChart(withSampleTemplates()), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Contains(res.Manifest, "hello: world")
+}
+
 // Regression test for #7955: Lookup must not connect to Kubernetes on a dry-run.
 func TestInstallRelease_DryRun_Lookup(t *testing.T) {
 	is := assert.New(t)
@@ -308,6 +357,38 @@ func TestInstallRelease_FailedHooks(t *testing.T) {
 	is.Equal(release.StatusFailed, res.Info.Status)
 }
 
+func TestInstallRelease_IgnoredFailedHooks(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = "ignored-failed-hooks"
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WatchUntilReadyError = fmt.Errorf("Failed watch")
+	instAction.cfg.KubeClient = failer
+
+	chrt := buildChart()
+	chrt.Templates[1].Data = []byte(`kind: ConfigMap
+metadata:
+  name: test-cm
+  annotations:
+    "helm.sh/hook": post-install
+    "helm.sh/hook-failure-policy": ignore
+data:
+  name: value`)
+
+	vals := map[string]interface{}{}
+	res, err := instAction.Run(chrt, vals)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Equal(release.StatusDeployed, res.Info.Status)
+	is.Equal(release.HookFailurePolicyIgnore, res.Hooks[0].FailurePolicy)
+	is.Equal(release.HookPhaseFailed, res.Hooks[0].LastRun.Phase, "expect the hook failure to be recorded")
+
+	rel, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	is.NoError(err)
+	is.Equal(release.HookPhaseFailed, rel.Hooks[0].LastRun.Phase)
+}
+
 func TestInstallRelease_ReplaceRelease(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -617,42 +698,36 @@ func TestNameAndChartGenerateName(t *testing.T) {
 	instAction.GenerateName = true
 
 	tests := []struct {
-		Name         string
-		Chart        string
-		ExpectedName string
+		Name  string
+		Chart string
 	}{
 		{
 			"local filepath",
 			"./chart",
-			fmt.Sprintf("chart-%d", time.Now().Unix()),
 		},
 		{
 			"dot filepath",
 			".",
-			fmt.Sprintf("chart-%d", time.Now().Unix()),
 		},
 		{
 			"empty filepath",
 			"",
-			fmt.Sprintf("chart-%d", time.Now().Unix()),
 		},
 		{
 			"packaged chart",
 			"chart.tgz",
-			fmt.Sprintf("chart-%d", time.Now().Unix()),
 		},
 		{
 			"packaged chart with .tar.gz extension",
 			"chart.tar.gz",
-			fmt.Sprintf("chart-%d", time.Now().Unix()),
 		},
 		{
 			"packaged chart with local extension",
 			"./chart.tgz",
-			fmt.Sprintf("chart-%d", time.Now().Unix()),
 		},
 	}
 
+	start := time.Now().Unix()
 	for _, tc := range tests {
 		tc := tc
 		t.Run(tc.Name, func(t *testing.T) {
@@ -663,8 +738,220 @@ func TestNameAndChartGenerateName(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			is.Equal(tc.ExpectedName, name)
+			assertGeneratedName(t, name, "chart", start)
 			is.Equal(tc.Chart, chrt)
 		})
 	}
 }
+
+func TestNameAndChartGenerateNameSeed(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = ""
+	instAction.GenerateName = true
+	instAction.DryRun = true
+	instAction.GenerateNameSeed = 42
+
+	first, _, err := instAction.NameAndChart([]string{"./chart"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := instAction.NameAndChart([]string{"./chart"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal(first, second, "expected the same name for the same seed")
+	is.Regexp(`^chart-\d{10}$`, first)
+
+	instAction.GenerateNameSeed = 43
+	other, _, err := instAction.NameAndChart([]string{"./chart"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.NotEqual(first, other, "expected a different name for a different seed")
+
+	// the seed is ignored outside of a dry-run
+	instAction.DryRun = false
+	start := time.Now().Unix()
+	name, _, err := instAction.NameAndChart([]string{"./chart"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGeneratedName(t, name, "chart", start)
+}
+
+// assertGeneratedName checks that name is prefix followed by the time it was
+// generated at, between start and now.
+func assertGeneratedName(t *testing.T, name, prefix string, start int64) {
+	t.Helper()
+	end := time.Now().Unix()
+	if !strings.HasPrefix(name, prefix+"-") {
+		t.Fatalf("expected a name starting with %q, got %q", prefix+"-", name)
+	}
+	generated, err := strconv.ParseInt(strings.TrimPrefix(name, prefix+"-"), 10, 64)
+	if err != nil {
+		t.Fatalf("expected a name ending with a timestamp, got %q", name)
+	}
+	if generated < start || generated > end {
+		t.Errorf("expected a timestamp between %d and %d, got %d", start, end, generated)
+	}
+}
+
+func TestInstallReleaseOptions(t *testing.T) {
+	is := assert.New(t)
+	tpl := &chart.File{
+		Name: "templates/release",
+		Data: []byte("revision: {{ .Release.Revision }}\n{{ if .Release.IsUpgrade }}upgrade: true{{ else }}install: true{{ end }}\nservice: {{ .Release.Service }}"),
+	}
+	withReleaseTemplate := func(opts *chartOptions) {
+		opts.Templates = append(opts.Templates, tpl)
+	}
+
+	instAction := installAction(t)
+	instAction.ClientOnly = true
+	instAction.DryRun = true
+	instAction.ReleaseOptions = &chartutil.ReleaseOptions{Revision: 1, IsInstall: true}
+	res, err := instAction.Run(buildChart(withReleaseTemplate), nil)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Contains(res.Manifest, "revision: 1\ninstall: true\nservice: Helm")
+
+	instAction = installAction(t)
+	instAction.ClientOnly = true
+	instAction.DryRun = true
+	instAction.ReleaseOptions = &chartutil.ReleaseOptions{Revision: 4, IsUpgrade: true, Service: "Tiller"}
+	res, err = instAction.Run(buildChart(withReleaseTemplate), nil)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Contains(res.Manifest, "revision: 4\nupgrade: true\nservice: Tiller")
+	is.Equal(4, res.Version)
+
+	instAction = installAction(t)
+	instAction.ClientOnly = true
+	instAction.DryRun = true
+	instAction.ReleaseOptions = &chartutil.ReleaseOptions{Revision: 1, IsUpgrade: true}
+	_, err = instAction.Run(buildChart(withReleaseTemplate), nil)
+	is.Error(err, "an upgrade with revision 1 is invalid")
+
+	instAction = installAction(t)
+	instAction.ReleaseOptions = &chartutil.ReleaseOptions{Revision: 1, IsInstall: true}
+	_, err = instAction.Run(buildChart(withReleaseTemplate), nil)
+	is.Error(err, "release options are only supported client-side")
+}
+
+func TestNameAndChartGenerateNameSuffix(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = ""
+	instAction.GenerateName = true
+	instAction.GenerateNameLength = 12
+
+	name, _, err := instAction.NameAndChart([]string{"./chart"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Regexp(`^chart-[a-z0-9]{12}$`, name)
+
+	instAction.GenerateNameLength = 0
+	instAction.GenerateNameCharset = "abc"
+	name, _, err = instAction.NameAndChart([]string{"./chart"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Regexp(fmt.Sprintf(`^chart-[abc]{%d}$`, DefaultGenerateNameLength), name)
+
+	// a seeded dry-run keeps the random suffix stable
+	instAction.DryRun = true
+	instAction.GenerateNameSeed = 42
+	first, _, err := instAction.NameAndChart([]string{"./chart"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := instAction.NameAndChart([]string{"./chart"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal(first, second)
+
+	instAction.GenerateNameCharset = "ABC"
+	_, _, err = instAction.NameAndChart([]string{"./chart"})
+	is.Error(err, "expected uppercase characters to be rejected")
+
+	instAction.GenerateNameCharset = ""
+	instAction.GenerateNameLength = 60
+	_, _, err = instAction.NameAndChart([]string{"./chart"})
+	is.Error(err, "expected a name longer than 53 characters to be rejected")
+}
+
+func TestInstallRelease_ReleaseAnnotations(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseAnnotations = map[string]string{"commit": "1a2b3c4"}
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	rel, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	is.NoError(err)
+	is.Equal(map[string]string{"commit": "1a2b3c4"}, rel.Annotations)
+}
+
+func TestInstallRelease_SortByName(t *testing.T) {
+	is := assert.New(t)
+	withUnsortedTemplates := func(opts *chartOptions) {
+		opts.Templates = append(opts.Templates,
+			&chart.File{Name: "templates/a", Data: []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: beta\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: zeta\n")},
+			&chart.File{Name: "templates/b", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: alpha\n")},
+		)
+	}
+
+	var manifests []string
+	for i := 0; i < 2; i++ {
+		instAction := installAction(t)
+		instAction.DryRun = true
+		instAction.SortByName = true
+		res, err := instAction.Run(buildChart(withUnsortedTemplates), map[string]interface{}{})
+		if err != nil {
+			t.Fatalf("Failed install: %s", err)
+		}
+		manifests = append(manifests, res.Manifest)
+	}
+
+	is.Equal(manifests[0], manifests[1])
+	alpha := strings.Index(manifests[0], "name: alpha")
+	zeta := strings.Index(manifests[0], "name: zeta")
+	beta := strings.Index(manifests[0], "name: beta")
+	is.True(alpha >= 0 && alpha < zeta, "expected ConfigMap alpha before ConfigMap zeta")
+	is.True(zeta < beta, "expected ConfigMaps before the Service")
+}
+
+func TestInstallRelease_UnsetValues(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.DryRun = true
+	instAction.UnsetValues = []string{"image.tag", "hosts[1]"}
+	withValuesTemplate := func(opts *chartOptions) {
+		opts.Templates = append(opts.Templates, &chart.File{
+			Name: "templates/values",
+			Data: []byte("values: {{ toJson .Values }}"),
+		})
+	}
+	chartDefaults := withValues(map[string]interface{}{
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.19"},
+		"hosts": []interface{}{"a.example.com", "b.example.com", "c.example.com"},
+	})
+	res, err := instAction.Run(buildChart(chartDefaults, withValuesTemplate), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Contains(res.Manifest, `values: {"hosts":["a.example.com","c.example.com"],"image":{"repository":"nginx"}}`)
+
+	instAction = installAction(t)
+	instAction.DryRun = true
+	instAction.UnsetValues = []string{"image[0]"}
+	_, err = instAction.Run(buildChart(chartDefaults, withValuesTemplate), map[string]interface{}{})
+	is.Error(err)
+}
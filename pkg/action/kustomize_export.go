@@ -0,0 +1,138 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// kustomizationFile is the name of the kustomization file of a kustomize base.
+const kustomizationFile = "kustomization.yaml"
+
+// kustomization is the kustomization file written by KustomizeExport.
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
+}
+
+// KustomizeExport is the action for exporting the resources of a release as
+// a kustomize base.
+//
+// Each resource of the stored release manifest is written to its own file in
+// Destination, named after its kind and name, and listed in install order by a
+// generated kustomization.yaml. Hooks are not exported. Resources are written
+// as rendered, so those without a namespace are not bound to the namespace of
+// the release.
+type KustomizeExport struct {
+	cfg *Configuration
+
+	// Initializing Version to 0 will export the latest revision of the release.
+	Version int
+	// Destination is the directory the kustomize base is written to. It is
+	// created if it does not exist.
+	Destination string
+}
+
+// NewKustomizeExport creates a new KustomizeExport object with the given configuration.
+func NewKustomizeExport(cfg *Configuration) *KustomizeExport {
+	return &KustomizeExport{
+		cfg: cfg,
+	}
+}
+
+// Run exports the named release and returns the names of the resource files
+// written, in install order.
+func (k *KustomizeExport) Run(name string) ([]string, error) {
+	rel, err := k.cfg.releaseContent(name, k.Version)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := k.cfg.getCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	_, manifests, err := releaseutil.SortManifests(releaseutil.SplitManifests(rel.Manifest), caps.APIVersions, releaseutil.InstallOrder)
+	if err != nil {
+		return nil, errors.Wrapf(err, "corrupted release record for %s", name)
+	}
+
+	kfile := filepath.Join(k.Destination, kustomizationFile)
+	if _, err := os.Stat(kfile); err == nil {
+		return nil, errors.Errorf("%s already exists", kfile)
+	}
+	if err := os.MkdirAll(k.Destination, 0755); err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(manifests))
+	used := map[string]bool{}
+	for _, m := range manifests {
+		file := resourceFileName(m, used)
+		content := strings.TrimSpace(m.Content) + "\n"
+		if err := ioutil.WriteFile(filepath.Join(k.Destination, file), []byte(content), 0644); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	data, err := yaml.Marshal(kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  files,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, ioutil.WriteFile(kfile, data, 0644)
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// resourceFileName returns the file name of the resource in m, such as
+// "deployment-web.yaml", that is not yet in used. Resources sharing a kind and
+// name get a numbered suffix in the order they are exported.
+func resourceFileName(m releaseutil.Manifest, used map[string]bool) string {
+	base := "resource"
+	if m.Head != nil {
+		base = strings.ToLower(m.Head.Kind)
+		if m.Head.Metadata != nil && m.Head.Metadata.Name != "" {
+			base += "-" + strings.ToLower(m.Head.Metadata.Name)
+		}
+	}
+	base = strings.Trim(unsafeFileNameChars.ReplaceAllString(base, "-"), "-.")
+	if base == "" {
+		base = "resource"
+	}
+
+	file := base + ".yaml"
+	for i := 2; used[file] || file == kustomizationFile; i++ {
+		file = fmt.Sprintf("%s-%d.yaml", base, i)
+	}
+	used[file] = true
+	return file
+}
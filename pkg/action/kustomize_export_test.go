@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+)
+
+func TestKustomizeExport(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	rel := releaseStub()
+	rel.Manifest = `---
+# Source: hello/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: hello/templates/settings.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+# Source: hello/templates/settings-other.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: other
+---
+# Source: hello/templates/namespace.yaml
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: other
+`
+	require.NoError(t, cfg.Releases.Create(rel))
+
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+
+	export := NewKustomizeExport(cfg)
+	export.Destination = filepath.Join(dir, "base")
+	files, err := export.Run(rel.Name)
+	require.NoError(t, err)
+
+	expected := []string{
+		"namespace-other.yaml",
+		"configmap-settings.yaml",
+		"configmap-settings-2.yaml",
+		"deployment-web.yaml",
+	}
+	assert.Equal(t, expected, files)
+
+	kustomization, err := ioutil.ReadFile(filepath.Join(export.Destination, "kustomization.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- namespace-other.yaml
+- configmap-settings.yaml
+- configmap-settings-2.yaml
+- deployment-web.yaml
+`, string(kustomization))
+
+	other, err := ioutil.ReadFile(filepath.Join(export.Destination, "configmap-settings-2.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(other), "namespace: other")
+
+	// an existing base is not overwritten
+	_, err = export.Run(rel.Name)
+	assert.Error(t, err)
+}
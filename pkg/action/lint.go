@@ -20,12 +20,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/lint"
+	"helm.sh/helm/v3/pkg/lint/rules"
 	"helm.sh/helm/v3/pkg/lint/support"
 )
 
@@ -36,6 +38,22 @@ type Lint struct {
 	Strict        bool
 	Namespace     string
 	WithSubcharts bool
+	// UnusedValues warns about the values that no template references
+	UnusedValues bool
+	// KubeVersion, when set, reports the rendered resources using APIs
+	// deprecated or removed in this Kubernetes version
+	KubeVersion string
+	// UnsetValues are paths, as read by chartutil.UnsetValueAtPath, removed
+	// from the values once they are coalesced with the chart's defaults
+	UnsetValues []string
+	// RequireResources, when set, reports the containers of the rendered
+	// workloads that declare no resource requests, limits or either, with
+	// RequireResourcesSeverity
+	RequireResources         rules.ResourceRequirement
+	RequireResourcesSeverity int
+	// SecretValuePattern matches the dotted paths of the values NOTES.txt
+	// should not print. It defaults to rules.DefaultSecretValuePattern.
+	SecretValuePattern *regexp.Regexp
 }
 
 // LintResult is the result of Lint
@@ -43,11 +61,27 @@ type LintResult struct {
 	TotalChartsLinted int
 	Messages          []support.Message
 	Errors            []error
+	// ChartMessages holds the Messages, and the errors of the charts that
+	// could not be linted, along with the chart they were found in.
+	ChartMessages []LintMessage
+}
+
+// LintMessage is a lint message in a form suitable for serialization.
+type LintMessage struct {
+	// Chart is the path of the linted chart
+	Chart string `json:"chart"`
+	// Severity is the name of the severity, such as "WARNING"
+	Severity string `json:"severity"`
+	// Path is the file or directory within the chart the message is about
+	Path    string `json:"path"`
+	Message string `json:"message"`
 }
 
 // NewLint creates a new Lint object with the given configuration.
 func NewLint() *Lint {
-	return &Lint{}
+	return &Lint{
+		RequireResourcesSeverity: support.WarningSev,
+	}
 }
 
 // Run executes 'helm Lint' against the given chart.
@@ -58,15 +92,26 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	}
 	result := &LintResult{}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.Strict)
+		linter, err := l.lintChart(path, vals)
 		if err != nil {
 			result.Errors = append(result.Errors, err)
+			result.ChartMessages = append(result.ChartMessages, LintMessage{
+				Chart:    path,
+				Severity: support.SeverityName(support.ErrorSev),
+				Message:  err.Error(),
+			})
 			continue
 		}
 
 		result.Messages = append(result.Messages, linter.Messages...)
 		result.TotalChartsLinted++
 		for _, msg := range linter.Messages {
+			result.ChartMessages = append(result.ChartMessages, LintMessage{
+				Chart:    path,
+				Severity: support.SeverityName(msg.Severity),
+				Path:     msg.Path,
+				Message:  msg.Err.Error(),
+			})
 			if msg.Severity >= lowestTolerance {
 				result.Errors = append(result.Errors, msg.Err)
 			}
@@ -75,7 +120,7 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	return result
 }
 
-func lintChart(path string, vals map[string]interface{}, namespace string, strict bool) (support.Linter, error) {
+func (l *Lint) lintChart(path string, vals map[string]interface{}) (support.Linter, error) {
 	var chartPath string
 	linter := support.Linter{}
 
@@ -114,5 +159,13 @@ func lintChart(path string, vals map[string]interface{}, namespace string, stric
 		return linter, errors.Wrap(err, "unable to check Chart.yaml file in chart")
 	}
 
-	return lint.All(chartPath, vals, namespace, strict), nil
+	linter = lint.AllWithOptions(chartPath, vals, l.Namespace, lint.Options{
+		KubeVersion:              l.KubeVersion,
+		UnsetValues:              l.UnsetValues,
+		UnusedValues:             l.UnusedValues,
+		RequireResources:         l.RequireResources,
+		RequireResourcesSeverity: l.RequireResourcesSeverity,
+		SecretValuePattern:       l.SecretValuePattern,
+	})
+	return linter, nil
 }
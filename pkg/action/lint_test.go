@@ -78,7 +78,8 @@ func TestLintChart(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, strict)
+			testLint := &Lint{Namespace: namespace, Strict: strict}
+			_, err := testLint.lintChart(tt.chartPath, map[string]interface{}{})
 			switch {
 			case err != nil && !tt.err:
 				t.Errorf("%s", err)
@@ -158,3 +159,37 @@ func TestLint_ChartWithWarnings(t *testing.T) {
 		}
 	})
 }
+
+func TestLint_ChartMessages(t *testing.T) {
+	testCharts := []string{chartWithNoTemplatesDir, "non-existent-chart.tgz"}
+	result := NewLint().Run(testCharts, values)
+
+	expected := []LintMessage{
+		{
+			Chart:    chartWithNoTemplatesDir,
+			Severity: "WARNING",
+			Path:     "templates/",
+			Message:  "directory not found",
+		},
+		{
+			Chart:    "non-existent-chart.tgz",
+			Severity: "ERROR",
+			Message:  "unable to open tarball: open non-existent-chart.tgz: no such file or directory",
+		},
+	}
+	for _, want := range expected {
+		found := false
+		for _, msg := range result.ChartMessages {
+			if msg == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected message %+v in %+v", want, result.ChartMessages)
+		}
+	}
+	if len(result.ChartMessages) != len(result.Messages)+1 {
+		t.Errorf("expected a message for each lint message and chart error, got %d", len(result.ChartMessages))
+	}
+}
@@ -17,13 +17,16 @@ limitations under the License.
 package action
 
 import (
+	"fmt"
 	"path"
 	"regexp"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/labels"
 
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 // ListStates represents zero or more status codes that a list item may have set
@@ -158,14 +161,20 @@ func (l *List) Run() ([]*release.Release, error) {
 		}
 	}
 
-	results, err := l.cfg.Releases.List(func(rel *release.Release) bool {
+	// The selector is parsed first, as the paged listing applies it too
+	selectorObj, err := labels.Parse(l.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := l.listReleases(func(rel *release.Release) bool {
 		// Skip anything that doesn't match the filter.
 		if filter != nil && !filter.MatchString(rel.Name) {
 			return false
 		}
 
 		return true
-	})
+	}, selectorObj)
 
 	if err != nil {
 		return nil, err
@@ -175,23 +184,7 @@ func (l *List) Run() ([]*release.Release, error) {
 		return results, nil
 	}
 
-	// by definition, superseded releases are never shown if
-	// only the latest releases are returned. so if requested statemask
-	// is _only_ ListSuperseded, skip the latest release filter
-	if l.StateMask != ListSuperseded {
-		results = filterLatestReleases(results)
-	}
-
-	// State mask application must occur after filtering to
-	// latest releases, otherwise outdated entries can be returned
-	results = l.filterStateMask(results)
-
-	// Skip anything that doesn't match the selector
-	selectorObj, err := labels.Parse(l.Selector)
-	if err != nil {
-		return nil, err
-	}
-	results = l.filterSelector(results, selectorObj)
+	results = l.filterReleases(results, selectorObj)
 
 	// Unfortunately, we have to sort before truncating, which can incur substantial overhead
 	l.sort(results)
@@ -215,6 +208,119 @@ func (l *List) Run() ([]*release.Release, error) {
 	return results, err
 }
 
+// listReleases reads the releases satisfying filter from storage.
+//
+// When a limit is set, the releases of a single namespace are listed by
+// ascending name, the default, and the storage driver pages releases itself
+// (see driver.PagedLister), they are read page by page, Limit revisions at a
+// time, until enough releases are found to fill the requested range, rather
+// than reading every release and truncating the list afterwards. Other drivers
+// would read every release for each page, so their releases are read at once.
+//
+// The paging drivers return the releases in the order of their storage keys,
+// "<name>.v<version>", which differs from the order of their names for a name
+// that is the start of another one followed by a "-" or a ".", such as "web"
+// and "web-api". Reading stops only once no release still to be read can sort
+// by name before the last release listed.
+func (l *List) listReleases(filter func(*release.Release) bool, selector labels.Selector) ([]*release.Release, error) {
+	if l.Limit <= 0 || l.AllNamespaces || l.ByDate || l.SortReverse || l.Sort != 0 {
+		return l.cfg.Releases.List(filter)
+	}
+	paged, ok := l.cfg.Releases.Driver.(driver.PagedLister)
+	if !ok {
+		return l.cfg.Releases.List(filter)
+	}
+
+	var (
+		results []*release.Release
+		token   string
+	)
+	for {
+		page, next, err := paged.ListPaged(token, l.Limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, rls := range page {
+			if filter(rls) {
+				results = append(results, rls)
+			}
+		}
+		if next == "" {
+			return results, nil
+		}
+		token = next
+
+		if len(page) == 0 {
+			continue
+		}
+		last := page[len(page)-1]
+		complete := completeReleases(results, last)
+		filtered := l.filterReleases(complete, selector)
+		if len(filtered) < l.Offset+l.Limit {
+			continue
+		}
+		releaseutil.SortByName(filtered)
+		if !mayPrecedeUnread(filtered[l.Offset+l.Limit-1].Name, fmt.Sprintf("%s.v%d", last.Name, last.Version)) {
+			return complete, nil
+		}
+	}
+}
+
+// completeReleases returns the releases whose revisions have all been read,
+// last being the last release read in storage key order.
+//
+// The keys still to be read sort after the key of last. A release may have
+// more revisions among them only if the key of last starts with the key
+// prefix of the release, "<name>.v": that is last itself, but also a release
+// whose name is followed in the name of last by ".v", such as "web" for the
+// release "web.v2", whose revisions sort between those of "web".
+func completeReleases(releases []*release.Release, last *release.Release) []*release.Release {
+	lastKey := fmt.Sprintf("%s.v%d", last.Name, last.Version)
+	list := make([]*release.Release, 0, len(releases))
+	for _, r := range releases {
+		if r.Namespace == last.Namespace && strings.HasPrefix(lastKey, r.Name+".v") {
+			continue
+		}
+		list = append(list, r)
+	}
+	return list
+}
+
+// mayPrecedeUnread reports whether a release whose keys sort after lastKey
+// may sort by name before name. Such a release has a name that is the start
+// of name followed by "-" or ".", like "web" for "web-api", whose keys sort
+// after those of name: "web-api.v1" before "web.v1".
+func mayPrecedeUnread(name, lastKey string) bool {
+	for i := 1; i < len(name); i++ {
+		if name[i] != '-' && name[i] != '.' {
+			continue
+		}
+		prefix := name[:i] + ".v"
+		if prefix > lastKey || strings.HasPrefix(lastKey, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterReleases returns the latest revision of the releases, dropping those
+// not matching the state mask or the selector.
+func (l *List) filterReleases(results []*release.Release, selector labels.Selector) []*release.Release {
+	// by definition, superseded releases are never shown if
+	// only the latest releases are returned. so if requested statemask
+	// is _only_ ListSuperseded, skip the latest release filter
+	if l.StateMask != ListSuperseded {
+		results = filterLatestReleases(results)
+	}
+
+	// State mask application must occur after filtering to
+	// latest releases, otherwise outdated entries can be returned
+	results = l.filterStateMask(results)
+
+	// Skip anything that doesn't match the selector
+	return l.filterSelector(results, selector)
+}
+
 // sort is an in-place sort where order is based on the value of a.Sort
 func (l *List) sort(rels []*release.Release) {
 	if l.SortReverse {
@@ -17,12 +17,16 @@ limitations under the License.
 package action
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 func TestListStates(t *testing.T) {
@@ -205,6 +209,142 @@ func TestList_StateMaskWithStaleRevisions(t *testing.T) {
 	is.Equal("failed", res[0].Name)
 }
 
+// pageCountingDriver pages the releases in the order of their storage keys,
+// as the Kubernetes drivers do, and counts the pages read.
+type pageCountingDriver struct {
+	driver.Driver
+	pages int
+}
+
+func (d *pageCountingDriver) ListPaged(continueToken string, limit int) ([]*release.Release, string, error) {
+	d.pages++
+	all, err := d.List(func(*release.Release) bool { return true })
+	if err != nil {
+		return nil, "", err
+	}
+	key := func(r *release.Release) string { return fmt.Sprintf("%s.v%d", r.Name, r.Version) }
+	sort.Slice(all, func(i, j int) bool { return key(all[i]) < key(all[j]) })
+
+	offset := 0
+	if continueToken != "" {
+		offset, _ = strconv.Atoi(continueToken)
+	}
+	if offset+limit >= len(all) {
+		return all[offset:], "", nil
+	}
+	return all[offset : offset+limit], strconv.Itoa(offset + limit), nil
+}
+
+func TestList_LimitPaged(t *testing.T) {
+	is := assert.New(t)
+	lister := newListFixture(t)
+	makeMeSomeReleasesWithStaleFailure(lister.cfg.Releases, t)
+	counter := &pageCountingDriver{Driver: lister.cfg.Releases.Driver}
+	lister.cfg.Releases.Driver = counter
+
+	// the first release is found on the first page, but the second page is
+	// needed to know that the first release has no more revisions
+	lister.Limit = 1
+	res, err := lister.Run()
+	is.NoError(err)
+	is.Len(res, 1)
+	is.Equal("clean", res[0].Name)
+	is.Equal(2, counter.pages)
+
+	// the revisions of "dirty" span two pages, the latest one being deployed
+	counter.pages = 0
+	lister.Limit = 2
+	res, err = lister.Run()
+	is.NoError(err)
+	is.Len(res, 2)
+	is.Equal("clean", res[0].Name)
+	is.Equal("dirty", res[1].Name)
+	is.Equal(3, res[1].Version)
+	is.Equal(3, counter.pages)
+
+	// sorting by date needs every release
+	counter.pages = 0
+	lister.ByDate = true
+	_, err = lister.Run()
+	is.NoError(err)
+	is.Equal(0, counter.pages)
+}
+
+func TestList_LimitPagedDottedNames(t *testing.T) {
+	is := assert.New(t)
+	lister := newListFixture(t)
+
+	// the revisions of "web.v1" sort between those of "web": web.v1,
+	// web.v1.v1, web.v2
+	stale := namedReleaseStub("web", release.StatusDeployed)
+	stale.Namespace = "default"
+	latest := namedReleaseStub("web", release.StatusFailed)
+	latest.Namespace = "default"
+	latest.Version = 2
+	dotted := namedReleaseStub("web.v1", release.StatusDeployed)
+	dotted.Namespace = "default"
+	for _, rel := range []*release.Release{stale, latest, dotted} {
+		if err := lister.cfg.Releases.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counter := &pageCountingDriver{Driver: lister.cfg.Releases.Driver}
+	lister.cfg.Releases.Driver = counter
+
+	lister.Limit = 1
+	res, err := lister.Run()
+	is.NoError(err)
+	is.Len(res, 1)
+	is.Equal("web", res[0].Name)
+	is.Equal(2, res[0].Version)
+	is.Equal(3, counter.pages)
+}
+
+func TestList_LimitPagedHyphenatedNames(t *testing.T) {
+	is := assert.New(t)
+	lister := newListFixture(t)
+
+	// the keys of "web-api" sort before those of "web": web-api.v1,
+	// web-db.v1, web.v1
+	for _, name := range []string{"web", "web-api", "web-db"} {
+		rel := namedReleaseStub(name, release.StatusDeployed)
+		rel.Namespace = "default"
+		if err := lister.cfg.Releases.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counter := &pageCountingDriver{Driver: lister.cfg.Releases.Driver}
+	lister.cfg.Releases.Driver = counter
+
+	lister.Limit = 1
+	res, err := lister.Run()
+	is.NoError(err)
+	is.Len(res, 1)
+	is.Equal("web", res[0].Name)
+
+	lister.Limit = 2
+	res, err = lister.Run()
+	is.NoError(err)
+	is.Len(res, 2)
+	is.Equal("web", res[0].Name)
+	is.Equal("web-api", res[1].Name)
+}
+
+func TestList_LimitUnpagedDriver(t *testing.T) {
+	is := assert.New(t)
+	lister := newListFixture(t)
+	lister.Limit = 1
+	makeMeSomeReleases(lister.cfg.Releases, t)
+
+	// the memory driver does not page releases, so they are read at once
+	_, ok := lister.cfg.Releases.Driver.(driver.PagedLister)
+	is.False(ok)
+	res, err := lister.Run()
+	is.NoError(err)
+	is.Len(res, 1)
+	is.Equal("one", res[0].Name)
+}
+
 func makeMeSomeReleasesWithStaleFailure(store *storage.Storage, t *testing.T) {
 	t.Helper()
 	one := namedReleaseStub("clean", release.StatusDeployed)
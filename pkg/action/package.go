@@ -18,20 +18,56 @@ package action
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/mattn/go-shellwords"
 	"github.com/pkg/errors"
 	"golang.org/x/term"
 
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/provenance"
 )
 
+// PrePackageHookAnnotation is the Chart.yaml annotation declaring a command,
+// such as a code generator, run in the chart directory before it is packaged.
+const PrePackageHookAnnotation = "helm.sh/pre-package"
+
+// DefaultPrePackageHookTimeout is how long the pre-package command may run
+// when Package.PrePackageHookTimeout is not set.
+const DefaultPrePackageHookTimeout = 5 * time.Minute
+
+// Chart.yaml annotations recording the build metadata of a packaged chart.
+const (
+	BuildCommitAnnotation = "helm.sh/build-commit"
+	BuildTimeAnnotation   = "helm.sh/build-time"
+	BuilderAnnotation     = "helm.sh/builder"
+)
+
+// BuildMetadata describes the build that packaged a chart.
+type BuildMetadata struct {
+	// Commit is the source revision the chart was built from, such as a git
+	// commit.
+	Commit string
+	// Time is when the chart was built. It defaults to the current time, so
+	// set it to a fixed time to keep packaging reproducible.
+	Time time.Time
+	// Builder identifies who or what built the chart, such as a CI job.
+	Builder string
+}
+
 // Package is the action for packaging a chart.
 //
 // It provides the implementation of 'helm package'.
@@ -44,6 +80,22 @@ type Package struct {
 	AppVersion       string
 	Destination      string
 	DependencyUpdate bool
+	// SBOM is the path to a software bill of materials (e.g. SPDX or CycloneDX)
+	// written alongside the packaged chart as a sibling ".sbom" file.
+	SBOM string
+	// RunPrePackageHook runs the command declared by the chart in its
+	// PrePackageHookAnnotation before packaging it. The command is arbitrary
+	// code shipped with the chart, so it is never run unless this is set.
+	RunPrePackageHook bool
+	// PrePackageHookTimeout bounds the pre-package command. Defaults to
+	// DefaultPrePackageHookTimeout.
+	PrePackageHookTimeout time.Duration
+	// PrePackageHookOutput receives the combined output of the pre-package
+	// command. It is discarded when nil.
+	PrePackageHookOutput io.Writer
+	// BuildMetadata, when set, is recorded in the Chart.yaml annotations of
+	// the packaged chart, where 'helm show chart' displays it.
+	BuildMetadata *BuildMetadata
 
 	RepositoryConfig string
 	RepositoryCache  string
@@ -56,6 +108,12 @@ func NewPackage() *Package {
 
 // Run executes 'helm package' against the given chart and returns the path to the packaged chart.
 func (p *Package) Run(path string, vals map[string]interface{}) (string, error) {
+	if p.RunPrePackageHook {
+		if err := p.runPrePackageHook(path); err != nil {
+			return "", err
+		}
+	}
+
 	ch, err := loader.LoadDir(path)
 	if err != nil {
 		return "", err
@@ -74,6 +132,10 @@ func (p *Package) Run(path string, vals map[string]interface{}) (string, error)
 		ch.Metadata.AppVersion = p.AppVersion
 	}
 
+	if p.BuildMetadata != nil {
+		p.BuildMetadata.annotate(ch.Metadata)
+	}
+
 	if reqs := ch.Metadata.Dependencies; reqs != nil {
 		if err := CheckDependencies(ch, reqs); err != nil {
 			return "", err
@@ -98,10 +160,102 @@ func (p *Package) Run(path string, vals map[string]interface{}) (string, error)
 	}
 
 	if p.Sign {
-		err = p.Clearsign(name)
+		if err := p.Clearsign(name); err != nil {
+			return name, err
+		}
+	}
+
+	if p.SBOM != "" {
+		if err := attachSBOM(p.SBOM, name); err != nil {
+			return name, err
+		}
+	}
+
+	return name, nil
+}
+
+// annotate records the build metadata in the annotations of md, leaving out
+// the empty fields.
+func (b *BuildMetadata) annotate(md *chart.Metadata) {
+	if md.Annotations == nil {
+		md.Annotations = map[string]string{}
+	}
+	if b.Commit != "" {
+		md.Annotations[BuildCommitAnnotation] = b.Commit
+	}
+	if b.Builder != "" {
+		md.Annotations[BuilderAnnotation] = b.Builder
+	}
+	t := b.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	md.Annotations[BuildTimeAnnotation] = t.UTC().Format(time.RFC3339)
+}
+
+// runPrePackageHook runs the pre-package command declared by the chart at path
+// in the chart directory, if any.
+func (p *Package) runPrePackageHook(path string) error {
+	md, err := chartutil.LoadChartfile(filepath.Join(path, chartutil.ChartfileName))
+	if err != nil {
+		return err
+	}
+	command := strings.TrimSpace(md.Annotations[PrePackageHookAnnotation])
+	if command == "" {
+		return nil
+	}
+	args, err := shellwords.Parse(command)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s annotation", PrePackageHookAnnotation)
+	}
+	if len(args) == 0 || args[0] == "" {
+		return errors.Errorf("invalid %s annotation: no command to run", PrePackageHookAnnotation)
 	}
 
-	return name, err
+	timeout := p.PrePackageHookTimeout
+	if timeout <= 0 {
+		timeout = DefaultPrePackageHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = path
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+	var writeErr error
+	shown := false
+	if p.PrePackageHookOutput != nil {
+		_, writeErr = p.PrePackageHookOutput.Write(output.Bytes())
+		shown = writeErr == nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("pre-package command %q timed out after %s", command, timeout)
+	}
+	if err != nil {
+		if shown {
+			return errors.Wrapf(err, "pre-package command %q failed", command)
+		}
+		return errors.Wrapf(err, "pre-package command %q failed. output:\n%s", command, output.String())
+	}
+	if writeErr != nil {
+		return errors.Wrapf(writeErr, "unable to write the output of pre-package command %q. output:\n%s", command, output.String())
+	}
+	return nil
+}
+
+// attachSBOM copies the SBOM at path next to the chart archive at filename.
+func attachSBOM(path, filename string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read SBOM")
+	}
+	if len(b) == 0 {
+		return errors.Errorf("SBOM %s is empty", path)
+	}
+	return ioutil.WriteFile(filename+".sbom", b, 0644)
 }
 
 // validateVersion Verify that version is a Version, and error out if it is not.
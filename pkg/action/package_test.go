@@ -17,14 +17,22 @@ limitations under the License.
 package action
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 func TestPassphraseFileFetcher(t *testing.T) {
@@ -123,3 +131,221 @@ func TestValidateVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachSBOM(t *testing.T) {
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+
+	sbom := path.Join(dir, "sbom.spdx.json")
+	if err := ioutil.WriteFile(sbom, []byte(`{"spdxVersion": "SPDX-2.2"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chart := path.Join(dir, "chart-0.1.0.tgz")
+	if err := attachSBOM(sbom, chart); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(chart + ".sbom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"spdxVersion": "SPDX-2.2"}` {
+		t.Errorf("unexpected SBOM content: %s", b)
+	}
+
+	empty := path.Join(dir, "empty.json")
+	if err := ioutil.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := attachSBOM(empty, chart); err == nil {
+		t.Error("expected an error attaching an empty SBOM")
+	}
+	if err := attachSBOM(path.Join(dir, "missing.json"), chart); err == nil {
+		t.Error("expected an error attaching a missing SBOM")
+	}
+}
+
+func TestPackagePrePackageHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the pre-package hook tests use POSIX commands")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		enabled bool
+		timeout time.Duration
+		wantErr string
+	}{
+		{name: "no-op command", command: "true", enabled: true},
+		{name: "disabled", command: "false", enabled: false},
+		{name: "failing command", command: "false", enabled: true, wantErr: "failed"},
+		{name: "empty command", command: `""`, enabled: true, wantErr: "no command to run"},
+		{name: "timeout", command: "sleep 5", enabled: true, timeout: 10 * time.Millisecond, wantErr: "timed out"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := ensure.TempDir(t)
+			defer os.RemoveAll(dir)
+
+			var out bytes.Buffer
+			p := NewPackage()
+			p.Destination = dir
+			p.RunPrePackageHook = tt.enabled
+			p.PrePackageHookTimeout = tt.timeout
+			p.PrePackageHookOutput = &out
+
+			_, err := p.Run(writePrePackageHookChart(t, dir, tt.command), nil)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := os.Stat(path.Join(dir, "hooked-0.1.0.tgz")); err != nil {
+					t.Errorf("expected the chart to be packaged: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPackagePrePackageHookOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the pre-package hook tests use POSIX commands")
+	}
+
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+
+	chartDir := writePrePackageHookChart(t, dir, "echo generated")
+
+	var out bytes.Buffer
+	p := NewPackage()
+	p.Destination = dir
+	p.RunPrePackageHook = true
+	p.PrePackageHookOutput = &out
+	if _, err := p.Run(chartDir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "generated\n" {
+		t.Errorf("expected the command output to be captured, got %q", out.String())
+	}
+
+	out.Reset()
+	chartDir = writePrePackageHookChart(t, dir, "sh -c 'echo broken; exit 1'")
+	_, err := p.Run(chartDir, nil)
+	if err == nil {
+		t.Fatal("expected the failing command to fail packaging")
+	}
+	if out.String() != "broken\n" {
+		t.Errorf("expected the failing command output to be captured, got %q", out.String())
+	}
+	if strings.Contains(err.Error(), "output:") {
+		t.Errorf("expected the captured output not to be repeated in the error, got %q", err)
+	}
+}
+
+func TestPackagePrePackageHookOutputWriteError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the pre-package hook tests use POSIX commands")
+	}
+
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+
+	p := NewPackage()
+	p.Destination = dir
+	p.RunPrePackageHook = true
+	p.PrePackageHookOutput = failingWriter{}
+
+	_, err := p.Run(writePrePackageHookChart(t, dir, "sh -c 'echo broken; exit 1'"), nil)
+	if err == nil || !strings.Contains(err.Error(), "failed. output:\nbroken") {
+		t.Errorf("expected the output of the failing command in the error, got %v", err)
+	}
+
+	_, err = p.Run(writePrePackageHookChart(t, dir, "echo generated"), nil)
+	if err == nil || !strings.Contains(err.Error(), "unable to write") || !strings.Contains(err.Error(), "output:\ngenerated") {
+		t.Errorf("expected the write error and the command output, got %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+// writePrePackageHookChart writes a chart declaring command as its pre-package
+// hook in dir and returns its path.
+func writePrePackageHookChart(t *testing.T, dir, command string) string {
+	t.Helper()
+	chartDir := path.Join(dir, "hooked")
+	if err := os.MkdirAll(chartDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chartfile := fmt.Sprintf("apiVersion: v2\nname: hooked\nversion: 0.1.0\nannotations:\n  %s: %q\n", PrePackageHookAnnotation, command)
+	if err := ioutil.WriteFile(path.Join(chartDir, "Chart.yaml"), []byte(chartfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return chartDir
+}
+
+func TestPackageBuildMetadata(t *testing.T) {
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+
+	chartDir := path.Join(dir, "traced")
+	if err := os.MkdirAll(chartDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chartfile := "apiVersion: v2\nname: traced\nversion: 0.1.0\nannotations:\n  owner: platform\n"
+	if err := ioutil.WriteFile(path.Join(chartDir, "Chart.yaml"), []byte(chartfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pack := func(t *testing.T, md *BuildMetadata) map[string]string {
+		t.Helper()
+		p := NewPackage()
+		p.Destination = dir
+		p.BuildMetadata = md
+		name, err := p.Run(chartDir, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch, err := loader.Load(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ch.Metadata.Annotations
+	}
+
+	built := time.Date(2021, 2, 3, 4, 5, 6, 0, time.FixedZone("CET", 3600))
+	md := &BuildMetadata{Commit: "1a2b3c4", Time: built, Builder: "ci/job-42"}
+	expect := map[string]string{
+		"owner":               "platform",
+		BuildCommitAnnotation: "1a2b3c4",
+		BuildTimeAnnotation:   "2021-02-03T03:05:06Z",
+		BuilderAnnotation:     "ci/job-42",
+	}
+	if got := pack(t, md); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected annotations %v, got %v", expect, got)
+	}
+	// a fixed time packages the same metadata again
+	if got := pack(t, md); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected annotations %v on the second package, got %v", expect, got)
+	}
+
+	got := pack(t, &BuildMetadata{})
+	if _, err := time.Parse(time.RFC3339, got[BuildTimeAnnotation]); err != nil {
+		t.Errorf("expected the current build time, got %q", got[BuildTimeAnnotation])
+	}
+	if _, ok := got[BuildCommitAnnotation]; ok {
+		t.Errorf("expected no commit annotation, got %v", got)
+	}
+
+	if got := pack(t, nil); !reflect.DeepEqual(got, map[string]string{"owner": "platform"}) {
+		t.Errorf("expected no build metadata by default, got %v", got)
+	}
+}
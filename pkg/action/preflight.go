@@ -0,0 +1,243 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// PreflightAnnotation is the chart annotation declaring the preflight checks
+// of the chart, as a YAML list of PreflightCheck.
+const PreflightAnnotation = "helm.sh/preflight-checks"
+
+// PreflightCheckType is the kind of a preflight check.
+type PreflightCheckType string
+
+const (
+	// PreflightKubeVersion checks that the Kubernetes version satisfies the
+	// semver constraint in Value.
+	PreflightKubeVersion PreflightCheckType = "kubeVersion"
+	// PreflightAPIVersion checks that the cluster serves the "group/version" or
+	// "group/version/Kind" in Value, such as the one of a required CRD.
+	PreflightAPIVersion PreflightCheckType = "apiVersion"
+	// PreflightStorageClass checks that the StorageClass named in Value exists.
+	PreflightStorageClass PreflightCheckType = "storageClass"
+	// PreflightQuota checks that the resource quotas of the namespace leave
+	// room for the "resource=quantity" in Value, e.g. "pods=3".
+	PreflightQuota PreflightCheckType = "quota"
+)
+
+// PreflightCheck is a declarative check of the cluster a release targets.
+type PreflightCheck struct {
+	Type  PreflightCheckType `json:"type"`
+	Value string             `json:"value"`
+	// Hint replaces the default remediation hint shown when the check fails
+	Hint string `json:"hint,omitempty"`
+}
+
+// PreflightResult is the outcome of a preflight check.
+type PreflightResult struct {
+	Check   PreflightCheck
+	Passed  bool
+	Message string
+	// Hint suggests how to fix the cluster when the check failed
+	Hint string
+}
+
+// Preflight is the action for checking that a cluster is ready for a release.
+type Preflight struct {
+	cfg *Configuration
+
+	Namespace string
+
+	// clientSet overrides the clientset built from cfg
+	clientSet kubernetes.Interface
+}
+
+// NewPreflight creates a new Preflight object with the given configuration.
+func NewPreflight(cfg *Configuration) *Preflight {
+	return &Preflight{
+		cfg: cfg,
+	}
+}
+
+// PreflightChecks returns the preflight checks declared by the chart in its
+// PreflightAnnotation annotation.
+func PreflightChecks(ch *chart.Chart) ([]PreflightCheck, error) {
+	if ch.Metadata == nil || ch.Metadata.Annotations[PreflightAnnotation] == "" {
+		return nil, nil
+	}
+	var checks []PreflightCheck
+	if err := yaml.Unmarshal([]byte(ch.Metadata.Annotations[PreflightAnnotation]), &checks); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s annotation in chart %s", PreflightAnnotation, ch.Name())
+	}
+	return checks, nil
+}
+
+// Run runs the checks against the cluster and returns their results in the
+// same order. An error is returned for malformed checks and when the cluster
+// cannot be queried, not for failing checks.
+func (p *Preflight) Run(checks []PreflightCheck) ([]*PreflightResult, error) {
+	results := make([]*PreflightResult, 0, len(checks))
+	for _, check := range checks {
+		var (
+			r   *PreflightResult
+			err error
+		)
+		switch check.Type {
+		case PreflightKubeVersion:
+			r, err = p.checkKubeVersion(check)
+		case PreflightAPIVersion:
+			r, err = p.checkAPIVersion(check)
+		case PreflightStorageClass:
+			r, err = p.checkStorageClass(check)
+		case PreflightQuota:
+			r, err = p.checkQuota(check)
+		default:
+			return nil, errors.Errorf("unknown preflight check type %q", check.Type)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "preflight check %s %s", check.Type, check.Value)
+		}
+		if r.Passed {
+			r.Hint = ""
+		} else if check.Hint != "" {
+			r.Hint = check.Hint
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (p *Preflight) checkKubeVersion(check PreflightCheck) (*PreflightResult, error) {
+	caps, err := p.cfg.getCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	version := caps.KubeVersion.String()
+	if chartutil.IsCompatibleRange(check.Value, version) {
+		return &PreflightResult{Check: check, Passed: true, Message: fmt.Sprintf("Kubernetes %s satisfies %s", version, check.Value)}, nil
+	}
+	return &PreflightResult{
+		Check:   check,
+		Message: fmt.Sprintf("Kubernetes %s does not satisfy %s", version, check.Value),
+		Hint:    fmt.Sprintf("use a cluster running a Kubernetes version matching %s", check.Value),
+	}, nil
+}
+
+func (p *Preflight) checkAPIVersion(check PreflightCheck) (*PreflightResult, error) {
+	caps, err := p.cfg.getCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	if caps.APIVersions.Has(check.Value) {
+		return &PreflightResult{Check: check, Passed: true, Message: fmt.Sprintf("%s is served by the cluster", check.Value)}, nil
+	}
+	return &PreflightResult{
+		Check:   check,
+		Message: fmt.Sprintf("%s is not served by the cluster", check.Value),
+		Hint:    fmt.Sprintf("install the CustomResourceDefinition or API service providing %s", check.Value),
+	}, nil
+}
+
+func (p *Preflight) checkStorageClass(check PreflightCheck) (*PreflightResult, error) {
+	client, err := p.kubeClientSet()
+	if err != nil {
+		return nil, err
+	}
+	_, err = client.StorageV1().StorageClasses().Get(context.Background(), check.Value, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return &PreflightResult{
+			Check:   check,
+			Message: fmt.Sprintf("StorageClass %s does not exist", check.Value),
+			Hint:    fmt.Sprintf("create the StorageClass %s, or configure the chart to use an existing one", check.Value),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &PreflightResult{Check: check, Passed: true, Message: fmt.Sprintf("StorageClass %s exists", check.Value)}, nil
+}
+
+func (p *Preflight) checkQuota(check PreflightCheck) (*PreflightResult, error) {
+	name, quantity, err := parseQuotaRequest(check.Value)
+	if err != nil {
+		return nil, err
+	}
+	client, err := p.kubeClientSet()
+	if err != nil {
+		return nil, err
+	}
+	quotas, err := client.CoreV1().ResourceQuotas(p.Namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range quotas.Items {
+		hard, ok := q.Status.Hard[name]
+		if !ok {
+			if hard, ok = q.Spec.Hard[name]; !ok {
+				continue
+			}
+		}
+		remaining := hard.DeepCopy()
+		if used, ok := q.Status.Used[name]; ok {
+			remaining.Sub(used)
+		}
+		if remaining.Cmp(quantity) < 0 {
+			return &PreflightResult{
+				Check:   check,
+				Message: fmt.Sprintf("ResourceQuota %s leaves %s %s, %s needed", q.Name, remaining.String(), name, quantity.String()),
+				Hint:    fmt.Sprintf("raise the %s quota of namespace %s, or free up %s", name, p.Namespace, name),
+			}, nil
+		}
+	}
+	return &PreflightResult{Check: check, Passed: true, Message: fmt.Sprintf("the quotas of namespace %s leave room for %s %s", p.Namespace, quantity.String(), name)}, nil
+}
+
+// parseQuotaRequest parses a "resource=quantity" quota check value.
+func parseQuotaRequest(value string) (v1.ResourceName, resource.Quantity, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", resource.Quantity{}, errors.Errorf("invalid quota check %q, expected resource=quantity", value)
+	}
+	quantity, err := resource.ParseQuantity(parts[1])
+	if err != nil {
+		return "", quantity, errors.Wrapf(err, "invalid quantity in %q", value)
+	}
+	return v1.ResourceName(parts[0]), quantity, nil
+}
+
+func (p *Preflight) kubeClientSet() (kubernetes.Interface, error) {
+	if p.clientSet != nil {
+		return p.clientSet, nil
+	}
+	return p.cfg.KubernetesClientSet()
+}
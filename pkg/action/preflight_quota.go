@@ -0,0 +1,169 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// podTemplate is the pod template of a workload manifest.
+type podTemplate struct {
+	Spec v1.PodSpec `json:"spec"`
+}
+
+// workloadManifest holds the fields of the workloads that create pods needed
+// to sum up their resources.
+type workloadManifest struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Replicas    *int32      `json:"replicas"`
+		Parallelism *int32      `json:"parallelism"`
+		Template    podTemplate `json:"template"`
+		JobTemplate struct {
+			Spec struct {
+				Parallelism *int32      `json:"parallelism"`
+				Template    podTemplate `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// ManifestResourceRequests sums up the pods and the compute resources
+// requested by the workloads in manifest, keyed by their ResourceQuota name:
+// "pods", "requests.cpu" (and its alias "cpu"), "limits.memory" and so on.
+//
+// Each replica of a Deployment, ReplicaSet, ReplicationController or
+// StatefulSet is counted, as is each parallel pod of a Job or CronJob. A
+// DaemonSet is counted as a single pod, as its pod count depends on the nodes.
+func ManifestResourceRequests(manifest string) (v1.ResourceList, error) {
+	total := v1.ResourceList{}
+	for name, content := range releaseutil.SplitManifests(manifest) {
+		var w workloadManifest
+		if err := yaml.Unmarshal([]byte(content), &w); err != nil {
+			return nil, errors.Wrapf(err, "unable to parse %s", name)
+		}
+
+		var (
+			spec     v1.PodSpec
+			replicas *int32
+		)
+		switch w.Kind {
+		case "Pod":
+			var pod podTemplate
+			if err := yaml.Unmarshal([]byte(content), &pod); err != nil {
+				return nil, errors.Wrapf(err, "unable to parse %s", name)
+			}
+			spec = pod.Spec
+		case "Deployment", "ReplicaSet", "ReplicationController", "StatefulSet":
+			spec, replicas = w.Spec.Template.Spec, w.Spec.Replicas
+		case "DaemonSet":
+			spec = w.Spec.Template.Spec
+		case "Job":
+			spec, replicas = w.Spec.Template.Spec, w.Spec.Parallelism
+		case "CronJob":
+			spec, replicas = w.Spec.JobTemplate.Spec.Template.Spec, w.Spec.JobTemplate.Spec.Parallelism
+		default:
+			continue
+		}
+
+		pods := int64(1)
+		if replicas != nil {
+			pods = int64(*replicas)
+		}
+		addQuantity(total, v1.ResourcePods, *resource.NewQuantity(pods, resource.DecimalSI))
+		requests, limits := podResources(spec)
+		for res, q := range requests {
+			q = scaleQuantity(q, pods)
+			addQuantity(total, v1.ResourceName("requests."+res), q)
+			if res == v1.ResourceCPU || res == v1.ResourceMemory {
+				addQuantity(total, res, q)
+			}
+		}
+		for res, q := range limits {
+			addQuantity(total, v1.ResourceName("limits."+res), scaleQuantity(q, pods))
+		}
+	}
+	return total, nil
+}
+
+// podResources returns the effective requests and limits of a pod: the sum
+// of its containers, or the largest init container when that is higher.
+func podResources(spec v1.PodSpec) (requests, limits v1.ResourceList) {
+	requests, limits = v1.ResourceList{}, v1.ResourceList{}
+	for _, c := range spec.Containers {
+		for res, q := range c.Resources.Requests {
+			addQuantity(requests, res, q)
+		}
+		for res, q := range c.Resources.Limits {
+			addQuantity(limits, res, q)
+		}
+	}
+	for _, c := range spec.InitContainers {
+		maxQuantity(requests, c.Resources.Requests)
+		maxQuantity(limits, c.Resources.Limits)
+	}
+	return requests, limits
+}
+
+func addQuantity(list v1.ResourceList, name v1.ResourceName, q resource.Quantity) {
+	sum := list[name]
+	sum.Add(q)
+	list[name] = sum
+}
+
+func maxQuantity(list, other v1.ResourceList) {
+	for res, q := range other {
+		if current, ok := list[res]; !ok || q.Cmp(current) > 0 {
+			list[res] = q.DeepCopy()
+		}
+	}
+}
+
+func scaleQuantity(q resource.Quantity, n int64) resource.Quantity {
+	return *resource.NewMilliQuantity(q.MilliValue()*n, q.Format)
+}
+
+// CheckManifestQuota checks that the ResourceQuotas of the namespace leave
+// room for the pods and compute resources requested by the workloads in
+// manifest, as summed up by ManifestResourceRequests. A namespace without
+// quotas passes every check.
+func (p *Preflight) CheckManifestQuota(manifest string) ([]*PreflightResult, error) {
+	requested, err := ManifestResourceRequests(manifest)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(requested))
+	for name := range requested {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	checks := make([]PreflightCheck, 0, len(names))
+	for _, name := range names {
+		q := requested[v1.ResourceName(name)]
+		checks = append(checks, PreflightCheck{Type: PreflightQuota, Value: fmt.Sprintf("%s=%s", name, q.String())})
+	}
+	return p.Run(checks)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+var quotaManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        resources:
+          requests:
+            memory: 1Gi
+      containers:
+      - name: web
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+          limits:
+            cpu: 200m
+      - name: sidecar
+        resources:
+          requests:
+            cpu: 50m
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: seed
+spec:
+  template:
+    spec:
+      containers:
+      - name: seed
+        resources:
+          requests:
+            cpu: 250m
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+func TestManifestResourceRequests(t *testing.T) {
+	requested, err := ManifestResourceRequests(quotaManifest)
+	require.NoError(t, err)
+
+	expected := map[v1.ResourceName]string{
+		v1.ResourcePods:           "4",
+		v1.ResourceRequestsCPU:    "700m",
+		v1.ResourceCPU:            "700m",
+		v1.ResourceRequestsMemory: "3Gi",
+		v1.ResourceMemory:         "3Gi",
+		v1.ResourceLimitsCPU:      "600m",
+	}
+	assert.Len(t, requested, len(expected))
+	for name, want := range expected {
+		q := requested[name]
+		assert.Zero(t, q.Cmp(resource.MustParse(want)), "%s: expected %s, got %s", name, want, q.String())
+	}
+}
+
+func TestCheckManifestQuota(t *testing.T) {
+	p := NewPreflight(actionConfigFixture(t))
+	p.Namespace = "spaced"
+	p.clientSet = fakeclientset.NewSimpleClientset(
+		&v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "spaced"},
+			Status: v1.ResourceQuotaStatus{
+				Hard: v1.ResourceList{
+					v1.ResourcePods:        resource.MustParse("10"),
+					v1.ResourceRequestsCPU: resource.MustParse("1"),
+				},
+				Used: v1.ResourceList{
+					v1.ResourcePods:        resource.MustParse("2"),
+					v1.ResourceRequestsCPU: resource.MustParse("500m"),
+				},
+			},
+		},
+	)
+
+	results, err := p.CheckManifestQuota(quotaManifest)
+	require.NoError(t, err)
+
+	var failed []string
+	for _, r := range results {
+		if !r.Passed {
+			failed = append(failed, r.Check.Value)
+		}
+	}
+	assert.Equal(t, []string{"requests.cpu=700m"}, failed)
+
+	// a namespace without quotas fits anything
+	p.Namespace = "unlimited"
+	results, err = p.CheckManifestQuota(quotaManifest)
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.True(t, r.Passed, r.Message)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestPreflight(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	cfg := actionConfigFixture(t)
+	caps := *chartutil.DefaultCapabilities
+	caps.APIVersions = append(chartutil.VersionSet{"apps/v1/Deployment"}, caps.APIVersions...)
+	cfg.Capabilities = &caps
+	p := NewPreflight(cfg)
+	p.Namespace = "spaced"
+	p.clientSet = fakeclientset.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}},
+		&v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "spaced"},
+			Status: v1.ResourceQuotaStatus{
+				Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("10")},
+				Used: v1.ResourceList{v1.ResourcePods: resource.MustParse("8")},
+			},
+		},
+	)
+
+	ch := buildChart()
+	ch.Metadata.Annotations = map[string]string{PreflightAnnotation: `
+- type: kubeVersion
+  value: ">= 1.18.0"
+- type: kubeVersion
+  value: ">= 1.99.0"
+- type: apiVersion
+  value: apps/v1/Deployment
+- type: apiVersion
+  value: cert-manager.io/v1/Certificate
+  hint: install cert-manager first
+- type: storageClass
+  value: fast
+- type: storageClass
+  value: slow
+- type: quota
+  value: pods=2
+- type: quota
+  value: pods=3
+`}
+	checks, err := PreflightChecks(ch)
+	req.NoError(err)
+	req.Len(checks, 8)
+
+	results, err := p.Run(checks)
+	req.NoError(err)
+	req.Len(results, 8)
+
+	passed := []bool{true, false, true, false, true, false, true, false}
+	for i, r := range results {
+		is.Equal(passed[i], r.Passed, "check %s %s: %s", r.Check.Type, r.Check.Value, r.Message)
+		if r.Passed {
+			is.Empty(r.Hint)
+		} else {
+			is.NotEmpty(r.Hint)
+		}
+	}
+	is.Equal("install cert-manager first", results[3].Hint)
+	is.Contains(results[7].Message, "leaves 2 pods, 3 needed")
+
+	_, err = p.Run([]PreflightCheck{{Type: "nosuchcheck"}})
+	is.Error(err)
+	_, err = p.Run([]PreflightCheck{{Type: PreflightQuota, Value: "pods"}})
+	is.Error(err)
+
+	checks, err = PreflightChecks(&chart.Chart{Metadata: &chart.Metadata{Name: "plain"}})
+	is.NoError(err)
+	is.Empty(checks)
+}
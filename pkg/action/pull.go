@@ -43,7 +43,9 @@ type Pull struct {
 	Devel       bool
 	Untar       bool
 	VerifyLater bool
+	SBOM        bool
 	UntarDir    string
+	UntarPaths  []string // only extract these chart files and directories; Chart.yaml is always extracted
 	DestDir     string
 	cfg         *Configuration
 }
@@ -79,11 +81,13 @@ func (p *Pull) Run(chartRef string) (string, error) {
 		Out:     &out,
 		Keyring: p.Keyring,
 		Verify:  downloader.VerifyNever,
+		SBOM:    p.SBOM,
 		Getters: getter.All(p.Settings),
 		Options: []getter.Option{
 			getter.WithBasicAuth(p.Username, p.Password),
 			getter.WithTLSClientConfig(p.CertFile, p.KeyFile, p.CaFile),
 			getter.WithInsecureSkipVerifyTLS(p.InsecureSkipTLSverify),
+			getter.WithUserAgent(p.UserAgent),
 		},
 		RepositoryConfig: p.Settings.RepositoryConfig,
 		RepositoryCache:  p.Settings.RepositoryCache,
@@ -94,6 +98,7 @@ func (p *Pull) Run(chartRef string) (string, error) {
 			return out.String(), errors.Errorf("--version flag is explicitly required for OCI registries")
 		}
 
+		c.RegistryClient = p.cfg.RegistryClient
 		c.Options = append(c.Options,
 			getter.WithRegistryClient(p.cfg.RegistryClient),
 			getter.WithTagName(p.Version))
@@ -105,6 +110,10 @@ func (p *Pull) Run(chartRef string) (string, error) {
 		c.Verify = downloader.VerifyLater
 	}
 
+	if len(p.UntarPaths) > 0 && !p.Untar {
+		return out.String(), errors.New("extracting selected paths of a chart requires untar")
+	}
+
 	// If untar is set, we fetch to a tempdir, then untar and copy after
 	// verification.
 	dest := p.DestDir
@@ -162,7 +171,7 @@ func (p *Pull) Run(chartRef string) (string, error) {
 			return out.String(), errors.Errorf("failed to untar: a file or directory with the name %s already exists", udCheck)
 		}
 
-		return out.String(), chartutil.ExpandFile(ud, saved)
+		return out.String(), chartutil.ExpandFilePaths(ud, saved, p.UntarPaths)
 	}
 	return out.String(), nil
 }
@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestPullUserAgent(t *testing.T) {
+	var userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		w.Write([]byte("not really a chart"))
+	}))
+	defer srv.Close()
+
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(dir, "repositories.yaml")
+	settings.RepositoryCache = dir
+	settings.PluginsDirectory = dir
+
+	p := NewPull()
+	p.Settings = settings
+	p.DestDir = dir
+	p.UserAgent = "mytool/1.0"
+
+	if _, err := p.Run(srv.URL + "/foo-0.1.0.tgz"); err != nil {
+		t.Fatal(err)
+	}
+	if userAgent != "mytool/1.0" {
+		t.Errorf("Expected User-Agent %q, got %q", "mytool/1.0", userAgent)
+	}
+}
+
+func TestPullUntarPaths(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../chartutil/testdata/frobnitz-1.2.3.tgz")
+	}))
+	defer srv.Close()
+
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(dir, "repositories.yaml")
+	settings.RepositoryCache = dir
+	settings.PluginsDirectory = dir
+
+	p := NewPull()
+	p.Settings = settings
+	p.DestDir = dir
+	p.UntarPaths = []string{"templates/", "values.yaml"}
+
+	if _, err := p.Run(srv.URL + "/frobnitz-1.2.3.tgz"); err == nil {
+		t.Error("expected an error extracting selected paths without untar")
+	}
+
+	p.Untar = true
+	p.UntarDir = filepath.Join(dir, "untar")
+	if _, err := p.Run(srv.URL + "/frobnitz-1.2.3.tgz"); err != nil {
+		t.Fatal(err)
+	}
+
+	chartPath := filepath.Join(p.UntarDir, "frobnitz")
+	for _, name := range []string{"Chart.yaml", "values.yaml", "templates/template.tpl"} {
+		if _, err := os.Stat(filepath.Join(chartPath, name)); err != nil {
+			t.Errorf("expected %s to be extracted: %s", name, err)
+		}
+	}
+	for _, name := range []string{"README.md", "docs", "charts"} {
+		if _, err := os.Stat(filepath.Join(chartPath, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be extracted", name)
+		}
+	}
+}
@@ -20,13 +20,27 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+const (
+	// maxTestResultLogLines is the number of trailing log lines of a test pod
+	// stored with the test results.
+	maxTestResultLogLines = 20
+	// maxTestResultLogBytes bounds the size of the logs stored for a test pod.
+	maxTestResultLogBytes = 2048
 )
 
 // ReleaseTesting is the action for testing a release.
@@ -37,7 +51,12 @@ type ReleaseTesting struct {
 	Timeout time.Duration
 	// Used for fetching logs from test pods
 	Namespace string
-	Filters   map[string][]string
+	// Filters select the tests to run. "name" and "!name" take test names or
+	// glob patterns to include or exclude, "label" takes label selectors
+	// matched against the labels of the test resources.
+	Filters map[string][]string
+	// PersistResults stores the outcome of the test run in the release info
+	PersistResults bool
 }
 
 // NewReleaseTesting creates a new ReleaseTesting object with the given configuration.
@@ -64,40 +83,91 @@ func (r *ReleaseTesting) Run(name string) (*release.Release, error) {
 		return rel, err
 	}
 
-	skippedHooks := []*release.Hook{}
-	executingHooks := []*release.Hook{}
-	if len(r.Filters["!name"]) != 0 {
-		for _, h := range rel.Hooks {
-			if contains(r.Filters["!name"], h.Name) {
-				skippedHooks = append(skippedHooks, h)
-			} else {
-				executingHooks = append(executingHooks, h)
-			}
-		}
-		rel.Hooks = executingHooks
-	}
-	if len(r.Filters["name"]) != 0 {
-		executingHooks = nil
-		for _, h := range rel.Hooks {
-			if contains(r.Filters["name"], h.Name) {
-				executingHooks = append(executingHooks, h)
-			} else {
-				skippedHooks = append(skippedHooks, h)
-			}
-		}
-		rel.Hooks = executingHooks
+	executingHooks, skippedHooks, err := r.FilterTests(rel.Hooks)
+	if err != nil {
+		return rel, err
 	}
+	rel.Hooks = executingHooks
+	started := helmtime.Now()
 
-	if err := r.cfg.execHook(rel, release.HookTest, r.Timeout); err != nil {
+	if err := r.cfg.execHook(rel, release.HookTest, r.Timeout, 0); err != nil {
 		rel.Hooks = append(skippedHooks, rel.Hooks...)
+		r.recordResults(rel, executingHooks, started)
 		r.cfg.Releases.Update(rel)
 		return rel, err
 	}
 
 	rel.Hooks = append(skippedHooks, rel.Hooks...)
+	r.recordResults(rel, executingHooks, started)
 	return rel, r.cfg.Releases.Update(rel)
 }
 
+// recordResults stores the outcome of the tests run since started in the
+// release info, when PersistResults is set. Tests that did not run because an
+// earlier test failed are recorded with an unknown phase.
+func (r *ReleaseTesting) recordResults(rel *release.Release, hooks []*release.Hook, started helmtime.Time) {
+	if !r.PersistResults {
+		return
+	}
+
+	var client kubernetes.Interface
+	if r.cfg.RESTClientGetter != nil {
+		var err error
+		if client, err = r.cfg.KubernetesClientSet(); err != nil {
+			r.cfg.Log("unable to get kubernetes client to fetch test logs: %s", err)
+		}
+	}
+
+	run := &release.TestRun{
+		StartedAt:   started,
+		CompletedAt: helmtime.Now(),
+		Passed:      true,
+	}
+	for _, h := range hooks {
+		if !hasHookEvent(h, release.HookTest) {
+			continue
+		}
+		result := &release.TestResult{Name: h.Name, Phase: release.HookPhaseUnknown}
+		if !h.LastRun.StartedAt.Before(started) {
+			result.Phase = h.LastRun.Phase
+			result.StartedAt = h.LastRun.StartedAt
+			result.CompletedAt = h.LastRun.CompletedAt
+			if client != nil {
+				result.Logs = r.logsTail(client, h.Name)
+			}
+		}
+		if result.Phase != release.HookPhaseSucceeded {
+			run.Passed = false
+		}
+		run.Tests = append(run.Tests, result)
+	}
+	rel.Info.TestResults = run
+}
+
+// logsTail returns the last lines of the logs of the named test pod, bounded
+// to maxTestResultLogBytes.
+func (r *ReleaseTesting) logsTail(client kubernetes.Interface, name string) string {
+	tailLines := int64(maxTestResultLogLines)
+	logs, err := client.CoreV1().Pods(r.Namespace).GetLogs(name, &v1.PodLogOptions{TailLines: &tailLines}).Do(context.Background()).Raw()
+	if err != nil {
+		r.cfg.Log("unable to get pod logs for %s: %s", name, err)
+		return ""
+	}
+	if len(logs) > maxTestResultLogBytes {
+		logs = logs[len(logs)-maxTestResultLogBytes:]
+	}
+	return strings.TrimSpace(string(logs))
+}
+
+func hasHookEvent(h *release.Hook, event release.HookEvent) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
 // GetPodLogs will write the logs for all test pods in the given release into
 // the given writer. These can be immediately output to the user or captured for
 // other uses
@@ -128,11 +198,69 @@ func (r *ReleaseTesting) GetPodLogs(out io.Writer, rel *release.Release) error {
 	return nil
 }
 
-func contains(arr []string, value string) bool {
+// FilterTests splits the given hooks into the ones selected by the filters and
+// the ones skipped.
+func (r *ReleaseTesting) FilterTests(hooks []*release.Hook) (selected, skipped []*release.Hook, err error) {
+	var selectors []labels.Selector
+	for _, l := range r.Filters["label"] {
+		s, err := labels.Parse(l)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid label filter %q", l)
+		}
+		selectors = append(selectors, s)
+	}
+
+	for _, h := range hooks {
+		if r.selectTest(h, selectors) {
+			selected = append(selected, h)
+		} else {
+			skipped = append(skipped, h)
+		}
+	}
+	return selected, skipped, nil
+}
+
+func (r *ReleaseTesting) selectTest(h *release.Hook, selectors []labels.Selector) bool {
+	if matchesAny(r.Filters["!name"], h.Name) {
+		return false
+	}
+	if len(r.Filters["name"]) != 0 && !matchesAny(r.Filters["name"], h.Name) {
+		return false
+	}
+	if len(selectors) == 0 {
+		return true
+	}
+	set := labels.Set(hookLabels(h))
+	for _, s := range selectors {
+		if s.Matches(set) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny returns true if value is equal to or matches the glob pattern of any item in arr
+func matchesAny(arr []string, value string) bool {
 	for _, item := range arr {
 		if item == value {
 			return true
 		}
+		if ok, _ := path.Match(item, value); ok {
+			return true
+		}
 	}
 	return false
 }
+
+// hookLabels returns the labels set in the hook's manifest
+func hookLabels(h *release.Hook) map[string]string {
+	var m struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(h.Manifest), &m); err != nil {
+		return nil
+	}
+	return m.Metadata.Labels
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReleaseTestingFilterTests(t *testing.T) {
+	testHook := func(name, tier string) *release.Hook {
+		return &release.Hook{
+			Name:     name,
+			Kind:     "Pod",
+			Events:   []release.HookEvent{release.HookTest},
+			Manifest: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: " + name + "\n  labels:\n    tier: " + tier + "\n",
+		}
+	}
+	hooks := []*release.Hook{
+		testHook("app-smoke", "smoke"),
+		testHook("app-integration", "full"),
+		testHook("db-smoke", "smoke"),
+	}
+
+	tests := []struct {
+		name     string
+		filters  map[string][]string
+		selected []string
+	}{
+		{"no filters", map[string][]string{}, []string{"app-smoke", "app-integration", "db-smoke"}},
+		{"exact name", map[string][]string{"name": {"db-smoke"}}, []string{"db-smoke"}},
+		{"name pattern", map[string][]string{"name": {"*-smoke"}}, []string{"app-smoke", "db-smoke"}},
+		{"excluded name pattern", map[string][]string{"!name": {"app-*"}}, []string{"db-smoke"}},
+		{"label selector", map[string][]string{"label": {"tier=full"}}, []string{"app-integration"}},
+		{"name and label", map[string][]string{"name": {"app-*"}, "label": {"tier in (smoke)"}}, []string{"app-smoke"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewReleaseTesting(actionConfigFixture(t))
+			client.Filters = tt.filters
+			selected, skipped, err := client.FilterTests(hooks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(selected)+len(skipped) != len(hooks) {
+				t.Errorf("expected %d hooks in total, got %d", len(hooks), len(selected)+len(skipped))
+			}
+			var names []string
+			for _, h := range selected {
+				names = append(names, h.Name)
+			}
+			if len(names) != len(tt.selected) {
+				t.Fatalf("expected %v selected, got %v", tt.selected, names)
+			}
+			for i := range names {
+				if names[i] != tt.selected[i] {
+					t.Errorf("expected %v selected, got %v", tt.selected, names)
+				}
+			}
+		})
+	}
+
+	client := NewReleaseTesting(actionConfigFixture(t))
+	client.Filters["label"] = []string{"tier in (smoke"}
+	if _, _, err := client.FilterTests(hooks); err == nil {
+		t.Error("expected an error for an invalid label selector")
+	}
+}
+
+func TestReleaseTestingPersistResults(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	config := actionConfigFixture(t)
+	rel := namedReleaseStub("tested", release.StatusDeployed)
+	rel.Hooks = nil
+	for _, name := range []string{"a-test", "b-test"} {
+		rel.Hooks = append(rel.Hooks, &release.Hook{
+			Name:     name,
+			Kind:     "Pod",
+			Path:     "templates/" + name + ".yaml",
+			Events:   []release.HookEvent{release.HookTest},
+			Manifest: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: " + name + "\n",
+		})
+	}
+	req.NoError(config.Releases.Create(rel))
+
+	client := NewReleaseTesting(config)
+	client.PersistResults = true
+	_, err := client.Run(rel.Name)
+	req.NoError(err)
+
+	stored, err := config.Releases.Get(rel.Name, rel.Version)
+	req.NoError(err)
+	results := stored.Info.TestResults
+	req.NotNil(results, "expected the test results to be stored")
+	is.True(results.Passed)
+	req.Len(results.Tests, 2)
+	for _, r := range results.Tests {
+		is.Equal(release.HookPhaseSucceeded, r.Phase)
+		is.False(r.CompletedAt.IsZero())
+	}
+
+	// the first test fails, so the second one never runs
+	failer := config.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WatchUntilReadyError = fmt.Errorf("test failed")
+	_, err = client.Run(rel.Name)
+	is.Error(err)
+
+	stored, err = config.Releases.Get(rel.Name, rel.Version)
+	req.NoError(err)
+	results = stored.Info.TestResults
+	is.False(results.Passed)
+	req.Len(results.Tests, 2)
+	is.Equal("a-test", results.Tests[0].Name)
+	is.Equal(release.HookPhaseFailed, results.Tests[0].Phase)
+	is.Equal(release.HookPhaseUnknown, results.Tests[1].Phase)
+	is.True(results.Tests[1].StartedAt.IsZero())
+}
@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// RenderArchive loads the chart archive read from in and renders it with vals
+// for a cluster with the capabilities caps, entirely in memory. Nothing is
+// read from or written to the filesystem, so it suits embedded and serverless
+// uses.
+//
+// The chart must carry its dependencies in the archive. As with 'helm
+// template', the values are validated against the chart schemas, the
+// resources are sorted by kind and name, and the returned release holds the
+// rendered manifest, the hooks and the notes, but is not stored anywhere. A nil caps defaults to
+// chartutil.DefaultCapabilities.
+func RenderArchive(in io.Reader, vals map[string]interface{}, options chartutil.ReleaseOptions, caps *chartutil.Capabilities) (*release.Release, error) {
+	chrt, err := loader.LoadArchive(in)
+	if err != nil {
+		return nil, err
+	}
+	if req := chrt.Metadata.Dependencies; req != nil {
+		if err := CheckDependencies(chrt, req); err != nil {
+			return nil, err
+		}
+	}
+	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
+		return nil, err
+	}
+
+	if caps == nil {
+		caps = chartutil.DefaultCapabilities
+	}
+	valuesToRender, err := chartutil.ToRenderValues(chrt, vals, options, caps)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Configuration{Capabilities: caps}
+	hooks, manifest, notes, err := cfg.renderResources(chrt, valuesToRender, options.Name, "", false, false, false, true, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	return &release.Release{
+		Name:      options.Name,
+		Namespace: options.Namespace,
+		Chart:     chrt,
+		Config:    vals,
+		Manifest:  manifest.String(),
+		Hooks:     hooks,
+		Info:      &release.Info{Notes: notes},
+		Version:   options.Revision,
+	}, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// memoryArchive returns a gzipped tar of files, built in memory.
+func memoryArchive(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestRenderArchive(t *testing.T) {
+	archive := memoryArchive(t, map[string]string{
+		"memchart/Chart.yaml":             "apiVersion: v2\nname: memchart\nversion: 0.1.0\n",
+		"memchart/values.yaml":            "greeting: hello\nsub:\n  port: 80\n",
+		"memchart/values.schema.json":     `{"properties": {"greeting": {"type": "string"}}}`,
+		"memchart/config/app.conf":        "log_level = debug\n",
+		"memchart/templates/_helpers.tpl": `{{ define "memchart.name" }}{{ .Release.Name }}-config{{ end }}`,
+		"memchart/templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "memchart.name" . }}
+data:
+  greeting: {{ .Values.greeting }}
+  app.conf: {{ .Files.Get "config/app.conf" | quote }}
+`,
+		"memchart/templates/NOTES.txt":           "Rendered {{ .Release.Name }} for Kubernetes {{ .Capabilities.KubeVersion.Minor }}",
+		"memchart/charts/sub/Chart.yaml":         "apiVersion: v2\nname: sub\nversion: 0.1.0\n",
+		"memchart/charts/sub/templates/svc.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: sub\nspec:\n  ports:\n  - port: {{ .Values.port }}\n",
+	})
+
+	vals := map[string]interface{}{"greeting": "hi"}
+	options := chartutil.ReleaseOptions{Name: "inmem", Namespace: "spaced", Revision: 1, IsInstall: true}
+	caps := &chartutil.Capabilities{
+		KubeVersion: chartutil.KubeVersion{Version: "v1.19.0", Major: "1", Minor: "19"},
+		APIVersions: chartutil.DefaultVersionSet,
+	}
+
+	rel, err := RenderArchive(archive, vals, options, caps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"# Source: memchart/templates/configmap.yaml\n",
+		"  name: inmem-config\n",
+		"  greeting: hi\n",
+		`  app.conf: "log_level = debug\n"`,
+		"# Source: memchart/charts/sub/templates/svc.yaml\n",
+		"  - port: 80\n",
+	} {
+		if !strings.Contains(rel.Manifest, expected) {
+			t.Errorf("Expected manifest to contain %q, got:\n%s", expected, rel.Manifest)
+		}
+	}
+	if rel.Info.Notes != "Rendered inmem for Kubernetes 19" {
+		t.Errorf("Unexpected notes %q", rel.Info.Notes)
+	}
+
+	// values are validated against the schema
+	archive = memoryArchive(t, map[string]string{
+		"memchart/Chart.yaml":         "apiVersion: v2\nname: memchart\nversion: 0.1.0\n",
+		"memchart/values.schema.json": `{"properties": {"greeting": {"type": "string"}}}`,
+	})
+	if _, err := RenderArchive(archive, map[string]interface{}{"greeting": 1}, options, nil); err == nil {
+		t.Error("Expected values not matching the schema to fail")
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ResourceID identifies a resource of a release.
+type ResourceID struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+func (r ResourceID) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s/%s (%s)", r.Kind, r.Name, r.APIVersion)
+	}
+	return fmt.Sprintf("%s %s/%s (%s)", r.Kind, r.Namespace, r.Name, r.APIVersion)
+}
+
+// parseResourceID returns the identity of the resource defined by manifest.
+// Resources without a namespace are taken to be in namespace. ok is false
+// when the manifest has no kind or no name.
+func parseResourceID(manifest, namespace string) (id ResourceID, ok bool, err error) {
+	var head struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(manifest), &head); err != nil {
+		return id, false, err
+	}
+	if head.Kind == "" || head.Metadata.Name == "" {
+		return id, false, nil
+	}
+	id = ResourceID{
+		APIVersion: head.APIVersion,
+		Kind:       head.Kind,
+		Namespace:  head.Metadata.Namespace,
+		Name:       head.Metadata.Name,
+	}
+	if id.Namespace == "" {
+		id.Namespace = namespace
+	}
+	return id, true, nil
+}
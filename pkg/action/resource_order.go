@@ -0,0 +1,81 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// ResourceOrder is the action for computing the order in which Helm applies
+// and deletes the resources of a release.
+//
+// The order is derived from the stored manifest of the release, using the
+// same kind ordering as install and uninstall. Hooks are not included.
+type ResourceOrder struct {
+	cfg *Configuration
+
+	// Initializing Version to 0 will use the latest revision of the release.
+	Version int
+}
+
+// NewResourceOrder creates a new ResourceOrder object with the given configuration.
+func NewResourceOrder(cfg *Configuration) *ResourceOrder {
+	return &ResourceOrder{
+		cfg: cfg,
+	}
+}
+
+// Run returns the resources of the named release in the order they are
+// installed, and in the order they are deleted on uninstall.
+func (r *ResourceOrder) Run(name string) (install, uninstall []ResourceID, err error) {
+	rel, err := r.cfg.releaseContent(name, r.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	caps, err := r.cfg.getCapabilities()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifests := releaseutil.SplitManifests(rel.Manifest)
+	if install, err = sortedResourceIDs(manifests, caps.APIVersions, releaseutil.InstallOrder); err != nil {
+		return nil, nil, errors.Wrapf(err, "corrupted release record for %s", name)
+	}
+	if uninstall, err = sortedResourceIDs(manifests, caps.APIVersions, releaseutil.UninstallOrder); err != nil {
+		return nil, nil, errors.Wrapf(err, "corrupted release record for %s", name)
+	}
+	return install, uninstall, nil
+}
+
+func sortedResourceIDs(manifests map[string]string, apis chartutil.VersionSet, ordering releaseutil.KindSortOrder) ([]ResourceID, error) {
+	_, files, err := releaseutil.SortManifests(manifests, apis, ordering)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ResourceID, 0, len(files))
+	for _, f := range files {
+		id := ResourceID{APIVersion: f.Head.Version, Kind: f.Head.Kind}
+		if f.Head.Metadata != nil {
+			id.Name = f.Head.Metadata.Name
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
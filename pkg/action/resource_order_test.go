@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceOrder(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	rel := releaseStub()
+	rel.Manifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+`
+	require.NoError(t, cfg.Releases.Create(rel))
+
+	install, uninstall, err := NewResourceOrder(cfg).Run(rel.Name)
+	require.NoError(t, err)
+
+	assert.Equal(t, []ResourceID{
+		{APIVersion: "v1", Kind: "Namespace", Name: "web"},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "web-config"},
+		{APIVersion: "v1", Kind: "Service", Name: "web"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+	}, install)
+	assert.Equal(t, []ResourceID{
+		{APIVersion: "v1", Kind: "Service", Name: "web"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "web-config"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "web"},
+	}, uninstall)
+}
+
+func TestResourceOrderMissingRelease(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	_, _, err := NewResourceOrder(cfg).Run("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// SchemaGenerate is the action for generating a draft values schema.
+//
+// It provides the implementation of 'helm schema generate'.
+type SchemaGenerate struct {
+	// Force overwrites an existing values.schema.json.
+	Force bool
+}
+
+// NewSchemaGenerate creates a new SchemaGenerate object.
+func NewSchemaGenerate() *SchemaGenerate {
+	return &SchemaGenerate{}
+}
+
+// Run writes a values.schema.json inferred from the values.yaml of the chart
+// directory at path and returns the path of the schema file. See
+// chartutil.GenerateSchema.
+func (s *SchemaGenerate) Run(path string) (string, error) {
+	if ok, err := chartutil.IsChartDir(path); !ok {
+		return "", err
+	}
+
+	schemaPath := filepath.Join(path, chartutil.SchemafileName)
+	if _, err := os.Stat(schemaPath); err == nil && !s.Force {
+		return "", errors.Errorf("%s already exists", schemaPath)
+	}
+
+	values, err := chartutil.ReadValuesFile(filepath.Join(path, chartutil.ValuesfileName))
+	if err != nil && !os.IsNotExist(err) {
+		return "", errors.Wrapf(err, "cannot load %s", chartutil.ValuesfileName)
+	}
+	schema, err := chartutil.GenerateSchema(values)
+	if err != nil {
+		return "", err
+	}
+	return schemaPath, ioutil.WriteFile(schemaPath, schema, 0644)
+}
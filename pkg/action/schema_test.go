@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestSchemaGenerate(t *testing.T) {
+	dir := ensure.TempDir(t)
+	chartPath, err := chartutil.Create("schemaless", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewSchemaGenerate()
+	schemaPath, err := client.Run(chartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(chartPath, chartutil.SchemafileName); schemaPath != expected {
+		t.Errorf("Expected schema to be written to %s, got %s", expected, schemaPath)
+	}
+	schema, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(schema), `"replicaCount": {
+      "type": "integer"
+    }`) {
+		t.Errorf("Expected replicaCount to be inferred as an integer, got:\n%s", schema)
+	}
+
+	// an existing schema is only overwritten with Force
+	if err := ioutil.WriteFile(schemaPath, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Run(chartPath); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an error for an existing schema, got %v", err)
+	}
+	client.Force = true
+	if _, err := client.Run(chartPath); err != nil {
+		t.Fatal(err)
+	}
+	if schema, _ := ioutil.ReadFile(schemaPath); string(schema) == "{}" {
+		t.Error("Expected the existing schema to be overwritten")
+	}
+
+	if _, err := client.Run(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing chart")
+	}
+}
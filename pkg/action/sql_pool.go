@@ -0,0 +1,54 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// sqlPoolOptionsFromEnv reads the connection pool settings of the SQL driver
+// from the HELM_DRIVER_SQL_MAX_OPEN_CONNS, HELM_DRIVER_SQL_MAX_IDLE_CONNS and
+// HELM_DRIVER_SQL_CONN_MAX_LIFETIME environment variables.
+func sqlPoolOptionsFromEnv() (driver.SQLPoolOptions, error) {
+	var pool driver.SQLPoolOptions
+	for name, n := range map[string]*int{
+		"HELM_DRIVER_SQL_MAX_OPEN_CONNS": &pool.MaxOpenConns,
+		"HELM_DRIVER_SQL_MAX_IDLE_CONNS": &pool.MaxIdleConns,
+	} {
+		if v := os.Getenv(name); v != "" {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return pool, errors.Wrapf(err, "invalid %s", name)
+			}
+			*n = i
+		}
+	}
+	if v := os.Getenv("HELM_DRIVER_SQL_CONN_MAX_LIFETIME"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return pool, errors.Wrap(err, "invalid HELM_DRIVER_SQL_CONN_MAX_LIFETIME")
+		}
+		pool.ConnMaxLifetime = d
+	}
+	return pool, nil
+}
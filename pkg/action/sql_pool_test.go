@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func TestSQLPoolOptionsFromEnv(t *testing.T) {
+	env := map[string]string{
+		"HELM_DRIVER_SQL_MAX_OPEN_CONNS":    "20",
+		"HELM_DRIVER_SQL_MAX_IDLE_CONNS":    "5",
+		"HELM_DRIVER_SQL_CONN_MAX_LIFETIME": "30m",
+	}
+	for name, v := range env {
+		os.Setenv(name, v)
+		defer os.Unsetenv(name)
+	}
+
+	pool, err := sqlPoolOptionsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := driver.SQLPoolOptions{MaxOpenConns: 20, MaxIdleConns: 5, ConnMaxLifetime: 30 * time.Minute}
+	if pool != expect {
+		t.Errorf("Expected %+v, got %+v", expect, pool)
+	}
+
+	for name, v := range map[string]string{
+		"HELM_DRIVER_SQL_MAX_IDLE_CONNS":    "some",
+		"HELM_DRIVER_SQL_CONN_MAX_LIFETIME": "forever",
+	} {
+		os.Setenv(name, v)
+		if _, err := sqlPoolOptionsFromEnv(); err == nil {
+			t.Errorf("Expected an error for %s=%s", name, v)
+		}
+		os.Setenv(name, env[name])
+	}
+}
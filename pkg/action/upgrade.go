@@ -24,11 +24,13 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/resource"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
@@ -62,10 +64,18 @@ type Upgrade struct {
 	SkipCRDs bool
 	// Timeout is the timeout for this operation
 	Timeout time.Duration
+	// ApplyTimeout, when set, fails the creation or update of any single
+	// resource that takes longer, apart from the Timeout spent waiting for
+	// readiness.
+	ApplyTimeout time.Duration
 	// Wait determines whether the wait operation should be performed after the upgrade is requested.
 	Wait bool
 	// WaitForJobs determines whether the wait operation for the Jobs should be performed after the upgrade is requested.
 	WaitForJobs bool
+	// WaitRetries is the number of consecutive transient API server errors tolerated while waiting.
+	WaitRetries int
+	// WaitSkipKinds lists the kinds of resources not waited for, such as Jobs run asynchronously.
+	WaitSkipKinds []string
 	// DisableHooks disables hook processing if set to true.
 	DisableHooks bool
 	// DryRun controls whether the operation is prepared, but not executed.
@@ -98,12 +108,49 @@ type Upgrade struct {
 	PostRenderer postrender.PostRenderer
 	// DisableOpenAPIValidation controls whether OpenAPI validation is enforced.
 	DisableOpenAPIValidation bool
+	// ApplySetPrune labels the release's resources as an apply set and, after the
+	// update, deletes any live resource carrying that label which is no longer
+	// part of the release. Only kinds found in the old or new manifest are pruned.
+	ApplySetPrune bool
+	// StrictSchema rejects values that the chart schemas do not declare, even
+	// where they allow additional properties.
+	StrictSchema bool
+	// RenderValueTemplates renders the values containing templates, such as
+	// "{{ .Values.host }}", before the chart templates. See
+	// engine.RenderValueTemplates.
+	RenderValueTemplates bool
+	// UnsetValues are paths, as read by chartutil.UnsetValueAtPath, removed
+	// from the values once they are coalesced with the chart's defaults.
+	UnsetValues []string
+	// AllowDuplicateResources skips the check rejecting rendered manifests
+	// that define the same resource more than once.
+	AllowDuplicateResources bool
+	// WaitForPrunedDeletion deletes the resources removed from the release
+	// and waits up to Timeout for them to be gone before applying the new
+	// manifest, so that the new resources cannot conflict with them.
+	WaitForPrunedDeletion bool
+	// InjectedValues are layered above all other values, including those
+	// passed to Run and those reused from the current release, as the
+	// highest-precedence overrides. They let embedders supply values computed
+	// at call time, such as from a config service.
+	InjectedValues map[string]interface{}
+	// ReleaseAnnotations are stored with the new release revision. See
+	// release.Release.Annotations.
+	ReleaseAnnotations map[string]string
+	// RollbackFailedResources, when applying the new manifest fails, restores
+	// only the resources that failed to their state in the current release,
+	// deleting those the upgrade failed to create, and keeps the resources that
+	// were applied. This leaves the cluster running a mix of both revisions
+	// that matches neither manifest, so the failed release still has to be
+	// fixed by another upgrade or a rollback. It cannot be used with Atomic.
+	RollbackFailedResources bool
 }
 
 // NewUpgrade creates a new Upgrade object with the given configuration.
 func NewUpgrade(cfg *Configuration) *Upgrade {
 	return &Upgrade{
-		cfg: cfg,
+		cfg:         cfg,
+		WaitRetries: kube.DefaultWaitRetries,
 	}
 }
 
@@ -117,6 +164,10 @@ func (u *Upgrade) Run(name string, chart *chart.Chart, vals map[string]interface
 	// the user doesn't have to specify both
 	u.Wait = u.Wait || u.Atomic
 
+	if u.Atomic && u.RollbackFailedResources {
+		return nil, errors.New("atomic and rolling back only the failed resources cannot be used together")
+	}
+
 	if err := chartutil.ValidateReleaseName(name); err != nil {
 		return nil, errors.Errorf("release name is invalid: %s", name)
 	}
@@ -187,6 +238,19 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 	if err != nil {
 		return nil, nil, err
 	}
+	vals, err = injectValues(vals, u.InjectedValues)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// move values from keys the chart renamed since they were supplied
+	vals, migrated, err := chartutil.MigrateValues(chart, vals)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, m := range migrated {
+		u.cfg.Log("warning: %s", m)
+	}
 
 	if err := chartutil.ProcessDependencies(chart, vals); err != nil {
 		return nil, nil, err
@@ -211,11 +275,29 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := chartutil.UnsetRenderValues(valuesToRender, u.UnsetValues); err != nil {
+		return nil, nil, err
+	}
+	if u.RenderValueTemplates {
+		if err := engine.RenderValueTemplates(chart, valuesToRender); err != nil {
+			return nil, nil, err
+		}
+	}
+	if u.StrictSchema {
+		if err := checkUnknownValues(chart, valuesToRender); err != nil {
+			return nil, nil, err
+		}
+	}
 
-	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(chart, valuesToRender, "", "", u.SubNotes, false, false, u.PostRenderer, u.DryRun)
+	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(chart, valuesToRender, "", "", u.SubNotes, false, false, false, u.PostRenderer, u.DryRun)
 	if err != nil {
 		return nil, nil, err
 	}
+	if !u.AllowDuplicateResources {
+		if err := checkDuplicateResources(manifestDoc.String(), currentRelease.Namespace); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	// Store an upgraded release.
 	upgradedRelease := &release.Release{
@@ -229,9 +311,10 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 			Status:        release.StatusPendingUpgrade,
 			Description:   "Preparing upgrade", // This should be overwritten later.
 		},
-		Version:  revision,
-		Manifest: manifestDoc.String(),
-		Hooks:    hooks,
+		Version:     revision,
+		Manifest:    manifestDoc.String(),
+		Hooks:       hooks,
+		Annotations: u.ReleaseAnnotations,
 	}
 
 	if len(notesTxt) > 0 {
@@ -264,6 +347,15 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 		return upgradedRelease, err
 	}
 
+	if u.ApplySetPrune {
+		if _, ok := u.cfg.KubeClient.(kube.LabelLister); !ok {
+			return upgradedRelease, errors.New("apply set pruning is not supported by the Kubernetes client")
+		}
+		if err := target.Visit(setApplySetLabelVisitor(upgradedRelease.Name, upgradedRelease.Namespace)); err != nil {
+			return upgradedRelease, err
+		}
+	}
+
 	// Do a basic diff using gvk + name to figure out what new resources are being created so we can validate they don't already exist
 	existingResources := make(map[string]bool)
 	for _, r := range current {
@@ -307,19 +399,43 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 
 	// pre-upgrade hooks
 	if !u.DisableHooks {
-		if err := u.cfg.execHook(upgradedRelease, release.HookPreUpgrade, u.Timeout); err != nil {
+		if err := u.cfg.execHook(upgradedRelease, release.HookPreUpgrade, u.Timeout, u.ApplyTimeout); err != nil {
 			return u.failRelease(upgradedRelease, kube.ResourceList{}, fmt.Errorf("pre-upgrade hooks failed: %s", err))
 		}
 	} else {
 		u.cfg.Log("upgrade hooks disabled for %s", upgradedRelease.Name)
 	}
 
-	results, err := u.cfg.KubeClient.Update(current, target, u.Force)
+	if u.WaitForPrunedDeletion {
+		if err := u.deletePrunedResources(current, target); err != nil {
+			u.cfg.recordRelease(originalRelease)
+			return u.failRelease(upgradedRelease, kube.ResourceList{}, err)
+		}
+	}
+
+	results, err := u.cfg.updateResources(current, target, u.Force, u.ApplyTimeout)
 	if err != nil {
 		u.cfg.recordRelease(originalRelease)
+		if u.RollbackFailedResources && results != nil {
+			err = u.rollbackFailedResources(current, results, err)
+		}
 		return u.failRelease(upgradedRelease, results.Created, err)
 	}
 
+	if u.ApplySetPrune {
+		scope := append(kube.ResourceList{}, current...)
+		scope = append(scope, target...)
+		// resources already deleted by the update are not pruned a second time
+		exclude := append(kube.ResourceList{}, target...)
+		exclude = append(exclude, results.Deleted...)
+		pruned, err := pruneApplySet(u.cfg, scope, exclude, upgradedRelease.Name, upgradedRelease.Namespace)
+		if err != nil {
+			u.cfg.recordRelease(originalRelease)
+			return u.failRelease(upgradedRelease, results.Created, err)
+		}
+		results.Deleted = append(results.Deleted, pruned...)
+	}
+
 	if u.Recreate {
 		// NOTE: Because this is not critical for a release to succeed, we just
 		// log if an error occurs and continue onward. If we ever introduce log
@@ -331,22 +447,15 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 	}
 
 	if u.Wait {
-		if u.WaitForJobs {
-			if err := u.cfg.KubeClient.WaitWithJobs(target, u.Timeout); err != nil {
-				u.cfg.recordRelease(originalRelease)
-				return u.failRelease(upgradedRelease, results.Created, err)
-			}
-		} else {
-			if err := u.cfg.KubeClient.Wait(target, u.Timeout); err != nil {
-				u.cfg.recordRelease(originalRelease)
-				return u.failRelease(upgradedRelease, results.Created, err)
-			}
+		if err := u.cfg.waitForResources(target, u.Timeout, u.WaitForJobs, u.WaitRetries, u.WaitSkipKinds); err != nil {
+			u.cfg.recordRelease(originalRelease)
+			return u.failRelease(upgradedRelease, results.Created, err)
 		}
 	}
 
 	// post-upgrade hooks
 	if !u.DisableHooks {
-		if err := u.cfg.execHook(upgradedRelease, release.HookPostUpgrade, u.Timeout); err != nil {
+		if err := u.cfg.execHook(upgradedRelease, release.HookPostUpgrade, u.Timeout, u.ApplyTimeout); err != nil {
 			return u.failRelease(upgradedRelease, results.Created, fmt.Errorf("post-upgrade hooks failed: %s", err))
 		}
 	}
@@ -410,6 +519,8 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 		rollin.Version = filteredHistory[0].Version
 		rollin.Wait = true
 		rollin.WaitForJobs = u.WaitForJobs
+		rollin.WaitRetries = u.WaitRetries
+		rollin.WaitSkipKinds = u.WaitSkipKinds
 		rollin.DisableHooks = u.DisableHooks
 		rollin.Recreate = u.Recreate
 		rollin.Force = u.Force
@@ -423,6 +534,74 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 	return rel, err
 }
 
+// rollbackFailedResources restores the failed resources of results that exist
+// in the current release to their state in it, and deletes the others, unless
+// their creation failed because they already exist: the release does not own
+// them. It returns updateErr, the error that failed the upgrade, along with
+// the outcome.
+func (u *Upgrade) rollbackFailedResources(current kube.ResourceList, results *kube.Result, updateErr error) error {
+	failed := results.Failed
+	if len(failed) == 0 {
+		return updateErr
+	}
+	u.cfg.Log("rolling back %d failed resources", len(failed))
+
+	if previous := current.Intersect(failed); len(previous) > 0 {
+		if _, err := u.cfg.updateResources(failed.Intersect(current), previous, u.Force, u.ApplyTimeout); err != nil {
+			return errors.Wrapf(err, "an error occurred while rolling back the failed resources. original upgrade error: %s", updateErr)
+		}
+	}
+	var created kube.ResourceList
+	for _, info := range failed.Difference(current) {
+		if apierrors.IsAlreadyExists(results.FailedErrors[info]) {
+			u.cfg.Log("not deleting %s %q: it already existed, and is not owned by the release", info.Mapping.GroupVersionKind.Kind, info.Name)
+			continue
+		}
+		created = append(created, info)
+	}
+	if len(created) > 0 {
+		if _, errs := u.cfg.KubeClient.Delete(created); errs != nil {
+			var errorList []string
+			for _, e := range errs {
+				errorList = append(errorList, e.Error())
+			}
+			return errors.Wrapf(fmt.Errorf("unable to delete resources: %s", strings.Join(errorList, ", ")), "an error occurred while rolling back the failed resources. original upgrade error: %s", updateErr)
+		}
+	}
+	return errors.Wrapf(updateErr, "%d failed resources have been rolled back", len(failed))
+}
+
+// NewlyRequiredValues returns the values paths that the schema of chart
+// requires but the schema of the deployed release's chart does not, and that
+// the values the upgrade would use do not set. It warns before an upgrade that
+// would fail schema validation.
+func (u *Upgrade) NewlyRequiredValues(name string, chart *chart.Chart, vals map[string]interface{}) ([]string, error) {
+	if chart == nil {
+		return nil, errMissingChart
+	}
+	currentRelease, err := u.cfg.Releases.Deployed(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// reuseValues may replace the chart's default values, so leave the caller's
+	// chart untouched
+	upgradeChart := *chart
+	vals, err = u.reuseValues(&upgradeChart, currentRelease, vals)
+	if err != nil {
+		return nil, err
+	}
+	vals, err = injectValues(vals, u.InjectedValues)
+	if err != nil {
+		return nil, err
+	}
+	cvals, err := chartutil.CoalesceValues(&upgradeChart, vals)
+	if err != nil {
+		return nil, err
+	}
+	return chartutil.NewlyRequiredValues(currentRelease.Chart, &upgradeChart, cvals)
+}
+
 // reuseValues copies values from the current release to a new release if the
 // new release does not have any values.
 //
@@ -0,0 +1,218 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// ManifestChangeType describes how an upgrade changes a resource.
+type ManifestChangeType string
+
+const (
+	// ManifestAdded is a resource that only the proposed release has.
+	ManifestAdded ManifestChangeType = "added"
+	// ManifestModified is a resource whose manifest differs between the
+	// deployed and the proposed release, including its apiVersion.
+	ManifestModified ManifestChangeType = "modified"
+	// ManifestRemoved is a resource that only the deployed release has.
+	ManifestRemoved ManifestChangeType = "removed"
+)
+
+// ManifestChange is a resource that an upgrade would add, modify or remove.
+type ManifestChange struct {
+	Type ManifestChangeType
+	// Resource identifies the resource, as in the proposed release when it is
+	// part of it.
+	Resource ResourceID
+	// Current and Proposed are the manifests of the resource in the deployed
+	// and in the proposed release. Either is empty when it is not part of it.
+	Current  string
+	Proposed string
+	// Live is the resource as found in the cluster, or nil if it does not exist.
+	Live runtime.Object
+}
+
+// UpgradePreviewResult is what an upgrade would do to a release.
+type UpgradePreviewResult struct {
+	// Release is the release the upgrade would create, with its manifest and hooks.
+	Release *release.Release
+	// Changes lists the resources the upgrade would change, by kind, namespace and name.
+	Changes []ManifestChange
+}
+
+// UpgradePreview is the action for reviewing an upgrade before running it.
+//
+// It renders the upgrade as a dry-run, validating the manifests against the
+// OpenAPI schema of the cluster unless DisableOpenAPIValidation is set,
+// compares them with the deployed release, and fetches the live state of every
+// changed resource. The manifests are not sent to the API server, so admission
+// controllers and defaulting do not run. All the options of Upgrade apply.
+type UpgradePreview struct {
+	*Upgrade
+}
+
+// NewUpgradePreview creates a new UpgradePreview object with the given configuration.
+func NewUpgradePreview(cfg *Configuration) *UpgradePreview {
+	return &UpgradePreview{Upgrade: NewUpgrade(cfg)}
+}
+
+// Run previews upgrading the release name to chart with vals.
+func (p *UpgradePreview) Run(name string, chart *chart.Chart, vals map[string]interface{}) (*UpgradePreviewResult, error) {
+	dryRun := p.DryRun
+	p.DryRun = true
+	defer func() { p.DryRun = dryRun }()
+
+	proposed, err := p.Upgrade.Run(name, chart, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := p.cfg.Releases.Deployed(name)
+	if err != nil {
+		if current, err = p.cfg.Releases.Last(name); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := diffManifests(current.Manifest, proposed.Manifest, proposed.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		manifest := changes[i].Current
+		if manifest == "" {
+			manifest = changes[i].Proposed
+		}
+		if changes[i].Live, err = p.liveObject(manifest); err != nil {
+			return nil, errors.Wrapf(err, "unable to get the live state of %s", changes[i].Resource)
+		}
+	}
+
+	return &UpgradePreviewResult{Release: proposed, Changes: changes}, nil
+}
+
+// liveObject returns the live state of the resource in manifest, or nil if
+// it does not exist.
+func (p *UpgradePreview) liveObject(manifest string) (runtime.Object, error) {
+	resources, err := p.cfg.KubeClient.Build(bytes.NewBufferString(manifest), false)
+	if err != nil || len(resources) == 0 {
+		return nil, err
+	}
+	info := resources[0]
+	if err := info.Get(); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return info.Object, nil
+}
+
+// diffManifests returns the resources added, modified or removed between the
+// current and proposed release manifests, sorted by kind, namespace and name.
+// Resources without a namespace are in namespace. Like an upgrade, a resource
+// of the same kind, namespace and name rendered with another apiVersion is
+// modified rather than replaced.
+func diffManifests(current, proposed, namespace string) ([]ManifestChange, error) {
+	currentDocs, err := manifestsByResource(current, namespace)
+	if err != nil {
+		return nil, err
+	}
+	proposedDocs, err := manifestsByResource(proposed, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ManifestChange
+	var removed, added []ResourceID
+	for id, doc := range proposedDocs {
+		old, ok := currentDocs[id]
+		switch {
+		case !ok:
+			added = append(added, id)
+		case !reflect.DeepEqual(old.object, doc.object):
+			changes = append(changes, ManifestChange{Type: ManifestModified, Resource: id, Current: old.manifest, Proposed: doc.manifest})
+		}
+	}
+	for id := range currentDocs {
+		if _, ok := proposedDocs[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sortResourceIDs(removed)
+	sortResourceIDs(added)
+	for _, id := range removed {
+		i := indexOfObject(added, id)
+		if i < 0 {
+			changes = append(changes, ManifestChange{Type: ManifestRemoved, Resource: id, Current: currentDocs[id].manifest})
+			continue
+		}
+		changes = append(changes, ManifestChange{Type: ManifestModified, Resource: added[i], Current: currentDocs[id].manifest, Proposed: proposedDocs[added[i]].manifest})
+		added = append(added[:i], added[i+1:]...)
+	}
+	for _, id := range added {
+		changes = append(changes, ManifestChange{Type: ManifestAdded, Resource: id, Proposed: proposedDocs[id].manifest})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i].Resource, changes[j].Resource
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return changes, nil
+}
+
+type manifestDoc struct {
+	manifest string
+	object   map[string]interface{}
+}
+
+func manifestsByResource(manifest, namespace string) (map[ResourceID]manifestDoc, error) {
+	docs := make(map[ResourceID]manifestDoc)
+	for _, m := range releaseutil.SplitManifests(manifest) {
+		id, ok, err := parseResourceID(m, namespace)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse the release manifest")
+		}
+		if !ok {
+			continue
+		}
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(m), &object); err != nil {
+			return nil, errors.Wrap(err, "unable to parse the release manifest")
+		}
+		docs[id] = manifestDoc{manifest: m, object: object}
+	}
+	return docs, nil
+}
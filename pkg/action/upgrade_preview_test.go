@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const previewCurrentManifest = `---
+# Source: hello/templates/cm
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: old
+---
+# Source: hello/templates/svc
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: hello/templates/sa
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+`
+
+func TestUpgradePreview(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	preview := NewUpgradePreview(actionConfigFixture(t))
+	preview.Namespace = "spaced"
+
+	rel := releaseStub()
+	rel.Name = "previewed"
+	rel.Namespace = "spaced"
+	rel.Manifest = previewCurrentManifest
+	req.NoError(preview.cfg.Releases.Create(rel))
+
+	withPreviewTemplates := func(opts *chartOptions) {
+		opts.Templates = []*chart.File{
+			{Name: "templates/cm", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  mode: new\n")},
+			{Name: "templates/deploy", Data: []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n")},
+			{Name: "templates/sa", Data: []byte("apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web\n")},
+		}
+	}
+
+	res, err := preview.Run(rel.Name, buildChart(withPreviewTemplates), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(2, res.Release.Version)
+	is.Contains(res.Release.Manifest, "mode: new")
+	is.False(preview.DryRun, "expected the dry-run option to be restored")
+
+	// the upgrade must not have been recorded
+	last, err := preview.cfg.Releases.Last(rel.Name)
+	req.NoError(err)
+	is.Equal(1, last.Version)
+
+	req.Len(res.Changes, 3)
+	is.Equal(ManifestModified, res.Changes[0].Type)
+	is.Equal("ConfigMap", res.Changes[0].Resource.Kind)
+	is.Contains(res.Changes[0].Current, "mode: old")
+	is.Contains(res.Changes[0].Proposed, "mode: new")
+	is.Equal(ManifestAdded, res.Changes[1].Type)
+	is.Equal("Deployment", res.Changes[1].Resource.Kind)
+	is.Empty(res.Changes[1].Current)
+	is.Equal(ManifestRemoved, res.Changes[2].Type)
+	is.Equal("Service", res.Changes[2].Resource.Kind)
+	is.Equal("spaced", res.Changes[2].Resource.Namespace)
+	is.Empty(res.Changes[2].Proposed)
+}
+
+func TestUpgradePreviewMissingRelease(t *testing.T) {
+	preview := NewUpgradePreview(actionConfigFixture(t))
+	_, err := preview.Run("missing", buildChart(), map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestDiffManifestsAPIVersionChange(t *testing.T) {
+	current := "apiVersion: extensions/v1beta1\nkind: Ingress\nmetadata:\n  name: web\n"
+	proposed := "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: web\n---\napiVersion: v1\nkind: Ingress\nmetadata:\n  name: web\n  namespace: other\n"
+
+	changes, err := diffManifests(current, proposed, "spaced")
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, ManifestAdded, changes[0].Type)
+	assert.Equal(t, ResourceID{APIVersion: "v1", Kind: "Ingress", Namespace: "other", Name: "web"}, changes[0].Resource)
+	assert.Equal(t, ManifestModified, changes[1].Type)
+	assert.Equal(t, ResourceID{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Namespace: "spaced", Name: "web"}, changes[1].Resource)
+	assert.Contains(t, changes[1].Current, "extensions/v1beta1")
+}
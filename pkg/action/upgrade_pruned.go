@@ -0,0 +1,58 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+// deletePrunedResources deletes the resources of current that are not part of
+// target, except those annotated to be kept, and waits up to u.Timeout for
+// them to be gone.
+func (u *Upgrade) deletePrunedResources(current, target kube.ResourceList) error {
+	kubeClient, ok := u.cfg.KubeClient.(kube.DeleteWaiter)
+	if !ok {
+		return errors.New("waiting for pruned resources is not supported by the Kubernetes client")
+	}
+
+	var pruned kube.ResourceList
+	for _, info := range current.Difference(target) {
+		annotations, err := accessor.Annotations(info.Object)
+		if err != nil {
+			u.cfg.Log("Unable to get annotations on %s, err: %s", resourceString(info), err)
+		}
+		if annotations[kube.ResourcePolicyAnno] == kube.KeepPolicy {
+			u.cfg.Log("Skipping delete of %s due to annotation [%s=%s]", resourceString(info), kube.ResourcePolicyAnno, kube.KeepPolicy)
+			continue
+		}
+		pruned.Append(info)
+	}
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	u.cfg.Log("deleting %d pruned resources before applying the upgrade", len(pruned))
+	if _, errs := u.cfg.KubeClient.Delete(pruned); len(errs) > 0 {
+		return errors.Errorf("unable to delete pruned resources: %s", joinErrors(errs))
+	}
+	if err := kubeClient.WaitForDelete(pruned, u.Timeout); err != nil {
+		return errors.Wrap(err, "pruned resources were not deleted")
+	}
+	return nil
+}
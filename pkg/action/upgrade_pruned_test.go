@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// prunedKubeClient records the order in which resources are deleted, waited
+// for and updated.
+type prunedKubeClient struct {
+	manifestKubeClient
+	calls []string
+}
+
+func (c *prunedKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	for _, r := range resources {
+		c.calls = append(c.calls, "delete "+r.Name)
+	}
+	return &kube.Result{Deleted: resources}, nil
+}
+
+func (c *prunedKubeClient) WaitForDelete(resources kube.ResourceList, d time.Duration) error {
+	for _, r := range resources {
+		c.calls = append(c.calls, "wait "+r.Name)
+	}
+	return c.manifestKubeClient.WaitForDelete(resources, d)
+}
+
+func (c *prunedKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	c.calls = append(c.calls, "update")
+	return &kube.Result{Updated: target}, nil
+}
+
+func TestUpgradeRelease_WaitForPrunedDeletion(t *testing.T) {
+	configMap := func(name string, annotations string) string {
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n" + annotations
+	}
+	upgrade := func(t *testing.T, waitErr error) (*prunedKubeClient, *release.Release, error) {
+		upAction := upgradeAction(t)
+		client := &prunedKubeClient{}
+		client.PrintingKubeClient.Out = ioutil.Discard
+		client.WaitError = waitErr
+		upAction.cfg.KubeClient = client
+		upAction.WaitForPrunedDeletion = true
+
+		rel := releaseStub()
+		rel.Name = "pruning"
+		rel.Manifest = strings.Join([]string{
+			configMap("kept", ""),
+			configMap("lingering", ""),
+			configMap("precious", "  annotations:\n    helm.sh/resource-policy: keep\n"),
+		}, "\n---\n")
+		require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+		ch := buildChart()
+		ch.Templates = []*chart.File{
+			{Name: "templates/kept", Data: []byte(configMap("kept", ""))},
+		}
+		res, err := upAction.Run(rel.Name, ch, map[string]interface{}{})
+		return client, res, err
+	}
+
+	client, res, err := upgrade(t, nil)
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusDeployed, res.Info.Status)
+	// the lingering resource is gone before the new manifest is applied
+	assert.Equal(t, []string{"delete lingering", "wait lingering", "update"}, client.calls)
+
+	client, res, err = upgrade(t, fmt.Errorf("lingering is still there"))
+	require.Error(t, err)
+	assert.Contains(t, res.Info.Description, "lingering is still there")
+	assert.Equal(t, release.StatusFailed, res.Info.Status)
+	assert.Equal(t, []string{"delete lingering", "wait lingering"}, client.calls)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// failedResourcesKubeClient fails the first update of the named resources,
+// those in exists as already existing, and records the resources updated and
+// deleted after it.
+type failedResourcesKubeClient struct {
+	manifestKubeClient
+	fail    []string
+	exists  []string
+	updates [][]string
+	deleted []string
+}
+
+func (c *failedResourcesKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	var names []string
+	for _, r := range target {
+		names = append(names, r.Name)
+	}
+	c.updates = append(c.updates, names)
+	if len(c.updates) > 1 {
+		return &kube.Result{Updated: target}, nil
+	}
+
+	res := &kube.Result{FailedErrors: map[*resource.Info]error{}}
+	for _, r := range target {
+		var err error
+		for _, name := range c.fail {
+			if r.Name == name {
+				err = errors.New("failed")
+			}
+		}
+		for _, name := range c.exists {
+			if r.Name == name {
+				err = apierrors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, name)
+			}
+		}
+		if err != nil {
+			res.Failed = append(res.Failed, r)
+			res.FailedErrors[r] = err
+		} else {
+			res.Updated = append(res.Updated, r)
+		}
+	}
+	if len(res.Failed) > 0 {
+		return res, fmt.Errorf("%d resources failed", len(res.Failed))
+	}
+	return res, nil
+}
+
+func (c *failedResourcesKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	for _, r := range resources {
+		c.deleted = append(c.deleted, r.Name)
+	}
+	return &kube.Result{Deleted: resources}, nil
+}
+
+func TestUpgradeRelease_RollbackFailedResources(t *testing.T) {
+	configMap := func(name, data string) string {
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\ndata:\n  key: " + data + "\n"
+	}
+	upgrade := func(t *testing.T, rollbackFailed bool, exists []string, fail ...string) (*failedResourcesKubeClient, *release.Release, error) {
+		upAction := upgradeAction(t)
+		client := &failedResourcesKubeClient{fail: fail, exists: exists}
+		client.PrintingKubeClient.Out = ioutil.Discard
+		upAction.cfg.KubeClient = client
+		upAction.RollbackFailedResources = rollbackFailed
+
+		rel := releaseStub()
+		rel.Name = "partial"
+		rel.Manifest = strings.Join([]string{configMap("settings", "old"), configMap("web", "old")}, "\n---\n")
+		require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+		ch := buildChart()
+		ch.Templates = []*chart.File{
+			{Name: "templates/settings", Data: []byte(configMap("settings", "new"))},
+			{Name: "templates/web", Data: []byte(configMap("web", "new"))},
+			{Name: "templates/extra", Data: []byte(configMap("extra", "new"))},
+		}
+		res, err := upAction.Run(rel.Name, ch, map[string]interface{}{})
+		return client, res, err
+	}
+
+	t.Run("only the failed resources are rolled back", func(t *testing.T) {
+		client, res, err := upgrade(t, true, nil, "web", "extra")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 failed resources have been rolled back")
+		assert.Equal(t, release.StatusFailed, res.Info.Status)
+		// web is restored to the current release, extra did not exist in it
+		assert.Equal(t, [][]string{{"extra", "settings", "web"}, {"web"}}, client.updates)
+		assert.Equal(t, []string{"extra"}, client.deleted)
+	})
+
+	t.Run("resources that already existed are not deleted", func(t *testing.T) {
+		client, _, err := upgrade(t, true, []string{"extra"}, "web")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 failed resources have been rolled back")
+		assert.Equal(t, [][]string{{"extra", "settings", "web"}, {"web"}}, client.updates)
+		assert.Empty(t, client.deleted)
+	})
+
+	t.Run("nothing is rolled back when disabled", func(t *testing.T) {
+		client, res, err := upgrade(t, false, nil, "web")
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "rolled back")
+		assert.Equal(t, release.StatusFailed, res.Info.Status)
+		assert.Len(t, client.updates, 1)
+		assert.Empty(t, client.deleted)
+	})
+
+	t.Run("cannot be combined with atomic", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		upAction.Atomic = true
+		upAction.RollbackFailedResources = true
+		_, err := upAction.Run("partial", buildChart(), map[string]interface{}{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be used together")
+	})
+}
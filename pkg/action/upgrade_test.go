@@ -296,3 +296,56 @@ func TestUpgradeRelease_Pending(t *testing.T) {
 	_, err := upAction.Run(rel.Name, buildChart(), vals)
 	req.Contains(err.Error(), "progress", err)
 }
+
+func TestUpgradeRelease_NewlyRequiredValues(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "schema-change"
+	rel.Info.Status = release.StatusDeployed
+	upAction.cfg.Releases.Create(rel)
+
+	ch := buildChart()
+	ch.Schema = []byte(`{"type": "object", "required": ["name", "replicas"]}`)
+
+	// without new values, the upgrade copies the release values over
+	missing, err := upAction.NewlyRequiredValues(rel.Name, ch, map[string]interface{}{})
+	req.NoError(err)
+	is.Equal([]string{"replicas"}, missing)
+
+	missing, err = upAction.NewlyRequiredValues(rel.Name, ch, map[string]interface{}{"replicas": 3})
+	req.NoError(err)
+	is.Equal([]string{"name"}, missing)
+
+	upAction.ReuseValues = true
+	missing, err = upAction.NewlyRequiredValues(rel.Name, ch, map[string]interface{}{"replicas": 3})
+	req.NoError(err)
+	is.Empty(missing)
+	is.Nil(ch.Values, "expected the chart values to be left untouched")
+}
+
+func TestUpgradeRelease_ValueMigrations(t *testing.T) {
+	is := assert.New(t)
+	upAction := upgradeAction(t)
+
+	rel := releaseStub()
+	rel.Name = "renamed"
+	rel.Info.Status = release.StatusDeployed
+	is.NoError(upAction.cfg.Releases.Create(rel))
+
+	ch := buildChart()
+	ch.Metadata.ValueMigrations = []*chart.ValueMigration{
+		{From: "name", To: "app.name"},
+	}
+	res, err := upAction.Run(rel.Name, ch, map[string]interface{}{})
+	is.NoError(err)
+
+	expected := map[string]interface{}{
+		"app": map[string]interface{}{"name": "value"},
+	}
+	is.Equal(expected, res.Config)
+	// the values of the previous release are left untouched
+	is.Equal(map[string]interface{}{"name": "value"}, rel.Config)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+// waitForResources waits for the resources to be ready, including jobs if
+// waitForJobs is set, ignoring the resources of the skipKinds kinds. Up to
+// retries consecutive transient API server errors are tolerated when the
+// Kubernetes client supports it.
+func (c *Configuration) waitForResources(resources kube.ResourceList, timeout time.Duration, waitForJobs bool, retries int, skipKinds []string) error {
+	if len(skipKinds) > 0 {
+		resources = resources.Filter(func(r *resource.Info) bool {
+			return !isKindIn(r.Mapping.GroupVersionKind.Kind, skipKinds)
+		})
+	}
+	if kubeClient, ok := c.KubeClient.(kube.RetryWaiter); ok {
+		return kubeClient.WaitWithRetries(resources, timeout, waitForJobs, retries)
+	}
+	if waitForJobs {
+		return c.KubeClient.WaitWithJobs(resources, timeout)
+	}
+	return c.KubeClient.Wait(resources, timeout)
+}
+
+// isKindIn reports whether kind is one of kinds, ignoring case.
+func isKindIn(kind string, kinds []string) bool {
+	for _, k := range kinds {
+		if strings.EqualFold(kind, k) {
+			return true
+		}
+	}
+	return false
+}
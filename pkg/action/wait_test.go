@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+)
+
+// waitRecordingKubeClient records the names of the resources it waits for
+type waitRecordingKubeClient struct {
+	kubefake.PrintingKubeClient
+	waited []string
+}
+
+func (c *waitRecordingKubeClient) WaitWithRetries(resources kube.ResourceList, _ time.Duration, _ bool, _ int) error {
+	for _, r := range resources {
+		c.waited = append(c.waited, r.Name)
+	}
+	return nil
+}
+
+func TestWaitForResourcesSkipKinds(t *testing.T) {
+	kubeClient := &waitRecordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+	cfg := &Configuration{KubeClient: kubeClient}
+
+	info := func(kind, name string) *resource.Info {
+		return &resource.Info{
+			Name:    name,
+			Mapping: &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Kind: kind}},
+		}
+	}
+	resources := kube.ResourceList{
+		info("Deployment", "web"),
+		info("Job", "migrate"),
+		info("Service", "web-svc"),
+		info("Job", "backfill"),
+	}
+
+	if err := cfg.waitForResources(resources, time.Minute, true, 0, []string{"job"}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"web", "web-svc"}; !reflect.DeepEqual(kubeClient.waited, expected) {
+		t.Errorf("expected to wait for %v, waited for %v", expected, kubeClient.waited)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chart
+
+import (
+	"path"
+	"sort"
+)
+
+// ComponentsEnabledKey is the key of the values that enable or disable the
+// Components of a chart, such as:
+//
+//	componentsEnabled:
+//	  metrics: false
+//
+// Components are enabled unless set to false.
+const ComponentsEnabledKey = "componentsEnabled"
+
+// TemplateComponents returns the sorted names of the Components the template,
+// named relative to the chart such as "templates/ingress.yaml", belongs to.
+func (md *Metadata) TemplateComponents(template string) []string {
+	var components []string
+	for name, patterns := range md.Components {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, template); ok {
+				components = append(components, name)
+				break
+			}
+		}
+	}
+	sort.Strings(components)
+	return components
+}
@@ -47,6 +47,15 @@ type Dependency struct {
 	ImportValues []interface{} `json:"import-values,omitempty"`
 	// Alias usable alias to be used for the chart
 	Alias string `json:"alias,omitempty"`
+	// BaseValues merges the values of the dependency beneath the values of
+	// the chart, as its lowest precedence defaults. The chart's own values,
+	// its imported values and user supplied values all take precedence over
+	// them. When several dependencies set it, the first one listed wins.
+	BaseValues bool `json:"base-values,omitempty"`
+	// Digest is the digest of the chart archive pulled from an OCI registry.
+	//
+	// It is only recorded in lock files.
+	Digest string `json:"digest,omitempty"`
 }
 
 // Validate checks for common problems with the dependency datastructure in
@@ -53,16 +53,10 @@ func LoadDir(dir string) (*chart.Chart, error) {
 	// Just used for errors.
 	c := &chart.Chart{}
 
-	rules := ignore.Empty()
-	ifile := filepath.Join(topdir, ignore.HelmIgnore)
-	if _, err := os.Stat(ifile); err == nil {
-		r, err := ignore.ParseFile(ifile)
-		if err != nil {
-			return c, err
-		}
-		rules = r
+	rules, err := loadIgnoreRules(topdir)
+	if err != nil {
+		return c, err
 	}
-	rules.AddDefaults()
 
 	files := []*BufferedFile{}
 	topdir += string(filepath.Separator)
@@ -118,3 +112,64 @@ func LoadDir(dir string) (*chart.Chart, error) {
 
 	return LoadFiles(files)
 }
+
+// ListFiles applies the .helmignore rules of the chart directory and returns
+// the chart-relative paths of the files that would be loaded or packaged and
+// the paths that are ignored. An ignored directory is listed once, with a
+// trailing slash, since none of its contents are considered.
+func ListFiles(dir string) (included, excluded []string, err error) {
+	topdir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rules, err := loadIgnoreRules(topdir)
+	if err != nil {
+		return nil, nil, err
+	}
+	topdir += string(filepath.Separator)
+
+	walk := func(name string, fi os.FileInfo, err error) error {
+		n := strings.TrimPrefix(name, topdir)
+		if n == "" {
+			return nil
+		}
+		n = filepath.ToSlash(n)
+
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if rules.Ignore(n, fi) {
+				excluded = append(excluded, n+"/")
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rules.Ignore(n, fi) {
+			excluded = append(excluded, n)
+			return nil
+		}
+		included = append(included, n)
+		return nil
+	}
+	if err := sympath.Walk(topdir, walk); err != nil {
+		return nil, nil, err
+	}
+	return included, excluded, nil
+}
+
+// loadIgnoreRules returns the rules of the chart's .helmignore, if any, with the defaults added.
+func loadIgnoreRules(topdir string) (*ignore.Rules, error) {
+	rules := ignore.Empty()
+	ifile := filepath.Join(topdir, ignore.HelmIgnore)
+	if _, err := os.Stat(ifile); err == nil {
+		r, err := ignore.ParseFile(ifile)
+		if err != nil {
+			return nil, err
+		}
+		rules = r
+	}
+	rules.AddDefaults()
+	return rules, nil
+}
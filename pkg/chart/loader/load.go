@@ -21,7 +21,10 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
@@ -34,12 +37,50 @@ type ChartLoader interface {
 	Load() (*chart.Chart, error)
 }
 
+// LoaderPredicate reports whether a registered loader handles the chart at
+// name, given the result of stat-ing it.
+type LoaderPredicate func(name string, fi os.FileInfo) bool
+
+// LoaderFunc returns a ChartLoader for the chart at name.
+type LoaderFunc func(name string) (ChartLoader, error)
+
+type registeredLoader struct {
+	predicate LoaderPredicate
+	loader    LoaderFunc
+}
+
+var (
+	registeredLoadersMu sync.RWMutex
+	registeredLoaders   []registeredLoader
+)
+
+// RegisterLoader registers a loader for charts stored in formats other than a
+// directory or a tar archive.
+//
+// Loader consults the registered loaders in registration order before falling
+// back to the directory and archive loaders, and uses the first one whose
+// predicate matches.
+func RegisterLoader(predicate LoaderPredicate, loader LoaderFunc) {
+	registeredLoadersMu.Lock()
+	defer registeredLoadersMu.Unlock()
+	registeredLoaders = append(registeredLoaders, registeredLoader{predicate: predicate, loader: loader})
+}
+
 // Loader returns a new ChartLoader appropriate for the given chart name
 func Loader(name string) (ChartLoader, error) {
 	fi, err := os.Stat(name)
 	if err != nil {
 		return nil, err
 	}
+
+	registeredLoadersMu.RLock()
+	defer registeredLoadersMu.RUnlock()
+	for _, r := range registeredLoaders {
+		if r.predicate(name, fi) {
+			return r.loader(name)
+		}
+	}
+
 	if fi.IsDir() {
 		return DirLoader(name), nil
 	}
@@ -162,39 +203,88 @@ func LoadFiles(files []*BufferedFile) (*chart.Chart, error) {
 		return c, err
 	}
 
-	for n, files := range subcharts {
-		var sc *chart.Chart
-		var err error
-		switch {
-		case strings.IndexAny(n, "_.") == 0:
+	// load the subcharts concurrently, in name order so that the dependencies
+	// of the chart are deterministic
+	names := make([]string, 0, len(subcharts))
+	for n := range subcharts {
+		if strings.IndexAny(n, "_.") == 0 {
 			continue
-		case filepath.Ext(n) == ".tgz":
-			file := files[0]
-			if file.Name != n {
-				return c, errors.Errorf("error unpacking tar in %s: expected %s, got %s", c.Name(), n, file.Name)
-			}
-			// Untar the chart and add to c.Dependencies
-			sc, err = LoadArchive(bytes.NewBuffer(file.Data))
+		}
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	loaded := make([]*chart.Chart, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, n := range names {
+		select {
+		case loadSlots <- struct{}{}:
+			wg.Add(1)
+			go func(i int, n string) {
+				defer func() {
+					<-loadSlots
+					wg.Done()
+				}()
+				loaded[i], errs[i] = loadSubchart(c.Name(), n, subcharts[n])
+			}(i, n)
 		default:
-			// We have to trim the prefix off of every file, and ignore any file
-			// that is in charts/, but isn't actually a chart.
-			buff := make([]*BufferedFile, 0, len(files))
-			for _, f := range files {
-				parts := strings.SplitN(f.Name, "/", 2)
-				if len(parts) < 2 {
-					continue
-				}
-				f.Name = parts[1]
-				buff = append(buff, f)
-			}
-			sc, err = LoadFiles(buff)
+			// every slot is taken, possibly by the charts this one belongs
+			// to, so load it here rather than wait for a slot
+			loaded[i], errs[i] = loadSubchart(c.Name(), n, subcharts[n])
 		}
+	}
+	wg.Wait()
 
+	var failed []error
+	for i, err := range errs {
 		if err != nil {
-			return c, errors.Wrapf(err, "error unpacking %s in %s", n, c.Name())
+			failed = append(failed, errors.Wrapf(err, "error unpacking %s in %s", names[i], c.Name()))
+		}
+	}
+	switch len(failed) {
+	case 0:
+	case 1:
+		return c, failed[0]
+	default:
+		msgs := make([]string, len(failed))
+		for i, err := range failed {
+			msgs[i] = err.Error()
 		}
+		return c, errors.Errorf("%d subcharts failed to load: %s", len(failed), strings.Join(msgs, "; "))
+	}
+	for _, sc := range loaded {
 		c.AddDependency(sc)
 	}
 
 	return c, nil
 }
+
+// loadSlots bounds the number of subcharts loaded concurrently.
+var loadSlots = make(chan struct{}, runtime.GOMAXPROCS(0))
+
+// loadSubchart loads the subchart n of the chart parent from its files in the
+// charts/ directory.
+func loadSubchart(parent, n string, files []*BufferedFile) (*chart.Chart, error) {
+	if filepath.Ext(n) == ".tgz" {
+		file := files[0]
+		if file.Name != n {
+			return nil, errors.Errorf("error unpacking tar in %s: expected %s, got %s", parent, n, file.Name)
+		}
+		// Untar the chart and add to c.Dependencies
+		return LoadArchive(bytes.NewBuffer(file.Data))
+	}
+
+	// We have to trim the prefix off of every file, and ignore any file
+	// that is in charts/, but isn't actually a chart.
+	buff := make([]*BufferedFile, 0, len(files))
+	for _, f := range files {
+		parts := strings.SplitN(f.Name, "/", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		f.Name = parts[1]
+		buff = append(buff, f)
+	}
+	return LoadFiles(buff)
+}
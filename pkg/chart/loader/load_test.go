@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
@@ -87,6 +88,63 @@ func TestLoadDirWithSymlink(t *testing.T) {
 	verifyDependenciesLock(t, c)
 }
 
+func TestListFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-list-files-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		".helmignore":                     "*.bak\ndocs/\ntemplates/tests/secret.yaml\n",
+		"Chart.yaml":                      "apiVersion: v2\nname: ignored\nversion: 0.1.0\n",
+		"values.yaml":                     "",
+		"values.yaml.bak":                 "",
+		"docs/README.md":                  "",
+		"docs/images/logo.png":            "",
+		"templates/deployment.yaml":       "",
+		"templates/tests/test.yaml":       "",
+		"templates/tests/test.yaml.bak":   "",
+		"templates/tests/secret.yaml":     "",
+		"charts/sub/templates/extra.yaml": "",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	included, excluded, err := ListFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectIncluded := []string{
+		".helmignore",
+		"Chart.yaml",
+		"charts/sub/templates/extra.yaml",
+		"templates/deployment.yaml",
+		"templates/tests/test.yaml",
+		"values.yaml",
+	}
+	expectExcluded := []string{
+		"docs/",
+		"templates/tests/secret.yaml",
+		"templates/tests/test.yaml.bak",
+		"values.yaml.bak",
+	}
+	if strings.Join(included, ",") != strings.Join(expectIncluded, ",") {
+		t.Errorf("Expected included files %v, got %v", expectIncluded, included)
+	}
+	if strings.Join(excluded, ",") != strings.Join(expectExcluded, ",") {
+		t.Errorf("Expected excluded files %v, got %v", expectExcluded, excluded)
+	}
+}
+
 func TestBomTestData(t *testing.T) {
 	testFiles := []string{"frobnitz_with_bom/.helmignore", "frobnitz_with_bom/templates/template.tpl", "frobnitz_with_bom/Chart.yaml"}
 	for _, file := range testFiles {
@@ -207,6 +265,54 @@ func TestLoadFile(t *testing.T) {
 	verifyDependencies(t, c)
 }
 
+// bundleLoader loads a chart stored as a single Chart.yaml bundle file.
+type bundleLoader string
+
+func (l bundleLoader) Load() (*chart.Chart, error) {
+	data, err := ioutil.ReadFile(string(l))
+	if err != nil {
+		return nil, err
+	}
+	return LoadFiles([]*BufferedFile{{Name: "Chart.yaml", Data: data}})
+}
+
+func TestRegisterLoader(t *testing.T) {
+	defer func(orig []registeredLoader) { registeredLoaders = orig }(registeredLoaders)
+
+	dir, err := ioutil.TempDir("", "helm-loader-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bundle := filepath.Join(dir, "bundled.chartbundle")
+	if err := ioutil.WriteFile(bundle, []byte("apiVersion: v2\nname: bundled\nversion: 0.1.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterLoader(func(name string, fi os.FileInfo) bool {
+		return !fi.IsDir() && filepath.Ext(name) == ".chartbundle"
+	}, func(name string) (ChartLoader, error) {
+		return bundleLoader(name), nil
+	})
+
+	c, err := Load(bundle)
+	if err != nil {
+		t.Fatalf("Failed to load bundle: %s", err)
+	}
+	if c.Name() != "bundled" || c.Metadata.Version != "0.1.0" {
+		t.Errorf("Unexpected chart %s-%s", c.Name(), c.Metadata.Version)
+	}
+
+	// charts not matching the predicate still use the builtin loaders
+	l, err := Loader("testdata/frobnitz-1.2.3.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.(FileLoader); !ok {
+		t.Errorf("Expected a FileLoader, got %T", l)
+	}
+}
+
 func TestLoadFiles_BadCases(t *testing.T) {
 	for _, tt := range []struct {
 		name          string
@@ -233,6 +339,71 @@ func TestLoadFiles_BadCases(t *testing.T) {
 	}
 }
 
+// umbrellaFiles returns the files of a chart with n subcharts, named sub00,
+// sub01 and so on. The subcharts named in broken have no Chart.yaml.
+func umbrellaFiles(n int, broken ...string) []*BufferedFile {
+	files := []*BufferedFile{
+		{Name: "Chart.yaml", Data: []byte("apiVersion: v2\nname: umbrella\nversion: 1.0.0\n")},
+		{Name: "values.yaml", Data: []byte("global: {}\n")},
+	}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("sub%02d", i)
+		dir := "charts/" + name + "/"
+		isBroken := false
+		for _, b := range broken {
+			isBroken = isBroken || b == name
+		}
+		if !isBroken {
+			files = append(files, &BufferedFile{Name: dir + "Chart.yaml", Data: []byte("apiVersion: v2\nname: " + name + "\nversion: 0.1.0\n")})
+		}
+		files = append(files,
+			&BufferedFile{Name: dir + "values.yaml", Data: []byte("replicas: 1\nimage: nginx\n")},
+			&BufferedFile{Name: dir + "templates/deployment.yaml", Data: []byte("kind: Deployment\n")},
+		)
+	}
+	return files
+}
+
+func TestLoadFilesSubcharts(t *testing.T) {
+	for attempt := 0; attempt < 5; attempt++ {
+		c, err := LoadFiles(umbrellaFiles(12))
+		if err != nil {
+			t.Fatal(err)
+		}
+		deps := c.Dependencies()
+		if len(deps) != 12 {
+			t.Fatalf("Expected 12 dependencies, got %d", len(deps))
+		}
+		for i, d := range deps {
+			if expected := fmt.Sprintf("sub%02d", i); d.Name() != expected {
+				t.Fatalf("Expected dependency %d to be %s, got %s", i, expected, d.Name())
+			}
+			if d.Parent() != c {
+				t.Errorf("Expected the parent of %s to be the umbrella chart", d.Name())
+			}
+		}
+	}
+
+	_, err := LoadFiles(umbrellaFiles(6, "sub02", "sub04"))
+	if err == nil {
+		t.Fatal("Expected an error loading broken subcharts")
+	}
+	for _, name := range []string{"sub02", "sub04"} {
+		if !strings.Contains(err.Error(), "error unpacking "+name+" in umbrella") {
+			t.Errorf("Expected the error to report subchart %s, got %q", name, err)
+		}
+	}
+}
+
+func BenchmarkLoadFilesSubcharts(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		// LoadFiles trims the names of the subchart files, so build them anew
+		if _, err := LoadFiles(umbrellaFiles(50)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestLoadFiles(t *testing.T) {
 	goodFiles := []*BufferedFile{
 		{
@@ -16,6 +16,7 @@ limitations under the License.
 package chart
 
 import (
+	"path"
 	"strings"
 	"unicode"
 
@@ -77,6 +78,13 @@ type Metadata struct {
 	Dependencies []*Dependency `json:"dependencies,omitempty"`
 	// Specifies the chart type: application or library
 	Type string `json:"type,omitempty"`
+	// ValueMigrations move user supplied values from renamed keys on upgrade.
+	ValueMigrations []*ValueMigration `json:"valueMigrations,omitempty"`
+	// Components map the name of a component to the templates it is made of,
+	// as path patterns such as "templates/ingress.yaml" or
+	// "templates/metrics/*". Templates are skipped when rendering if all of
+	// their components are disabled in the ComponentsEnabledKey values.
+	Components map[string][]string `json:"components,omitempty"`
 }
 
 // Validate checks the metadata for known issues and sanitizes string
@@ -130,6 +138,23 @@ func (md *Metadata) Validate() error {
 			return err
 		}
 	}
+
+	for _, migration := range md.ValueMigrations {
+		if err := migration.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for name, patterns := range md.Components {
+		if name == "" {
+			return ValidationError("components must have a name")
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return ValidationErrorf("component %q has an invalid template pattern %q", name, pattern)
+			}
+		}
+	}
 	return nil
 }
 
@@ -72,6 +72,28 @@ func TestValidate(t *testing.T) {
 			},
 			ValidationError("dependency \"bad\" has disallowed characters in the alias"),
 		},
+		{
+			&Metadata{
+				Name:       "test",
+				APIVersion: "v2",
+				Version:    "1.0",
+				ValueMigrations: []*ValueMigration{
+					{From: "name", To: "app.name", Transform: "upper"},
+				},
+			},
+			ValidationError("value migration from \"name\" has unknown transform \"upper\""),
+		},
+		{
+			&Metadata{
+				Name:       "test",
+				APIVersion: "v2",
+				Version:    "1.0",
+				Components: map[string][]string{
+					"metrics": {"templates/metrics/["},
+				},
+			},
+			ValidationError("component \"metrics\" has an invalid template pattern \"templates/metrics/[\""),
+		},
 		{
 			&Metadata{APIVersion: "v2", Name: "test", Version: "1.2.3.4"},
 			ValidationError("chart.metadata.version \"1.2.3.4\" is invalid"),
@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chart
+
+// Transforms a value migration may apply to the migrated value.
+const (
+	// ValueTransformString converts the value to a string.
+	ValueTransformString = "string"
+	// ValueTransformInt converts the value to an integer.
+	ValueTransformInt = "int"
+	// ValueTransformBool converts the value to a boolean.
+	ValueTransformBool = "bool"
+	// ValueTransformList wraps a value that is not a list in a single element list.
+	ValueTransformList = "list"
+)
+
+// ValueMigration moves a user supplied value from a key the chart no longer
+// uses to its replacement, so that values written for an older version of the
+// chart keep working on upgrade.
+type ValueMigration struct {
+	// From is the dotted path of the old key, such as "image.name".
+	From string `json:"from"`
+	// To is the dotted path of the new key, such as "image.repository".
+	To string `json:"to"`
+	// Transform optionally converts the value while moving it. It is one of
+	// "string", "int", "bool" or "list".
+	Transform string `json:"transform,omitempty"`
+}
+
+// Validate checks the value migration for missing paths and unknown transforms.
+func (m *ValueMigration) Validate() error {
+	m.From = sanitizeString(m.From)
+	m.To = sanitizeString(m.To)
+	if m.From == "" || m.To == "" {
+		return ValidationError("value migrations require a from and a to path")
+	}
+	if m.From == m.To {
+		return ValidationErrorf("value migration from %q moves the value onto itself", m.From)
+	}
+	switch m.Transform {
+	case "", ValueTransformString, ValueTransformInt, ValueTransformBool, ValueTransformList:
+		return nil
+	}
+	return ValidationErrorf("value migration from %q has unknown transform %q", m.From, m.Transform)
+}
@@ -18,6 +18,8 @@ package chartutil
 
 import (
 	"log"
+	"reflect"
+	"sort"
 
 	"github.com/mitchellh/copystructure"
 	"github.com/pkg/errors"
@@ -35,6 +37,29 @@ import (
 //	- A chart has access to all of the variables for it, as well as all of
 //		the values destined for its dependencies.
 func CoalesceValues(chrt *chart.Chart, vals map[string]interface{}) (Values, error) {
+	return CoalesceValuesWithGlobalsPolicy(chrt, vals, GlobalsParentWins)
+}
+
+// GlobalsPolicy decides how a global value is merged into a subchart when the
+// subchart sets it too, either as a default or through the values given to
+// the subchart, to a different value than its parent chart.
+type GlobalsPolicy int
+
+const (
+	// GlobalsParentWins keeps the global value of the parent chart. This is
+	// the behavior of CoalesceValues.
+	GlobalsParentWins GlobalsPolicy = iota
+	// GlobalsSubchartWins keeps the global value of the subchart, for the
+	// subchart and its own dependencies.
+	GlobalsSubchartWins
+	// GlobalsErrorOnConflict fails with an error naming the conflicting
+	// global.
+	GlobalsErrorOnConflict
+)
+
+// CoalesceValuesWithGlobalsPolicy is like CoalesceValues, but merges the
+// globals of the parent charts into their subcharts according to policy.
+func CoalesceValuesWithGlobalsPolicy(chrt *chart.Chart, vals map[string]interface{}, policy GlobalsPolicy) (Values, error) {
 	v, err := copystructure.Copy(vals)
 	if err != nil {
 		return vals, err
@@ -45,19 +70,78 @@ func CoalesceValues(chrt *chart.Chart, vals map[string]interface{}) (Values, err
 	if valsCopy == nil {
 		valsCopy = make(map[string]interface{})
 	}
-	return coalesce(chrt, valsCopy)
+	return coalesce(chrt, valsCopy, policy)
+}
+
+// CoalesceValuesStrict is like CoalesceValues, but returns an error naming
+// the key path of the first value in vals that overrides a table of the
+// chart (or subchart) defaults with a scalar or array, or the other way
+// around. CoalesceValues silently keeps one side of such a conflict. Null
+// values, which remove keys, never conflict.
+func CoalesceValuesStrict(chrt *chart.Chart, vals map[string]interface{}) (Values, error) {
+	if err := checkOverrideTypes(chrt, "", vals); err != nil {
+		return nil, err
+	}
+	return CoalesceValues(chrt, vals)
+}
+
+// checkOverrideTypes checks vals against the defaults of chrt and of its
+// dependencies. prefix is the key path of vals.
+func checkOverrideTypes(chrt *chart.Chart, prefix string, vals map[string]interface{}) error {
+	if err := checkTableTypes(prefix, vals, chrt.Values); err != nil {
+		return err
+	}
+	for _, subchart := range chrt.Dependencies() {
+		if sv, ok := vals[subchart.Name()].(map[string]interface{}); ok {
+			if err := checkOverrideTypes(subchart, joinValuesPath(prefix, subchart.Name()), sv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkTableTypes returns an error for the first key, in sorted order, that
+// is a table in only one of vals and defaults.
+func checkTableTypes(prefix string, vals, defaults map[string]interface{}) error {
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val, def := vals[key], defaults[key]
+		if val == nil || def == nil {
+			continue
+		}
+		path := joinValuesPath(prefix, key)
+		vt, vok := val.(map[string]interface{})
+		dt, dok := def.(map[string]interface{})
+		switch {
+		case vok && dok:
+			if err := checkTableTypes(path, vt, dt); err != nil {
+				return err
+			}
+		case vok:
+			return errors.Errorf("type mismatch on %s: cannot override %T value %v with a table", path, def, def)
+		case dok:
+			return errors.Errorf("type mismatch on %s: cannot override a table with %T value %v", path, val, val)
+		}
+	}
+	return nil
 }
 
 // coalesce coalesces the dest values and the chart values, giving priority to the dest values.
 //
 // This is a helper function for CoalesceValues.
-func coalesce(ch *chart.Chart, dest map[string]interface{}) (map[string]interface{}, error) {
+func coalesce(ch *chart.Chart, dest map[string]interface{}, policy GlobalsPolicy) (map[string]interface{}, error) {
 	coalesceValues(ch, dest)
-	return coalesceDeps(ch, dest)
+	return coalesceDeps(ch, dest, policy)
 }
 
 // coalesceDeps coalesces the dependencies of the given chart.
-func coalesceDeps(chrt *chart.Chart, dest map[string]interface{}) (map[string]interface{}, error) {
+func coalesceDeps(chrt *chart.Chart, dest map[string]interface{}, policy GlobalsPolicy) (map[string]interface{}, error) {
 	for _, subchart := range chrt.Dependencies() {
 		if c, ok := dest[subchart.Name()]; !ok {
 			// If dest doesn't already have the key, create it.
@@ -68,12 +152,29 @@ func coalesceDeps(chrt *chart.Chart, dest map[string]interface{}) (map[string]in
 		if dv, ok := dest[subchart.Name()]; ok {
 			dvmap := dv.(map[string]interface{})
 
+			var own map[string]interface{}
+			if policy != GlobalsParentWins {
+				var err error
+				if own, err = subchartGlobals(subchart, dvmap); err != nil {
+					return dest, err
+				}
+			}
+			if parent, ok := dest[GlobalKey].(map[string]interface{}); ok && policy == GlobalsErrorOnConflict {
+				if err := checkGlobalConflicts(subchart.Name(), "", parent, own); err != nil {
+					return dest, err
+				}
+			}
+
 			// Get globals out of dest and merge them into dvmap.
 			coalesceGlobals(dvmap, dest)
 
+			if dg, ok := dvmap[GlobalKey].(map[string]interface{}); ok && policy == GlobalsSubchartWins {
+				dvmap[GlobalKey] = CoalesceTables(own, dg)
+			}
+
 			// Now coalesce the rest of the values.
 			var err error
-			dest[subchart.Name()], err = coalesce(subchart, dvmap)
+			dest[subchart.Name()], err = coalesce(subchart, dvmap, policy)
 			if err != nil {
 				return dest, err
 			}
@@ -82,6 +183,55 @@ func coalesceDeps(chrt *chart.Chart, dest map[string]interface{}) (map[string]in
 	return dest, nil
 }
 
+// subchartGlobals returns a copy of the globals the subchart sets itself, in
+// the values given to it in dvmap or else in its default values.
+func subchartGlobals(subchart *chart.Chart, dvmap map[string]interface{}) (map[string]interface{}, error) {
+	own := map[string]interface{}{}
+	for _, g := range []interface{}{dvmap[GlobalKey], subchart.Values[GlobalKey]} {
+		table, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		v, err := copystructure.Copy(table)
+		if err != nil {
+			return nil, err
+		}
+		own = CoalesceTables(own, v.(map[string]interface{}))
+	}
+	return own, nil
+}
+
+// checkGlobalConflicts returns an error for the first global, in sorted
+// order, that the subchart sets to a different value than its parent.
+func checkGlobalConflicts(subchart, prefix string, parent, own map[string]interface{}) error {
+	keys := make([]string, 0, len(own))
+	for key := range own {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		pv, ok := parent[key]
+		if !ok {
+			continue
+		}
+		ov := own[key]
+		path := joinValuesPath(prefix, key)
+		pt, pok := pv.(map[string]interface{})
+		ot, ook := ov.(map[string]interface{})
+		if pok && ook {
+			if err := checkGlobalConflicts(subchart, path, pt, ot); err != nil {
+				return err
+			}
+			continue
+		}
+		if !reflect.DeepEqual(pv, ov) {
+			return errors.Errorf("conflicting global %s: the parent chart sets %v, but subchart %s sets %v", path, pv, subchart, ov)
+		}
+	}
+	return nil
+}
+
 // coalesceGlobals copies the globals out of src and merges them into dest.
 //
 // For convenience, returns dest.
@@ -146,7 +296,17 @@ func copyMap(src map[string]interface{}) map[string]interface{} {
 //
 // Values in v will override the values in the chart.
 func coalesceValues(c *chart.Chart, v map[string]interface{}) {
-	for key, val := range c.Values {
+	// Tables of c.Values copied into v would otherwise be modified when the
+	// values of the subcharts are coalesced into them, changing the chart
+	// defaults. This cannot return an error, so c.Values is used as a fallback.
+	values := c.Values
+	if vc, err := copystructure.Copy(c.Values); err != nil {
+		log.Printf("warning: unable to copy values, err: %s", err)
+	} else if vc, ok := vc.(map[string]interface{}); ok {
+		values = vc
+	}
+
+	for key, val := range values {
 		if value, ok := v[key]; ok {
 			if value == nil {
 				// When the YAML value is null, we remove the value's key.
@@ -306,3 +306,182 @@ func TestCoalesceTables(t *testing.T) {
 		t.Errorf("Expected hole string, got %v", dst2["boat"])
 	}
 }
+
+func TestCoalesceValuesStrict(t *testing.T) {
+	c := withDeps(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby"},
+		Values: map[string]interface{}{
+			"name":      "moby",
+			"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+			"ports":     []interface{}{80},
+		},
+	},
+		&chart.Chart{
+			Metadata: &chart.Metadata{Name: "ahab"},
+			Values: map[string]interface{}{
+				"boat":   true,
+				"nested": map[string]interface{}{"foo": false},
+			},
+		},
+	)
+
+	tests := []struct {
+		name string
+		vals map[string]interface{}
+		err  string
+	}{
+		{
+			name: "compatible overrides",
+			vals: map[string]interface{}{
+				"name":      "pequod",
+				"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "2", "memory": "1Gi"}},
+				"ports":     []interface{}{8080},
+				"new":       map[string]interface{}{"key": "value"},
+				"ahab":      map[string]interface{}{"boat": false, "nested": map[string]interface{}{"foo": true}},
+			},
+		},
+		{
+			name: "null removes a table",
+			vals: map[string]interface{}{"resources": nil},
+		},
+		{
+			name: "scalar over map",
+			vals: map[string]interface{}{"resources": "none"},
+			err:  "type mismatch on resources: cannot override a table with string value none",
+		},
+		{
+			name: "nested scalar over map",
+			vals: map[string]interface{}{"resources": map[string]interface{}{"limits": int64(1)}},
+			err:  "type mismatch on resources.limits: cannot override a table with int64 value 1",
+		},
+		{
+			name: "map over scalar",
+			vals: map[string]interface{}{"name": map[string]interface{}{"first": "moby"}},
+			err:  "type mismatch on name: cannot override string value moby with a table",
+		},
+		{
+			name: "array over map",
+			vals: map[string]interface{}{"resources": []interface{}{"cpu"}},
+			err:  "type mismatch on resources: cannot override a table with []interface {} value [cpu]",
+		},
+		{
+			name: "map over scalar in a subchart",
+			vals: map[string]interface{}{"ahab": map[string]interface{}{"boat": map[string]interface{}{"name": "pequod"}}},
+			err:  "type mismatch on ahab.boat: cannot override bool value true with a table",
+		},
+		{
+			name: "scalar over map in a subchart",
+			vals: map[string]interface{}{"ahab": map[string]interface{}{"nested": "flat"}},
+			err:  "type mismatch on ahab.nested: cannot override a table with string value flat",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CoalesceValuesStrict(c, tt.vals)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestCoalesceValuesWithGlobalsPolicy(t *testing.T) {
+	newChart := func(subGlobals map[string]interface{}) *chart.Chart {
+		return withDeps(&chart.Chart{
+			Metadata: &chart.Metadata{Name: "parent"},
+			Values: map[string]interface{}{
+				GlobalKey: map[string]interface{}{
+					"registry": "quay.io",
+					"shared":   "same",
+					"nested":   map[string]interface{}{"a": 1},
+				},
+			},
+		},
+			&chart.Chart{
+				Metadata: &chart.Metadata{Name: "sub"},
+				Values:   map[string]interface{}{GlobalKey: subGlobals},
+			},
+		)
+	}
+	conflicting := map[string]interface{}{
+		"registry": "docker.io",
+		"shared":   "same",
+		"nested":   map[string]interface{}{"b": 2},
+		"own":      "sub",
+	}
+
+	tests := []struct {
+		name       string
+		policy     GlobalsPolicy
+		subGlobals map[string]interface{}
+		registry   string
+		err        string
+	}{
+		{
+			name:       "parent wins",
+			policy:     GlobalsParentWins,
+			subGlobals: conflicting,
+			registry:   "quay.io",
+		},
+		{
+			name:       "subchart wins",
+			policy:     GlobalsSubchartWins,
+			subGlobals: conflicting,
+			registry:   "docker.io",
+		},
+		{
+			name:       "error on conflict",
+			policy:     GlobalsErrorOnConflict,
+			subGlobals: conflicting,
+			err:        "conflicting global registry: the parent chart sets quay.io, but subchart sub sets docker.io",
+		},
+		{
+			name:   "error on conflict without conflicts",
+			policy: GlobalsErrorOnConflict,
+			subGlobals: map[string]interface{}{
+				"shared": "same",
+				"nested": map[string]interface{}{"a": 1, "b": 2},
+				"own":    "sub",
+			},
+			registry: "quay.io",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := assert.New(t)
+			v, err := CoalesceValuesWithGlobalsPolicy(newChart(tt.subGlobals), map[string]interface{}{}, tt.policy)
+			if tt.err != "" {
+				is.EqualError(err, tt.err)
+				return
+			}
+			is.NoError(err)
+
+			globals, err := v.Table("sub." + GlobalKey)
+			is.NoError(err)
+			is.Equal(tt.registry, globals["registry"])
+			is.Equal("same", globals["shared"])
+			is.Equal("sub", globals["own"])
+			is.Equal(map[string]interface{}{"a": 1, "b": 2}, globals["nested"])
+
+			// the parent's own globals are never changed
+			parent, err := v.Table(GlobalKey)
+			is.NoError(err)
+			is.Equal("quay.io", parent["registry"])
+		})
+	}
+
+	// user supplied values for the subchart count as its own globals
+	vals := map[string]interface{}{
+		"sub": map[string]interface{}{GlobalKey: map[string]interface{}{"registry": "gcr.io"}},
+	}
+	v, err := CoalesceValuesWithGlobalsPolicy(newChart(nil), vals, GlobalsSubchartWins)
+	assert.NoError(t, err)
+	globals, _ := v.Table("sub." + GlobalKey)
+	assert.Equal(t, "gcr.io", globals["registry"])
+	_, err = CoalesceValuesWithGlobalsPolicy(newChart(nil), vals, GlobalsErrorOnConflict)
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// ArchiveDiff lists the files that differ between two packaged charts.
+//
+// Files of subcharts packaged in the charts/ directory are compared one by
+// one, and named after their archive, such as
+// "charts/mysql-1.0.0.tgz/values.yaml".
+type ArchiveDiff struct {
+	// Added are the files only found in the second chart.
+	Added []string
+	// Removed are the files only found in the first chart.
+	Removed []string
+	// Modified are the files whose content differs.
+	Modified []string
+}
+
+// Equal returns true if the charts have the same files with the same content.
+func (d *ArchiveDiff) Equal() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// CompareArchives compares the content of two packaged charts, ignoring the
+// metadata of the archives such as the modification times of their files.
+func CompareArchives(a, b string) (*ArchiveDiff, error) {
+	filesA, err := loadArchive(a)
+	if err != nil {
+		return nil, err
+	}
+	filesB, err := loadArchive(b)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ArchiveDiff{}
+	compareFiles("", filesA, filesB, diff)
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+	return diff, nil
+}
+
+// loadArchive reads the files of the chart archive at filename, checking that
+// they make a valid chart.
+func loadArchive(filename string) (map[string][]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files, err := loader.LoadArchiveFiles(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read chart archive %s", filename)
+	}
+	if _, err := loader.LoadFiles(files); err != nil {
+		return nil, errors.Wrapf(err, "%s is not a valid chart", filename)
+	}
+	return archiveFiles(files), nil
+}
+
+func archiveFiles(files []*loader.BufferedFile) map[string][]byte {
+	m := make(map[string][]byte, len(files))
+	for _, f := range files {
+		m[f.Name] = f.Data
+	}
+	return m
+}
+
+// compareFiles records the differences between the files a and b in diff,
+// naming them with prefix.
+func compareFiles(prefix string, a, b map[string][]byte, diff *ArchiveDiff) {
+	for name, dataA := range a {
+		dataB, ok := b[name]
+		switch {
+		case !ok:
+			diff.Removed = append(diff.Removed, prefix+name)
+		case bytes.Equal(dataA, dataB):
+		case isSubchartArchive(name):
+			nestedA, errA := loader.LoadArchiveFiles(bytes.NewReader(dataA))
+			nestedB, errB := loader.LoadArchiveFiles(bytes.NewReader(dataB))
+			if errA != nil || errB != nil {
+				diff.Modified = append(diff.Modified, prefix+name)
+				continue
+			}
+			compareFiles(prefix+name+"/", archiveFiles(nestedA), archiveFiles(nestedB), diff)
+		default:
+			diff.Modified = append(diff.Modified, prefix+name)
+		}
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			diff.Added = append(diff.Added, prefix+name)
+		}
+	}
+}
+
+func isSubchartArchive(name string) bool {
+	return path.Dir(name) == "charts" && (strings.HasSuffix(name, ".tgz") || strings.HasSuffix(name, ".tar.gz"))
+}
@@ -0,0 +1,139 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+)
+
+// tarGz packages files in a gzipped tarball under the directory name, with
+// every file modified at mtime.
+func tarGz(t *testing.T, name string, files map[string]string, mtime time.Time) []byte {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for n := range files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.ModTime = mtime
+	tw := tar.NewWriter(zw)
+	for _, n := range names {
+		hdr := &tar.Header{
+			Name:    name + "/" + n,
+			Mode:    0644,
+			Size:    int64(len(files[n])),
+			ModTime: mtime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[n])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompareArchives(t *testing.T) {
+	tmp := ensure.TempDir(t)
+	defer os.RemoveAll(tmp)
+
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	subchart := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: sub\nversion: 0.1.0\n",
+		"values.yaml": "replicas: 1\n",
+	}
+	chartFiles := func(mtime time.Time, sub map[string]string) map[string]string {
+		return map[string]string{
+			"Chart.yaml":                "apiVersion: v2\nname: app\nversion: 1.0.0\n",
+			"values.yaml":               "image: nginx\n",
+			"charts/sub-0.1.0.tgz":      string(tarGz(t, "sub", sub, mtime)),
+			"templates/deployment.yaml": "kind: Deployment\n",
+			"templates/NOTES.txt":       "installed\n",
+		}
+	}
+
+	write := func(name string, files map[string]string, mtime time.Time) string {
+		filename := filepath.Join(tmp, name)
+		if err := ioutil.WriteFile(filename, tarGz(t, "app", files, mtime), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	a := write("a.tgz", chartFiles(first, subchart), first)
+	b := write("b.tgz", chartFiles(second, subchart), second)
+	diff, err := CompareArchives(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Equal() {
+		t.Errorf("expected charts differing only by modification times to be equal, got %+v", diff)
+	}
+
+	changedSub := map[string]string{
+		"Chart.yaml":  subchart["Chart.yaml"],
+		"values.yaml": "replicas: 3\n",
+	}
+	changed := chartFiles(second, changedSub)
+	changed["values.yaml"] = "image: httpd\n"
+	changed["templates/ingress.yaml"] = "kind: Ingress\n"
+	delete(changed, "templates/NOTES.txt")
+	c := write("c.tgz", changed, second)
+
+	diff, err = CompareArchives(a, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ArchiveDiff{
+		Added:    []string{"templates/ingress.yaml"},
+		Removed:  []string{"templates/NOTES.txt"},
+		Modified: []string{"charts/sub-0.1.0.tgz/values.yaml", "values.yaml"},
+	}
+	if diff.Equal() || !reflect.DeepEqual(diff, expected) {
+		t.Errorf("expected %+v, got %+v", expected, diff)
+	}
+
+	notAChart := filepath.Join(tmp, "d.tgz")
+	if err := ioutil.WriteFile(notAChart, tarGz(t, "app", map[string]string{"values.yaml": "a: b\n"}, first), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CompareArchives(a, notAChart); err == nil {
+		t.Error("expected an error comparing an archive without Chart.yaml")
+	}
+}
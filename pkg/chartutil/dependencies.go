@@ -19,6 +19,9 @@ import (
 	"log"
 	"strings"
 
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+
 	"helm.sh/helm/v3/pkg/chart"
 )
 
@@ -273,7 +276,40 @@ func processImportValues(c *chart.Chart) error {
 	return nil
 }
 
-// processDependencyImportValues imports specified chart values from child to parent.
+// processBaseValues merges the values of the dependencies marked with
+// BaseValues beneath the chart's values.
+//
+// From the highest to the lowest precedence, the values of a chart are: user
+// supplied values, values imported from dependencies with import-values, the
+// chart's values.yaml, then the values of its base values dependencies.
+func processBaseValues(c *chart.Chart) error {
+	for _, r := range c.Metadata.Dependencies {
+		if !r.BaseValues {
+			continue
+		}
+		name := r.Name
+		if r.Alias != "" {
+			name = r.Alias
+		}
+		for _, d := range c.Dependencies() {
+			if d.Name() != name {
+				continue
+			}
+			base, err := copystructure.Copy(d.Values)
+			if err != nil {
+				return errors.Wrapf(err, "cannot copy the base values of chart %s", name)
+			}
+			if c.Values == nil {
+				c.Values = make(map[string]interface{})
+			}
+			c.Values = CoalesceTables(c.Values, base.(map[string]interface{}))
+		}
+	}
+	return nil
+}
+
+// processDependencyImportValues imports specified chart values from child to
+// parent, then merges the base values of the dependencies beneath them.
 func processDependencyImportValues(c *chart.Chart) error {
 	for _, d := range c.Dependencies() {
 		// recurse
@@ -281,5 +317,8 @@ func processDependencyImportValues(c *chart.Chart) error {
 			return err
 		}
 	}
-	return processImportValues(c)
+	if err := processImportValues(c); err != nil {
+		return err
+	}
+	return processBaseValues(c)
 }
@@ -15,6 +15,7 @@ limitations under the License.
 package chartutil
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -269,6 +270,66 @@ func TestProcessDependencyImportValuesForEnabledCharts(t *testing.T) {
 	}
 }
 
+func TestProcessDependencyBaseValues(t *testing.T) {
+	base := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "base", Version: "0.1.0"},
+		Values: map[string]interface{}{
+			"replicas": 1,
+			"team":     "platform",
+			"image": map[string]interface{}{
+				"tag":        "base",
+				"pullPolicy": "Always",
+			},
+		},
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:    "parent",
+			Version: "0.1.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "base", Version: "0.1.0", BaseValues: true},
+			},
+		},
+		Values: map[string]interface{}{
+			"replicas": 2,
+			"image": map[string]interface{}{
+				"tag": "chart",
+			},
+		},
+	}
+	c.AddDependency(base)
+
+	userVals := map[string]interface{}{"replicas": 3}
+	if err := ProcessDependencies(c, userVals); err != nil {
+		t.Fatalf("processing dependencies %v", err)
+	}
+	vals, err := CoalesceValues(c, userVals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"replicas":         "3",
+		"team":             "platform",
+		"image.tag":        "chart",
+		"image.pullPolicy": "Always",
+	}
+	for kk, vv := range expected {
+		pv, err := vals.PathValue(kk)
+		if err != nil {
+			t.Fatalf("retrieving import values table %v %v", kk, err)
+		}
+		if s := fmt.Sprint(pv); s != vv {
+			t.Errorf("failed to match %s: expected %q, got %q", kk, vv, s)
+		}
+	}
+
+	// the base chart keeps its own values
+	if base.Values["team"] != "platform" || base.Values["replicas"] != 1 {
+		t.Errorf("base chart values were modified: %v", base.Values)
+	}
+}
+
 func TestGetAliasDependency(t *testing.T) {
 	c := loadChart(t, "testdata/frobnitz")
 	req := c.Metadata.Dependencies
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/pkg/errors"
@@ -32,6 +33,13 @@ import (
 
 // Expand uncompresses and extracts a chart into the specified directory.
 func Expand(dir string, r io.Reader) error {
+	return ExpandPaths(dir, r, nil)
+}
+
+// ExpandPaths is like Expand, but only extracts the files and directories of
+// the chart listed in paths, such as "templates/" or "values.yaml", along with
+// Chart.yaml. An empty paths extracts the whole chart.
+func ExpandPaths(dir string, r io.Reader, paths []string) error {
 	files, err := loader.LoadArchiveFiles(r)
 	if err != nil {
 		return err
@@ -61,6 +69,9 @@ func Expand(dir string, r io.Reader) error {
 	// Copy all files verbatim. We don't parse these files because parsing can remove
 	// comments.
 	for _, file := range files {
+		if len(paths) > 0 && file.Name != ChartfileName && !inPaths(file.Name, paths) {
+			continue
+		}
 		outpath, err := securejoin.SecureJoin(chartdir, file.Name)
 		if err != nil {
 			return err
@@ -82,10 +93,28 @@ func Expand(dir string, r io.Reader) error {
 
 // ExpandFile expands the src file into the dest directory.
 func ExpandFile(dest, src string) error {
+	return ExpandFilePaths(dest, src, nil)
+}
+
+// ExpandFilePaths expands the paths of the src file into the dest directory.
+// See ExpandPaths.
+func ExpandFilePaths(dest, src string, paths []string) error {
 	h, err := os.Open(src)
 	if err != nil {
 		return err
 	}
 	defer h.Close()
-	return Expand(dest, h)
+	return ExpandPaths(dest, h, paths)
+}
+
+// inPaths reports whether the chart file name is one of paths or is inside
+// one of them.
+func inPaths(name string, paths []string) bool {
+	for _, p := range paths {
+		p = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(p)), "/")
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+	return false
 }
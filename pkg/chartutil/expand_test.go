@@ -131,3 +131,37 @@ func TestExpandFile(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandFilePaths(t *testing.T) {
+	dest, err := ioutil.TempDir("", "helm-testing-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dest)
+
+	if err := ExpandFilePaths(dest, "testdata/frobnitz-1.2.3.tgz", []string{"templates/", "values.yaml", "charts/alpine/templates"}); err != nil {
+		t.Fatal(err)
+	}
+
+	chartPath := filepath.Join(dest, "frobnitz")
+	for _, name := range []string{
+		"Chart.yaml",
+		"values.yaml",
+		"templates/template.tpl",
+		"charts/alpine/templates/alpine-pod.yaml",
+	} {
+		if _, err := os.Stat(filepath.Join(chartPath, name)); err != nil {
+			t.Errorf("expected %s to be extracted: %s", name, err)
+		}
+	}
+	for _, name := range []string{
+		"README.md",
+		"docs",
+		"charts/mariner-4.3.2.tgz",
+		"charts/alpine/values.yaml",
+	} {
+		if _, err := os.Stat(filepath.Join(chartPath, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be extracted", name)
+		}
+	}
+}
@@ -18,7 +18,11 @@ package chartutil
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -85,3 +89,247 @@ func ValidateAgainstSingleSchema(values Values, schemaJSON []byte) error {
 
 	return nil
 }
+
+// UnknownValues returns the dotted path of every key of values that is not
+// declared by the schema of chrt, or by the schema of the subchart it belongs
+// to, so that typos can be rejected.
+//
+// Unlike ValidateAgainstSchema, a key is unknown even where the schema allows
+// additional properties, unless additionalProperties is itself a schema.
+// Objects whose schema declares no properties are not checked, and neither
+// are charts without a schema.
+func UnknownValues(chrt *chart.Chart, values map[string]interface{}) ([]string, error) {
+	return unknownChartValues(chrt, values, "")
+}
+
+func unknownChartValues(chrt *chart.Chart, values map[string]interface{}, prefix string) ([]string, error) {
+	var unknown []string
+	if chrt.Schema != nil {
+		schema := map[string]interface{}{}
+		if err := json.Unmarshal(chrt.Schema, &schema); err != nil {
+			return nil, errors.Wrapf(err, "cannot parse the schema of chart %s", chrt.Name())
+		}
+		// globals and subchart values are checked against the subchart schemas
+		skip := func(key string) bool {
+			return key == GlobalKey || dependencyByName(chrt, key) != nil
+		}
+		unknown = unknownSchemaValues(schema, values, prefix, skip, unknown)
+	}
+
+	for _, subchart := range chrt.Dependencies() {
+		subchartValues, _ := asTable(values[subchart.Name()])
+		subchartUnknown, err := unknownChartValues(subchart, subchartValues, joinValuesPath(prefix, subchart.Name()))
+		if err != nil {
+			return nil, err
+		}
+		unknown = append(unknown, subchartUnknown...)
+	}
+	return unknown, nil
+}
+
+func unknownSchemaValues(schema map[string]interface{}, v interface{}, path string, skip func(string) bool, unknown []string) []string {
+	if items, ok := v.([]interface{}); ok {
+		if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range items {
+				unknown = unknownSchemaValues(itemSchema, item, fmt.Sprintf("%s[%d]", path, i), nil, unknown)
+			}
+		}
+		return unknown
+	}
+
+	table, ok := asTable(v)
+	if !ok {
+		return unknown
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	patterns, _ := schema["patternProperties"].(map[string]interface{})
+	additional, _ := schema["additionalProperties"].(map[string]interface{})
+	if properties == nil && patterns == nil && additional == nil {
+		return unknown
+	}
+
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if skip != nil && skip(k) {
+			continue
+		}
+		keyPath := joinValuesPath(path, k)
+		if propSchema, ok := properties[k]; ok {
+			if propSchema, ok := propSchema.(map[string]interface{}); ok {
+				unknown = unknownSchemaValues(propSchema, table[k], keyPath, nil, unknown)
+			}
+			continue
+		}
+		if patternSchema, ok := matchPatternProperty(patterns, k); ok {
+			unknown = unknownSchemaValues(patternSchema, table[k], keyPath, nil, unknown)
+			continue
+		}
+		if additional != nil {
+			unknown = unknownSchemaValues(additional, table[k], keyPath, nil, unknown)
+			continue
+		}
+		unknown = append(unknown, keyPath)
+	}
+	return unknown
+}
+
+// NewlyRequiredValues returns the dotted path of every value that the schema
+// of newChart requires, the schema of oldChart does not, and values does not
+// set. values are the coalesced values newChart is about to be rendered with.
+//
+// It is meant to warn about an upgrade from oldChart to newChart that would
+// fail schema validation. Subcharts are matched by name, and a value nested
+// under an object that values does not set is not reported.
+func NewlyRequiredValues(oldChart, newChart *chart.Chart, values map[string]interface{}) ([]string, error) {
+	return newlyRequiredChartValues(oldChart, newChart, values, "")
+}
+
+func newlyRequiredChartValues(oldChart, newChart *chart.Chart, values map[string]interface{}, prefix string) ([]string, error) {
+	var missing []string
+	if newChart.Schema != nil {
+		newSchema := map[string]interface{}{}
+		if err := json.Unmarshal(newChart.Schema, &newSchema); err != nil {
+			return nil, errors.Wrapf(err, "cannot parse the schema of chart %s", newChart.Name())
+		}
+		oldSchema := map[string]interface{}{}
+		if oldChart != nil && oldChart.Schema != nil {
+			if err := json.Unmarshal(oldChart.Schema, &oldSchema); err != nil {
+				return nil, errors.Wrapf(err, "cannot parse the schema of chart %s", oldChart.Name())
+			}
+		}
+		missing = newlyRequiredSchemaValues(oldSchema, newSchema, values, prefix, missing)
+	}
+
+	for _, subchart := range newChart.Dependencies() {
+		var oldSubchart *chart.Chart
+		if oldChart != nil {
+			oldSubchart = dependencyByName(oldChart, subchart.Name())
+		}
+		subchartValues, _ := asTable(values[subchart.Name()])
+		subchartMissing, err := newlyRequiredChartValues(oldSubchart, subchart, subchartValues, joinValuesPath(prefix, subchart.Name()))
+		if err != nil {
+			return nil, err
+		}
+		missing = append(missing, subchartMissing...)
+	}
+	return missing, nil
+}
+
+func newlyRequiredSchemaValues(oldSchema, newSchema map[string]interface{}, values map[string]interface{}, path string, missing []string) []string {
+	oldRequired := map[string]bool{}
+	if oldSchema != nil {
+		for _, name := range schemaRequired(oldSchema) {
+			oldRequired[name] = true
+		}
+	}
+	for _, name := range schemaRequired(newSchema) {
+		if _, ok := values[name]; !ok && !oldRequired[name] {
+			missing = append(missing, joinValuesPath(path, name))
+		}
+	}
+
+	properties, _ := newSchema["properties"].(map[string]interface{})
+	oldProperties, _ := oldSchema["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		propSchema, ok := properties[k].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		table, ok := asTable(values[k])
+		if !ok {
+			continue
+		}
+		oldPropSchema, _ := oldProperties[k].(map[string]interface{})
+		missing = newlyRequiredSchemaValues(oldPropSchema, propSchema, table, joinValuesPath(path, k), missing)
+	}
+	return missing
+}
+
+// schemaRequired returns the property names listed as required by schema.
+func schemaRequired(schema map[string]interface{}) []string {
+	required, _ := schema["required"].([]interface{})
+	names := make([]string, 0, len(required))
+	for _, r := range required {
+		if name, ok := r.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// matchPatternProperty returns the schema of the first patternProperties
+// pattern matching key.
+func matchPatternProperty(patterns map[string]interface{}, key string) (map[string]interface{}, bool) {
+	for pattern, schema := range patterns {
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+			s, _ := schema.(map[string]interface{})
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+func joinValuesPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// GenerateSchema returns a JSON Schema draft-07 skeleton for values, with
+// the types inferred from the values themselves. Maps become nested object
+// schemas and the item type of an array is inferred from its first element.
+// Null values and empty arrays leave the type open.
+//
+// The schema is a best-effort starting point meant to be refined by hand.
+func GenerateSchema(values Values) ([]byte, error) {
+	schema := schemaForValue(map[string]interface{}(values))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaForValue returns the schema inferred from a single value.
+func schemaForValue(v interface{}) map[string]interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		props := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			props[key] = schemaForValue(val)
+		}
+		return map[string]interface{}{"type": "object", "properties": props}
+	case []interface{}:
+		schema := map[string]interface{}{"type": "array"}
+		if len(v) > 0 {
+			schema["items"] = schemaForValue(v[0])
+		}
+		return schema
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	case float64:
+		if v == math.Trunc(v) {
+			return map[string]interface{}{"type": "integer"}
+		}
+		return map[string]interface{}{"type": "number"}
+	case int, int64:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -18,6 +18,7 @@ package chartutil
 
 import (
 	"io/ioutil"
+	"reflect"
 	"testing"
 
 	"helm.sh/helm/v3/pkg/chart"
@@ -141,3 +142,197 @@ func TestValidateAgainstSchemaNegative(t *testing.T) {
 		t.Errorf("Error string :\n`%s`\ndoes not match expected\n`%s`", errString, expectedErrString)
 	}
 }
+
+const strictSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "image": {
+      "type": "object",
+      "properties": {
+        "repository": {"type": "string"},
+        "tag": {"type": "string"}
+      }
+    },
+    "labels": {
+      "type": "object",
+      "additionalProperties": {"type": "string"}
+    },
+    "ports": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "port": {"type": "integer"}
+        }
+      }
+    },
+    "extra": {
+      "type": "object"
+    }
+  },
+  "patternProperties": {
+    "^x-": {}
+  },
+  "additionalProperties": true
+}`
+
+func TestUnknownValues(t *testing.T) {
+	subchart := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "subchart"},
+		Schema:   []byte(subchartSchema),
+	}
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "chrt"},
+		Schema:   []byte(strictSchema),
+	}
+	chrt.AddDependency(subchart)
+
+	allowed := map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.19"},
+		"labels":   map[string]interface{}{"team": "web"},
+		"ports":    []interface{}{map[string]interface{}{"port": 80}},
+		"extra":    map[string]interface{}{"anything": "goes"},
+		"x-vendor": "acme",
+		"global":   map[string]interface{}{"env": "prod"},
+		"subchart": map[string]interface{}{"age": 25},
+	}
+	unknown, err := UnknownValues(chrt, allowed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unknown) != 0 {
+		t.Errorf("expected no unknown values, got %v", unknown)
+	}
+
+	rejected := map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "nginx", "tagg": "1.19"},
+		"ports":    []interface{}{map[string]interface{}{"prot": 80}},
+		"replicas": 2,
+		"subchart": map[string]interface{}{"age": 25, "agee": 26},
+	}
+	unknown, err = UnknownValues(chrt, rejected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"image.tagg", "ports[0].prot", "replicas", "subchart.agee"}
+	if !reflect.DeepEqual(unknown, expected) {
+		t.Errorf("expected unknown values %v, got %v", expected, unknown)
+	}
+}
+
+func TestNewlyRequiredValues(t *testing.T) {
+	oldChart := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "chrt"},
+		Schema: []byte(`{
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "image": {"type": "object", "required": ["repository"]}
+  }
+}`),
+	}
+	oldChart.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "subchart"}})
+
+	newChart := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "chrt"},
+		Schema: []byte(`{
+  "type": "object",
+  "required": ["name", "replicas", "port"],
+  "properties": {
+    "image": {"type": "object", "required": ["repository", "tag"]},
+    "tls": {"type": "object", "required": ["secret"]}
+  }
+}`),
+	}
+	newChart.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "subchart"},
+		Schema:   []byte(`{"type": "object", "required": ["age"]}`),
+	})
+
+	values := map[string]interface{}{
+		"port":     8080,
+		"image":    map[string]interface{}{"repository": "nginx"},
+		"subchart": map[string]interface{}{},
+	}
+	missing, err := NewlyRequiredValues(oldChart, newChart, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// name is required by both schemas, and tls is not set at all
+	expected := []string{"replicas", "image.tag", "subchart.age"}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Errorf("expected newly required values %v, got %v", expected, missing)
+	}
+}
+
+func TestGenerateSchema(t *testing.T) {
+	values, err := ReadValues([]byte(`
+name: web
+replicas: 2
+ratio: 0.5
+enabled: true
+annotations: {}
+tolerations: []
+nodeSelector: null
+ports:
+- name: http
+  port: 80
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "properties": {
+    "annotations": {
+      "properties": {},
+      "type": "object"
+    },
+    "enabled": {
+      "type": "boolean"
+    },
+    "name": {
+      "type": "string"
+    },
+    "nodeSelector": {},
+    "ports": {
+      "items": {
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "port": {
+            "type": "integer"
+          }
+        },
+        "type": "object"
+      },
+      "type": "array"
+    },
+    "ratio": {
+      "type": "number"
+    },
+    "replicas": {
+      "type": "integer"
+    },
+    "tolerations": {
+      "type": "array"
+    }
+  },
+  "type": "object"
+}
+`
+	schema, err := GenerateSchema(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(schema) != expected {
+		t.Errorf("Expected schema:\n%s\nGot:\n%s", expected, schema)
+	}
+
+	// the values the schema was generated from must validate against it
+	if err := ValidateAgainstSingleSchema(values, schema); err != nil {
+		t.Errorf("Expected values to validate against the generated schema, got %s", err)
+	}
+}
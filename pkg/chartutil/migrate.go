@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Deprecated dependency files of apiVersion v1 charts.
+const (
+	requirementsName     = "requirements.yaml"
+	requirementsLockName = "requirements.lock"
+)
+
+// MigrateToV2 converts an apiVersion v1 chart to apiVersion v2 in place.
+//
+// The dependencies loaded from requirements.yaml are kept in the chart
+// metadata and written to Chart.yaml when the chart is saved. Likewise the
+// lock loaded from requirements.lock becomes the Chart.lock. The deprecated
+// requirements files are removed from the chart.
+//
+// Charts that are already apiVersion v2 are left untouched.
+func MigrateToV2(c *chart.Chart) error {
+	if c.Metadata == nil {
+		return errors.New("chart metadata (Chart.yaml) missing")
+	}
+	switch c.Metadata.APIVersion {
+	case chart.APIVersionV2:
+		return nil
+	case chart.APIVersionV1, "":
+	default:
+		return errors.Errorf("cannot migrate chart %q: unknown apiVersion %q", c.Name(), c.Metadata.APIVersion)
+	}
+
+	c.Metadata.APIVersion = chart.APIVersionV2
+	c.Files = withoutRequirements(c.Files)
+	c.Raw = withoutRequirements(c.Raw)
+
+	if err := c.Validate(); err != nil {
+		return errors.Wrapf(err, "migrated chart %q is invalid", c.Name())
+	}
+	return nil
+}
+
+func withoutRequirements(files []*chart.File) []*chart.File {
+	var out []*chart.File
+	for _, f := range files {
+		if f.Name == requirementsName || f.Name == requirementsLockName {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
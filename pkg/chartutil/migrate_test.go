@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestMigrateToV2(t *testing.T) {
+	c, err := loader.Load("testdata/migrate-v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MigrateToV2(c); err != nil {
+		t.Fatalf("Failed to migrate: %s", err)
+	}
+	if c.Metadata.APIVersion != chart.APIVersionV2 {
+		t.Errorf("Expected apiVersion %q, got %q", chart.APIVersionV2, c.Metadata.APIVersion)
+	}
+	for _, f := range c.Files {
+		if f.Name == "requirements.yaml" || f.Name == "requirements.lock" {
+			t.Errorf("Expected %s to be removed", f.Name)
+		}
+	}
+
+	tmp, err := ioutil.TempDir("", "helm-migrate-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := SaveDir(c, tmp); err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+	for _, name := range []string{"requirements.yaml", "requirements.lock"} {
+		if _, err := os.Stat(filepath.Join(tmp, "migrate-v1", name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be saved", name)
+		}
+	}
+
+	c2, err := loader.LoadDir(filepath.Join(tmp, "migrate-v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c2.Validate(); err != nil {
+		t.Fatalf("Migrated chart is invalid: %s", err)
+	}
+	if c2.Metadata.APIVersion != chart.APIVersionV2 {
+		t.Errorf("Expected apiVersion %q, got %q", chart.APIVersionV2, c2.Metadata.APIVersion)
+	}
+
+	deps := c2.Metadata.Dependencies
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 dependencies in Chart.yaml, got %d", len(deps))
+	}
+	if deps[0].Name != "alpine" || deps[0].Condition != "alpine.enabled" {
+		t.Errorf("Unexpected first dependency: %+v", deps[0])
+	}
+	if deps[1].Name != "mariner" || deps[1].Alias != "boat" {
+		t.Errorf("Unexpected second dependency: %+v", deps[1])
+	}
+
+	if c2.Lock == nil {
+		t.Fatal("Expected Chart.lock to be saved")
+	}
+	if len(c2.Lock.Dependencies) != 2 {
+		t.Errorf("Expected 2 locked dependencies, got %d", len(c2.Lock.Dependencies))
+	}
+	if c2.Lock.Digest != c.Lock.Digest {
+		t.Errorf("Expected lock digest %q, got %q", c.Lock.Digest, c2.Lock.Digest)
+	}
+
+	// migrating again is a no-op
+	if err := MigrateToV2(c2); err != nil {
+		t.Errorf("Expected no error migrating a v2 chart, got %s", err)
+	}
+}
+
+func TestMigrateToV2UnknownAPIVersion(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v3", Name: "future", Version: "1.0.0"},
+	}
+	if err := MigrateToV2(c); err == nil {
+		t.Error("Expected an error migrating an unknown apiVersion")
+	}
+}
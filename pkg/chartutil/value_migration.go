@@ -0,0 +1,133 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// MigrateValues applies the value migrations declared by the chart to the
+// user supplied values vals, returning the migrated values and a description
+// of every migration applied. vals is not modified.
+//
+// A migration moves the value at its From path to its To path, applying its
+// transform. When vals already sets the To path, the value at the From path
+// is dropped instead. Migrations whose From path is not set are skipped.
+func MigrateValues(c *chart.Chart, vals map[string]interface{}) (map[string]interface{}, []string, error) {
+	if c.Metadata == nil || len(c.Metadata.ValueMigrations) == 0 {
+		return vals, nil, nil
+	}
+	copied, err := copystructure.Copy(vals)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, _ := copied.(map[string]interface{})
+	if out == nil {
+		out = map[string]interface{}{}
+	}
+
+	var applied []string
+	for _, m := range c.Metadata.ValueMigrations {
+		from := strings.Split(m.From, ".")
+		table, ok := parentTable(out, from, false)
+		if !ok {
+			continue
+		}
+		v, ok := table[from[len(from)-1]]
+		if !ok {
+			continue
+		}
+		delete(table, from[len(from)-1])
+
+		to := strings.Split(m.To, ".")
+		if table, ok := parentTable(out, to, false); ok {
+			if _, ok := table[to[len(to)-1]]; ok {
+				applied = append(applied, fmt.Sprintf("chart %s no longer uses value %q, dropped it in favor of %q", c.Name(), m.From, m.To))
+				continue
+			}
+		}
+		v, err := transformValue(v, m.Transform)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "cannot migrate value %q to %q", m.From, m.To)
+		}
+		table, ok = parentTable(out, to, true)
+		if !ok {
+			return nil, nil, errors.Errorf("cannot migrate value %q to %q: a parent of %q is not a table", m.From, m.To, m.To)
+		}
+		table[to[len(to)-1]] = v
+		applied = append(applied, fmt.Sprintf("chart %s renamed value %q to %q", c.Name(), m.From, m.To))
+	}
+	return out, applied, nil
+}
+
+// parentTable returns the table holding the last of the keys in vals. When
+// create is set, missing tables along the path are created.
+func parentTable(vals map[string]interface{}, keys []string, create bool) (map[string]interface{}, bool) {
+	for _, k := range keys[:len(keys)-1] {
+		v, exists := vals[k]
+		if !exists && create {
+			v = map[string]interface{}{}
+			vals[k] = v
+		}
+		next, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		vals = next
+	}
+	return vals, true
+}
+
+// transformValue converts v as named by transform.
+func transformValue(v interface{}, transform string) (interface{}, error) {
+	switch transform {
+	case "":
+		return v, nil
+	case chart.ValueTransformString:
+		return fmt.Sprint(v), nil
+	case chart.ValueTransformInt:
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case int64:
+			return n, nil
+		case float64:
+			return int64(n), nil
+		}
+		i, err := strconv.ParseInt(fmt.Sprint(v), 10, 64)
+		return i, errors.Wrapf(err, "cannot convert %v to an integer", v)
+	case chart.ValueTransformBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		b, err := strconv.ParseBool(fmt.Sprint(v))
+		return b, errors.Wrapf(err, "cannot convert %v to a boolean", v)
+	case chart.ValueTransformList:
+		if l, ok := v.([]interface{}); ok {
+			return l, nil
+		}
+		return []interface{}{v}, nil
+	}
+	return nil, errors.Errorf("unknown transform %q", transform)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestMigrateValues(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "web",
+			ValueMigrations: []*chart.ValueMigration{
+				{From: "image.name", To: "image.repository"},
+				{From: "replicaCount", To: "replicas", Transform: chart.ValueTransformInt},
+				{From: "host", To: "ingress.hosts", Transform: chart.ValueTransformList},
+				{From: "oldPort", To: "port"},
+				{From: "missing", To: "present"},
+			},
+		},
+	}
+	vals := map[string]interface{}{
+		"image":        map[string]interface{}{"name": "nginx", "tag": "1.19"},
+		"replicaCount": "3",
+		"host":         "example.com",
+		"oldPort":      80,
+		"port":         8080,
+	}
+
+	out, applied, err := MigrateValues(c, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.19"},
+		"replicas": int64(3),
+		"ingress":  map[string]interface{}{"hosts": []interface{}{"example.com"}},
+		"port":     8080,
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expected migrated values %v, got %v", expected, out)
+	}
+	if len(applied) != 4 {
+		t.Errorf("expected 4 applied migrations, got %d: %v", len(applied), applied)
+	}
+	if _, ok := vals["image"].(map[string]interface{})["name"]; !ok {
+		t.Error("expected the supplied values not to be modified")
+	}
+}
+
+func TestMigrateValuesInvalidTransform(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "web",
+			ValueMigrations: []*chart.ValueMigration{
+				{From: "replicaCount", To: "replicas", Transform: chart.ValueTransformInt},
+			},
+		},
+	}
+	if _, _, err := MigrateValues(c, map[string]interface{}{"replicaCount": "three"}); err == nil {
+		t.Error("expected an error converting a non-numeric value to an integer")
+	}
+}
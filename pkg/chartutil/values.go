@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -129,6 +130,28 @@ type ReleaseOptions struct {
 	Revision  int
 	IsUpgrade bool
 	IsInstall bool
+	// Service is the service rendering the release. Defaults to "Helm".
+	Service string
+}
+
+// Validate checks that the release options describe a consistent release.
+// An empty Name is left for the caller to default, and is not checked.
+func (o ReleaseOptions) Validate() error {
+	if o.Name != "" {
+		if err := ValidateReleaseName(o.Name); err != nil {
+			return err
+		}
+	}
+	if o.Revision < 1 {
+		return errors.Errorf("invalid release revision %d: must be 1 or greater", o.Revision)
+	}
+	if o.IsInstall == o.IsUpgrade {
+		return errors.New("release must be either an install or an upgrade")
+	}
+	if o.IsUpgrade && o.Revision < 2 {
+		return errors.Errorf("invalid release revision %d: an upgrade must have a revision greater than 1", o.Revision)
+	}
+	return nil
 }
 
 // ToRenderValues composes the struct from the data coming from the Releases, Charts and Values files
@@ -138,6 +161,10 @@ func ToRenderValues(chrt *chart.Chart, chrtVals map[string]interface{}, options
 	if caps == nil {
 		caps = DefaultCapabilities
 	}
+	service := options.Service
+	if service == "" {
+		service = "Helm"
+	}
 	top := map[string]interface{}{
 		"Chart":        chrt.Metadata,
 		"Capabilities": caps,
@@ -147,7 +174,7 @@ func ToRenderValues(chrt *chart.Chart, chrtVals map[string]interface{}, options
 			"IsUpgrade": options.IsUpgrade,
 			"IsInstall": options.IsInstall,
 			"Revision":  options.Revision,
-			"Service":   "Helm",
+			"Service":   service,
 		},
 	}
 
@@ -210,3 +237,213 @@ func (v Values) pathValue(path []string) (interface{}, error) {
 func parsePath(key string) []string { return strings.Split(key, ".") }
 
 func joinPath(path ...string) string { return strings.Join(path, ".") }
+
+// GetValueAtPath returns the value at the end of a path through the values,
+// along with the name of its type: "table", "list", "string", "number",
+// "bool" or "null". Unlike PathValue, the path may end at a table and may
+// index into lists with the same syntax as --set, so "x.y[0].z" is the key z
+// of the first item of the list x.y. A backslash escapes a '.' or '[' that is
+// part of a key. An ErrNoValue is returned if nothing is found at the path.
+func GetValueAtPath(values Values, path string) (interface{}, string, error) {
+	segments, err := parseValuesPath(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var cur interface{} = map[string]interface{}(values)
+	for _, s := range segments {
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			if s.isIndex {
+				return nil, "", ErrNoValue{path}
+			}
+			v, ok := c[s.key]
+			if !ok {
+				return nil, "", ErrNoValue{path}
+			}
+			cur = v
+		case []interface{}:
+			if !s.isIndex || s.index >= len(c) {
+				return nil, "", ErrNoValue{path}
+			}
+			cur = c[s.index]
+		default:
+			return nil, "", ErrNoValue{path}
+		}
+	}
+	return cur, valueType(cur), nil
+}
+
+// UnsetValueAtPath removes the value at the end of a path through the values,
+// written as for GetValueAtPath: a key is deleted from its table and an item
+// is removed from its list. Nothing is done if there is no value at the path.
+func UnsetValueAtPath(values Values, path string) error {
+	segments, err := parseValuesPath(path)
+	if err != nil {
+		return err
+	}
+	_, err = unsetIn(map[string]interface{}(values), segments, path)
+	return err
+}
+
+// UnsetRenderValues removes the values at paths from the chart values of
+// valuesToRender, the top-level context returned by ToRenderValues. See
+// UnsetValueAtPath.
+func UnsetRenderValues(valuesToRender Values, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	vals, err := valuesToRender.Table("Values")
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := UnsetValueAtPath(vals, path); err != nil {
+			return errors.Wrap(err, "failed to unset value")
+		}
+	}
+	return nil
+}
+
+// unsetIn removes the value at segments from v, returning the updated v.
+func unsetIn(v interface{}, segments []pathSegment, path string) (interface{}, error) {
+	s := segments[0]
+	last := len(segments) == 1
+
+	switch c := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		if s.isIndex {
+			return nil, errors.Errorf("%q: cannot take item %d of a table", path, s.index)
+		}
+		child, ok := c[s.key]
+		if !ok {
+			return c, nil
+		}
+		if last {
+			delete(c, s.key)
+			return c, nil
+		}
+		child, err := unsetIn(child, segments[1:], path)
+		if err != nil {
+			return nil, err
+		}
+		c[s.key] = child
+		return c, nil
+	case []interface{}:
+		if !s.isIndex {
+			return nil, errors.Errorf("%q: cannot take key %q of a list", path, s.key)
+		}
+		if s.index >= len(c) {
+			return c, nil
+		}
+		if last {
+			out := make([]interface{}, 0, len(c)-1)
+			out = append(out, c[:s.index]...)
+			return append(out, c[s.index+1:]...), nil
+		}
+		child, err := unsetIn(c[s.index], segments[1:], path)
+		if err != nil {
+			return nil, err
+		}
+		c[s.index] = child
+		return c, nil
+	default:
+		return nil, errors.Errorf("%q: cannot remove a value from a %s", path, valueType(v))
+	}
+}
+
+// pathSegment is a key or a list index of a path parsed by parseValuesPath.
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func parseValuesPath(path string) ([]pathSegment, error) {
+	if path == "" {
+		return nil, errors.New("values path cannot be empty")
+	}
+
+	var segments []pathSegment
+	var key strings.Builder
+	// afterIndex is set right after a closing ']', where only '.' or '[' may follow.
+	afterIndex := false
+	rs := []rune(path)
+	for i := 0; i < len(rs); i++ {
+		switch r := rs[i]; {
+		case afterIndex && r != '.' && r != '[':
+			return nil, errors.Errorf("invalid path %q: expected '.' or '[' after ']'", path)
+		case r == '\\':
+			if i+1 == len(rs) {
+				return nil, errors.Errorf("invalid path %q: trailing escape", path)
+			}
+			i++
+			key.WriteRune(rs[i])
+		case r == '.':
+			if key.Len() == 0 && !afterIndex {
+				return nil, errors.Errorf("invalid path %q: empty key", path)
+			}
+			if key.Len() > 0 {
+				segments = append(segments, pathSegment{key: key.String()})
+				key.Reset()
+			}
+			afterIndex = false
+			if i+1 == len(rs) {
+				return nil, errors.Errorf("invalid path %q: empty key", path)
+			}
+		case r == '[':
+			if key.Len() > 0 {
+				segments = append(segments, pathSegment{key: key.String()})
+				key.Reset()
+			} else if !afterIndex {
+				return nil, errors.Errorf("invalid path %q: list index without a key", path)
+			}
+			j := i + 1
+			for j < len(rs) && rs[j] != ']' {
+				j++
+			}
+			if j == len(rs) {
+				return nil, errors.Errorf("invalid path %q: missing ']'", path)
+			}
+			digits := string(rs[i+1 : j])
+			index, err := strconv.Atoi(digits)
+			if err != nil {
+				return nil, errors.Errorf("invalid path %q: list index %q is not a number", path, digits)
+			}
+			if index < 0 {
+				return nil, errors.Errorf("invalid path %q: negative %d index not allowed", path, index)
+			}
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+			i = j
+			afterIndex = true
+		default:
+			key.WriteRune(r)
+		}
+	}
+	if key.Len() > 0 {
+		segments = append(segments, pathSegment{key: key.String()})
+	}
+	return segments, nil
+}
+
+// valueType names the type of a value as GetValueAtPath reports it.
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "table"
+	case []interface{}:
+		return "list"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
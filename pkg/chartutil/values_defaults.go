@@ -0,0 +1,113 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// DefaultsReport lists the leaf values of a release by dotted path, sorted,
+// depending on whether they come from the chart defaults or from the user.
+type DefaultsReport struct {
+	// Defaulted are the values that take a chart default
+	Defaulted []string `json:"defaulted"`
+	// Overridden are the chart defaults that the user sets
+	Overridden []string `json:"overridden"`
+	// Added are the values that the user sets and no chart defines
+	Added []string `json:"added"`
+	// Removed are the chart defaults that the user unsets with null
+	Removed []string `json:"removed"`
+}
+
+// ReportDefaults compares the values supplied by the user with the defaults
+// of chrt and its subcharts, coalescing them as for a release.
+//
+// Globals are only reported at the top level, not where they are copied into
+// the values of the subcharts.
+func ReportDefaults(chrt *chart.Chart, userVals map[string]interface{}) (*DefaultsReport, error) {
+	defaults, err := CoalesceValues(chrt, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	vals, err := CoalesceValues(chrt, userVals)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DefaultsReport{
+		Defaulted:  []string{},
+		Overridden: []string{},
+		Added:      []string{},
+		Removed:    []string{},
+	}
+	walkLeaves(vals, nil, func(path []string) {
+		if isInheritedGlobal(path) {
+			return
+		}
+		key := strings.Join(path, ".")
+		switch {
+		case !hasPath(userVals, path):
+			report.Defaulted = append(report.Defaulted, key)
+		case hasPath(defaults, path):
+			report.Overridden = append(report.Overridden, key)
+		default:
+			report.Added = append(report.Added, key)
+		}
+	})
+	walkLeaves(defaults, nil, func(path []string) {
+		if !isInheritedGlobal(path) && isUnset(userVals, path) {
+			report.Removed = append(report.Removed, strings.Join(path, "."))
+		}
+	})
+
+	for _, keys := range [][]string{report.Defaulted, report.Overridden, report.Added, report.Removed} {
+		sort.Strings(keys)
+	}
+	return report, nil
+}
+
+// isUnset returns true if vals sets path, or one of its parent tables, to
+// null.
+func isUnset(vals map[string]interface{}, path []string) bool {
+	for _, k := range path {
+		v, ok := vals[k]
+		if !ok {
+			return false
+		}
+		if v == nil {
+			return true
+		}
+		if vals, ok = asTable(v); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+// isInheritedGlobal returns true for the globals of subcharts, which are
+// copies of the top-level ones.
+func isInheritedGlobal(path []string) bool {
+	for _, k := range path[1:] {
+		if k == GlobalKey {
+			return true
+		}
+	}
+	return false
+}
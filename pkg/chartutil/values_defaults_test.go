@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestReportDefaults(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub"},
+		Values: map[string]interface{}{
+			"replicas": 1,
+			"global":   map[string]interface{}{"env": "dev"},
+		},
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent"},
+		Values: map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": "nginx",
+				"tag":        "latest",
+			},
+			"resources": map[string]interface{}{
+				"limits": map[string]interface{}{"cpu": "100m"},
+			},
+			"annotations": map[string]interface{}{"team": "a"},
+			"global":      map[string]interface{}{"env": "prod"},
+		},
+	}
+	parent.AddDependency(sub)
+
+	userVals := map[string]interface{}{
+		"image":       map[string]interface{}{"tag": "1.20"},
+		"resources":   nil,
+		"annotations": map[string]interface{}{"owner": "b"},
+		"sub":         map[string]interface{}{"replicas": 3},
+	}
+	report, err := ReportDefaults(parent, userVals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &DefaultsReport{
+		Defaulted:  []string{"annotations.team", "global.env", "image.repository"},
+		Overridden: []string{"image.tag", "sub.replicas"},
+		Added:      []string{"annotations.owner"},
+		Removed:    []string{"resources.limits.cpu"},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected %+v, got %+v", expected, report)
+	}
+	if _, ok := userVals["sub"].(map[string]interface{})["global"]; ok {
+		t.Error("expected the user values to be left unchanged")
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expectedJSON = `{"defaulted":["annotations.team","global.env","image.repository"],"overridden":["image.tag","sub.replicas"],"added":["annotations.owner"],"removed":["resources.limits.cpu"]}`
+	if string(b) != expectedJSON {
+		t.Errorf("expected %s, got %s", expectedJSON, b)
+	}
+}
+
+func TestReportDefaultsNoUserValues(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "plain"},
+		Values:   map[string]interface{}{"a": 1, "b": map[string]interface{}{}},
+	}
+	report, err := ReportDefaults(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Defaulted, []string{"a", "b"}) {
+		t.Errorf("expected every value to be defaulted, got %v", report.Defaulted)
+	}
+	if len(report.Overridden)+len(report.Added)+len(report.Removed) != 0 {
+		t.Errorf("expected nothing else, got %+v", report)
+	}
+}
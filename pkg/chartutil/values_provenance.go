@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Sources reported by ValuesProvenance for values not supplied by the user.
+const (
+	// SourceChartDefault is the values.yaml of a chart
+	SourceChartDefault = "chart default"
+	// SourceParentGlobal is a global value inherited from a parent chart
+	SourceParentGlobal = "parent global"
+	// SourceUnknown is used when no source sets the value
+	SourceUnknown = "unknown"
+)
+
+// ValuesProvenance reports the source that won each leaf key of vals, the
+// coalesced values of chrt, keyed by its dotted path.
+//
+// userSources maps each key supplied by the user to its source, such as a
+// values file or a --set flag. Other keys are reported as
+// "chart default (<chart>)" for the outermost chart whose values.yaml sets
+// them, or as "parent global (<source>)" for the globals a subchart inherits
+// from its parent.
+func ValuesProvenance(chrt *chart.Chart, vals map[string]interface{}, userSources map[string]string) map[string]string {
+	provenance := make(map[string]string)
+	walkLeaves(vals, nil, func(path []string) {
+		provenance[strings.Join(path, ".")] = valueSource(chrt, vals, path, userSources)
+	})
+	return provenance
+}
+
+func valueSource(chrt *chart.Chart, vals map[string]interface{}, path []string, userSources map[string]string) string {
+	if src, ok := userSources[strings.Join(path, ".")]; ok {
+		return src
+	}
+
+	c, rel := chrt, path
+	for {
+		parent := path[:len(path)-len(rel)]
+		if c != chrt && rel[0] == GlobalKey && len(parent) > 0 {
+			// the globals of a parent override those of its subcharts
+			global := append(append([]string{}, parent[:len(parent)-1]...), rel...)
+			if hasPath(vals, global) {
+				return fmt.Sprintf("%s (%s)", SourceParentGlobal, valueSource(chrt, vals, global, userSources))
+			}
+		}
+		if hasPath(c.Values, rel) {
+			return fmt.Sprintf("%s (%s)", SourceChartDefault, c.Name())
+		}
+		if len(rel) < 2 {
+			return SourceUnknown
+		}
+		sub := dependencyByName(c, rel[0])
+		if sub == nil {
+			return SourceUnknown
+		}
+		c, rel = sub, rel[1:]
+	}
+}
+
+// walkLeaves calls fn with the path of every value of vals that is not a
+// non-empty table.
+func walkLeaves(vals map[string]interface{}, prefix []string, fn func([]string)) {
+	for k, v := range vals {
+		path := append(append([]string{}, prefix...), k)
+		if t, ok := asTable(v); ok && len(t) > 0 {
+			walkLeaves(t, path, fn)
+			continue
+		}
+		fn(path)
+	}
+}
+
+func hasPath(vals map[string]interface{}, path []string) bool {
+	for i, k := range path {
+		v, ok := vals[k]
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		if vals, ok = asTable(v); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+func asTable(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return t, true
+	case Values:
+		return t, true
+	}
+	return nil, false
+}
+
+func dependencyByName(c *chart.Chart, name string) *chart.Chart {
+	for _, d := range c.Dependencies() {
+		if d.Name() == name {
+			return d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestValuesProvenance(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub"},
+		Values: map[string]interface{}{
+			"replicas": 1,
+			"port":     80,
+			"global": map[string]interface{}{
+				"env":    "dev",
+				"region": "eu",
+			},
+		},
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent"},
+		Values: map[string]interface{}{
+			"name": "parent",
+			"tag":  "latest",
+			"global": map[string]interface{}{
+				"env": "prod",
+			},
+			"sub": map[string]interface{}{
+				"replicas": 2,
+			},
+		},
+	}
+	parent.AddDependency(sub)
+
+	userVals := map[string]interface{}{
+		"name": "web",
+		"tag":  "1.0",
+	}
+	vals, err := CoalesceValues(parent, userVals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// coalescing must leave the chart defaults alone, or the subchart
+	// defaults would be attributed to the parent
+	if sv := parent.Values["sub"]; !reflect.DeepEqual(sv, map[string]interface{}{"replicas": 2}) {
+		t.Fatalf("expected the parent defaults for sub to be unchanged, got %v", sv)
+	}
+	userSources := map[string]string{
+		"name": "override.yaml",
+		"tag":  "--set",
+	}
+
+	expected := map[string]string{
+		"name":              "override.yaml",
+		"tag":               "--set",
+		"global.env":        "chart default (parent)",
+		"sub.replicas":      "chart default (parent)",
+		"sub.port":          "chart default (sub)",
+		"sub.global.env":    "parent global (chart default (parent))",
+		"sub.global.region": "chart default (sub)",
+	}
+	if got := ValuesProvenance(parent, vals, userSources); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected provenance %v, got %v", expected, got)
+	}
+}
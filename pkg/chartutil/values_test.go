@@ -19,10 +19,12 @@ package chartutil
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"testing"
 	"text/template"
 
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/strvals"
 )
 
 func TestReadValues(t *testing.T) {
@@ -152,6 +154,32 @@ func TestToRenderValues(t *testing.T) {
 	}
 }
 
+func TestReleaseOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ReleaseOptions
+		valid   bool
+	}{
+		{"install", ReleaseOptions{Name: "rel", Revision: 1, IsInstall: true}, true},
+		{"upgrade", ReleaseOptions{Name: "rel", Revision: 3, IsUpgrade: true}, true},
+		{"invalid name", ReleaseOptions{Name: "Not_Valid", Revision: 1, IsInstall: true}, false},
+		{"default name", ReleaseOptions{Revision: 1, IsInstall: true}, true},
+		{"zero revision", ReleaseOptions{Name: "rel", IsInstall: true}, false},
+		{"neither install nor upgrade", ReleaseOptions{Name: "rel", Revision: 1}, false},
+		{"both install and upgrade", ReleaseOptions{Name: "rel", Revision: 2, IsInstall: true, IsUpgrade: true}, false},
+		{"upgrade of first revision", ReleaseOptions{Name: "rel", Revision: 1, IsUpgrade: true}, false},
+	}
+	for _, tt := range tests {
+		err := tt.options.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
 func TestReadValuesFile(t *testing.T) {
 	data, err := ReadValuesFile("./testdata/coleridge.yaml")
 	if err != nil {
@@ -290,3 +318,181 @@ chapter:
 		}
 	}
 }
+
+func TestGetValueAtPath(t *testing.T) {
+	doc := `
+title: "Moby Dick"
+pages: 635
+chapter:
+  one:
+    title: "Loomings"
+    characters:
+    - name: Ishmael
+      aliases: [narrator]
+    - name: Queequeg
+  two: ~
+matrix:
+- [1, 2]
+- [3, 4]
+"dotted.key": yes
+`
+	d, err := ReadValues([]byte(doc))
+	if err != nil {
+		t.Fatalf("Failed to parse the White Whale: %s", err)
+	}
+
+	tests := []struct {
+		path  string
+		value interface{}
+		typ   string
+	}{
+		{"title", "Moby Dick", "string"},
+		{"pages", float64(635), "number"},
+		{"chapter.one.title", "Loomings", "string"},
+		{"chapter.one.characters[1].name", "Queequeg", "string"},
+		{"chapter.one.characters[0].aliases[0]", "narrator", "string"},
+		{"chapter.one.characters[0]", map[string]interface{}{"name": "Ishmael", "aliases": []interface{}{"narrator"}}, "table"},
+		{"chapter.one.characters[0].aliases", []interface{}{"narrator"}, "list"},
+		{"chapter.two", nil, "null"},
+		{"matrix[1][0]", float64(3), "number"},
+		{`dotted\.key`, true, "bool"},
+	}
+	for _, tt := range tests {
+		v, typ, err := GetValueAtPath(d, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, tt.value) {
+			t.Errorf("%s: expected value %v, got %v", tt.path, tt.value, v)
+		}
+		if typ != tt.typ {
+			t.Errorf("%s: expected type %q, got %q", tt.path, tt.typ, typ)
+		}
+	}
+
+	for _, path := range []string{
+		"subtitle",
+		"chapter.three",
+		"chapter.one.title.text",
+		"chapter.one.characters[2]",
+		"chapter.one.characters.name",
+		"chapter[0]",
+		"matrix[0][5]",
+	} {
+		if _, _, err := GetValueAtPath(d, path); err == nil {
+			t.Errorf("%s: expected a missing value", path)
+		} else if _, ok := err.(ErrNoValue); !ok {
+			t.Errorf("%s: expected ErrNoValue, got %T: %s", path, err, err)
+		}
+	}
+
+	for _, path := range []string{
+		"",
+		".title",
+		"chapter..one",
+		"chapter.",
+		"[0]",
+		"matrix[0",
+		"matrix[x]",
+		"matrix[-1]",
+		"matrix[0]x",
+		`title\`,
+	} {
+		if _, _, err := GetValueAtPath(d, path); err == nil {
+			t.Errorf("%q: expected an invalid path error", path)
+		} else if _, ok := err.(ErrNoValue); ok {
+			t.Errorf("%q: expected an invalid path error, got %s", path, err)
+		}
+	}
+}
+
+func TestUnsetValueAtPath(t *testing.T) {
+	doc := `
+image:
+  repository: nginx
+  tag: "1.19"
+hosts: [a.example.com, b.example.com, c.example.com]
+matrix:
+- [1, 2]
+- [3, 4]
+"dotted.key": yes
+`
+	d, err := ReadValues([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"image.tag", "hosts[1]", "matrix[0][0]", `dotted\.key`, "resources.limits", "hosts[5]"} {
+		if err := UnsetValueAtPath(d, path); err != nil {
+			t.Errorf("%s: unexpected error: %s", path, err)
+		}
+	}
+	expected := Values{
+		"image": map[string]interface{}{"repository": "nginx"},
+		"hosts": []interface{}{"a.example.com", "c.example.com"},
+		"matrix": []interface{}{
+			[]interface{}{float64(2)},
+			[]interface{}{float64(3), float64(4)},
+		},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("expected values %v, got %v", expected, d)
+	}
+
+	for _, path := range []string{"image[0]", "hosts.name", "image.repository.name", "image..tag"} {
+		if err := UnsetValueAtPath(d, path); err == nil {
+			t.Errorf("%q: expected an error", path)
+		}
+	}
+}
+
+func TestUnsetRenderValues(t *testing.T) {
+	top := Values{
+		"Release": map[string]interface{}{"Name": "test"},
+		"Values": map[string]interface{}{
+			"image":     map[string]interface{}{"repository": "nginx", "tag": "1.19"},
+			"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+		},
+	}
+	if err := UnsetRenderValues(top, []string{"image.tag", "resources.limits"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"image":     map[string]interface{}{"repository": "nginx"},
+		"resources": map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(top["Values"], expected) {
+		t.Errorf("expected values %v, got %v", expected, top["Values"])
+	}
+
+	if err := UnsetRenderValues(top, []string{"image[0]"}); err == nil {
+		t.Error("expected an error for an invalid path")
+	}
+}
+
+// TestValuesPathMatchesStrvals checks that a values path reaches the value
+// that --set sets with the same key.
+func TestValuesPathMatchesStrvals(t *testing.T) {
+	for _, key := range []string{
+		"name",
+		"image.tag",
+		`annotations.example\.com/owner`,
+		"servers[1].port",
+		"matrix[0][1]",
+		`weird\[key]`,
+	} {
+		vals := map[string]interface{}{}
+		if err := strvals.ParseInto(key+"=x", vals); err != nil {
+			t.Fatalf("%s: %s", key, err)
+		}
+		if v, _, err := GetValueAtPath(vals, key); err != nil || v != "x" {
+			t.Errorf("%s: expected the value set with --set, got %v, %v", key, v, err)
+		}
+		if err := UnsetValueAtPath(vals, key); err != nil {
+			t.Errorf("%s: unexpected error: %s", key, err)
+		}
+		if _, _, err := GetValueAtPath(vals, key); err == nil {
+			t.Errorf("%s: expected the value to be unset", key)
+		}
+	}
+}
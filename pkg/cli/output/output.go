@@ -17,12 +17,15 @@ limitations under the License.
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/gosuri/uitable"
 	"github.com/pkg/errors"
+	"k8s.io/client-go/util/jsonpath"
 	"sigs.k8s.io/yaml"
 )
 
@@ -35,6 +38,16 @@ const (
 	YAML  Format = "yaml"
 )
 
+// jsonPathPrefix starts the formats printing the result of a JSONPath
+// template applied to the JSON output, such as 'jsonpath={.version}'.
+const jsonPathPrefix = "jsonpath="
+
+// JSONPath returns the format printing the result of the JSONPath template
+// applied to the JSON output.
+func JSONPath(template string) Format {
+	return Format(jsonPathPrefix + template)
+}
+
 // Formats returns a list of the string representation of the supported formats
 func Formats() []string {
 	return []string{Table.String(), JSON.String(), YAML.String()}
@@ -51,6 +64,9 @@ func (o Format) String() string {
 // Write the output in the given format to the io.Writer. Unsupported formats
 // will return an error
 func (o Format) Write(out io.Writer, w Writer) error {
+	if template, ok := o.jsonPathTemplate(); ok {
+		return writeJSONPath(out, w, template)
+	}
 	switch o {
 	case Table:
 		return w.WriteTable(out)
@@ -63,8 +79,16 @@ func (o Format) Write(out io.Writer, w Writer) error {
 }
 
 // ParseFormat takes a raw string and returns the matching Format.
-// If the format does not exists, ErrInvalidFormatType is returned
+// If the format does not exists, ErrInvalidFormatType is returned. A
+// 'jsonpath=' format whose template cannot be parsed returns the parse error.
 func ParseFormat(s string) (out Format, err error) {
+	if template, ok := Format(s).jsonPathTemplate(); ok {
+		if _, err := parseJSONPath(template); err != nil {
+			return "", err
+		}
+		return Format(s), nil
+	}
+
 	switch s {
 	case Table.String():
 		out, err = Table, nil
@@ -78,6 +102,53 @@ func ParseFormat(s string) (out Format, err error) {
 	return
 }
 
+// jsonPathTemplate returns the JSONPath template of a 'jsonpath=' format.
+func (o Format) jsonPathTemplate() (string, bool) {
+	if !strings.HasPrefix(string(o), jsonPathPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(string(o), jsonPathPrefix), true
+}
+
+func parseJSONPath(template string) (*jsonpath.JSONPath, error) {
+	if template == "" {
+		return nil, errors.New("jsonpath format requires a template, such as 'jsonpath={.name}'")
+	}
+	jp := jsonpath.New("output")
+	if err := jp.Parse(template); err != nil {
+		return nil, errors.Wrapf(err, "invalid JSONPath template %q", template)
+	}
+	return jp, nil
+}
+
+// writeJSONPath applies the JSONPath template to the JSON output of w. Nothing
+// is written to out unless the whole template could be applied.
+func writeJSONPath(out io.Writer, w Writer, template string) error {
+	jp, err := parseJSONPath(template)
+	if err != nil {
+		return err
+	}
+
+	var raw bytes.Buffer
+	if err := w.WriteJSON(&raw); err != nil {
+		return err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(raw.Bytes(), &obj); err != nil {
+		return errors.Wrap(err, "unable to apply JSONPath template")
+	}
+
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, obj); err != nil {
+		return errors.Wrapf(err, "unable to apply JSONPath template %q", template)
+	}
+	_, err = out.Write(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "unable to write JSONPath output")
+	}
+	return nil
+}
+
 // Writer is an interface that any type can implement to write supported formats
 type Writer interface {
 	// WriteTable will write tabular output into the given io.Writer, returning
@@ -17,9 +17,14 @@ limitations under the License.
 package values
 
 import (
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -34,34 +39,105 @@ type Options struct {
 	StringValues []string
 	Values       []string
 	FileValues   []string
+	// JSONValues set keys to JSON values, such as servers=[{"port":80}].
+	// Objects are merged into the values from files
+	JSONValues []string
+	// FileListValues set keys to the list of the contents of the files
+	// matching a glob, sorted by name
+	FileListValues []string
+	// UnsetValues are dotted paths, such as "a.b" or "a.list[1]", to remove
+	// from the values once they are coalesced with the chart's defaults. They
+	// are not applied by MergeValues, but passed on to the install, upgrade
+	// or lint; see chartutil.UnsetValueAtPath.
+	UnsetValues []string
+	// WarnOverrides reports keys set by a values file that a later values file overrides
+	WarnOverrides bool
+	// Stdin is read for the values file "-". It defaults to os.Stdin.
+	Stdin io.Reader
+	// Report, when set, is filled in by MergeValues
+	Report *MergeReport
+}
+
+// stdinValuesName names the values read from stdin in errors and sources.
+const stdinValuesName = "stdin"
+
+// Override describes a key set by one values file and overridden by a later one.
+type Override struct {
+	// Path is the dotted path of the overridden key
+	Path string
+	// File is the values file that previously set the key
+	File string
+	// OverriddenBy is the values file that overrode the key
+	OverriddenBy string
+	// TypeChanged is true when the key was overridden with a value of a different type
+	TypeChanged bool
+}
+
+func (o Override) String() string {
+	if o.TypeChanged {
+		return fmt.Sprintf("%s overrides %q set by %s with a value of a different type", o.OverriddenBy, o.Path, o.File)
+	}
+	return fmt.Sprintf("%s overrides %q set by %s", o.OverriddenBy, o.Path, o.File)
+}
+
+// MergeReport describes how the values merged by MergeValues were set.
+type MergeReport struct {
+	// Sources maps the dotted path of every key set by the user to its
+	// source: the last values file that set the key, or the flag
+	// (--set-json, --set, --set-string, --set-file or --set-file-list)
+	// that set it
+	Sources map[string]string
+	// Overrides lists every key set by a values file that a later values
+	// file overrides with a different value
+	Overrides []Override
 }
 
 // MergeValues merges values from files specified via -f/--values and directly
-// via --set, --set-string, or --set-file, marshaling them to YAML
+// via --set, --set-json, --set-string, or --set-file, marshaling them to YAML.
+// When opts.Report is set, it is filled in with the sources of the values.
 func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, error) {
 	base := map[string]interface{}{}
+	sources := map[string]string{}
+	var overrides []Override
+
+	files := &fileReader{providers: p, stdin: opts.stdin()}
 
 	// User specified a values files via -f/--values
 	for _, filePath := range opts.ValueFiles {
 		currentMap := map[string]interface{}{}
 
-		bytes, err := readFile(filePath, p)
+		bytes, err := files.read(filePath)
 		if err != nil {
 			return nil, err
 		}
+		if isStdin(filePath) {
+			filePath = stdinValuesName
+		}
 
 		if err := yaml.Unmarshal(bytes, &currentMap); err != nil {
 			return nil, errors.Wrapf(err, "failed to parse %s", filePath)
 		}
 		// Merge with the previous map
+		overrides = findOverrides(base, currentMap, "", filePath, sources, overrides)
 		base = mergeMaps(base, currentMap)
 	}
 
+	// User specified a value via --set-json
+	for _, value := range opts.JSONValues {
+		set := map[string]interface{}{}
+		if err := strvals.ParseJSON(value, set); err != nil {
+			return nil, errors.Wrap(err, "failed parsing --set-json data")
+		}
+		base = mergeMaps(base, set)
+		recordSources(value, "--set-json", sources, strvals.ParseJSON)
+	}
+
 	// User specified a value via --set
 	for _, value := range opts.Values {
 		if err := strvals.ParseInto(value, base); err != nil {
 			return nil, errors.Wrap(err, "failed parsing --set data")
 		}
+		recordSources(value, "--set", sources, strvals.ParseInto)
 	}
 
 	// User specified a value via --set-string
@@ -69,22 +145,75 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 		if err := strvals.ParseIntoString(value, base); err != nil {
 			return nil, errors.Wrap(err, "failed parsing --set-string data")
 		}
+		recordSources(value, "--set-string", sources, strvals.ParseIntoString)
 	}
 
 	// User specified a value via --set-file
 	for _, value := range opts.FileValues {
 		reader := func(rs []rune) (interface{}, error) {
-			bytes, err := readFile(string(rs), p)
-			return string(bytes), err
+			contents, err := files.readGlob(string(rs))
+			return strings.Join(contents, fileGlobSeparator), err
 		}
 		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
 			return nil, errors.Wrap(err, "failed parsing --set-file data")
 		}
+		// only the keys are needed, so don't read the file a second time
+		recordSources(value, "--set-file", sources, func(s string, dest map[string]interface{}) error {
+			return strvals.ParseIntoFile(s, dest, func([]rune) (interface{}, error) { return "", nil })
+		})
 	}
 
+	// User specified a list value via --set-file-list
+	for _, value := range opts.FileListValues {
+		reader := func(rs []rune) (interface{}, error) {
+			contents, err := files.readGlob(string(rs))
+			list := make([]interface{}, len(contents))
+			for i, c := range contents {
+				list[i] = c
+			}
+			return list, err
+		}
+		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
+			return nil, errors.Wrap(err, "failed parsing --set-file-list data")
+		}
+		recordSources(value, "--set-file-list", sources, func(s string, dest map[string]interface{}) error {
+			return strvals.ParseIntoFile(s, dest, func([]rune) (interface{}, error) { return "", nil })
+		})
+	}
+
+	// User removed a value via --unset
+	for _, path := range opts.UnsetValues {
+		for k := range sources {
+			if k == path || strings.HasPrefix(k, path+".") {
+				delete(sources, k)
+			}
+		}
+	}
+
+	if opts.Report != nil {
+		opts.Report.Sources = sources
+		opts.Report.Overrides = overrides
+	}
 	return base, nil
 }
 
+func (opts *Options) stdin() io.Reader {
+	if opts.Stdin != nil {
+		return opts.Stdin
+	}
+	return os.Stdin
+}
+
+// recordSources records source as the source of every key set by the --set
+// style expression value, parsed with parse.
+func recordSources(value, source string, sources map[string]string, parse func(string, map[string]interface{}) error) {
+	set := map[string]interface{}{}
+	if err := parse(value, set); err != nil {
+		return
+	}
+	findOverrides(nil, set, "", source, sources, nil)
+}
+
 func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
 	out := make(map[string]interface{}, len(a))
 	for k, v := range a {
@@ -104,15 +233,111 @@ func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
 	return out
 }
 
-// readFile load a file from stdin, the local directory, or a remote file with a url.
-func readFile(filePath string, p getter.Providers) ([]byte, error) {
-	if strings.TrimSpace(filePath) == "-" {
-		return ioutil.ReadAll(os.Stdin)
+// findOverrides records the values file that sets each key in b, and returns
+// overrides with every key in a that b replaces with a different value.
+func findOverrides(a, b map[string]interface{}, prefix, file string, sources map[string]string, overrides []Override) []Override {
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := b[k]
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		old, exists := a[k]
+		if vm, ok := v.(map[string]interface{}); ok {
+			if om, ok := old.(map[string]interface{}); ok {
+				overrides = findOverrides(om, vm, path, file, sources, overrides)
+				continue
+			}
+		}
+		if exists && !reflect.DeepEqual(old, v) {
+			overrides = append(overrides, Override{
+				Path:         path,
+				File:         sources[path],
+				OverriddenBy: file,
+				TypeChanged:  reflect.TypeOf(old) != reflect.TypeOf(v),
+			})
+		}
+		sources[path] = file
+		if vm, ok := v.(map[string]interface{}); ok {
+			// record the nested keys of a newly set map
+			overrides = findOverrides(nil, vm, path, file, sources, overrides)
+		}
+	}
+	return overrides
+}
+
+// fileGlobSeparator separates the contents of the files matching the glob of
+// a --set-file value.
+const fileGlobSeparator = "\n"
+
+// fileReader reads the files of the values options. Stdin ("-") can only be
+// consumed once, so it may be read by only one of them.
+type fileReader struct {
+	providers getter.Providers
+	stdin     io.Reader
+	readStdin bool
+}
+
+// isStdin returns true if filePath names stdin.
+func isStdin(filePath string) bool {
+	return strings.TrimSpace(filePath) == "-"
+}
+
+// readGlob reads the files matching the glob pattern, sorted by name. A path
+// to an existing file, or a URL, is read as it is. Matching no file is an
+// error.
+func (r *fileReader) readGlob(pattern string) ([]string, error) {
+	if _, err := os.Stat(pattern); err == nil || !strings.ContainsAny(pattern, "*?[") {
+		data, err := r.read(pattern)
+		return []string{string(data)}, err
+	}
+	if u, err := url.Parse(pattern); err == nil {
+		if _, err := r.providers.ByScheme(u.Scheme); err == nil {
+			data, err := r.read(pattern)
+			return []string{string(data)}, err
+		}
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid glob %q", pattern)
+	}
+	if len(matches) == 0 {
+		return nil, errors.Errorf("no files match %q", pattern)
+	}
+	sort.Strings(matches)
+
+	contents := make([]string, 0, len(matches))
+	for _, m := range matches {
+		data, err := ioutil.ReadFile(m)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, string(data))
+	}
+	return contents, nil
+}
+
+// read load a file from stdin, the local directory, or a remote file with a url.
+func (r *fileReader) read(filePath string) ([]byte, error) {
+	if isStdin(filePath) {
+		if r.readStdin {
+			return nil, errors.New(`values can only be read from stdin ("-") once`)
+		}
+		r.readStdin = true
+		data, err := ioutil.ReadAll(r.stdin)
+		return data, errors.Wrap(err, "failed to read values from stdin")
 	}
 	u, _ := url.Parse(filePath)
 
 	// FIXME: maybe someone handle other protocols like ftp.
-	g, err := p.ByScheme(u.Scheme)
+	g, err := r.providers.ByScheme(u.Scheme)
 	if err != nil {
 		return ioutil.ReadFile(filePath)
 	}
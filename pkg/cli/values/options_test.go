@@ -17,8 +17,14 @@ limitations under the License.
 package values
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/getter"
 )
 
 func TestMergeValues(t *testing.T) {
@@ -75,3 +81,334 @@ func TestMergeValues(t *testing.T) {
 		t.Errorf("Expected a map with different keys to merge properly with another map. Expected: %v, got %v", expectedMap, testMap)
 	}
 }
+
+func TestFindOverrides(t *testing.T) {
+	first := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.19",
+		},
+		"replicas": float64(1),
+		"service":  "ClusterIP",
+	}
+	second := map[string]interface{}{
+		"image": map[string]interface{}{
+			"tag": "1.20",
+		},
+		"replicas": float64(1),
+		"service": map[string]interface{}{
+			"type": "NodePort",
+		},
+	}
+
+	sources := map[string]string{}
+	overrides := findOverrides(map[string]interface{}{}, first, "", "first.yaml", sources, nil)
+	if len(overrides) != 0 {
+		t.Fatalf("expected no overrides for the first file, got %v", overrides)
+	}
+	overrides = findOverrides(first, second, "", "second.yaml", sources, overrides)
+
+	expected := []Override{
+		{Path: "image.tag", File: "first.yaml", OverriddenBy: "second.yaml"},
+		{Path: "service", File: "first.yaml", OverriddenBy: "second.yaml", TypeChanged: true},
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("Expected overrides %v, got %v", expected, overrides)
+	}
+
+	// keys of a map that replaced a scalar are attributed to the file that set them
+	third := map[string]interface{}{
+		"service": map[string]interface{}{
+			"type": "LoadBalancer",
+		},
+	}
+	overrides = findOverrides(mergeMaps(first, second), third, "", "third.yaml", sources, nil)
+	expected = []Override{
+		{Path: "service.type", File: "second.yaml", OverriddenBy: "third.yaml"},
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("Expected overrides %v, got %v", expected, overrides)
+	}
+}
+
+func TestMergeValuesReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-values-sources-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	valuesFile := filepath.Join(dir, "values.yaml")
+	if err := ioutil.WriteFile(valuesFile, []byte("image:\n  repository: nginx\n  tag: \"1.19\"\nreplicas: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "tls.crt")
+	if err := ioutil.WriteFile(certFile, []byte("cert"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &Options{
+		ValueFiles:   []string{valuesFile},
+		Values:       []string{"image.tag=1.20"},
+		StringValues: []string{"name=web"},
+		FileValues:   []string{"tls.cert=" + certFile},
+	}
+	report := &MergeReport{}
+	opts.Report = report
+	vals, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := report.Sources
+	if tag := vals["image"].(map[string]interface{})["tag"]; tag != "1.20" {
+		t.Errorf("expected --set to override image.tag, got %v", tag)
+	}
+
+	expected := map[string]string{
+		"image.repository": valuesFile,
+		"image.tag":        "--set",
+		"replicas":         valuesFile,
+		"name":             "--set-string",
+		"tls.cert":         "--set-file",
+	}
+	for k, v := range expected {
+		if sources[k] != v {
+			t.Errorf("expected source of %q to be %q, got %q", k, v, sources[k])
+		}
+	}
+}
+
+func TestMergeValuesStdin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-values-stdin-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := filepath.Join(dir, "first.yaml")
+	if err := ioutil.WriteFile(first, []byte("replicas: 1\nname: first\nport: 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	last := filepath.Join(dir, "last.yaml")
+	if err := ioutil.WriteFile(last, []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// stdin takes its place in the usual precedence of the values files
+	opts := &Options{
+		ValueFiles: []string{first, "-", last},
+		Values:     []string{"replicas=3"},
+		Stdin:      strings.NewReader("name: piped\nport: 443\nimage:\n  tag: \"1.20\"\n"),
+	}
+	report := &MergeReport{}
+	opts.Report = report
+	vals, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := report.Sources
+	expected := map[string]interface{}{
+		"replicas": int64(3),
+		"name":     "piped",
+		"port":     float64(8080),
+		"image":    map[string]interface{}{"tag": "1.20"},
+	}
+	if !reflect.DeepEqual(vals, expected) {
+		t.Errorf("expected values %v, got %v", expected, vals)
+	}
+	if sources["name"] != "stdin" {
+		t.Errorf("expected the source of name to be stdin, got %q", sources["name"])
+	}
+
+	opts = &Options{
+		ValueFiles: []string{"-", first, "-"},
+		Stdin:      strings.NewReader("name: piped\n"),
+	}
+	if _, err := opts.MergeValues(getter.Providers{}); err == nil || !strings.Contains(err.Error(), "only be read from stdin") {
+		t.Errorf("expected an error reading stdin twice, got %v", err)
+	}
+
+	opts = &Options{
+		ValueFiles: []string{"-"},
+		Stdin:      strings.NewReader("name: [piped\n"),
+	}
+	if _, err := opts.MergeValues(getter.Providers{}); err == nil || !strings.Contains(err.Error(), "failed to parse stdin") {
+		t.Errorf("expected a parse error naming stdin, got %v", err)
+	}
+
+	// --set-file reads stdin from opts.Stdin too, and shares the single read
+	opts = &Options{
+		FileValues: []string{"script=-"},
+		Stdin:      strings.NewReader("echo piped"),
+	}
+	vals, err = opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vals["script"] != "echo piped" {
+		t.Errorf("expected --set-file to read stdin, got %v", vals["script"])
+	}
+
+	opts = &Options{
+		ValueFiles: []string{"-"},
+		FileValues: []string{"script=-"},
+		Stdin:      strings.NewReader("name: piped\n"),
+	}
+	if _, err := opts.MergeValues(getter.Providers{}); err == nil || !strings.Contains(err.Error(), "only be read from stdin") {
+		t.Errorf("expected an error reading stdin for -f and --set-file, got %v", err)
+	}
+}
+
+func TestMergeValuesUnset(t *testing.T) {
+	opts := &Options{
+		Values: []string{
+			"image.repository=nginx",
+			"image.tag=1.19",
+		},
+		UnsetValues: []string{"image.tag", "resources.limits"},
+	}
+	report := &MergeReport{}
+	opts.Report = report
+	vals, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the values are only unset once coalesced with the chart's defaults
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.19",
+		},
+	}
+	if !reflect.DeepEqual(vals, expected) {
+		t.Errorf("expected values %v, got %v", expected, vals)
+	}
+	if _, ok := report.Sources["image.tag"]; ok {
+		t.Errorf("expected no source for unset key image.tag, got %q", report.Sources["image.tag"])
+	}
+	if _, ok := report.Sources["image.repository"]; !ok {
+		t.Error("expected a source for image.repository")
+	}
+}
+
+func TestMergeValuesFileGlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-values-glob-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"policies/b.rego":  "package b",
+		"policies/a.rego":  "package a",
+		"policies/c.txt":   "not a policy",
+		"single/only.rego": "package only",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected interface{}
+		err      string
+	}{
+		{
+			name:     "several files",
+			opts:     Options{FileValues: []string{"policy=" + filepath.Join(dir, "policies", "*.rego")}},
+			expected: "package a\npackage b",
+		},
+		{
+			name:     "one file",
+			opts:     Options{FileValues: []string{"policy=" + filepath.Join(dir, "single", "*.rego")}},
+			expected: "package only",
+		},
+		{
+			name: "no files",
+			opts: Options{FileValues: []string{"policy=" + filepath.Join(dir, "missing", "*.rego")}},
+			err:  "no files match",
+		},
+		{
+			name:     "list of several files",
+			opts:     Options{FileListValues: []string{"policy=" + filepath.Join(dir, "policies", "*.rego")}},
+			expected: []interface{}{"package a", "package b"},
+		},
+		{
+			name:     "list of a plain path",
+			opts:     Options{FileListValues: []string{"policy=" + filepath.Join(dir, "single", "only.rego")}},
+			expected: []interface{}{"package only"},
+		},
+		{
+			name: "list of no files",
+			opts: Options{FileListValues: []string{"policy=" + filepath.Join(dir, "missing", "*.rego")}},
+			err:  "no files match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vals, err := tt.opts.MergeValues(getter.Providers{})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(vals["policy"], tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, vals["policy"])
+			}
+		})
+	}
+}
+
+func TestMergeValuesJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-values-json-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	valuesFile := filepath.Join(dir, "values.yaml")
+	if err := ioutil.WriteFile(valuesFile, []byte("image:\n  repository: nginx\n  tag: \"1.19\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &Options{
+		ValueFiles: []string{valuesFile},
+		JSONValues: []string{`image={"tag":"1.20","pullPolicy":"Always"}`, `servers=[{"port":80},{"port":443}]`},
+		Values:     []string{"image.pullPolicy=IfNotPresent"},
+	}
+	vals, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.20",
+			"pullPolicy": "IfNotPresent",
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"port": float64(80)},
+			map[string]interface{}{"port": float64(443)},
+		},
+	}
+	if !reflect.DeepEqual(expected, vals) {
+		t.Errorf("expected %v, got %v", expected, vals)
+	}
+
+	opts = &Options{JSONValues: []string{`servers=[{"port":80}`}}
+	if _, err := opts.MergeValues(getter.Providers{}); err == nil || !strings.Contains(err.Error(), "failed parsing --set-json data") {
+		t.Errorf("expected an error for malformed JSON, got %v", err)
+	}
+}
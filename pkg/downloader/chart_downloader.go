@@ -16,6 +16,7 @@ limitations under the License.
 package downloader
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/url"
@@ -65,6 +66,9 @@ type ChartDownloader struct {
 	Verify VerificationStrategy
 	// Keyring is the keyring file used for verification.
 	Keyring string
+	// SBOM indicates that the software bill of materials published alongside
+	// the chart should be downloaded as well.
+	SBOM bool
 	// Getter collection for the operation
 	Getters getter.Providers
 	// Options provide parameters to be passed along to the Getter being initialized.
@@ -80,6 +84,7 @@ type ChartDownloader struct {
 // If Verify is set to VerifyIfPossible, this will return a verification (or nil on failure), and print a warning on failure.
 // If Verify is set to VerifyAlways, this will return a verification or an error if the verification fails.
 // If Verify is set to VerifyLater, this will download the prov file (if it exists), but not verify it.
+// If SBOM is set, the chart's SBOM is downloaded next to it as a ".sbom" file and a missing SBOM is an error.
 //
 // For VerifyNever and VerifyIfPossible, the Verification may be empty.
 //
@@ -111,6 +116,12 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 		return destfile, nil, err
 	}
 
+	if c.SBOM {
+		if err := c.downloadSBOM(g, u, version, destfile); err != nil {
+			return destfile, nil, err
+		}
+	}
+
 	// If provenance is requested, verify it.
 	ver := &provenance.Verification{}
 	if c.Verify > VerifyNever {
@@ -139,6 +150,32 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 	return destfile, ver, nil
 }
 
+// downloadSBOM writes the software bill of materials of the chart at u next to
+// destfile. Charts in OCI registries have it attached as a referrer, charts in
+// repositories as a sibling ".sbom" file.
+func (c *ChartDownloader) downloadSBOM(g getter.Getter, u *url.URL, version, destfile string) error {
+	var body []byte
+	if u.Scheme == "oci" {
+		if c.RegistryClient == nil {
+			return errors.New("a registry client is required to fetch the SBOM of an OCI chart")
+		}
+		ref, err := registry.ParseReference(fmt.Sprintf("%s:%s", strings.TrimPrefix(u.String(), "oci://"), version))
+		if err != nil {
+			return err
+		}
+		if body, err = c.RegistryClient.PullSBOM(ref); err != nil {
+			return errors.Wrapf(err, "failed to fetch SBOM for %q", u.String())
+		}
+	} else {
+		buf, err := g.Get(u.String() + ".sbom")
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch SBOM %q", u.String()+".sbom")
+		}
+		body = buf.Bytes()
+	}
+	return fileutil.AtomicWriteFile(destfile+".sbom", bytes.NewReader(body), 0644)
+}
+
 // ResolveChartVersion resolves a chart reference to a URL.
 //
 // It returns the URL and sets the ChartDownloader's Options that can fetch
@@ -197,6 +234,9 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 				getter.WithBasicAuth(rc.Username, rc.Password),
 			)
 		}
+		if rc.Proxy != "" || rc.NoProxy != "" {
+			c.Options = append(c.Options, getter.WithProxy(rc.Proxy, rc.NoProxy))
+		}
 		return u, nil
 	}
 
@@ -226,6 +266,9 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 		if r.Config.Username != "" && r.Config.Password != "" {
 			c.Options = append(c.Options, getter.WithBasicAuth(r.Config.Username, r.Config.Password))
 		}
+		if r.Config.Proxy != "" || r.Config.NoProxy != "" {
+			c.Options = append(c.Options, getter.WithProxy(r.Config.Proxy, r.Config.NoProxy))
+		}
 	}
 
 	// Next, we need to load the index, and actually look up the chart.
@@ -356,3 +356,42 @@ func TestScanReposForURL(t *testing.T) {
 		t.Fatalf("expected ErrNoOwnerRepo, got %v", err)
 	}
 }
+
+func TestDownloadTo_SBOM(t *testing.T) {
+	defer ensure.HelmHome(t)()
+
+	dest := ensure.TempDir(t)
+	defer os.RemoveAll(dest)
+
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := ChartDownloader{
+		Out:              os.Stderr,
+		SBOM:             true,
+		RepositoryConfig: repoConfig,
+		RepositoryCache:  repoCache,
+		Getters: getter.All(&cli.EnvSettings{
+			RepositoryConfig: repoConfig,
+			RepositoryCache:  repoCache,
+		}),
+	}
+	cname := "/local-subchart-0.1.0.tgz"
+	if _, _, err := c.DownloadTo(srv.URL()+cname, "", dest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, cname+".sbom")); err != nil {
+		t.Fatal(err)
+	}
+
+	// signtest is not published with an SBOM
+	if _, _, err := c.DownloadTo(srv.URL()+"/signtest-0.1.0.tgz", "", dest); err == nil {
+		t.Error("expected an error fetching a missing SBOM")
+	}
+}
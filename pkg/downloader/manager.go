@@ -16,6 +16,8 @@ limitations under the License.
 package downloader
 
 import (
+	"bytes"
+	"context"
 	"crypto"
 	"encoding/hex"
 	"fmt"
@@ -32,9 +34,11 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/internal/experimental/registry"
+	"helm.sh/helm/v3/internal/fileutil"
 	"helm.sh/helm/v3/internal/resolver"
 	"helm.sh/helm/v3/internal/third_party/dep/fs"
 	"helm.sh/helm/v3/internal/urlutil"
@@ -43,6 +47,7 @@ import (
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/provenance"
 	"helm.sh/helm/v3/pkg/repo"
 )
 
@@ -57,6 +62,10 @@ func (e ErrRepoNotFound) Error() string {
 	return fmt.Sprintf("no repository definition for %s", strings.Join(e.Repos, ", "))
 }
 
+// DefaultConcurrency is the number of dependencies downloaded in parallel
+// when Manager.Concurrency is not set.
+const DefaultConcurrency = 4
+
 // Manager handles the lifecycle of fetching, resolving, and storing dependencies.
 type Manager struct {
 	// Out is used to print warnings and notifications.
@@ -76,6 +85,9 @@ type Manager struct {
 	RegistryClient   *registry.Client
 	RepositoryConfig string
 	RepositoryCache  string
+	// Concurrency is the maximum number of dependencies downloaded at once.
+	// If it is less than 1, DefaultConcurrency is used.
+	Concurrency int
 }
 
 // Build rebuilds a local charts directory from a lockfile.
@@ -268,7 +280,9 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 
 	fmt.Fprintf(m.Out, "Saving %d charts\n", len(deps))
 	var saveError error
-	churls := make(map[string]struct{})
+	var downloads []chartDownload
+	out := &syncWriter{w: m.Out}
+	churls := make(map[string]int)
 	for _, dep := range deps {
 		// No repository means the chart is in charts directory
 		if dep.Repository == "" {
@@ -316,15 +330,17 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 			break
 		}
 
-		if _, ok := churls[churl]; ok {
+		if i, ok := churls[churl]; ok {
 			fmt.Fprintf(m.Out, "Already downloaded %s from repo %s\n", dep.Name, dep.Repository)
+			downloads[i].deps = append(downloads[i].deps, dep)
 			continue
 		}
+		churls[churl] = len(downloads)
 
 		fmt.Fprintf(m.Out, "Downloading %s from repo %s\n", dep.Name, dep.Repository)
 
 		dl := ChartDownloader{
-			Out:              m.Out,
+			Out:              out,
 			Verify:           m.Verify,
 			Keyring:          m.Keyring,
 			RepositoryConfig: m.RepositoryConfig,
@@ -346,18 +362,18 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 			if err != nil {
 				return errors.Wrapf(err, "could not parse OCI reference")
 			}
-			dl.Options = append(dl.Options,
-				getter.WithRegistryClient(m.RegistryClient),
-				getter.WithTagName(version))
 		}
 
-		_, _, err = dl.DownloadTo(churl, version, destPath)
-		if err != nil {
-			saveError = errors.Wrapf(err, "could not download %s", churl)
-			break
-		}
+		downloads = append(downloads, chartDownload{
+			url:     churl,
+			version: version,
+			deps:    []*chart.Dependency{dep},
+			dl:      dl,
+		})
+	}
 
-		churls[churl] = struct{}{}
+	if saveError == nil {
+		saveError = m.downloadCharts(downloads, destPath)
 	}
 
 	if saveError == nil {
@@ -395,6 +411,105 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 	return nil
 }
 
+// chartDownload is a remote chart queued for download by downloadAll, along
+// with the dependencies that resolved to it.
+type chartDownload struct {
+	url     string
+	version string
+	deps    []*chart.Dependency
+	dl      ChartDownloader
+}
+
+// downloadCharts downloads the queued charts into dest, running at most
+// m.Concurrency downloads at a time.
+//
+// The first failure stops any downloads that have not started yet and is
+// returned once the running ones have finished.
+func (m *Manager) downloadCharts(downloads []chartDownload, dest string) error {
+	limit := m.Concurrency
+	if limit < 1 {
+		limit = DefaultConcurrency
+	}
+
+	g, ctx := errgroup.WithContext(context.Background())
+	sem := make(chan struct{}, limit)
+loop:
+	for _, d := range downloads {
+		d := d
+		select {
+		case <-ctx.Done():
+			break loop
+		case sem <- struct{}{}:
+		}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return nil
+			}
+			if strings.HasPrefix(d.url, "oci://") {
+				return m.pullOCIChart(d, dest)
+			}
+			if _, _, err := d.dl.DownloadTo(d.url, d.version, dest); err != nil {
+				return errors.Wrapf(err, "could not download %s", d.url)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// pullOCIChart pulls a chart from an OCI registry into dest and records the
+// digest of the pulled archive on the dependencies that resolved to it.
+//
+// A dependency that already carries a digest, as it does when built from a
+// lock file, must match the pulled archive.
+func (m *Manager) pullOCIChart(d chartDownload, dest string) error {
+	if m.RegistryClient == nil {
+		return errors.Errorf("could not download %s: no registry client configured", d.url)
+	}
+
+	ref, err := registry.ParseReference(fmt.Sprintf("%s:%s", strings.TrimPrefix(d.url, "oci://"), d.version))
+	if err != nil {
+		return errors.Wrapf(err, "could not parse OCI reference")
+	}
+	buf, err := m.RegistryClient.PullChart(ref)
+	if err != nil {
+		return errors.Wrapf(err, "could not download %s", d.url)
+	}
+
+	sum, err := provenance.Digest(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	digest := "sha256:" + sum
+	for _, dep := range d.deps {
+		if dep.Digest != "" && dep.Digest != digest {
+			return errors.Errorf("digest of %s:%s is %s, but the lock file expects %s", d.url, d.version, digest, dep.Digest)
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s.tgz", path.Base(ref.Repo), d.version)
+	if err := fileutil.AtomicWriteFile(filepath.Join(dest, name), buf, 0644); err != nil {
+		return err
+	}
+	for _, dep := range d.deps {
+		dep.Digest = digest
+	}
+	return nil
+}
+
+// syncWriter serializes writes from concurrent downloads to the same writer.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
 func parseOCIRef(chartRef string) (string, string, error) {
 	refTagRegexp := regexp.MustCompile(`^(oci://[^:]+(:[0-9]{1,5})?[^:]+):(.*)$`)
 	caps := refTagRegexp.FindStringSubmatch(chartRef)
@@ -555,8 +670,7 @@ func (m *Manager) resolveRepoNames(deps []*chart.Dependency) (map[string]string,
 	missing := []string{}
 	for _, dd := range deps {
 		// Don't map the repository, we don't need to download chart from charts directory
-		// When OCI is used there is no Helm repository
-		if dd.Repository == "" || strings.HasPrefix(dd.Repository, "oci://") {
+		if dd.Repository == "" {
 			continue
 		}
 		// if dep chart is from local path, verify the path is valid
@@ -572,6 +686,9 @@ func (m *Manager) resolveRepoNames(deps []*chart.Dependency) (map[string]string,
 			continue
 		}
 
+		// When OCI is used there is no Helm repository to look up. The
+		// registry is mapped so the resolver and ensureMissingRepos treat
+		// it as known.
 		if strings.HasPrefix(dd.Repository, "oci://") {
 			reposMap[dd.Name] = dd.Repository
 			continue
@@ -17,13 +17,17 @@ package downloader
 
 import (
 	"bytes"
+	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
 
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/provenance"
 	"helm.sh/helm/v3/pkg/repo/repotest"
 )
 
@@ -431,6 +435,173 @@ func TestBuild_WithRepositoryAlias(t *testing.T) {
 	})
 }
 
+func TestUpdateConcurrentDownloads(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		t.Run(fmt.Sprintf("concurrency-%d", concurrency), func(t *testing.T) {
+			srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer srv.Stop()
+			if err := srv.LinkIndices(); err != nil {
+				t.Fatal(err)
+			}
+			dir := func(p ...string) string {
+				return filepath.Join(append([]string{srv.Root()}, p...)...)
+			}
+
+			c := &chart.Chart{
+				Metadata: &chart.Metadata{
+					Name:       "with-dependencies",
+					Version:    "0.1.0",
+					APIVersion: "v2",
+					Dependencies: []*chart.Dependency{
+						{Name: "signtest", Version: "0.1.0", Repository: srv.URL()},
+						{Name: "local-subchart", Version: "0.1.0", Repository: srv.URL()},
+					},
+				},
+			}
+			if err := chartutil.SaveDir(c, dir()); err != nil {
+				t.Fatal(err)
+			}
+
+			g := getter.Providers{getter.Provider{
+				Schemes: []string{"http", "https"},
+				New:     getter.NewHTTPGetter,
+			}}
+			m := &Manager{
+				ChartPath:        dir(c.Metadata.Name),
+				Out:              bytes.NewBuffer(nil),
+				Getters:          g,
+				RepositoryConfig: dir("repositories.yaml"),
+				RepositoryCache:  dir(),
+				Concurrency:      concurrency,
+			}
+			if err := m.Update(); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, name := range []string{"signtest-0.1.0.tgz", "local-subchart-0.1.0.tgz"} {
+				if _, err := os.Stat(dir(c.Metadata.Name, "charts", name)); err != nil {
+					t.Errorf("expected %s to be downloaded: %s", name, err)
+				}
+			}
+
+			updated, err := loader.LoadDir(dir(c.Metadata.Name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, dep := range updated.Lock.Dependencies {
+				names = append(names, dep.Name)
+			}
+			if expect := []string{"signtest", "local-subchart"}; !reflect.DeepEqual(names, expect) {
+				t.Errorf("expected lock dependencies %v, got %v", expect, names)
+			}
+		})
+	}
+}
+
+func TestUpdateWithOCIDependency(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+	dir := func(p ...string) string {
+		return filepath.Join(append([]string{srv.Root()}, p...)...)
+	}
+
+	ociSrv, err := repotest.NewOCIServer(t, srv.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ociChart := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "oci-dependent-chart",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+		},
+	}
+	if _, err := chartutil.Save(ociChart, ociSrv.Dir); err != nil {
+		t.Fatal(err)
+	}
+	ociSrv.Run(t)
+
+	if err := os.Setenv("HELM_EXPERIMENTAL_OCI", "1"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("HELM_EXPERIMENTAL_OCI")
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "with-oci-dependency",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{
+				{Name: "local-subchart", Version: "0.1.0", Repository: srv.URL()},
+				{Name: "oci-dependent-chart", Version: "0.1.0", Repository: fmt.Sprintf("oci://%s/u/ocitestuser", ociSrv.RegistryURL)},
+			},
+		},
+	}
+	if err := chartutil.SaveDir(c, dir()); err != nil {
+		t.Fatal(err)
+	}
+
+	g := getter.Providers{getter.Provider{
+		Schemes: []string{"http", "https"},
+		New:     getter.NewHTTPGetter,
+	}}
+	m := &Manager{
+		ChartPath:        dir(c.Metadata.Name),
+		Out:              bytes.NewBuffer(nil),
+		Getters:          g,
+		RegistryClient:   ociSrv.Client,
+		RepositoryConfig: dir("repositories.yaml"),
+		RepositoryCache:  dir(),
+	}
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"local-subchart-0.1.0.tgz", "oci-dependent-chart-0.1.0.tgz"} {
+		if _, err := os.Stat(dir(c.Metadata.Name, "charts", name)); err != nil {
+			t.Errorf("expected %s to be downloaded: %s", name, err)
+		}
+	}
+
+	sum, err := provenance.DigestFile(dir(c.Metadata.Name, "charts", "oci-dependent-chart-0.1.0.tgz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := loader.LoadDir(dir(c.Metadata.Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dep := range updated.Lock.Dependencies {
+		switch dep.Name {
+		case "local-subchart":
+			if dep.Digest != "" {
+				t.Errorf("expected no digest for %s, got %s", dep.Name, dep.Digest)
+			}
+		case "oci-dependent-chart":
+			if expect := "sha256:" + sum; dep.Digest != expect {
+				t.Errorf("expected digest %s for %s, got %s", expect, dep.Name, dep.Digest)
+			}
+		default:
+			t.Errorf("unexpected lock dependency %s", dep.Name)
+		}
+	}
+
+	// Building from the lock file pulls the same archive again.
+	if err := m.Build(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestErrRepoNotFound_Error(t *testing.T) {
 	type fields struct {
 		Repos []string
@@ -17,12 +17,14 @@ limitations under the License.
 package engine
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -40,8 +42,28 @@ type Engine struct {
 	Strict bool
 	// In LintMode, some 'required' template values may be missing, so don't fail
 	LintMode bool
+	// ErrorContextLines is the number of template source lines shown before and
+	// after the line a rendering error points at. Zero disables the snippet.
+	ErrorContextLines int
+	// MaxFileSize is the size in bytes above which the b64file function
+	// refuses to embed a chart file. Zero uses DefaultMaxFileSize and a
+	// negative size disables the limit.
+	MaxFileSize int64
+	// ContinueOnError keeps rendering the other templates when one fails to
+	// parse or execute. Render then returns the templates that rendered along
+	// with a RenderErrors holding the error of every template that did not.
+	ContinueOnError bool
 	// the rest config to connect to the kubernetes api
 	config *rest.Config
+	// ctx aborts the render when done. See RenderWithContext.
+	ctx context.Context
+}
+
+// New creates a new instance of Engine using the passed in rest config.
+func New(config *rest.Config) Engine {
+	return Engine{
+		config: config,
+	}
 }
 
 // Render takes a chart, optional values, and value overrides, and attempts to render the Go templates.
@@ -68,6 +90,48 @@ func (e Engine) Render(chrt *chart.Chart, values chartutil.Values) (map[string]s
 	return e.render(tmap)
 }
 
+// RenderWithContext is Render, giving up when ctx is done, such as when its
+// deadline passes. It then returns the error of ctx.
+//
+// The include, tpl and lookup functions, and the calls lookup makes to the
+// cluster, stop as soon as ctx is done, and so does the render between
+// templates. A template that loops without calling any of them cannot be
+// interrupted, and keeps running in the background after RenderWithContext
+// returns, until it completes.
+func (e Engine) RenderWithContext(ctx context.Context, chrt *chart.Chart, values chartutil.Values) (map[string]string, error) {
+	e.ctx = ctx
+
+	type result struct {
+		rendered map[string]string
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		rendered, err := e.Render(chrt, values)
+		done <- result{rendered, err}
+	}()
+
+	select {
+	case r := <-done:
+		// an aborted render fails in whichever template noticed it first
+		if ctx.Err() != nil {
+			return map[string]string{}, errors.Wrap(ctx.Err(), "rendering aborted")
+		}
+		return r.rendered, r.err
+	case <-ctx.Done():
+		return map[string]string{}, errors.Wrap(ctx.Err(), "rendering aborted")
+	}
+}
+
+// renderContext returns the context of the render, which is never done unless set
+// by RenderWithContext.
+func (e Engine) renderContext() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
 // Render takes a chart, optional values, and value overrides, and attempts to
 // render the Go templates using the default options.
 func Render(chrt *chart.Chart, values chartutil.Values) (map[string]string, error) {
@@ -78,9 +142,7 @@ func Render(chrt *chart.Chart, values chartutil.Values) (map[string]string, erro
 // render the Go templates using the default options. This engine is client aware and so can have template
 // functions that interact with the client
 func RenderWithClient(chrt *chart.Chart, values chartutil.Values, config *rest.Config) (map[string]string, error) {
-	return Engine{
-		config: config,
-	}.Render(chrt, values)
+	return New(config).Render(chrt, values)
 }
 
 // renderable is an object that can be rendered.
@@ -110,6 +172,9 @@ func (e Engine) initFunMap(t *template.Template, referenceTpls map[string]render
 
 	// Add the 'include' function here so we can close over t.
 	funcMap["include"] = func(name string, data interface{}) (string, error) {
+		if err := e.renderContext().Err(); err != nil {
+			return "", err
+		}
 		var buf strings.Builder
 		if v, ok := includedNames[name]; ok {
 			if v > recursionMaxNums {
@@ -126,6 +191,9 @@ func (e Engine) initFunMap(t *template.Template, referenceTpls map[string]render
 
 	// Add the 'tpl' function here
 	funcMap["tpl"] = func(tpl string, vals chartutil.Values) (string, error) {
+		if err := e.renderContext().Err(); err != nil {
+			return "", err
+		}
 		basePath, err := vals.PathValue("Template.BasePath")
 		if err != nil {
 			return "", errors.Wrapf(err, "cannot retrieve Template.Basepath from values inside tpl function: %s", tpl)
@@ -173,10 +241,19 @@ func (e Engine) initFunMap(t *template.Template, referenceTpls map[string]render
 		return val, nil
 	}
 
+	// Add the 'b64file' function here so it uses the engine's size limit
+	funcMap["b64file"] = func(f files, name string) (string, error) {
+		limit := e.MaxFileSize
+		if limit == 0 {
+			limit = DefaultMaxFileSize
+		}
+		return base64File(f, name, limit)
+	}
+
 	// If we are not linting and have a cluster connection, provide a Kubernetes-backed
 	// implementation.
 	if !e.LintMode && e.config != nil {
-		funcMap["lookup"] = NewLookupFunction(e.config)
+		funcMap["lookup"] = newContextLookupFunction(e.renderContext(), e.config)
 	}
 
 	t.Funcs(funcMap)
@@ -218,20 +295,32 @@ func (e Engine) renderWithReferences(tpls, referenceTpls map[string]renderable)
 	keys := sortTemplates(tpls)
 	referenceKeys := sortTemplates(referenceTpls)
 
+	var errs RenderErrors
+	// failed holds the templates that did not parse when ContinueOnError is set
+	failed := map[string]bool{}
 	for _, filename := range keys {
 		r := tpls[filename]
 		if _, err := t.New(filename).Parse(r.tpl); err != nil {
-			return map[string]string{}, cleanupParseError(filename, err)
+			err = e.withSourceContext(cleanupParseError(filename, err), err, referenceTpls)
+			if !e.ContinueOnError {
+				return map[string]string{}, err
+			}
+			errs = append(errs, err)
+			failed[filename] = true
 		}
 	}
 
 	// Adding the reference templates to the template context
 	// so they can be referenced in the tpl function
 	for _, filename := range referenceKeys {
-		if t.Lookup(filename) == nil {
+		if t.Lookup(filename) == nil && !failed[filename] {
 			r := referenceTpls[filename]
 			if _, err := t.New(filename).Parse(r.tpl); err != nil {
-				return map[string]string{}, cleanupParseError(filename, err)
+				err = e.withSourceContext(cleanupParseError(filename, err), err, referenceTpls)
+				if !e.ContinueOnError {
+					return map[string]string{}, err
+				}
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -240,15 +329,23 @@ func (e Engine) renderWithReferences(tpls, referenceTpls map[string]renderable)
 	for _, filename := range keys {
 		// Don't render partials. We don't care out the direct output of partials.
 		// They are only included from other templates.
-		if strings.HasPrefix(path.Base(filename), "_") {
+		if strings.HasPrefix(path.Base(filename), "_") || failed[filename] {
 			continue
 		}
+		if err := e.renderContext().Err(); err != nil {
+			return map[string]string{}, err
+		}
 		// At render time, add information about the template that is being rendered.
 		vals := tpls[filename].vals
 		vals["Template"] = chartutil.Values{"Name": filename, "BasePath": tpls[filename].basePath}
 		var buf strings.Builder
 		if err := t.ExecuteTemplate(&buf, filename, vals); err != nil {
-			return map[string]string{}, cleanupExecError(filename, err)
+			err = e.withSourceContext(cleanupExecError(filename, err), err, referenceTpls)
+			if !e.ContinueOnError {
+				return map[string]string{}, err
+			}
+			errs = append(errs, err)
+			continue
 		}
 
 		// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
@@ -257,9 +354,24 @@ func (e Engine) renderWithReferences(tpls, referenceTpls map[string]renderable)
 		rendered[filename] = strings.ReplaceAll(buf.String(), "<no value>", "")
 	}
 
+	if len(errs) > 0 {
+		return rendered, errs
+	}
 	return rendered, nil
 }
 
+// RenderErrors holds the errors of all the templates that failed to render
+// when Engine.ContinueOnError is set.
+type RenderErrors []error
+
+func (e RenderErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
 func cleanupParseError(filename string, err error) error {
 	tokens := strings.Split(err.Error(), ": ")
 	if len(tokens) == 1 {
@@ -297,6 +409,55 @@ func cleanupExecError(filename string, err error) error {
 	return err
 }
 
+// templateErrorLocation matches the template name and line number that Go
+// template errors point at.
+var templateErrorLocation = regexp.MustCompile(`template: ([^:\s]+):(\d+)`)
+
+// withSourceContext appends to err the source lines around the line that the
+// original template error raw points at, when e.ErrorContextLines is positive.
+// When templates include each other, the innermost location is used.
+func (e Engine) withSourceContext(err, raw error, tpls map[string]renderable) error {
+	if e.ErrorContextLines <= 0 {
+		return err
+	}
+	var (
+		name string
+		r    renderable
+		line int
+	)
+	matches := templateErrorLocation.FindAllStringSubmatch(raw.Error(), -1)
+	for i := len(matches) - 1; i >= 0 && name == ""; i-- {
+		if t, ok := tpls[matches[i][1]]; ok {
+			name, r = matches[i][1], t
+			line, _ = strconv.Atoi(matches[i][2])
+		}
+	}
+
+	lines := strings.Split(r.tpl, "\n")
+	if name == "" || line < 1 || line > len(lines) {
+		return err
+	}
+	first, last := line-e.ErrorContextLines, line+e.ErrorContextLines
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n\n%s:\n", err, name)
+	for i := first; i <= last; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&sb, "%s %*d | %s\n", marker, width, i, lines[i-1])
+	}
+	return errors.New(strings.TrimSuffix(sb.String(), "\n"))
+}
+
 func sortTemplates(tpls map[string]renderable) []string {
 	keys := make([]string, len(tpls))
 	i := 0
@@ -357,7 +518,7 @@ func recAllTpls(c *chart.Chart, templates map[string]renderable, vals chartutil.
 
 	newParentID := c.ChartFullPath()
 	for _, t := range c.Templates {
-		if !isTemplateValid(c, t.Name) {
+		if !isTemplateValid(c, t.Name) || isComponentDisabled(c, t.Name, next["Values"]) {
 			continue
 		}
 		templates[path.Join(newParentID, t.Name)] = renderable{
@@ -376,6 +537,38 @@ func isTemplateValid(ch *chart.Chart, templateName string) bool {
 	return true
 }
 
+// isComponentDisabled returns true if all the components the template belongs
+// to are disabled in the chart values. Partials are never skipped, as other
+// templates may include them.
+func isComponentDisabled(c *chart.Chart, templateName string, values interface{}) bool {
+	if strings.HasPrefix(path.Base(templateName), "_") {
+		return false
+	}
+	components := c.Metadata.TemplateComponents(templateName)
+	if len(components) == 0 {
+		return false
+	}
+
+	enabled := tableOf(tableOf(values)[chart.ComponentsEnabledKey])
+	for _, name := range components {
+		if on, ok := enabled[name].(bool); !ok || on {
+			return false
+		}
+	}
+	return true
+}
+
+// tableOf returns v as a table of values, or nil if it is not one.
+func tableOf(v interface{}) map[string]interface{} {
+	switch t := v.(type) {
+	case chartutil.Values:
+		return t
+	case map[string]interface{}:
+		return t
+	}
+	return nil
+}
+
 // isLibraryChart returns true if the chart is a library chart
 func isLibraryChart(c *chart.Chart) bool {
 	return strings.EqualFold(c.Metadata.Type, "library")
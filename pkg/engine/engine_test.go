@@ -17,10 +17,16 @@ limitations under the License.
 package engine
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
@@ -286,6 +292,82 @@ func TestExecErrors(t *testing.T) {
 	}
 }
 
+func TestContinueOnError(t *testing.T) {
+	tpls := func() map[string]renderable {
+		return map[string]renderable{
+			"good":    {tpl: `{{ "fine" }}`, vals: chartutil.Values{"Values": map[string]interface{}{}}},
+			"parse":   {tpl: `{{ .Values.foo | nosuchfunc }}`, vals: chartutil.Values{"Values": map[string]interface{}{}}},
+			"execute": {tpl: `{{ required "foo is required" .Values.foo }}`, vals: chartutil.Values{"Values": map[string]interface{}{}}},
+		}
+	}
+
+	// the default is to stop at the first error
+	out, err := new(Engine).render(tpls())
+	if err == nil {
+		t.Fatal("Expected failures while rendering")
+	}
+	if _, ok := err.(RenderErrors); ok {
+		t.Errorf("Expected a single error, got %q", err)
+	}
+	if len(out) != 0 {
+		t.Errorf("Expected no rendered templates, got %v", out)
+	}
+
+	out, err = Engine{ContinueOnError: true}.render(tpls())
+	errs, ok := err.(RenderErrors)
+	if !ok {
+		t.Fatalf("Expected RenderErrors, got %T: %v", err, err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %q", len(errs), err)
+	}
+	if !strings.Contains(errs[0].Error(), `parse error at (parse:1): function "nosuchfunc" not defined`) {
+		t.Errorf("Expected the parse error first, got %q", errs[0])
+	}
+	if expect := `execution error at (execute:1:3): foo is required`; errs[1].Error() != expect {
+		t.Errorf("Expected %q, got %q", expect, errs[1])
+	}
+	if expect := map[string]string{"good": "fine"}; !reflect.DeepEqual(out, expect) {
+		t.Errorf("Expected %v, got %v", expect, out)
+	}
+}
+
+func TestErrorContextLines(t *testing.T) {
+	vals := chartutil.Values{"Values": map[string]interface{}{}}
+	e := Engine{ErrorContextLines: 1}
+
+	tpls := map[string]renderable{
+		"parse": {tpl: "line one\nline two\n{{ .Values.foo | nosuchfunc }}\nline four\nline five", vals: vals},
+	}
+	_, err := e.render(tpls)
+	if err == nil {
+		t.Fatal("Expected failures while rendering")
+	}
+	expected := "parse:\n  2 | line two\n> 3 | {{ .Values.foo | nosuchfunc }}\n  4 | line four"
+	if !strings.HasSuffix(err.Error(), expected) {
+		t.Errorf("Expected the source snippet %q, got %q", expected, err.Error())
+	}
+
+	tpls = map[string]renderable{
+		"main":     {tpl: `{{ include "broken" . }}`, vals: vals},
+		"_helpers": {tpl: "{{- define \"broken\" -}}\n{{ required \"foo is required\" .Values.foo }}\n{{- end -}}", vals: vals},
+	}
+	_, err = e.render(tpls)
+	if err == nil {
+		t.Fatal("Expected failures while rendering")
+	}
+	expected = "_helpers:\n  1 | {{- define \"broken\" -}}\n> 2 | {{ required \"foo is required\" .Values.foo }}\n  3 | {{- end -}}"
+	if !strings.HasSuffix(err.Error(), expected) {
+		t.Errorf("Expected the source snippet %q, got %q", expected, err.Error())
+	}
+
+	// without context lines the error is unchanged
+	_, err = new(Engine).render(tpls)
+	if err == nil || strings.Contains(err.Error(), "_helpers:\n") {
+		t.Errorf("Expected no source snippet, got %v", err)
+	}
+}
+
 func TestAllTemplates(t *testing.T) {
 	ch1 := &chart.Chart{
 		Metadata: &chart.Metadata{Name: "ch1"},
@@ -738,3 +820,197 @@ func TestRenderRecursionLimit(t *testing.T) {
 	}
 
 }
+
+func TestRenderB64File(t *testing.T) {
+	binary := []byte{0x00, 0xff, '\r', '\n', 0x80}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/secret", Data: []byte(`{{ b64file .Files "files/blob.bin" }}`)},
+		},
+		Files: []*chart.File{
+			{Name: "files/blob.bin", Data: binary},
+		},
+	}
+	v, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Engine{}.Render(c, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := base64.StdEncoding.EncodeToString(binary); out["moby/templates/secret"] != expect {
+		t.Errorf("Expected %q, got %q", expect, out["moby/templates/secret"])
+	}
+
+	if _, err := (Engine{MaxFileSize: 4}).Render(c, v); err == nil {
+		t.Error("Expected an error embedding a file above MaxFileSize")
+	}
+}
+
+func TestRenderWithContext(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers", Data: []byte(`{{ define "whale" }}{{ range until 1000 }}.{{ end }}{{ end }}`)},
+			{Name: "templates/forever", Data: []byte(`{{ range until 100000000 }}{{ include "whale" . }}{{ end }}`)},
+		},
+	}
+	vals := map[string]interface{}{"Values": map[string]interface{}{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := Engine{}.RenderWithContext(ctx, c, vals)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the render to stop promptly, took %s", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+
+	// a render that completes in time is unaffected
+	c.Templates[1].Data = []byte(`{{ include "whale" . | len }}`)
+	out, err := Engine{}.RenderWithContext(context.Background(), c, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "1000"; out["moby/templates/forever"] != expect {
+		t.Errorf("Expected %q, got %q", expect, out["moby/templates/forever"])
+	}
+}
+
+func TestRenderTOML(t *testing.T) {
+	type server struct {
+		Host string `toml:"host"`
+		Port int    `toml:"port"`
+	}
+	type config struct {
+		Name   string `toml:"name"`
+		Server server `toml:"server"`
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/config", Data: []byte(`{{ toToml .Values.config }}`)},
+			{Name: "templates/roundtrip", Data: []byte(`{{ $c := fromToml (toToml .Values.config) }}{{ $c.name }} {{ $c.server.host }}:{{ $c.server.port }}`)},
+			{Name: "templates/error", Data: []byte(`{{ (fromToml "name = ").Error | empty | not }}`)},
+		},
+	}
+	vals := map[string]interface{}{
+		"Values": map[string]interface{}{
+			"config": config{Name: "pequod", Server: server{Host: "nantucket", Port: 1851}},
+		},
+	}
+
+	out, err := Render(c, vals)
+	if err != nil {
+		t.Fatalf("Failed to render templates: %s", err)
+	}
+
+	if got := out["moby/templates/config"]; !strings.Contains(got, `name = "pequod"`) || !strings.Contains(got, "[server]") || !strings.Contains(got, "port = 1851") {
+		t.Errorf("Expected the config rendered as TOML, got %q", got)
+	}
+	if expect, got := "pequod nantucket:1851", out["moby/templates/roundtrip"]; got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+	if expect, got := "true", out["moby/templates/error"]; got != expect {
+		t.Errorf("Expected the parse error in the result, got %q", got)
+	}
+}
+
+func TestRenderComponents(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "components",
+			Components: map[string][]string{
+				"metrics": {"templates/metrics/*", "templates/shared", "templates/_helpers.tpl"},
+				"ingress": {"templates/ingress", "templates/shared"},
+			},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "name" }}app{{ end }}`)},
+			{Name: "templates/deployment", Data: []byte(`{{ include "name" . }}`)},
+			{Name: "templates/ingress", Data: []byte("ingress")},
+			{Name: "templates/metrics/service", Data: []byte("metrics service")},
+			{Name: "templates/metrics/monitor", Data: []byte("metrics monitor")},
+			{Name: "templates/shared", Data: []byte("shared")},
+		},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "sub",
+			Components: map[string][]string{"jobs": {"templates/job"}},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/job", Data: []byte("job")},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		expected []string
+	}{
+		{
+			name:   "all enabled by default",
+			values: map[string]interface{}{},
+			expected: []string{
+				"components/charts/sub/templates/job",
+				"components/templates/deployment",
+				"components/templates/ingress",
+				"components/templates/metrics/monitor",
+				"components/templates/metrics/service",
+				"components/templates/shared",
+			},
+		},
+		{
+			name: "metrics disabled",
+			values: map[string]interface{}{
+				"componentsEnabled": map[string]interface{}{"metrics": false, "ingress": true},
+			},
+			expected: []string{
+				"components/charts/sub/templates/job",
+				"components/templates/deployment",
+				"components/templates/ingress",
+				"components/templates/shared",
+			},
+		},
+		{
+			name: "all disabled",
+			values: map[string]interface{}{
+				"componentsEnabled": map[string]interface{}{"metrics": false, "ingress": false},
+				"sub": map[string]interface{}{
+					"componentsEnabled": map[string]interface{}{"jobs": false},
+				},
+			},
+			expected: []string{
+				"components/templates/deployment",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Render(c, map[string]interface{}{"Values": tt.values})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for name := range out {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected templates %v, got %v", tt.expected, names)
+			}
+			if out["components/templates/deployment"] != "app" {
+				t.Errorf("Expected the helpers to be rendered, got %q", out["components/templates/deployment"])
+			}
+		})
+	}
+}
@@ -22,10 +22,15 @@ import (
 	"strings"
 
 	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/chart"
 )
 
+// DefaultMaxFileSize is the default size limit of the b64file function,
+// matching the largest Secret or ConfigMap the API server accepts.
+const DefaultMaxFileSize = 1 << 20
+
 // files is a map of files in a chart that can be accessed from a template.
 type files map[string][]byte
 
@@ -158,3 +163,23 @@ func (f files) Lines(path string) []string {
 
 	return strings.Split(string(f[path]), "\n")
 }
+
+// base64File returns the named file base64-encoded from its raw bytes, so
+// that binary content and line endings survive unchanged. An error is
+// returned for a missing file, or one larger than limit bytes when limit is
+// positive.
+//
+// This is the implementation of the b64file template function:
+//
+//   data:
+//     logo.png: {{ b64file .Files "files/logo.png" }}
+func base64File(f files, name string, limit int64) (string, error) {
+	data, ok := f[name]
+	if !ok {
+		return "", errors.Errorf("b64file: file %q not found in chart", name)
+	}
+	if limit > 0 && int64(len(data)) > limit {
+		return "", errors.Errorf("b64file: file %q is %d bytes, larger than the limit of %d bytes", name, len(data), limit)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package engine
 
 import (
+	"encoding/base64"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -96,3 +97,23 @@ func TestLines(t *testing.T) {
 
 	as.Equal("bar", out[0])
 }
+
+func TestBase64File(t *testing.T) {
+	as := assert.New(t)
+
+	// binary content with NUL bytes, invalid UTF-8 and CRLF line endings
+	binary := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff, 0xfe}
+	f := files{"files/logo.png": binary}
+
+	out, err := base64File(f, "files/logo.png", 0)
+	as.NoError(err)
+	decoded, err := base64.StdEncoding.DecodeString(out)
+	as.NoError(err)
+	as.Equal(binary, decoded)
+
+	_, err = base64File(f, "files/logo.png", int64(len(binary)-1))
+	as.Error(err, "expected the size limit to be enforced")
+
+	_, err = base64File(f, "files/missing.png", 0)
+	as.Error(err, "expected an error for a missing file")
+}
@@ -37,6 +37,7 @@ import (
 //
 //	- "include"
 //	- "tpl"
+//	- "b64file"
 //
 // These are late-bound in Engine.Render().  The
 // version included in the FuncMap is a placeholder.
@@ -49,6 +50,7 @@ func funcMap() template.FuncMap {
 	// Add some extra functionality
 	extra := template.FuncMap{
 		"toToml":        toTOML,
+		"fromToml":      fromTOML,
 		"toYaml":        toYAML,
 		"fromYaml":      fromYAML,
 		"fromYamlArray": fromYAMLArray,
@@ -62,8 +64,11 @@ func funcMap() template.FuncMap {
 		"include":  func(string, interface{}) string { return "not implemented" },
 		"tpl":      func(string, interface{}) interface{} { return "not implemented" },
 		"required": func(string, interface{}) (interface{}, error) { return "not implemented", nil },
+		"b64file":  func(interface{}, string) (string, error) { return "not implemented", nil },
 		// Provide a placeholder for the "lookup" function, which requires a kubernetes
-		// connection.
+		// connection. Without one, as in 'helm template', client-only dry runs and
+		// linting, every lookup returns an empty map, including lookups across all
+		// namespaces.
 		"lookup": func(string, string, string, string) (map[string]interface{}, error) {
 			return map[string]interface{}{}, nil
 		},
@@ -133,6 +138,21 @@ func toTOML(v interface{}) string {
 	return b.String()
 }
 
+// fromTOML converts a TOML document into a map[string]interface{}.
+//
+// This is not a general-purpose TOML parser, and will not parse all valid
+// TOML documents. Additionally, because its intended use is within templates
+// it tolerates errors. It will insert the returned error message string into
+// m["Error"] in the returned map.
+func fromTOML(str string) map[string]interface{} {
+	m := make(map[string]interface{})
+
+	if _, err := toml.Decode(str, &m); err != nil {
+		m["Error"] = err.Error()
+	}
+	return m
+}
+
 // toJSON takes an interface, marshals it to json, and returns a string. It will
 // always return a string, even on marshal error (empty string).
 //
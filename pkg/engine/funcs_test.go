@@ -58,6 +58,18 @@ func TestFuncs(t *testing.T) {
 		tpl:    `{{ toToml . }}`,
 		expect: "[mast]\n  sail = \"white\"\n",
 		vars:   map[string]map[string]string{"mast": {"sail": "white"}},
+	}, {
+		tpl:    `{{ fromToml . }}`,
+		expect: "map[hello:world]",
+		vars:   `hello = "world"`,
+	}, {
+		tpl:    `{{ (fromToml .).mast.sail }}`,
+		expect: "white",
+		vars:   "[mast]\n  sail = \"white\"\n",
+	}, {
+		tpl:    `{{ hasKey (fromToml .) "Error" }}`,
+		expect: "true",
+		vars:   `hello = world = "x"`,
 	}, {
 		tpl:    `{{ fromYaml . }}`,
 		expect: "map[Error:error unmarshaling JSON: while decoding JSON: json: cannot unmarshal array into Go value of type map[string]interface {}]",
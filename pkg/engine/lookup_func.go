@@ -32,27 +32,54 @@ import (
 
 type lookupFunc = func(apiversion string, resource string, namespace string, name string) (map[string]interface{}, error)
 
+// lookupAllNamespaces is the namespace wildcard that makes lookup search
+// every namespace.
+const lookupAllNamespaces = "*"
+
+// lookupClientFunc returns a dynamic client for a kind, and whether the kind
+// is namespaced.
+type lookupClientFunc = func(apiversion string, kind string) (dynamic.NamespaceableResourceInterface, bool, error)
+
 // NewLookupFunction returns a function for looking up objects in the cluster.
 //
 // If the resource does not exist, no error is raised.
 //
+// The namespace is ignored for cluster-scoped resources, so it may be left
+// empty for them. For namespaced resources, the namespace "*" searches every
+// namespace: with an empty name it returns the list of the resources in all
+// namespaces, whose "items" can be used with range, and with a name it
+// returns the list of the resources with that name in any namespace.
+//
 // This function is considered deprecated, and will be renamed in Helm 4. It will no
 // longer be a public function.
 func NewLookupFunction(config *rest.Config) lookupFunc {
+	return newContextLookupFunction(context.Background(), config)
+}
+
+// newContextLookupFunction is NewLookupFunction, making the calls to the
+// cluster with ctx so that they are abandoned when it is done.
+func newContextLookupFunction(ctx context.Context, config *rest.Config) lookupFunc {
+	return newLookupFunction(ctx, func(apiversion string, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+		return getDynamicClientOnKind(apiversion, kind, config)
+	})
+}
+
+func newLookupFunction(ctx context.Context, clientFor lookupClientFunc) lookupFunc {
 	return func(apiversion string, resource string, namespace string, name string) (map[string]interface{}, error) {
 		var client dynamic.ResourceInterface
-		c, namespaced, err := getDynamicClientOnKind(apiversion, resource, config)
+		c, namespaced, err := clientFor(apiversion, resource)
 		if err != nil {
 			return map[string]interface{}{}, err
 		}
-		if namespaced && namespace != "" {
+		allNamespaces := namespaced && namespace == lookupAllNamespaces
+		if namespaced && namespace != "" && !allNamespaces {
 			client = c.Namespace(namespace)
 		} else {
 			client = c
 		}
-		if name != "" {
+		if name != "" && !allNamespaces {
 			// this will return a single object
-			obj, err := client.Get(context.Background(), name, metav1.GetOptions{})
+			obj, err := client.Get(ctx, name, metav1.GetOptions{})
 			if err != nil {
 				if apierrors.IsNotFound(err) {
 					// Just return an empty interface when the object was not found.
@@ -64,7 +91,7 @@ func NewLookupFunction(config *rest.Config) lookupFunc {
 			return obj.UnstructuredContent(), nil
 		}
 		//this will return a list
-		obj, err := client.List(context.Background(), metav1.ListOptions{})
+		obj, err := client.List(ctx, metav1.ListOptions{})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				// Just return an empty interface when the object was not found.
@@ -73,6 +100,16 @@ func NewLookupFunction(config *rest.Config) lookupFunc {
 			}
 			return map[string]interface{}{}, err
 		}
+		if name != "" {
+			// keep only the objects with the name, in whichever namespace they are
+			items := obj.Items[:0]
+			for _, item := range obj.Items {
+				if item.GetName() == name {
+					items = append(items, item)
+				}
+			}
+			obj.Items = items
+		}
 		return obj.UnstructuredContent(), nil
 	}
 }
@@ -0,0 +1,139 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func lookupObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func fakeLookupFunction() lookupFunc {
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "pods"}:       "PodList",
+		{Version: "v1", Resource: "namespaces"}: "NamespaceList",
+	}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		lookupObject("v1", "Pod", "alpha", "web"),
+		lookupObject("v1", "Pod", "alpha", "db"),
+		lookupObject("v1", "Pod", "beta", "web"),
+		lookupObject("v1", "Namespace", "", "alpha"),
+		lookupObject("v1", "Namespace", "", "beta"),
+	)
+	resources := map[string]struct {
+		resource   string
+		namespaced bool
+	}{
+		"Pod":       {"pods", true},
+		"Namespace": {"namespaces", false},
+	}
+	return newLookupFunction(context.Background(), func(apiversion string, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+		r := resources[kind]
+		gvr := schema.GroupVersionResource{Version: apiversion, Resource: r.resource}
+		return client.Resource(gvr), r.namespaced, nil
+	})
+}
+
+// lookupItems returns the namespace/name of every item of a listed lookup result.
+func lookupItems(t *testing.T, result map[string]interface{}) []string {
+	t.Helper()
+	items, ok := result["items"].([]interface{})
+	if !ok {
+		t.Fatalf("expected a list with items, got %v", result)
+	}
+	var names []string
+	for _, item := range items {
+		obj := unstructured.Unstructured{Object: item.(map[string]interface{})}
+		names = append(names, obj.GetNamespace()+"/"+obj.GetName())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestLookupFunction(t *testing.T) {
+	lookup := fakeLookupFunction()
+
+	obj, err := lookup("v1", "Pod", "beta", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (&unstructured.Unstructured{Object: obj}).GetNamespace(); got != "beta" {
+		t.Errorf("expected the pod in namespace beta, got %q", got)
+	}
+
+	obj, err = lookup("v1", "Pod", "beta", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(obj) != 0 {
+		t.Errorf("expected an empty result for a missing pod, got %v", obj)
+	}
+
+	obj, err = lookup("v1", "Namespace", "", "alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (&unstructured.Unstructured{Object: obj}).GetName(); got != "alpha" {
+		t.Errorf("expected the namespace alpha, got %q", got)
+	}
+}
+
+func TestLookupFunctionAllNamespaces(t *testing.T) {
+	lookup := fakeLookupFunction()
+
+	tests := []struct {
+		name      string
+		kind      string
+		namespace string
+		resName   string
+		expect    []string
+	}{
+		{"one namespace", "Pod", "alpha", "", []string{"alpha/db", "alpha/web"}},
+		{"all namespaces", "Pod", "*", "", []string{"alpha/db", "alpha/web", "beta/web"}},
+		{"by name in all namespaces", "Pod", "*", "web", []string{"alpha/web", "beta/web"}},
+		{"missing name in all namespaces", "Pod", "*", "cache", nil},
+		{"cluster-scoped with wildcard", "Namespace", "*", "", []string{"/alpha", "/beta"}},
+		{"cluster-scoped without namespace", "Namespace", "", "", []string{"/alpha", "/beta"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := lookup("v1", tt.kind, tt.namespace, tt.resName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lookupItems(t, result); !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("expected %v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
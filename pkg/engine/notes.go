@@ -0,0 +1,53 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"path"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// notesFileName is the name of the template rendered as the release notes.
+const notesFileName = "NOTES.txt"
+
+// RenderNotes renders only the NOTES.txt template of the top level chart.
+//
+// The other templates of the chart and its dependencies are parsed so that
+// NOTES.txt can include the named templates they define, but they are not
+// executed. A chart without a NOTES.txt renders to an empty string without
+// an error.
+func (e Engine) RenderNotes(chrt *chart.Chart, values chartutil.Values) (string, error) {
+	tpls := allTemplates(chrt, values)
+	name := path.Join(chrt.ChartFullPath(), "templates", notesFileName)
+	notes, ok := tpls[name]
+	if !ok {
+		return "", nil
+	}
+	rendered, err := e.renderWithReferences(map[string]renderable{name: notes}, tpls)
+	if err != nil {
+		return "", err
+	}
+	return rendered[name], nil
+}
+
+// RenderNotes renders the NOTES.txt template of a chart using the default
+// options.
+func RenderNotes(chrt *chart.Chart, values chartutil.Values) (string, error) {
+	return new(Engine).RenderNotes(chrt, values)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestRenderNotes(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{define "moby.name"}}{{.Values.name | title}}{{end}}`)},
+			{Name: "templates/NOTES.txt", Data: []byte(`Installed {{include "moby.name" .}}`)},
+			{Name: "templates/broken.yaml", Data: []byte(`{{fail "not rendered"}}`)},
+		},
+		Values: map[string]interface{}{"name": "dick"},
+	}
+
+	v, err := chartutil.CoalesceValues(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := RenderNotes(c, chartutil.Values{"Values": v})
+	if err != nil {
+		t.Fatalf("failed to render notes: %s", err)
+	}
+	if out != "Installed Dick" {
+		t.Errorf("expected %q, got %q", "Installed Dick", out)
+	}
+
+	c.Templates[1].Data = []byte(`{{.Values.name | nosuchfunc}}`)
+	if _, err := RenderNotes(c, chartutil.Values{"Values": v}); err == nil || !strings.Contains(err.Error(), "NOTES.txt") {
+		t.Errorf("expected an error about NOTES.txt, got %v", err)
+	}
+
+	c.Templates = c.Templates[:1]
+	out, err = RenderNotes(c, chartutil.Values{"Values": v})
+	if err != nil || out != "" {
+		t.Errorf("expected no notes and no error for a chart without NOTES.txt, got %q, %v", out, err)
+	}
+}
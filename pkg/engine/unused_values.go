@@ -0,0 +1,275 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// UnusedValues returns the dotted paths of the values set in the values.yaml
+// of c that no template references, sorted.
+//
+// The templates of c and of its subcharts are parsed and the .Values paths
+// they read are collected, following the dot through with blocks and
+// variables. A subchart reads the values of c under its name, and the
+// globals of c. The conditions and tags of the dependencies count as
+// references too. Values that are only reached in ways that cannot be
+// followed, such as with a computed key in index or through tpl, are
+// reported as unused, while passing a whole map, as in toYaml .Values.x,
+// uses all of its keys.
+func UnusedValues(c *chart.Chart) ([]string, error) {
+	refs := make(map[string]bool)
+	if err := recValuesRefs(c, nil, refs); err != nil {
+		return nil, err
+	}
+
+	var unused []string
+	for _, key := range valuesLeaves(c.Values, "") {
+		if !isReferenced(key, refs) {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	return unused, nil
+}
+
+// recValuesRefs collects the values paths referenced by c and its subcharts
+// into refs. prefix is the path of the values of c in those of the root
+// chart.
+func recValuesRefs(c *chart.Chart, prefix []string, refs map[string]bool) error {
+	record := func(p []string) {
+		refs[strings.Join(append(append([]string{}, prefix...), p...), ".")] = true
+		// globals are copied into the values of every subchart
+		if len(prefix) > 0 && len(p) > 0 && p[0] == "global" {
+			refs[strings.Join(p, ".")] = true
+		}
+	}
+
+	for _, dep := range c.Metadata.Dependencies {
+		for _, cond := range strings.Split(dep.Condition, ",") {
+			if cond = strings.TrimSpace(cond); cond != "" {
+				record(strings.Split(cond, "."))
+			}
+		}
+		for _, tag := range dep.Tags {
+			record([]string{"tags", tag})
+		}
+	}
+
+	for _, f := range c.Templates {
+		t, err := template.New(f.Name).Funcs(funcMap()).Parse(string(f.Data))
+		if err != nil {
+			return errors.Wrapf(err, "parse error in %q", path.Join(c.ChartFullPath(), f.Name))
+		}
+		// named templates are assumed to be included with the top-level
+		// context, as in include "name" .
+		for _, tt := range t.Templates() {
+			if tt.Tree == nil || tt.Tree.Root == nil {
+				continue
+			}
+			w := &valuesWalker{record: record, vars: map[string]dotRef{"$": {kind: refRoot}}}
+			w.walk(tt.Tree.Root, dotRef{kind: refRoot})
+		}
+	}
+
+	for _, dep := range c.Dependencies() {
+		next := append(append([]string{}, prefix...), dep.Name())
+		if err := recValuesRefs(dep, next, refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valuesLeaves returns the paths of the values that are not maps, or are
+// empty maps.
+func valuesLeaves(vals map[string]interface{}, prefix string) []string {
+	var leaves []string
+	for k, v := range vals {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			leaves = append(leaves, valuesLeaves(m, key)...)
+			continue
+		}
+		leaves = append(leaves, key)
+	}
+	return leaves
+}
+
+// isReferenced returns true if a reference reads key, its parent maps, or
+// something under it.
+func isReferenced(key string, refs map[string]bool) bool {
+	for ref := range refs {
+		if ref == "" || ref == key || strings.HasPrefix(key, ref+".") || strings.HasPrefix(ref, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	refUnknown = iota
+	refRoot
+	refValues
+)
+
+// dotRef is what an expression evaluates to: the top-level context, a path
+// in the values, or something else.
+type dotRef struct {
+	kind int
+	path []string
+}
+
+func (r dotRef) extend(fields []string) dotRef {
+	switch {
+	case len(fields) == 0:
+		return r
+	case r.kind == refRoot && fields[0] == "Values":
+		return dotRef{kind: refValues, path: fields[1:]}
+	case r.kind == refValues:
+		return dotRef{kind: refValues, path: append(append([]string{}, r.path...), fields...)}
+	}
+	return dotRef{}
+}
+
+// valuesWalker walks a template tree and records the values paths it reads.
+type valuesWalker struct {
+	record func([]string)
+	vars   map[string]dotRef
+}
+
+func (w *valuesWalker) use(r dotRef) {
+	if r.kind == refValues {
+		w.record(r.path)
+	}
+}
+
+func (w *valuesWalker) walk(node parse.Node, dot dotRef) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			w.walk(c, dot)
+		}
+	case *parse.ActionNode:
+		r := w.pipe(n.Pipe, dot)
+		if len(n.Pipe.Decl) == 0 {
+			w.use(r)
+		}
+	case *parse.IfNode:
+		w.use(w.pipe(n.Pipe, dot))
+		w.walk(n.List, dot)
+		w.walk(n.ElseList, dot)
+	case *parse.WithNode:
+		// the keys read inside the block are enough, not the whole map
+		w.walk(n.List, w.pipe(n.Pipe, dot))
+		w.walk(n.ElseList, dot)
+	case *parse.RangeNode:
+		w.use(w.pipe(n.Pipe, dot))
+		for _, v := range n.Pipe.Decl {
+			w.vars[v.Ident[0]] = dotRef{}
+		}
+		w.walk(n.List, dotRef{})
+		w.walk(n.ElseList, dot)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			w.use(w.pipe(n.Pipe, dot))
+		}
+	}
+}
+
+// pipe returns what the pipeline evaluates to, recording the values it
+// passes to functions, and assigns its variables.
+func (w *valuesWalker) pipe(p *parse.PipeNode, dot dotRef) dotRef {
+	if p == nil {
+		return dotRef{}
+	}
+	var r dotRef
+	for i, cmd := range p.Cmds {
+		if i > 0 {
+			// the result of a command is the last argument of the next one
+			w.use(r)
+		}
+		r = w.command(cmd, dot)
+	}
+	if len(p.Decl) == 1 {
+		w.vars[p.Decl[0].Ident[0]] = r
+	}
+	return r
+}
+
+func (w *valuesWalker) command(cmd *parse.CommandNode, dot dotRef) dotRef {
+	if len(cmd.Args) == 0 {
+		return dotRef{}
+	}
+	fn, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		for _, arg := range cmd.Args[1:] {
+			w.use(w.operand(arg, dot))
+		}
+		return w.operand(cmd.Args[0], dot)
+	}
+
+	args := cmd.Args[1:]
+	if fn.Ident == "index" && len(args) > 0 {
+		r := w.operand(args[0], dot)
+		for _, arg := range args[1:] {
+			if s, ok := arg.(*parse.StringNode); ok && r.kind == refValues {
+				r = r.extend([]string{s.Text})
+				continue
+			}
+			w.use(r)
+			w.use(w.operand(arg, dot))
+			r = dotRef{}
+		}
+		return r
+	}
+	for _, arg := range args {
+		w.use(w.operand(arg, dot))
+	}
+	return dotRef{}
+}
+
+// operand returns what an argument evaluates to.
+func (w *valuesWalker) operand(node parse.Node, dot dotRef) dotRef {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return dot.extend(n.Ident)
+	case *parse.VariableNode:
+		return w.vars[n.Ident[0]].extend(n.Ident[1:])
+	case *parse.ChainNode:
+		return w.operand(n.Node, dot).extend(n.Field)
+	case *parse.PipeNode:
+		return w.pipe(n, dot)
+	}
+	return dotRef{}
+}
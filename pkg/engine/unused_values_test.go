@@ -0,0 +1,135 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestUnusedValues(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "parent",
+			Dependencies: []*chart.Dependency{
+				{Name: "sub", Condition: "sub.enabled", Tags: []string{"backend"}},
+			},
+		},
+		Values: map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": "nginx",
+				"tag":        "1.20",
+				"pullPolicy": "Always",
+			},
+			"service": map[string]interface{}{
+				"port": 80,
+				"type": "ClusterIP",
+			},
+			"resources":    map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}},
+			"annotations":  map[string]interface{}{},
+			"labels":       map[string]interface{}{"team": "a"},
+			"hosts":        []interface{}{"a.example.com"},
+			"nameOverride": "",
+			"unused":       "value",
+			"nested": map[string]interface{}{
+				"used":   true,
+				"unused": true,
+			},
+			"global": map[string]interface{}{
+				"registry": "docker.io",
+				"unused":   "value",
+			},
+			"tags": map[string]interface{}{"backend": true},
+			"sub": map[string]interface{}{
+				"enabled":  true,
+				"replicas": 2,
+				"unused":   1,
+			},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "name" }}{{ default .Chart.Name .Values.nameOverride }}{{ end }}`)},
+			{Name: "templates/deployment", Data: []byte(`
+name: {{ include "name" . }}
+image: {{ .Values.image.repository }}:{{ index .Values "image" "tag" }}
+{{- $svc := .Values.service }}
+port: {{ $svc.port }}
+resources: {{ toYaml .Values.resources | nindent 2 }}
+{{- with .Values.nested }}
+{{- if .used }}used{{ end }}
+{{- end }}
+{{- range .Values.hosts }}
+host: {{ . }}
+{{- end }}
+annotations: {{ $.Values.annotations.extra | quote }}
+labels: {{ (.Values.labels).team }}
+`)},
+		},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub"},
+		Templates: []*chart.File{
+			{Name: "templates/job", Data: []byte(`replicas: {{ .Values.replicas }} registry: {{ .Values.global.registry }}`)},
+		},
+	})
+
+	unused, err := UnusedValues(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"global.unused",
+		"image.pullPolicy",
+		"nested.unused",
+		"service.type",
+		"sub.unused",
+		"unused",
+	}
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("expected unused values %v, got %v", expected, unused)
+	}
+}
+
+func TestUnusedValuesWholeValues(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "dump"},
+		Values:   map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2}},
+		Templates: []*chart.File{
+			{Name: "templates/config", Data: []byte(`{{ toYaml .Values }}`)},
+		},
+	}
+	unused, err := UnusedValues(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unused) != 0 {
+		t.Errorf("expected every value to be used, got %v", unused)
+	}
+}
+
+func TestUnusedValuesParseError(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "broken"},
+		Templates: []*chart.File{
+			{Name: "templates/broken", Data: []byte(`{{ .Values.a `)},
+		},
+	}
+	if _, err := UnusedValues(c); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -0,0 +1,279 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// valueTemplate is a string value that contains a template.
+type valueTemplate struct {
+	// path is the path of the value from the top of .Values
+	path []string
+	// scope is the path of the values of the chart the value belongs to
+	scope []string
+	chart *chart.Chart
+	tpl   *template.Template
+	// refs are the paths, from the top of .Values, of the values it references
+	refs [][]string
+}
+
+// RenderValueTemplates renders the string values of values["Values"] that
+// contain a template, such as "{{ .Values.host }}.{{ .Values.domain }}", so
+// that values can reference other values. values are the render values, as
+// returned by chartutil.ToRenderValues, and are updated in place.
+//
+// As in chart templates, .Values is scoped to the chart a value belongs to.
+// Templated values are rendered after the values they reference, and a
+// template referencing itself, directly or through other values, is reported
+// as a cycle. The "include", "tpl" and "lookup" functions are not available.
+func RenderValueTemplates(chrt *chart.Chart, values chartutil.Values) error {
+	vals, err := values.Table("Values")
+	if err != nil {
+		return nil
+	}
+
+	funcs := funcMap()
+	delete(funcs, "include")
+	delete(funcs, "tpl")
+	delete(funcs, "lookup")
+	funcs["required"] = func(warn string, val interface{}) (interface{}, error) {
+		if val == nil || val == "" {
+			return val, errors.New(warn)
+		}
+		return val, nil
+	}
+
+	tpls := make(map[string]*valueTemplate)
+	if err := collectValueTemplates(chrt, vals, nil, nil, funcs, tpls); err != nil {
+		return err
+	}
+
+	order, err := sortValueTemplates(tpls)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range order {
+		t := tpls[key]
+		data := map[string]interface{}{
+			"Chart":        t.chart.Metadata,
+			"Release":      values["Release"],
+			"Capabilities": values["Capabilities"],
+			"Values":       valuesTable(vals, t.scope),
+		}
+		var buf strings.Builder
+		if err := t.tpl.Execute(&buf, data); err != nil {
+			return errors.Wrapf(err, "failed to render the template of value %s", key)
+		}
+		parent := valuesTable(vals, t.path[:len(t.path)-1])
+		parent[t.path[len(t.path)-1]] = strings.Replace(buf.String(), "<no value>", "", -1)
+	}
+	return nil
+}
+
+// collectValueTemplates parses the templated string values of vals, found
+// at path, that belong to chart c whose values are at scope.
+func collectValueTemplates(c *chart.Chart, vals map[string]interface{}, path, scope []string, funcs template.FuncMap, tpls map[string]*valueTemplate) error {
+	for k, v := range vals {
+		p := append(append([]string{}, path...), k)
+		if table, ok := asValuesTable(v); ok {
+			sub, subScope := c, scope
+			if len(path) == len(scope) {
+				// the values of a subchart are scoped to it
+				for _, d := range c.Dependencies() {
+					if d.Name() == k {
+						sub, subScope = d, p
+					}
+				}
+			}
+			if err := collectValueTemplates(sub, table, p, subScope, funcs, tpls); err != nil {
+				return err
+			}
+			continue
+		}
+		s, ok := v.(string)
+		if !ok || !strings.Contains(s, "{{") {
+			continue
+		}
+
+		key := strings.Join(p, ".")
+		t, err := template.New(key).Option("missingkey=zero").Funcs(funcs).Parse(s)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the template of value %s", key)
+		}
+		vt := &valueTemplate{path: p, scope: scope, chart: c, tpl: t}
+		for _, ref := range valuesReferences(t.Tree.Root) {
+			vt.refs = append(vt.refs, append(append([]string{}, scope...), ref...))
+		}
+		tpls[key] = vt
+	}
+	return nil
+}
+
+// sortValueTemplates orders the keys of tpls so that every template comes
+// after the templates of the values it references.
+func sortValueTemplates(tpls map[string]*valueTemplate) ([]string, error) {
+	keys := make([]string, 0, len(tpls))
+	for k := range tpls {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(tpls))
+	order := make([]string, 0, len(tpls))
+
+	var visit func(key string, stack []string) error
+	visit = func(key string, stack []string) error {
+		switch state[key] {
+		case visited:
+			return nil
+		case visiting:
+			for i, k := range stack {
+				if k == key {
+					return errors.Errorf("value templates reference each other in a cycle: %s", strings.Join(append(stack[i:], key), " -> "))
+				}
+			}
+		}
+		state[key] = visiting
+		stack = append(stack, key)
+		for _, dep := range keys {
+			if referencesValue(tpls[key].refs, tpls[dep].path) {
+				if err := visit(dep, stack); err != nil {
+					return err
+				}
+			}
+		}
+		state[key] = visited
+		order = append(order, key)
+		return nil
+	}
+
+	for _, k := range keys {
+		if err := visit(k, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// referencesValue reports whether one of refs is the value at path, or one of
+// its parents or children.
+func referencesValue(refs [][]string, path []string) bool {
+	for _, ref := range refs {
+		n := len(ref)
+		if len(path) < n {
+			n = len(path)
+		}
+		match := true
+		for i := 0; i < n; i++ {
+			if ref[i] != path[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesReferences returns the paths under .Values, or $.Values, used by the
+// template tree rooted at node.
+func valuesReferences(node parse.Node) [][]string {
+	var refs [][]string
+	var walk func(parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.FieldNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "Values" {
+				refs = append(refs, n.Ident[1:])
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 2 && n.Ident[0] == "$" && n.Ident[1] == "Values" {
+				refs = append(refs, n.Ident[2:])
+			}
+		}
+	}
+	walk(node)
+	return refs
+}
+
+// valuesTable returns the table of vals at path.
+func valuesTable(vals map[string]interface{}, path []string) map[string]interface{} {
+	for _, k := range path {
+		vals, _ = asValuesTable(vals[k])
+	}
+	return vals
+}
+
+func asValuesTable(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return t, true
+	case chartutil.Values:
+		return t, true
+	}
+	return nil, false
+}
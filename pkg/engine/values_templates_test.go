@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestRenderValueTemplates(t *testing.T) {
+	sub := &chart.Chart{Metadata: &chart.Metadata{Name: "sub"}}
+	parent := &chart.Chart{Metadata: &chart.Metadata{Name: "parent"}}
+	parent.AddDependency(sub)
+
+	values := chartutil.Values{
+		"Release": map[string]interface{}{"Name": "web"},
+		"Values": map[string]interface{}{
+			"host":     "www",
+			"domain":   "example.com",
+			"fullHost": "{{ .Values.host }}.{{ .Values.domain }}",
+			"url":      "https://{{ .Values.fullHost }}/",
+			"name":     "{{ .Release.Name }}-{{ .Chart.Name }}",
+			"sub": map[string]interface{}{
+				"port":    8080,
+				"address": "{{ .Values.host }}:{{ .Values.port }}",
+				"host":    "db",
+			},
+		},
+	}
+
+	if err := RenderValueTemplates(parent, values); err != nil {
+		t.Fatal(err)
+	}
+
+	vals, err := values.Table("Values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"fullHost":    "www.example.com",
+		"url":         "https://www.example.com/",
+		"name":        "web-parent",
+		"sub.address": "db:8080",
+	}
+	for path, want := range expected {
+		got, err := vals.PathValue(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("expected %s to be %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestRenderValueTemplatesCycle(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "cycle"}}
+
+	tests := map[string]chartutil.Values{
+		"a -> b -> a": {
+			"a": "{{ .Values.b }}",
+			"b": "{{ $.Values.a }}",
+		},
+		"self -> self": {
+			"self": "{{ .Values.self }}-again",
+		},
+		"x.y -> x.y": {
+			"x": map[string]interface{}{
+				"y": "{{ .Values.x | toYaml }}",
+			},
+		},
+	}
+	for cycle, vals := range tests {
+		err := RenderValueTemplates(c, chartutil.Values{"Values": map[string]interface{}(vals)})
+		if err == nil {
+			t.Errorf("expected the cycle %s to be reported", cycle)
+			continue
+		}
+		if !strings.Contains(err.Error(), "cycle") {
+			t.Errorf("expected a cycle error, got %s", err)
+		}
+	}
+}
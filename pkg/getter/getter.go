@@ -39,11 +39,22 @@ type options struct {
 	username              string
 	password              string
 	userAgent             string
+	proxy                 string
+	noProxy               string
 	version               string
 	registryClient        *registry.Client
 	timeout               time.Duration
+	retryAttempts         int
+	retryBaseDelay        time.Duration
+	// progress is a pointer so that options, and the getters holding them,
+	// can be compared
+	progress *ProgressReporter
 }
 
+// ProgressReporter is called as a download progresses with the number of
+// bytes downloaded so far and the total size, which is -1 when unknown.
+type ProgressReporter func(downloaded, total int64)
+
 // Option allows specifying various settings configurable by the user for overriding the defaults
 // used when performing Get operations with the Getter.
 type Option func(*options)
@@ -65,12 +76,23 @@ func WithBasicAuth(username, password string) Option {
 }
 
 // WithUserAgent sets the request's User-Agent header to use the provided agent name.
+// An empty agent name keeps the default Helm user agent.
 func WithUserAgent(userAgent string) Option {
 	return func(opts *options) {
 		opts.userAgent = userAgent
 	}
 }
 
+// WithProxy sets the proxy URL used for requests and the comma-separated list of
+// hosts that bypass it. Empty values fall back to the HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables.
+func WithProxy(proxy, noProxy string) Option {
+	return func(opts *options) {
+		opts.proxy = proxy
+		opts.noProxy = noProxy
+	}
+}
+
 // WithInsecureSkipVerifyTLS determines if a TLS Certificate will be checked
 func WithInsecureSkipVerifyTLS(insecureSkipVerifyTLS bool) Option {
 	return func(opts *options) {
@@ -94,6 +116,28 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithRetry makes up to attempts requests while they fail with a connection
+// error or a 5xx response, backing off exponentially from baseDelay between
+// them. See GetWithRetry.
+func WithRetry(attempts int, baseDelay time.Duration) Option {
+	return func(opts *options) {
+		opts.retryAttempts = attempts
+		opts.retryBaseDelay = baseDelay
+	}
+}
+
+// WithProgressReporter sets a function called as the bytes of a download
+// stream in, for instance to render a progress bar.
+func WithProgressReporter(report ProgressReporter) Option {
+	return func(opts *options) {
+		if report == nil {
+			opts.progress = nil
+			return
+		}
+		opts.progress = &report
+	}
+}
+
 func WithTagName(tagname string) Option {
 	return func(opts *options) {
 		opts.version = tagname
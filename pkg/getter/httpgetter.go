@@ -20,8 +20,10 @@ import (
 	"crypto/tls"
 	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpproxy"
 
 	"helm.sh/helm/v3/internal/tlsutil"
 	"helm.sh/helm/v3/internal/urlutil"
@@ -38,7 +40,9 @@ func (g *HTTPGetter) Get(href string, options ...Option) (*bytes.Buffer, error)
 	for _, opt := range options {
 		opt(&g.opts)
 	}
-	return g.get(href)
+	return GetWithRetry(g.opts.retryAttempts, g.opts.retryBaseDelay, func() (*bytes.Buffer, error) {
+		return g.get(href)
+	})
 }
 
 func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
@@ -70,14 +74,37 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		return buf, err
 	}
 	if resp.StatusCode != 200 {
-		return buf, errors.Errorf("failed to fetch %s : %s", href, resp.Status)
+		resp.Body.Close()
+		return buf, &StatusError{URL: href, StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
-	_, err = io.Copy(buf, resp.Body)
+	var body io.Reader = resp.Body
+	if g.opts.progress != nil {
+		// ContentLength is -1 when the server did not send the header
+		body = &progressReader{r: resp.Body, total: resp.ContentLength, report: *g.opts.progress}
+	}
+	_, err = io.Copy(buf, body)
 	resp.Body.Close()
 	return buf, err
 }
 
+// progressReader reports the number of bytes read so far after each read.
+type progressReader struct {
+	r          io.Reader
+	downloaded int64
+	total      int64
+	report     ProgressReporter
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.downloaded += int64(n)
+		p.report(p.downloaded, p.total)
+	}
+	return n, err
+}
+
 // NewHTTPGetter constructs a valid http/https client as a Getter
 func NewHTTPGetter(options ...Option) (Getter, error) {
 	var client HTTPGetter
@@ -94,6 +121,9 @@ func (g *HTTPGetter) httpClient() (*http.Client, error) {
 		DisableCompression: true,
 		Proxy:              http.ProxyFromEnvironment,
 	}
+	if g.opts.proxy != "" || g.opts.noProxy != "" {
+		transport.Proxy = proxyFunc(g.opts.proxy, g.opts.noProxy)
+	}
 	if (g.opts.certFile != "" && g.opts.keyFile != "") || g.opts.caFile != "" {
 		tlsConf, err := tlsutil.NewClientTLS(g.opts.certFile, g.opts.keyFile, g.opts.caFile)
 		if err != nil {
@@ -127,3 +157,20 @@ func (g *HTTPGetter) httpClient() (*http.Client, error) {
 
 	return client, nil
 }
+
+// proxyFunc selects the proxy for a request like http.ProxyFromEnvironment,
+// with the environment settings overridden by proxy and noProxy when set.
+func proxyFunc(proxy, noProxy string) func(*http.Request) (*url.URL, error) {
+	cfg := httpproxy.FromEnvironment()
+	if proxy != "" {
+		cfg.HTTPProxy = proxy
+		cfg.HTTPSProxy = proxy
+	}
+	if noProxy != "" {
+		cfg.NoProxy = noProxy
+	}
+	fn := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return fn(req.URL)
+	}
+}
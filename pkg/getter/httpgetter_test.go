@@ -16,8 +16,10 @@ limitations under the License.
 package getter
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -25,6 +27,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -345,3 +348,167 @@ func verifyInsecureSkipVerify(t *testing.T, g HTTPGetter, caseName string, expec
 	}
 	return transport
 }
+
+func TestProxyFunc(t *testing.T) {
+	proxy := proxyFunc("http://proxy.example.com:3128", "internal.example.com,.corp.example.com")
+
+	tests := []struct {
+		url   string
+		proxy string
+	}{
+		{"https://charts.example.com/index.yaml", "http://proxy.example.com:3128"},
+		{"http://charts.example.com/index.yaml", "http://proxy.example.com:3128"},
+		{"https://internal.example.com/index.yaml", ""},
+		{"https://charts.corp.example.com/index.yaml", ""},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest("GET", tt.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if u != nil {
+			got = u.String()
+		}
+		if got != tt.proxy {
+			t.Errorf("Expected proxy %q for %s, got %q", tt.proxy, tt.url, got)
+		}
+	}
+}
+
+func TestHTTPGetterRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		failures  int
+		attempts  int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{"no retry by default", http.StatusServiceUnavailable, 1, 0, 1, true},
+		{"succeeds after 5xx", http.StatusServiceUnavailable, 2, 3, 3, false},
+		{"gives up after attempts", http.StatusBadGateway, 5, 3, 3, true},
+		{"4xx is not retried", http.StatusNotFound, 5, 3, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) <= int32(tt.failures) {
+					w.WriteHeader(tt.status)
+					return
+				}
+				fmt.Fprint(w, "ok")
+			}))
+			defer srv.Close()
+
+			g, err := NewHTTPGetter(WithRetry(tt.attempts, time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := g.Get(srv.URL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+					t.Errorf("expected a StatusError with code %d, got %v", tt.status, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if got.String() != "ok" {
+				t.Errorf("expected %q, got %q", "ok", got.String())
+			}
+			if n := atomic.LoadInt32(&calls); n != tt.wantCalls {
+				t.Errorf("expected %d requests, got %d", tt.wantCalls, n)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&StatusError{StatusCode: http.StatusInternalServerError}, true},
+		{errors.Wrap(&StatusError{StatusCode: http.StatusServiceUnavailable}, "wrapped"), true},
+		{&StatusError{StatusCode: http.StatusForbidden}, false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{errors.Wrap(context.DeadlineExceeded, "wrapped"), false},
+		{errors.New("invalid chart"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %t, want %t", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPGetterProgressReporter(t *testing.T) {
+	content := strings.Repeat("helm", 10000)
+
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantTotal int64
+	}{
+		{
+			name: "with content length",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				fmt.Fprint(w, content)
+			},
+			wantTotal: int64(len(content)),
+		},
+		{
+			name: "without content length",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// flushing before writing everything makes the response chunked
+				fmt.Fprint(w, content[:10])
+				w.(http.Flusher).Flush()
+				fmt.Fprint(w, content[10:])
+			},
+			wantTotal: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			var reports int
+			var last, total int64
+			g, err := NewHTTPGetter(WithProgressReporter(func(downloaded, size int64) {
+				reports++
+				last, total = downloaded, size
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := g.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != content {
+				t.Errorf("expected the content to be downloaded unchanged")
+			}
+			if reports == 0 {
+				t.Fatal("expected the progress to be reported")
+			}
+			if last != int64(len(content)) {
+				t.Errorf("expected %d bytes downloaded, got %d", len(content), last)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("expected a total of %d, got %d", tt.wantTotal, total)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getter
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StatusError is the error of a request answered with an unexpected HTTP
+// status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to fetch %s : %s", e.URL, e.Status)
+}
+
+// IsTransient returns true if err is a connection error or a 5xx response,
+// which may succeed when retried. Other responses, such as 4xx, are not.
+// Neither are timeouts: the timeout bounds the whole download, so retrying
+// would only multiply it.
+func IsTransient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && !netErr.Timeout()
+}
+
+// GetWithRetry calls get up to attempts times, for as long as it fails with
+// a transient error. The delay between attempts starts at baseDelay and
+// doubles after each attempt, with jitter so that clients do not retry in
+// lockstep.
+func GetWithRetry(attempts int, baseDelay time.Duration, get func() (*bytes.Buffer, error)) (*bytes.Buffer, error) {
+	buf, err := get()
+	for attempt := 1; attempt < attempts && err != nil && IsTransient(err); attempt++ {
+		time.Sleep(backoff(baseDelay, attempt))
+		buf, err = get()
+	}
+	return buf, err
+}
+
+// backoff returns the delay before the retry following the given attempt: a
+// random duration between half and all of baseDelay * 2^(attempt-1).
+func backoff(baseDelay time.Duration, attempt int) time.Duration {
+	delay := baseDelay << uint(attempt-1)
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
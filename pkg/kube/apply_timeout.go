@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest"
+)
+
+// timeoutRESTClient sets a timeout on every request made through a
+// resource.RESTClient. The timeout is sent to the API server and bounds the
+// request on the client side too.
+type timeoutRESTClient struct {
+	resource.RESTClient
+	timeout time.Duration
+}
+
+func (c *timeoutRESTClient) Get() *rest.Request {
+	return c.RESTClient.Get().Timeout(c.timeout)
+}
+
+func (c *timeoutRESTClient) Post() *rest.Request {
+	return c.RESTClient.Post().Timeout(c.timeout)
+}
+
+func (c *timeoutRESTClient) Patch(pt types.PatchType) *rest.Request {
+	return c.RESTClient.Patch(pt).Timeout(c.timeout)
+}
+
+func (c *timeoutRESTClient) Delete() *rest.Request {
+	return c.RESTClient.Delete().Timeout(c.timeout)
+}
+
+func (c *timeoutRESTClient) Put() *rest.Request {
+	return c.RESTClient.Put().Timeout(c.timeout)
+}
+
+// newApplyHelper returns a helper for creating or updating info, whose
+// requests time out after applyTimeout unless it is zero.
+func newApplyHelper(info *resource.Info, applyTimeout time.Duration) *resource.Helper {
+	client := info.Client
+	if applyTimeout > 0 {
+		client = &timeoutRESTClient{RESTClient: client, timeout: applyTimeout}
+	}
+	return resource.NewHelper(client, info.Mapping)
+}
+
+// applyTimeoutError tells an apply that ran out of time, started at start,
+// apart from other failures, so that a slow API server is not mistaken for a
+// resource that never became ready.
+func applyTimeoutError(err error, info *resource.Info, start time.Time, applyTimeout time.Duration) error {
+	if applyTimeout <= 0 || time.Since(start) < applyTimeout {
+		return err
+	}
+	return errors.Wrapf(err, "applying %s %q did not complete within the apply timeout of %s", info.Mapping.GroupVersionKind.Kind, info.Name, applyTimeout)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestCreateWithTimeout(t *testing.T) {
+	// a single pod: the fake REST client records every request it is sent
+	// without synchronization, so it cannot serve concurrent creates
+	list := newPodList("starfish")
+	body := runtime.EncodeOrDie(codec, &list.Items[0])
+
+	slow := false
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/namespaces/default/pods" || req.Method != "POST" {
+				t.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, errors.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+			}
+			if req.URL.Query().Get("timeout") == "" {
+				t.Error("expected the apply timeout to be sent to the API server")
+			}
+			if slow {
+				// a slow API server, taking longer than the apply timeout
+				select {
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				case <-time.After(2 * time.Second):
+				}
+			}
+			header := http.Header{}
+			header.Set("Content-Type", runtime.ContentTypeJSON)
+			return &http.Response{StatusCode: 201, Header: header, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		}),
+	}
+	resources, err := c.Build(objBody(&list), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.CreateWithTimeout(resources, time.Minute); err != nil {
+		t.Fatalf("expected a fast apply to succeed, got %s", err)
+	}
+
+	slow = true
+	start := time.Now()
+	_, err = c.CreateWithTimeout(resources, 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a slow apply to fail")
+	}
+	if !strings.Contains(err.Error(), "did not complete within the apply timeout of 50ms") {
+		t.Errorf("expected an apply timeout error, got %s", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 2*time.Second {
+		t.Errorf("expected the apply to be cut short, took %s", elapsed)
+	}
+}
@@ -38,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/watch"
@@ -63,6 +64,8 @@ type Client struct {
 	Namespace string
 
 	kubeClient *kubernetes.Clientset
+	// readinessEvaluators are the custom readiness checks used by Wait
+	readinessEvaluators map[schema.GroupVersionKind]ReadinessEvaluator
 }
 
 var addToScheme sync.Once
@@ -119,8 +122,17 @@ func (c *Client) IsReachable() error {
 
 // Create creates Kubernetes resources specified in the resource list.
 func (c *Client) Create(resources ResourceList) (*Result, error) {
+	return c.CreateWithTimeout(resources, 0)
+}
+
+// CreateWithTimeout is Create, failing the creation of any resource that the
+// API server does not complete within applyTimeout. Zero means no timeout.
+func (c *Client) CreateWithTimeout(resources ResourceList, applyTimeout time.Duration) (*Result, error) {
 	c.Log("creating %d resource(s)", len(resources))
-	if err := perform(resources, createResource); err != nil {
+	create := func(info *resource.Info) error {
+		return createResource(info, applyTimeout)
+	}
+	if err := perform(resources, create); err != nil {
 		return nil, err
 	}
 	return &Result{Created: resources}, nil
@@ -128,30 +140,55 @@ func (c *Client) Create(resources ResourceList) (*Result, error) {
 
 // Wait up to the given timeout for the specified resources to be ready
 func (c *Client) Wait(resources ResourceList, timeout time.Duration) error {
-	cs, err := c.getKubeClient()
-	if err != nil {
-		return err
-	}
-	w := waiter{
-		c:       cs,
-		log:     c.Log,
-		timeout: timeout,
-	}
-	return w.waitForResources(resources, false)
+	return c.WaitWithRetries(resources, timeout, false, DefaultWaitRetries)
 }
 
 // WaitWithJobs wait up to the given timeout for the specified resources to be ready, including jobs.
 func (c *Client) WaitWithJobs(resources ResourceList, timeout time.Duration) error {
+	return c.WaitWithRetries(resources, timeout, true, DefaultWaitRetries)
+}
+
+// WaitWithRetries waits up to the given timeout for the specified resources to
+// be ready, including jobs if waitForJobs is set. Up to retries consecutive
+// transient API server errors are tolerated before the wait fails.
+func (c *Client) WaitWithRetries(resources ResourceList, timeout time.Duration, waitForJobs bool, retries int) error {
 	cs, err := c.getKubeClient()
 	if err != nil {
 		return err
 	}
 	w := waiter{
-		c:       cs,
+		c:          cs,
+		log:        c.Log,
+		timeout:    timeout,
+		retries:    retries,
+		evaluators: c.readinessEvaluators,
+	}
+	return w.waitForResources(resources, waitForJobs)
+}
+
+// WaitForDelete waits up to the given timeout for the specified resources to
+// be deleted. Resources that still exist, even with a deletion timestamp set,
+// are waited for.
+func (c *Client) WaitForDelete(resources ResourceList, timeout time.Duration) error {
+	w := waiter{
 		log:     c.Log,
 		timeout: timeout,
 	}
-	return w.waitForResources(resources, true)
+	return w.waitForDeletedResources(resources)
+}
+
+// RegisterReadinessEvaluator makes Wait and its variants consider resources
+// of the given kind ready once evaluate returns true for their live object,
+// instead of using the built-in checks. Resources of kinds without a
+// registered evaluator keep the default behavior. Registering an evaluator
+// for a kind replaces the previous one.
+//
+// Evaluators must be registered before waiting, not concurrently with it.
+func (c *Client) RegisterReadinessEvaluator(gvk schema.GroupVersionKind, evaluate ReadinessEvaluator) {
+	if c.readinessEvaluators == nil {
+		c.readinessEvaluators = make(map[schema.GroupVersionKind]ReadinessEvaluator)
+	}
+	c.readinessEvaluators[gvk] = evaluate
 }
 
 func (c *Client) namespace() string {
@@ -195,6 +232,13 @@ func (c *Client) Build(reader io.Reader, validate bool) (ResourceList, error) {
 // resource updates, creations, and deletions that were attempted. These can be
 // used for cleanup or other logging purposes.
 func (c *Client) Update(original, target ResourceList, force bool) (*Result, error) {
+	return c.UpdateWithTimeout(original, target, force, 0)
+}
+
+// UpdateWithTimeout is Update, failing the creation or update of any resource
+// that the API server does not complete within applyTimeout. Zero means no
+// timeout.
+func (c *Client) UpdateWithTimeout(original, target ResourceList, force bool, applyTimeout time.Duration) (*Result, error) {
 	updateErrors := []string{}
 	res := &Result{}
 
@@ -214,7 +258,8 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 			res.Created = append(res.Created, info)
 
 			// Since the resource does not exist, create it.
-			if err := createResource(info); err != nil {
+			if err := createResource(info, applyTimeout); err != nil {
+				res.fail(info, err)
 				return errors.Wrap(err, "failed to create resource")
 			}
 
@@ -229,9 +274,10 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 			return errors.Errorf("no %s with the name %q found", kind, info.Name)
 		}
 
-		if err := updateResource(c, info, originalInfo.Object, force); err != nil {
+		if err := updateResource(c, info, originalInfo.Object, force, applyTimeout); err != nil {
 			c.Log("error updating the resource %q:\n\t %v", info.Name, err)
 			updateErrors = append(updateErrors, err.Error())
+			res.fail(info, err)
 		}
 		// Because we check for errors later, append the info regardless
 		res.Updated = append(res.Updated, info)
@@ -270,6 +316,50 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 	return res, nil
 }
 
+// ListByLabel lists the live resources matching the label selector for each
+// kind and namespace found in resources. Kinds that are no longer served by the
+// cluster are skipped.
+func (c *Client) ListByLabel(resources ResourceList, selector string) (ResourceList, error) {
+	var result ResourceList
+	listed := make(map[string]bool)
+	for _, info := range resources {
+		gvk := info.Mapping.GroupVersionKind
+		key := gvk.String() + "/" + info.Namespace
+		if listed[key] {
+			continue
+		}
+		listed[key] = true
+
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		list, err := helper.List(info.Namespace, gvk.GroupVersion().String(), &metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "could not list %s resources", gvk.Kind)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj, err := meta.Accessor(item)
+			if err != nil {
+				return nil, err
+			}
+			result.Append(&resource.Info{
+				Client:          info.Client,
+				Mapping:         info.Mapping,
+				Namespace:       obj.GetNamespace(),
+				Name:            obj.GetName(),
+				Object:          item,
+				ResourceVersion: obj.GetResourceVersion(),
+			})
+		}
+	}
+	return result, nil
+}
+
 // Delete deletes Kubernetes resources specified in the resources list. It will
 // attempt to delete all resources even if one or more fail and collect any
 // errors. All successfully deleted items will be returned in the `Deleted`
@@ -374,10 +464,11 @@ func batchPerform(infos ResourceList, fn func(*resource.Info) error, errs chan<-
 	}
 }
 
-func createResource(info *resource.Info) error {
-	obj, err := resource.NewHelper(info.Client, info.Mapping).Create(info.Namespace, true, info.Object)
+func createResource(info *resource.Info, applyTimeout time.Duration) error {
+	start := time.Now()
+	obj, err := newApplyHelper(info, applyTimeout).Create(info.Namespace, true, info.Object)
 	if err != nil {
-		return err
+		return applyTimeoutError(err, info, start, applyTimeout)
 	}
 	return info.Refresh(obj, true)
 }
@@ -439,11 +530,12 @@ func createPatch(target *resource.Info, current runtime.Object) ([]byte, types.P
 	return patch, types.StrategicMergePatchType, err
 }
 
-func updateResource(c *Client, target *resource.Info, currentObj runtime.Object, force bool) error {
+func updateResource(c *Client, target *resource.Info, currentObj runtime.Object, force bool, applyTimeout time.Duration) error {
 	var (
 		obj    runtime.Object
-		helper = resource.NewHelper(target.Client, target.Mapping)
+		helper = newApplyHelper(target, applyTimeout)
 		kind   = target.Mapping.GroupVersionKind.Kind
+		start  = time.Now()
 	)
 
 	// if --force is applied, attempt to replace the existing resource with the new object.
@@ -451,7 +543,7 @@ func updateResource(c *Client, target *resource.Info, currentObj runtime.Object,
 		var err error
 		obj, err = helper.Replace(target.Namespace, target.Name, true, target.Object)
 		if err != nil {
-			return errors.Wrap(err, "failed to replace object")
+			return errors.Wrap(applyTimeoutError(err, target, start, applyTimeout), "failed to replace object")
 		}
 		c.Log("Replaced %q with kind %s for kind %s", target.Name, currentObj.GetObjectKind().GroupVersionKind().Kind, kind)
 	} else {
@@ -470,9 +562,10 @@ func updateResource(c *Client, target *resource.Info, currentObj runtime.Object,
 			return nil
 		}
 		// send patch to server
+		start = time.Now()
 		obj, err = helper.Patch(target.Namespace, target.Name, patchType, patch, nil)
 		if err != nil {
-			return errors.Wrapf(err, "cannot patch %q with kind %s", target.Name, kind)
+			return errors.Wrapf(applyTimeoutError(err, target, start, applyTimeout), "cannot patch %q with kind %s", target.Name, kind)
 		}
 	}
 
@@ -66,6 +66,27 @@ func (f *FailingKubeClient) WaitWithJobs(resources kube.ResourceList, d time.Dur
 	return f.PrintingKubeClient.Wait(resources, d)
 }
 
+// WaitWithRetries returns the configured error if set or prints
+func (f *FailingKubeClient) WaitWithRetries(resources kube.ResourceList, d time.Duration, waitForJobs bool, retries int) error {
+	if f.WaitError != nil {
+		return f.WaitError
+	}
+	return f.PrintingKubeClient.WaitWithRetries(resources, d, waitForJobs, retries)
+}
+
+// WaitForDelete returns the configured error if set or prints
+func (f *FailingKubeClient) WaitForDelete(resources kube.ResourceList, d time.Duration) error {
+	if f.WaitError != nil {
+		return f.WaitError
+	}
+	return f.PrintingKubeClient.WaitForDelete(resources, d)
+}
+
+// CreateWithTimeout returns the configured error if set or prints
+func (f *FailingKubeClient) CreateWithTimeout(resources kube.ResourceList, _ time.Duration) (*kube.Result, error) {
+	return f.Create(resources)
+}
+
 // Delete returns the configured error if set or prints
 func (f *FailingKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
 	if f.DeleteError != nil {
@@ -90,6 +111,11 @@ func (f *FailingKubeClient) Update(r, modified kube.ResourceList, ignoreMe bool)
 	return f.PrintingKubeClient.Update(r, modified, ignoreMe)
 }
 
+// UpdateWithTimeout returns the configured error if set or prints
+func (f *FailingKubeClient) UpdateWithTimeout(r, modified kube.ResourceList, force bool, _ time.Duration) (*kube.Result, error) {
+	return f.Update(r, modified, force)
+}
+
 // Build returns the configured error if set or prints
 func (f *FailingKubeClient) Build(r io.Reader, _ bool) (kube.ResourceList, error) {
 	if f.BuildError != nil {
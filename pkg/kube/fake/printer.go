@@ -96,6 +96,35 @@ func (p *PrintingKubeClient) WaitAndGetCompletedPodPhase(_ string, _ time.Durati
 	return v1.PodSucceeded, nil
 }
 
+// WaitWithRetries implements KubeClient WaitWithRetries.
+func (p *PrintingKubeClient) WaitWithRetries(resources kube.ResourceList, _ time.Duration, _ bool, _ int) error {
+	_, err := io.Copy(p.Out, bufferize(resources))
+	return err
+}
+
+// CreateWithTimeout implements KubeClient CreateWithTimeout.
+func (p *PrintingKubeClient) CreateWithTimeout(resources kube.ResourceList, _ time.Duration) (*kube.Result, error) {
+	return p.Create(resources)
+}
+
+// UpdateWithTimeout implements KubeClient UpdateWithTimeout.
+func (p *PrintingKubeClient) UpdateWithTimeout(original, modified kube.ResourceList, force bool, _ time.Duration) (*kube.Result, error) {
+	return p.Update(original, modified, force)
+}
+
+// WaitForDelete implements KubeClient WaitForDelete.
+func (p *PrintingKubeClient) WaitForDelete(resources kube.ResourceList, _ time.Duration) error {
+	_, err := io.Copy(p.Out, bufferize(resources))
+	return err
+}
+
+// ListByLabel implements KubeClient ListByLabel.
+//
+// No live resources exist, so it always returns an empty list.
+func (p *PrintingKubeClient) ListByLabel(_ kube.ResourceList, _ string) (kube.ResourceList, error) {
+	return kube.ResourceList{}, nil
+}
+
 func bufferize(resources kube.ResourceList) io.Reader {
 	var builder strings.Builder
 	for _, info := range resources {
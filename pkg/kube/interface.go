@@ -66,3 +66,50 @@ type Interface interface {
 }
 
 var _ Interface = (*Client)(nil)
+
+// The interfaces below are optional capabilities of an Interface, each kept
+// separate so that Interface implementers are not required to provide them.
+// Callers check for a capability with a type assertion and fall back to the
+// Interface methods, or fail, when it is missing.
+//
+// TODO Helm 4: Integrate these methods into the Interface.
+
+// LabelLister lists the live resources matching a label selector.
+type LabelLister interface {
+	// ListByLabel returns the live resources matching the label selector.
+	//
+	// Only the kinds and namespaces present in resources are queried.
+	ListByLabel(resources ResourceList, selector string) (ResourceList, error)
+}
+
+// RetryWaiter waits for resources while tolerating transient errors.
+type RetryWaiter interface {
+	// WaitWithRetries is Wait, or WaitWithJobs if waitForJobs is set, tolerating
+	// up to retries consecutive transient API server errors.
+	WaitWithRetries(resources ResourceList, timeout time.Duration, waitForJobs bool, retries int) error
+}
+
+// TimeoutApplier creates and updates resources with a per resource timeout.
+type TimeoutApplier interface {
+	// CreateWithTimeout is Create, failing the creation of any resource that
+	// takes longer than applyTimeout.
+	CreateWithTimeout(resources ResourceList, applyTimeout time.Duration) (*Result, error)
+
+	// UpdateWithTimeout is Update, failing the creation or update of any
+	// resource that takes longer than applyTimeout.
+	UpdateWithTimeout(original, target ResourceList, force bool, applyTimeout time.Duration) (*Result, error)
+}
+
+// DeleteWaiter waits for deleted resources to be gone.
+type DeleteWaiter interface {
+	// WaitForDelete waits up to timeout for the resources to no longer exist,
+	// for instance while their finalizers run.
+	WaitForDelete(resources ResourceList, timeout time.Duration) error
+}
+
+var (
+	_ LabelLister    = (*Client)(nil)
+	_ RetryWaiter    = (*Client)(nil)
+	_ TimeoutApplier = (*Client)(nil)
+	_ DeleteWaiter   = (*Client)(nil)
+)
@@ -16,6 +16,8 @@ limitations under the License.
 
 package kube
 
+import "k8s.io/cli-runtime/pkg/resource"
+
 // Result contains the information of created, updated, and deleted resources
 // for various kube API calls along with helper methods for using those
 // resources
@@ -23,6 +25,20 @@ type Result struct {
 	Created ResourceList
 	Updated ResourceList
 	Deleted ResourceList
+	// Failed holds the resources whose creation or update failed, so that
+	// callers can tell them from those applied.
+	Failed ResourceList
+	// FailedErrors holds the error that failed each resource of Failed.
+	FailedErrors map[*resource.Info]error
+}
+
+// fail records that the creation or update of info failed with err.
+func (r *Result) fail(info *resource.Info, err error) {
+	if r.FailedErrors == nil {
+		r.FailedErrors = make(map[*resource.Info]error)
+	}
+	r.Failed = append(r.Failed, info)
+	r.FailedErrors[info] = err
 }
 
 // If needed, we can add methods to the Result type for things like diffing
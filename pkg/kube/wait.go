@@ -19,6 +19,7 @@ package kube // import "helm.sh/helm/v3/pkg/kube"
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/pkg/errors"
@@ -30,10 +31,13 @@ import (
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -41,119 +45,212 @@ import (
 	deploymentutil "helm.sh/helm/v3/internal/third_party/k8s.io/kubernetes/deployment/util"
 )
 
+// DefaultWaitRetries is the number of consecutive transient API server errors
+// tolerated by Wait and WaitWithJobs: none, so that any error fails the wait
+// as it always has. Tolerating errors is opt-in with WaitWithRetries.
+const DefaultWaitRetries = 0
+
+// defaultWaitInterval is the time between two checks of the resources.
+const defaultWaitInterval = 2 * time.Second
+
+// ReadinessEvaluator reports whether a resource is ready, given the live
+// object as last read from the cluster.
+type ReadinessEvaluator func(obj runtime.Object) (bool, error)
+
 type waiter struct {
 	c       kubernetes.Interface
 	timeout time.Duration
 	log     func(string, ...interface{})
+	// retries is the number of consecutive transient errors tolerated
+	retries int
+	// interval overrides defaultWaitInterval when set
+	interval time.Duration
+	// evaluators replace the built-in readiness checks of the kinds they are
+	// registered for
+	evaluators map[schema.GroupVersionKind]ReadinessEvaluator
 }
 
 // waitForResources polls to get the current status of all pods, PVCs, Services and
-// Jobs(optional) until all are ready or a timeout is reached
+// Jobs(optional) until all are ready or a timeout is reached.
+//
+// Transient API server errors, such as timeouts or 5xx responses, do not fail
+// the wait unless more than w.retries of them happen in a row.
 func (w *waiter) waitForResources(created ResourceList, waitForJobsEnabled bool) error {
 	w.log("beginning wait for %d resources with timeout of %v", len(created), w.timeout)
 
-	return wait.Poll(2*time.Second, w.timeout, func() (bool, error) {
-		for _, v := range created {
-			var (
-				// This defaults to true, otherwise we get to a point where
-				// things will always return false unless one of the objects
-				// that manages pods has been hit
-				ok  = true
-				err error
-			)
-			switch value := AsVersioned(v).(type) {
-			case *corev1.Pod:
-				pod, err := w.c.CoreV1().Pods(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
-				if err != nil || !w.isPodReady(pod) {
-					return false, err
-				}
-			case *batchv1.Job:
-				if waitForJobsEnabled {
-					job, err := w.c.BatchV1().Jobs(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
-					if err != nil || !w.jobReady(job) {
-						return false, err
-					}
-				}
-			case *appsv1.Deployment, *appsv1beta1.Deployment, *appsv1beta2.Deployment, *extensionsv1beta1.Deployment:
-				currentDeployment, err := w.c.AppsV1().Deployments(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
-				if err != nil {
-					return false, err
-				}
-				// If paused deployment will never be ready
-				if currentDeployment.Spec.Paused {
-					continue
-				}
-				// Find RS associated with deployment
-				newReplicaSet, err := deploymentutil.GetNewReplicaSet(currentDeployment, w.c.AppsV1())
-				if err != nil || newReplicaSet == nil {
-					return false, err
-				}
-				if !w.deploymentReady(newReplicaSet, currentDeployment) {
-					return false, nil
-				}
-			case *corev1.PersistentVolumeClaim:
-				claim, err := w.c.CoreV1().PersistentVolumeClaims(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
-				if err != nil {
-					return false, err
-				}
-				if !w.volumeReady(claim) {
-					return false, nil
-				}
-			case *corev1.Service:
-				svc, err := w.c.CoreV1().Services(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
-				if err != nil {
-					return false, err
-				}
-				if !w.serviceReady(svc) {
-					return false, nil
-				}
-			case *extensionsv1beta1.DaemonSet, *appsv1.DaemonSet, *appsv1beta2.DaemonSet:
-				ds, err := w.c.AppsV1().DaemonSets(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
-				if err != nil {
-					return false, err
-				}
-				if !w.daemonSetReady(ds) {
-					return false, nil
-				}
-			case *apiextv1beta1.CustomResourceDefinition:
-				if err := v.Get(); err != nil {
-					return false, err
-				}
-				crd := &apiextv1beta1.CustomResourceDefinition{}
-				if err := scheme.Scheme.Convert(v.Object, crd, nil); err != nil {
-					return false, err
-				}
-				if !w.crdBetaReady(*crd) {
-					return false, nil
-				}
-			case *apiextv1.CustomResourceDefinition:
+	interval := w.interval
+	if interval == 0 {
+		interval = defaultWaitInterval
+	}
+	failures := 0
+	return wait.Poll(interval, w.timeout, func() (bool, error) {
+		ready, err := w.resourcesReady(created, waitForJobsEnabled)
+		if err != nil && isTransientError(err) && failures < w.retries {
+			failures++
+			w.log("transient error while waiting for resources (retry %d/%d): %s", failures, w.retries, err)
+			return false, nil
+		}
+		if err == nil {
+			failures = 0
+		}
+		return ready, err
+	})
+}
+
+// waitForDeletedResources polls the resources until none of them exists
+// anymore or the timeout is reached.
+func (w *waiter) waitForDeletedResources(deleted ResourceList) error {
+	w.log("beginning wait for %d resources to be deleted with timeout of %v", len(deleted), w.timeout)
+
+	interval := w.interval
+	if interval == 0 {
+		interval = defaultWaitInterval
+	}
+	err := wait.PollImmediate(interval, w.timeout, func() (bool, error) {
+		for _, v := range deleted {
+			err := v.Get()
+			if err == nil {
+				w.log("%s %q is not deleted yet", v.Mapping.GroupVersionKind.Kind, v.Name)
+				return false, nil
+			}
+			if !apierrors.IsNotFound(err) {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out waiting for %d resources to be deleted", len(deleted))
+	}
+	return err
+}
+
+// isTransientError reports whether err is likely to go away on its own, for
+// instance while the API server restarts.
+func isTransientError(err error) bool {
+	if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) {
+		return true
+	}
+	if status, ok := err.(apierrors.APIStatus); ok && status.Status().Code >= http.StatusInternalServerError {
+		return true
+	}
+	return utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
+
+// resourcesReady checks once whether all the resources are ready.
+func (w *waiter) resourcesReady(created ResourceList, waitForJobsEnabled bool) (bool, error) {
+	for _, v := range created {
+		if v.Mapping != nil {
+			if evaluate, ok := w.evaluators[v.Mapping.GroupVersionKind]; ok {
 				if err := v.Get(); err != nil {
 					return false, err
 				}
-				crd := &apiextv1.CustomResourceDefinition{}
-				if err := scheme.Scheme.Convert(v.Object, crd, nil); err != nil {
+				if ready, err := evaluate(v.Object); !ready || err != nil {
 					return false, err
 				}
-				if !w.crdReady(*crd) {
-					return false, nil
-				}
-			case *appsv1.StatefulSet, *appsv1beta1.StatefulSet, *appsv1beta2.StatefulSet:
-				sts, err := w.c.AppsV1().StatefulSets(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
-				if err != nil {
+				continue
+			}
+		}
+
+		var (
+			// This defaults to true, otherwise we get to a point where
+			// things will always return false unless one of the objects
+			// that manages pods has been hit
+			ok  = true
+			err error
+		)
+		switch value := AsVersioned(v).(type) {
+		case *corev1.Pod:
+			pod, err := w.c.CoreV1().Pods(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
+			if err != nil || !w.isPodReady(pod) {
+				return false, err
+			}
+		case *batchv1.Job:
+			if waitForJobsEnabled {
+				job, err := w.c.BatchV1().Jobs(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
+				if err != nil || !w.jobReady(job) {
 					return false, err
 				}
-				if !w.statefulSetReady(sts) {
-					return false, nil
-				}
-			case *corev1.ReplicationController, *extensionsv1beta1.ReplicaSet, *appsv1beta2.ReplicaSet, *appsv1.ReplicaSet:
-				ok, err = w.podsReadyForObject(v.Namespace, value)
 			}
-			if !ok || err != nil {
+		case *appsv1.Deployment, *appsv1beta1.Deployment, *appsv1beta2.Deployment, *extensionsv1beta1.Deployment:
+			currentDeployment, err := w.c.AppsV1().Deployments(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			// If paused deployment will never be ready
+			if currentDeployment.Spec.Paused {
+				continue
+			}
+			// Find RS associated with deployment
+			newReplicaSet, err := deploymentutil.GetNewReplicaSet(currentDeployment, w.c.AppsV1())
+			if err != nil || newReplicaSet == nil {
+				return false, err
+			}
+			if !w.deploymentReady(newReplicaSet, currentDeployment) {
+				return false, nil
+			}
+		case *corev1.PersistentVolumeClaim:
+			claim, err := w.c.CoreV1().PersistentVolumeClaims(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			if !w.volumeReady(claim) {
+				return false, nil
+			}
+		case *corev1.Service:
+			svc, err := w.c.CoreV1().Services(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
+			if err != nil {
 				return false, err
 			}
+			if !w.serviceReady(svc) {
+				return false, nil
+			}
+		case *extensionsv1beta1.DaemonSet, *appsv1.DaemonSet, *appsv1beta2.DaemonSet:
+			ds, err := w.c.AppsV1().DaemonSets(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			if !w.daemonSetReady(ds) {
+				return false, nil
+			}
+		case *apiextv1beta1.CustomResourceDefinition:
+			if err := v.Get(); err != nil {
+				return false, err
+			}
+			crd := &apiextv1beta1.CustomResourceDefinition{}
+			if err := scheme.Scheme.Convert(v.Object, crd, nil); err != nil {
+				return false, err
+			}
+			if !w.crdBetaReady(*crd) {
+				return false, nil
+			}
+		case *apiextv1.CustomResourceDefinition:
+			if err := v.Get(); err != nil {
+				return false, err
+			}
+			crd := &apiextv1.CustomResourceDefinition{}
+			if err := scheme.Scheme.Convert(v.Object, crd, nil); err != nil {
+				return false, err
+			}
+			if !w.crdReady(*crd) {
+				return false, nil
+			}
+		case *appsv1.StatefulSet, *appsv1beta1.StatefulSet, *appsv1beta2.StatefulSet:
+			sts, err := w.c.AppsV1().StatefulSets(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			if !w.statefulSetReady(sts) {
+				return false, nil
+			}
+		case *corev1.ReplicationController, *extensionsv1beta1.ReplicaSet, *appsv1beta2.ReplicaSet, *appsv1.ReplicaSet:
+			ok, err = w.podsReadyForObject(v.Namespace, value)
 		}
-		return true, nil
-	})
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
 func (w *waiter) podsReadyForObject(namespace string, obj runtime.Object) (bool, error) {
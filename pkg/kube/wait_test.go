@@ -17,16 +17,30 @@ limitations under the License.
 package kube // import "helm.sh/helm/v3/pkg/kube"
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes/fake"
+	restfake "k8s.io/client-go/rest/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 const defaultNamespace = metav1.NamespaceDefault
@@ -242,6 +256,245 @@ func Test_waiter_podsReadyForObject(t *testing.T) {
 	}
 }
 
+func Test_waiter_waitForResourcesTransientErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		retries   int
+		err       error
+		wantErr   bool
+		wantCalls int
+	}{
+		{
+			name:      "recovers from transient errors",
+			failures:  2,
+			retries:   3,
+			err:       apierrors.NewServiceUnavailable("apiserver restarting"),
+			wantCalls: 3,
+		},
+		{
+			name:      "recovers from server timeouts",
+			failures:  1,
+			retries:   1,
+			err:       apierrors.NewServerTimeout(schema.GroupResource{Resource: "pods"}, "get", 1),
+			wantCalls: 2,
+		},
+		{
+			name:      "fails after too many consecutive transient errors",
+			failures:  3,
+			retries:   2,
+			err:       apierrors.NewInternalError(errors.New("etcd unavailable")),
+			wantErr:   true,
+			wantCalls: 3,
+		},
+		{
+			name:      "fails on non transient errors",
+			failures:  1,
+			retries:   3,
+			err:       apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "foo", errors.New("denied")),
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newPodWithCondition("foo", corev1.ConditionTrue)
+			c := fake.NewSimpleClientset(pod)
+			calls := 0
+			c.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				calls++
+				if calls <= tt.failures {
+					return true, nil, tt.err
+				}
+				return false, nil, nil
+			})
+			w := &waiter{
+				c:        c,
+				log:      nopLogger,
+				timeout:  5 * time.Second,
+				retries:  tt.retries,
+				interval: 10 * time.Millisecond,
+			}
+			resources := ResourceList{{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+				Object:    pod,
+				Mapping: &meta.RESTMapping{
+					GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+				},
+			}}
+
+			err := w.waitForResources(resources, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("waitForResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("waitForResources() made %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_waiter_readinessEvaluator(t *testing.T) {
+	widgetGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	widget := func(phase string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Widget",
+			"metadata":   map[string]interface{}{"name": "foo", "namespace": defaultNamespace},
+			"status":     map[string]interface{}{"phase": phase},
+		}}
+	}
+
+	gets := 0
+	client := &restfake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.Method != "GET" {
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+			}
+			gets++
+			phase := "Pending"
+			if gets >= 3 {
+				phase = "Running"
+			}
+			body, err := json.Marshal(widget(phase).Object)
+			if err != nil {
+				return nil, err
+			}
+			header := http.Header{}
+			header.Set("Content-Type", runtime.ContentTypeJSON)
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+		}),
+	}
+
+	c := &Client{}
+	evaluations := 0
+	c.RegisterReadinessEvaluator(widgetGVK, func(obj runtime.Object) (bool, error) {
+		evaluations++
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return false, fmt.Errorf("unexpected object %T", obj)
+		}
+		phase, _, err := unstructured.NestedString(u.Object, "status", "phase")
+		return phase == "Running", err
+	})
+
+	// the pod has no evaluator and falls back to the built-in check
+	pod := newPodWithCondition("bar", corev1.ConditionTrue)
+	w := &waiter{
+		c:          fake.NewSimpleClientset(pod),
+		log:        nopLogger,
+		timeout:    5 * time.Second,
+		interval:   10 * time.Millisecond,
+		evaluators: c.readinessEvaluators,
+	}
+	resources := ResourceList{
+		{
+			Client:    client,
+			Name:      "foo",
+			Namespace: defaultNamespace,
+			Object:    widget(""),
+			Mapping: &meta.RESTMapping{
+				Resource:         widgetGVK.GroupVersion().WithResource("widgets"),
+				GroupVersionKind: widgetGVK,
+				Scope:            meta.RESTScopeNamespace,
+			},
+		},
+		{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Object:    pod,
+			Mapping: &meta.RESTMapping{
+				GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			},
+		},
+	}
+
+	if err := w.waitForResources(resources, false); err != nil {
+		t.Fatal(err)
+	}
+	if evaluations != 3 {
+		t.Errorf("expected the widget to be evaluated 3 times, got %d", evaluations)
+	}
+
+	failing := errors.New("cannot evaluate")
+	c.RegisterReadinessEvaluator(widgetGVK, func(runtime.Object) (bool, error) {
+		return false, failing
+	})
+	w.evaluators = c.readinessEvaluators
+	if err := w.waitForResources(resources, false); !errors.Is(err, failing) {
+		t.Errorf("expected the evaluator error, got %v", err)
+	}
+}
+
+func Test_waiter_waitForDeletedResources(t *testing.T) {
+	serviceGVK := schema.GroupVersionKind{Version: "v1", Kind: "Service"}
+	newLingeringService := func(lingerFor int) (*resource.Info, *int) {
+		gets := 0
+		client := &restfake.RESTClient{
+			NegotiatedSerializer: unstructuredSerializer,
+			Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				if req.Method != "GET" {
+					t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				}
+				gets++
+				header := http.Header{}
+				header.Set("Content-Type", runtime.ContentTypeJSON)
+				if lingerFor >= 0 && gets > lingerFor {
+					return &http.Response{StatusCode: http.StatusNotFound, Header: header, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+				}
+				// the service is being deleted, but a finalizer holds it back
+				body, err := json.Marshal(map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Service",
+					"metadata": map[string]interface{}{
+						"name":              "web",
+						"namespace":         defaultNamespace,
+						"deletionTimestamp": "2021-01-01T00:00:00Z",
+						"finalizers":        []string{"example.com/cleanup"},
+					},
+				})
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+			}),
+		}
+		return &resource.Info{
+			Client:    client,
+			Name:      "web",
+			Namespace: defaultNamespace,
+			Mapping: &meta.RESTMapping{
+				Resource:         serviceGVK.GroupVersion().WithResource("services"),
+				GroupVersionKind: serviceGVK,
+				Scope:            meta.RESTScopeNamespace,
+			},
+		}, &gets
+	}
+
+	info, gets := newLingeringService(2)
+	w := &waiter{
+		log:      nopLogger,
+		timeout:  5 * time.Second,
+		interval: 10 * time.Millisecond,
+	}
+	if err := w.waitForDeletedResources(ResourceList{info}); err != nil {
+		t.Fatal(err)
+	}
+	if *gets != 3 {
+		t.Errorf("expected the service to be read 3 times, got %d", *gets)
+	}
+
+	// a resource that is never deleted times out
+	info, _ = newLingeringService(-1)
+	w.timeout = 50 * time.Millisecond
+	err := w.waitForDeletedResources(ResourceList{info})
+	if err == nil || err.Error() != "timed out waiting for 1 resources to be deleted" {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
 func Test_waiter_jobReady(t *testing.T) {
 	type args struct {
 		job *batchv1.Job
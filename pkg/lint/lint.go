@@ -18,6 +18,7 @@ package lint // import "helm.sh/helm/v3/pkg/lint"
 
 import (
 	"path/filepath"
+	"regexp"
 
 	"helm.sh/helm/v3/pkg/lint/rules"
 	"helm.sh/helm/v3/pkg/lint/support"
@@ -25,13 +26,51 @@ import (
 
 // All runs all of the available linters on the given base directory.
 func All(basedir string, values map[string]interface{}, namespace string, strict bool) support.Linter {
+	return AllWithOptions(basedir, values, namespace, Options{})
+}
+
+// Options configures the linters run by AllWithOptions.
+type Options struct {
+	// KubeVersion, when set, reports the rendered resources using APIs
+	// deprecated or removed in this Kubernetes version
+	KubeVersion string
+	// UnsetValues are paths, as read by chartutil.UnsetValueAtPath, removed
+	// from the values once they are coalesced with the chart's defaults
+	UnsetValues []string
+	// UnusedValues warns about the values that no template references
+	UnusedValues bool
+	// RequireResources, when set, reports the containers of the rendered
+	// workloads that declare no resource requests, limits or either, with
+	// RequireResourcesSeverity
+	RequireResources         rules.ResourceRequirement
+	RequireResourcesSeverity int
+	// SecretValuePattern matches the dotted paths of the values NOTES.txt
+	// should not print. It defaults to rules.DefaultSecretValuePattern.
+	SecretValuePattern *regexp.Regexp
+}
+
+// AllWithOptions runs all of the available linters on the given base
+// directory. The chart is rendered once, and the rules inspecting the
+// rendered templates share the result.
+func AllWithOptions(basedir string, values map[string]interface{}, namespace string, opts Options) support.Linter {
 	// Using abs path to get directory context
 	chartDir, _ := filepath.Abs(basedir)
 
 	linter := support.Linter{ChartDir: chartDir}
 	rules.Chartfile(&linter)
 	rules.ValuesWithOverrides(&linter, values)
-	rules.Templates(&linter, values, namespace, strict)
+	rendered := rules.RenderTemplates(&linter, values, rules.RenderOptions{
+		Namespace:   namespace,
+		KubeVersion: opts.KubeVersion,
+		UnsetValues: opts.UnsetValues,
+	})
+	rules.SecretNotes(&linter, rendered, opts.SecretValuePattern)
+	if opts.UnusedValues {
+		rules.UnusedValues(&linter, rendered)
+	}
+	if opts.RequireResources != 0 {
+		rules.ContainerResources(&linter, rendered, opts.RequireResources, opts.RequireResourcesSeverity)
+	}
 	rules.Dependencies(&linter)
 	return linter
 }
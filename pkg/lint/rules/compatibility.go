@@ -0,0 +1,215 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apiserver/pkg/endpoints/deprecation"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// APIIssueKind is the kind of problem found with an API a chart uses.
+type APIIssueKind string
+
+const (
+	// APIDeprecated is an API that is deprecated in the target version. It
+	// still works, so the chart remains compatible.
+	APIDeprecated APIIssueKind = "deprecated"
+	// APIRemoved is an API that has been removed in the target version.
+	APIRemoved APIIssueKind = "removed"
+	// APIUnavailable is an API missing from the API versions of the target.
+	APIUnavailable APIIssueKind = "unavailable"
+)
+
+// KubeTarget is a Kubernetes version a chart is checked against.
+type KubeTarget struct {
+	// Version is the Kubernetes version, such as "1.22" or "v1.22.3".
+	Version string
+	// APIVersions are the API versions served by the target, in the form
+	// "group/version" or "group/version/Kind". When empty, only the APIs
+	// removed by Version are reported.
+	APIVersions chartutil.VersionSet
+}
+
+// APIIssue is a problem with an API used by a rendered template.
+type APIIssue struct {
+	Kind       APIIssueKind
+	Template   string
+	APIVersion string
+	ObjectKind string
+	Message    string
+}
+
+// KubeCompatibility is the compatibility of a chart with a KubeTarget.
+type KubeCompatibility struct {
+	Target KubeTarget
+	// RenderError is set when the chart does not render for the target.
+	RenderError error
+	Issues      []APIIssue
+}
+
+// Compatible reports whether the chart renders for the target, using no
+// removed or unavailable APIs.
+func (k KubeCompatibility) Compatible() bool {
+	if k.RenderError != nil {
+		return false
+	}
+	for _, issue := range k.Issues {
+		if issue.Kind != APIDeprecated {
+			return false
+		}
+	}
+	return true
+}
+
+// KubeCompatibilityMatrix renders the chart with values for each of the
+// targets, as "helm template --kube-version" would, and checks the APIs of
+// the rendered resources against the deprecations and removals of the target
+// version. The results are returned in the order of targets.
+//
+// Deprecations and removals are only known for the APIs built into
+// Kubernetes, as of the client library Helm was built with.
+func KubeCompatibilityMatrix(c *chart.Chart, values map[string]interface{}, namespace string, targets []KubeTarget) ([]KubeCompatibility, error) {
+	cvals, err := chartutil.CoalesceValues(c, values)
+	if err != nil {
+		return nil, err
+	}
+	matrix := make([]KubeCompatibility, 0, len(targets))
+	for _, target := range targets {
+		caps, major, minor, err := targetCapabilities(target)
+		if err != nil {
+			return nil, err
+		}
+		result := KubeCompatibility{Target: target}
+		result.Issues, result.RenderError = checkKubeTarget(c, cvals, namespace, caps, target.APIVersions, major, minor)
+		matrix = append(matrix, result)
+	}
+	return matrix, nil
+}
+
+// targetCapabilities returns the capabilities of a cluster running target.
+func targetCapabilities(target KubeTarget) (*chartutil.Capabilities, int, int, error) {
+	v, err := semver.NewVersion(target.Version)
+	if err != nil {
+		return nil, 0, 0, errors.Wrapf(err, "invalid Kubernetes version %q", target.Version)
+	}
+	caps := &chartutil.Capabilities{
+		KubeVersion: chartutil.KubeVersion{
+			Version: "v" + v.String(),
+			Major:   strconv.FormatUint(v.Major(), 10),
+			Minor:   strconv.FormatUint(v.Minor(), 10),
+		},
+		APIVersions: target.APIVersions,
+		HelmVersion: chartutil.DefaultCapabilities.HelmVersion,
+	}
+	if len(caps.APIVersions) == 0 {
+		caps.APIVersions = chartutil.DefaultVersionSet
+	}
+	return caps, int(v.Major()), int(v.Minor()), nil
+}
+
+// checkKubeTarget renders the chart with caps and returns the issues with
+// the APIs of the rendered resources.
+func checkKubeTarget(c *chart.Chart, cvals map[string]interface{}, namespace string, caps *chartutil.Capabilities, apis chartutil.VersionSet, major, minor int) ([]APIIssue, error) {
+	options := chartutil.ReleaseOptions{Name: "test-release", Namespace: namespace}
+	valuesToRender, err := chartutil.ToRenderValues(c, cvals, options, caps)
+	if err != nil {
+		return nil, err
+	}
+	var e engine.Engine
+	e.LintMode = true
+	rendered, err := e.Render(c, valuesToRender)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []APIIssue
+	for _, name := range names {
+		if strings.HasPrefix(path.Base(name), "_") || strings.HasSuffix(name, "NOTES.txt") {
+			continue
+		}
+		decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(rendered[name]), 4096)
+		for {
+			var resource *K8sYamlStruct
+			err := decoder.Decode(&resource)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to parse %s", name)
+			}
+			if resource == nil || resource.APIVersion == "" || resource.Kind == "" {
+				continue
+			}
+			if issue, ok := checkKubeAPI(resource, apis, major, minor); ok {
+				issue.Template = name
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues, nil
+}
+
+// checkKubeAPI checks the API of resource against the target version
+// major.minor and, unless empty, the API versions apis.
+func checkKubeAPI(resource *K8sYamlStruct, apis chartutil.VersionSet, major, minor int) (APIIssue, bool) {
+	issue := APIIssue{APIVersion: resource.APIVersion, ObjectKind: resource.Kind}
+	gvk := fmt.Sprintf("%s %s", resource.APIVersion, resource.Kind)
+
+	obj, err := resourceToRuntimeObject(resource)
+	if err == nil {
+		if rmMajor, rmMinor, ok := apiRemovedIn(obj, major, minor); ok {
+			issue.Kind = APIRemoved
+			issue.Message = fmt.Sprintf("%s was removed in Kubernetes %d.%d", gvk, rmMajor, rmMinor)
+			return issue, true
+		}
+	} else if !runtime.IsNotRegisteredError(err) {
+		return issue, false
+	}
+
+	if len(apis) > 0 && !apis.Has(resource.APIVersion) && !apis.Has(resource.APIVersion+"/"+resource.Kind) {
+		issue.Kind = APIUnavailable
+		issue.Message = fmt.Sprintf("%s is not served by the target", gvk)
+		return issue, true
+	}
+
+	if obj != nil && deprecation.IsDeprecated(obj, major, minor) {
+		issue.Kind = APIDeprecated
+		issue.Message = deprecation.WarningMessage(obj)
+		return issue, true
+	}
+	return issue, false
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestKubeCompatibilityMatrix(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "matrix", APIVersion: "v2", Version: "0.1.0"},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n")},
+			{Name: "templates/deployment.yaml", Data: []byte(`{{- if semverCompare ">=1.16-0" .Capabilities.KubeVersion.Version }}
+apiVersion: apps/v1
+{{- else }}
+apiVersion: extensions/v1beta1
+{{- end }}
+kind: Deployment
+metadata:
+  name: web
+`)},
+			{Name: "templates/NOTES.txt", Data: []byte("installed on {{ .Capabilities.KubeVersion.Version }}")},
+		},
+	}
+
+	matrix, err := KubeCompatibilityMatrix(c, nil, namespace, []KubeTarget{
+		{Version: "1.7"},
+		{Version: "1.15.3"},
+		{Version: "v1.16"},
+		{Version: "1.20", APIVersions: chartutil.VersionSet{"v1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		compatible bool
+		issues     []APIIssueKind
+	}{
+		{true, nil},
+		{true, []APIIssueKind{APIDeprecated}},
+		{true, nil},
+		{false, []APIIssueKind{APIUnavailable}},
+	}
+	if len(matrix) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(matrix))
+	}
+	for i, result := range matrix {
+		if result.RenderError != nil {
+			t.Errorf("%s: unexpected render error: %s", result.Target.Version, result.RenderError)
+		}
+		if result.Compatible() != expected[i].compatible {
+			t.Errorf("%s: expected compatible to be %t", result.Target.Version, expected[i].compatible)
+		}
+		if len(result.Issues) != len(expected[i].issues) {
+			t.Errorf("%s: expected issues %v, got %v", result.Target.Version, expected[i].issues, result.Issues)
+			continue
+		}
+		for j, issue := range result.Issues {
+			if issue.Kind != expected[i].issues[j] || issue.Template != "matrix/templates/deployment.yaml" {
+				t.Errorf("%s: unexpected issue %+v", result.Target.Version, issue)
+			}
+		}
+	}
+
+	if _, err := KubeCompatibilityMatrix(c, nil, namespace, []KubeTarget{{Version: "one.twenty"}}); err == nil {
+		t.Error("expected an error for an invalid Kubernetes version")
+	}
+}
+
+func TestKubeCompatibilityRemovedAPI(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "removed", APIVersion: "v2", Version: "0.1.0"},
+		Templates: []*chart.File{
+			{Name: "templates/deployment.yaml", Data: []byte("apiVersion: extensions/v1beta1\nkind: Deployment\nmetadata:\n  name: web\n")},
+		},
+	}
+	matrix, err := KubeCompatibilityMatrix(c, nil, namespace, []KubeTarget{{Version: "1.16"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matrix[0].Compatible() || len(matrix[0].Issues) != 1 || matrix[0].Issues[0].Kind != APIRemoved {
+		t.Errorf("expected extensions/v1beta1 Deployment to be removed in 1.16, got %+v", matrix[0])
+	}
+}
@@ -18,11 +18,14 @@ package rules // import "helm.sh/helm/v3/pkg/lint/rules"
 
 import (
 	"fmt"
+	"strconv"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apiserver/pkg/endpoints/deprecation"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
+
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 const (
@@ -43,7 +46,10 @@ func (e deprecatedAPIError) Error() string {
 	return msg
 }
 
-func validateNoDeprecations(resource *K8sYamlStruct) error {
+// validateNoDeprecations returns an error if the API of resource is deprecated
+// in kubeVersion or, when kubeVersion is nil, in the Kubernetes version Helm
+// was built with. An API removed in a given kubeVersion is reported as well.
+func validateNoDeprecations(resource *K8sYamlStruct, kubeVersion *chartutil.KubeVersion) error {
 	// if `resource` does not have an APIVersion or Kind, we cannot test it for deprecation
 	if resource.APIVersion == "" {
 		return nil
@@ -60,10 +66,26 @@ func validateNoDeprecations(resource *K8sYamlStruct) error {
 		}
 		return err
 	}
-	if !deprecation.IsDeprecated(runtimeObject, k8sVersionMajor, k8sVersionMinor) {
+	gvk := fmt.Sprintf("%s %s", resource.APIVersion, resource.Kind)
+
+	major, minor := k8sVersionMajor, k8sVersionMinor
+	if kubeVersion != nil {
+		if major, err = strconv.Atoi(kubeVersion.Major); err != nil {
+			return err
+		}
+		if minor, err = strconv.Atoi(kubeVersion.Minor); err != nil {
+			return err
+		}
+		if rmMajor, rmMinor, ok := apiRemovedIn(runtimeObject, major, minor); ok {
+			return deprecatedAPIError{
+				Deprecated: gvk,
+				Message:    fmt.Sprintf("%s was removed in Kubernetes %d.%d", gvk, rmMajor, rmMinor),
+			}
+		}
+	}
+	if !deprecation.IsDeprecated(runtimeObject, major, minor) {
 		return nil
 	}
-	gvk := fmt.Sprintf("%s %s", resource.APIVersion, resource.Kind)
 	return deprecatedAPIError{
 		Deprecated: gvk,
 		Message:    deprecation.WarningMessage(runtimeObject),
@@ -82,3 +104,23 @@ func resourceToRuntimeObject(resource *K8sYamlStruct) (runtime.Object, error) {
 	out.GetObjectKind().SetGroupVersionKind(gvk)
 	return out, nil
 }
+
+// apiRemovedIn returns the Kubernetes version in which the API of obj is
+// removed, if that is major.minor or earlier.
+func apiRemovedIn(obj runtime.Object, major, minor int) (int, int, bool) {
+	removed, ok := obj.(removedAPI)
+	if !ok {
+		return 0, 0, false
+	}
+	rmMajor, rmMinor := removed.APILifecycleRemoved()
+	if rmMajor > 0 && (major > rmMajor || major == rmMajor && minor >= rmMinor) {
+		return rmMajor, rmMinor, true
+	}
+	return 0, 0, false
+}
+
+// removedAPI is implemented by the built-in API types that have a release
+// in which they are removed.
+type removedAPI interface {
+	APILifecycleRemoved() (major, minor int)
+}
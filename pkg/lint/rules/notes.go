@@ -0,0 +1,120 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+// DefaultSecretValuePattern matches the dotted paths of the values that
+// SecretNotes treats as secrets unless told otherwise, such as auth.password
+// or db.api-key.
+const DefaultSecretValuePattern = `(?i)(^|[._-])(password|passwd|secret|token|api_?key)$`
+
+var defaultSecretValuePattern = regexp.MustCompile(DefaultSecretValuePattern)
+
+// SecretNotes warns when the NOTES.txt template of the rendered chart prints
+// a value whose dotted path matches pattern, such as .Values.auth.password.
+// A nil pattern uses DefaultSecretValuePattern.
+//
+// The notes are rendered again with every such value replaced by a marker,
+// so that references through variables, includes and functions are
+// detected as long as the value is printed unchanged.
+func SecretNotes(linter *support.Linter, rendered *Rendered, pattern *regexp.Regexp) {
+	fpath := "templates/NOTES.txt"
+	if rendered == nil {
+		// reported by the Templates rule
+		return
+	}
+	if _, ok := rendered.Templates[path.Join(rendered.Chart.Name(), fpath)]; !ok {
+		return
+	}
+	if pattern == nil {
+		pattern = defaultSecretValuePattern
+	}
+
+	vals, err := rendered.Values.Table("Values")
+	if err != nil {
+		return
+	}
+	markers := map[string]string{}
+	marked := markSecretValues(vals, "", pattern, markers)
+	if len(markers) == 0 {
+		return
+	}
+
+	valuesToRender := chartutil.Values{}
+	for k, v := range rendered.Values {
+		valuesToRender[k] = v
+	}
+	valuesToRender["Values"] = marked
+	var e engine.Engine
+	e.LintMode = true
+	notes, err := e.RenderNotes(rendered.Chart, valuesToRender)
+	if err != nil {
+		return
+	}
+
+	var leaked []string
+	for marker, key := range markers {
+		if strings.Contains(notes, marker) {
+			leaked = append(leaked, key)
+		}
+	}
+	sort.Strings(leaked)
+	for _, key := range leaked {
+		linter.RunLinterRule(support.WarningSev, fpath, errors.Errorf("NOTES.txt prints the value of %q, which looks like a secret", key))
+	}
+}
+
+// markSecretValues returns a copy of vals where every scalar value whose
+// dotted path matches pattern is replaced by a unique marker, recorded in
+// markers with the path of the value.
+func markSecretValues(vals map[string]interface{}, prefix string, pattern *regexp.Regexp, markers map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(vals))
+	for k, v := range vals {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			out[k] = markSecretValues(v, key, pattern, markers)
+		case []interface{}, nil:
+			out[k] = v
+		default:
+			if !pattern.MatchString(key) {
+				out[k] = v
+				continue
+			}
+			marker := fmt.Sprintf("<helm-lint-secret-%d>", len(markers))
+			markers[marker] = key
+			out[k] = marker
+		}
+	}
+	return out
+}
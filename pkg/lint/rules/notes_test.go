@@ -0,0 +1,125 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+func TestNotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		notes    string
+		expected string
+	}{
+		{name: "nonotes"},
+		{name: "goodnotes", notes: "Thanks for installing {{ .Chart.Name }} {{ .Values.greeting }}"},
+		{name: "badnotes", notes: "{{ .Values.greeting | nosuchfunc }}", expected: `function "nosuchfunc" not defined`},
+		{name: "failingnotes", notes: `{{ fail "broken notes" }}`, expected: "broken notes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := &chart.Chart{
+				Metadata: &chart.Metadata{
+					Name:       tt.name,
+					APIVersion: "v2",
+					Version:    "0.1.0",
+				},
+				Values: map[string]interface{}{"greeting": "hello"},
+				Templates: []*chart.File{
+					{Name: "templates/configmap.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: notes\n")},
+				},
+			}
+			if tt.notes != "" {
+				ch.Templates = append(ch.Templates, &chart.File{Name: "templates/NOTES.txt", Data: []byte(tt.notes)})
+			}
+			dir := ensure.TempDir(t)
+			defer os.RemoveAll(dir)
+			if err := chartutil.SaveDir(ch, dir); err != nil {
+				t.Fatal(err)
+			}
+
+			linter := &support.Linter{ChartDir: filepath.Join(dir, tt.name)}
+			Templates(linter, nil, namespace, false)
+
+			if tt.expected == "" {
+				if len(linter.Messages) != 0 {
+					t.Errorf("expected no messages, got %v", linter.Messages)
+				}
+				return
+			}
+			// NOTES.txt is rendered with the other templates, and its errors
+			// are reported once
+			if len(linter.Messages) != 1 {
+				t.Fatalf("expected one message, got %v", linter.Messages)
+			}
+			if msg := linter.Messages[0]; msg.Severity != support.ErrorSev || !strings.Contains(msg.Err.Error(), "NOTES.txt") || !strings.Contains(msg.Err.Error(), tt.expected) {
+				t.Errorf("unexpected message %v", msg)
+			}
+		})
+	}
+}
+
+func TestSecretNotes(t *testing.T) {
+	linter := &support.Linter{ChartDir: "./testdata/leakynotes"}
+	SecretNotes(linter, renderChart(t, "./testdata/leakynotes", nil), nil)
+
+	// ui.hotkey does not match the pattern, which is anchored to a key
+	expected := []string{
+		`NOTES.txt prints the value of "auth.apiKey", which looks like a secret`,
+		`NOTES.txt prints the value of "auth.password", which looks like a secret`,
+	}
+	if len(linter.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %v", len(expected), linter.Messages)
+	}
+	for i, msg := range linter.Messages {
+		if msg.Severity != support.WarningSev || msg.Path != "templates/NOTES.txt" || msg.Err.Error() != expected[i] {
+			t.Errorf("unexpected message %v", msg)
+		}
+	}
+
+	// the values supplied by the user are the ones checked
+	linter = &support.Linter{ChartDir: "./testdata/leakynotes"}
+	vals := map[string]interface{}{"auth": map[string]interface{}{"password": nil}}
+	SecretNotes(linter, renderChart(t, "./testdata/leakynotes", vals), nil)
+	if len(linter.Messages) != 1 {
+		t.Errorf("expected one message once the password is unset, got %v", linter.Messages)
+	}
+
+	// the pattern is configurable
+	linter = &support.Linter{ChartDir: "./testdata/leakynotes"}
+	SecretNotes(linter, renderChart(t, "./testdata/leakynotes", nil), regexp.MustCompile(`^ui\.hotkey$`))
+	if len(linter.Messages) != 1 || !strings.Contains(linter.Messages[0].Err.Error(), `"ui.hotkey"`) {
+		t.Errorf("expected one message for ui.hotkey, got %v", linter.Messages)
+	}
+
+	linter = &support.Linter{ChartDir: "./testdata/goodone"}
+	SecretNotes(linter, renderChart(t, "./testdata/goodone", nil), nil)
+	if len(linter.Messages) != 0 {
+		t.Errorf("expected no messages, got %v", linter.Messages)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+// ResourceRequirement selects the compute resources that ContainerResources
+// expects every container to declare.
+type ResourceRequirement int
+
+const (
+	// RequireRequests expects resource requests.
+	RequireRequests ResourceRequirement = 1 << iota
+	// RequireLimits expects resource limits.
+	RequireLimits
+	// RequireRequestsAndLimits expects both resource requests and limits.
+	RequireRequestsAndLimits = RequireRequests | RequireLimits
+)
+
+// podTemplate is the pod template of a workload.
+type podTemplate struct {
+	Spec v1.PodSpec `json:"spec"`
+}
+
+// workload holds the fields of the resources that create pods needed to
+// find their containers.
+type workload struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		// Containers is set for a Pod, whose spec is the pod spec
+		Containers     []v1.Container `json:"containers"`
+		InitContainers []v1.Container `json:"initContainers"`
+		Template       podTemplate    `json:"template"`
+		JobTemplate    struct {
+			Spec struct {
+				Template podTemplate `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// podSpec returns the pod spec of the workload, or false for the resources
+// that do not create pods.
+func (w *workload) podSpec() (v1.PodSpec, bool) {
+	switch w.Kind {
+	case "Pod":
+		return v1.PodSpec{Containers: w.Spec.Containers, InitContainers: w.Spec.InitContainers}, true
+	case "Deployment", "ReplicaSet", "ReplicationController", "StatefulSet", "DaemonSet", "Job":
+		return w.Spec.Template.Spec, true
+	case "CronJob":
+		return w.Spec.JobTemplate.Spec.Template.Spec, true
+	}
+	return v1.PodSpec{}, false
+}
+
+// ContainerResources reports, with the given severity, each container of the
+// workloads of the rendered chart that declares no resource requests or
+// limits, as selected by required.
+//
+// Charts that did not render are left to the Templates rule.
+func ContainerResources(linter *support.Linter, rendered *Rendered, required ResourceRequirement, severity int) {
+	if rendered == nil {
+		return
+	}
+
+	names := make([]string, 0, len(rendered.Templates))
+	for name := range rendered.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if strings.HasPrefix(path.Base(name), "_") || strings.HasSuffix(name, "NOTES.txt") {
+			continue
+		}
+		fpath := strings.TrimPrefix(name, rendered.Chart.Name()+"/")
+		decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(rendered.Templates[name]), 4096)
+		for {
+			var w *workload
+			if err := decoder.Decode(&w); err != nil {
+				// invalid YAML is reported by the Templates rule
+				break
+			}
+			if w == nil {
+				continue
+			}
+			spec, ok := w.podSpec()
+			if !ok {
+				continue
+			}
+			for _, container := range spec.InitContainers {
+				linter.RunLinterRule(severity, fpath, validateContainerResources(w, "init container", container, required))
+			}
+			for _, container := range spec.Containers {
+				linter.RunLinterRule(severity, fpath, validateContainerResources(w, "container", container, required))
+			}
+		}
+	}
+}
+
+func validateContainerResources(w *workload, kind string, container v1.Container, required ResourceRequirement) error {
+	var missing []string
+	if required&RequireRequests != 0 && len(container.Resources.Requests) == 0 {
+		missing = append(missing, "requests")
+	}
+	if required&RequireLimits != 0 && len(container.Resources.Limits) == 0 {
+		missing = append(missing, "limits")
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return errors.Errorf("%s %q of %s %q has no resource %s", kind, container.Name, w.Kind, w.Metadata.Name, strings.Join(missing, " or "))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+func TestContainerResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		required ResourceRequirement
+		expected []string
+	}{
+		{
+			name:     "requests",
+			required: RequireRequests,
+			expected: []string{
+				`templates/missing.yaml: init container "init" of Pod "missing" has no resource requests`,
+				`templates/missing.yaml: container "app" of Pod "missing" has no resource requests`,
+			},
+		},
+		{
+			name:     "limits",
+			required: RequireLimits,
+			expected: []string{
+				`templates/missing.yaml: init container "init" of Pod "missing" has no resource limits`,
+				`templates/missing.yaml: container "app" of Pod "missing" has no resource limits`,
+				`templates/partial.yaml: container "job" of CronJob "partial" has no resource limits`,
+			},
+		},
+		{
+			name:     "both",
+			required: RequireRequestsAndLimits,
+			expected: []string{
+				`templates/missing.yaml: init container "init" of Pod "missing" has no resource requests or limits`,
+				`templates/missing.yaml: container "app" of Pod "missing" has no resource requests or limits`,
+				`templates/partial.yaml: container "job" of CronJob "partial" has no resource limits`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linter := support.Linter{ChartDir: "./testdata/container-resources"}
+			ContainerResources(&linter, renderChart(t, "./testdata/container-resources", nil), tt.required, support.WarningSev)
+
+			if len(linter.Messages) != len(tt.expected) {
+				t.Fatalf("expected %d lint messages, got %v", len(tt.expected), linter.Messages)
+			}
+			for i, msg := range linter.Messages {
+				if got := msg.Path + ": " + msg.Err.Error(); got != tt.expected[i] {
+					t.Errorf("expected message %q, got %q", tt.expected[i], got)
+				}
+				if msg.Severity != support.WarningSev {
+					t.Errorf("expected severity %d, got %d", support.WarningSev, msg.Severity)
+				}
+			}
+		})
+	}
+
+	// the severity is configurable, and values are taken into account
+	linter := support.Linter{ChartDir: "./testdata/container-resources"}
+	vals := map[string]interface{}{"resources": map[string]interface{}{"limits": nil}}
+	ContainerResources(&linter, renderChart(t, "./testdata/container-resources", vals), RequireLimits, support.ErrorSev)
+	if len(linter.Messages) != 4 {
+		t.Fatalf("expected 4 lint messages, got %v", linter.Messages)
+	}
+	if linter.Messages[0].Path != "templates/complete.yaml" || linter.HighestSeverity != support.ErrorSev {
+		t.Errorf("unexpected lint messages %v", linter.Messages)
+	}
+
+	// charts that did not render are skipped
+	linter = support.Linter{ChartDir: "./testdata/container-resources"}
+	ContainerResources(&linter, nil, RequireLimits, support.ErrorSev)
+	if len(linter.Messages) != 0 {
+		t.Errorf("expected no lint messages, got %v", linter.Messages)
+	}
+}
+
+// renderChart renders the chart in dir with values as the Templates rule
+// does, and fails the test if it does not render.
+func renderChart(t *testing.T, dir string, values map[string]interface{}) *Rendered {
+	t.Helper()
+	linter := support.Linter{ChartDir: dir}
+	rendered := RenderTemplates(&linter, values, RenderOptions{Namespace: namespace})
+	if rendered == nil {
+		t.Fatalf("chart %s did not render: %v", dir, linter.Messages)
+	}
+	return rendered
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/engine"
@@ -43,6 +44,36 @@ var (
 
 // Templates lints the templates in the Linter.
 func Templates(linter *support.Linter, values map[string]interface{}, namespace string, strict bool) {
+	RenderTemplates(linter, values, RenderOptions{Namespace: namespace})
+}
+
+// RenderOptions are the options the chart is rendered with by RenderTemplates.
+type RenderOptions struct {
+	Namespace string
+	// KubeVersion is the version of the cluster the chart is rendered for.
+	// Resources using APIs deprecated or removed in that version are
+	// reported as warnings. When empty, the chart is linted for the
+	// Kubernetes version Helm was built with.
+	KubeVersion string
+	// UnsetValues are paths, as read by chartutil.UnsetValueAtPath, removed
+	// from the values once they are coalesced with the chart's defaults
+	UnsetValues []string
+}
+
+// Rendered is a chart rendered for linting. It is shared by the rules that
+// inspect the rendered templates so that the chart is only rendered once.
+type Rendered struct {
+	Chart *chart.Chart
+	// Values is the top-level context the templates were rendered with
+	Values chartutil.Values
+	// Templates are the rendered templates by name, NOTES.txt included
+	Templates map[string]string
+}
+
+// RenderTemplates lints the templates in the Linter, and returns the rendered
+// chart for the other rules to inspect. It returns nil when the chart could not be rendered,
+// which has been reported.
+func RenderTemplates(linter *support.Linter, values map[string]interface{}, opts RenderOptions) *Rendered {
 	fpath := "templates/"
 	templatesPath := filepath.Join(linter.ChartDir, fpath)
 
@@ -50,7 +81,7 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 
 	// Templates directory is optional for now
 	if !templatesDirExist {
-		return
+		return nil
 	}
 
 	// Load chart and parse templates
@@ -59,22 +90,31 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 	chartLoaded := linter.RunLinterRule(support.ErrorSev, fpath, err)
 
 	if !chartLoaded {
-		return
+		return nil
 	}
 
 	options := chartutil.ReleaseOptions{
 		Name:      "test-release",
-		Namespace: namespace,
+		Namespace: opts.Namespace,
 	}
 
-	cvals, err := chartutil.CoalesceValues(chart, values)
-	if err != nil {
-		return
+	var caps *chartutil.Capabilities
+	var targetVersion *chartutil.KubeVersion
+	if opts.KubeVersion != "" {
+		caps, _, _, err = targetCapabilities(KubeTarget{Version: opts.KubeVersion})
+		if !linter.RunLinterRule(support.ErrorSev, fpath, err) {
+			return nil
+		}
+		targetVersion = &caps.KubeVersion
 	}
-	valuesToRender, err := chartutil.ToRenderValues(chart, cvals, options, nil)
+
+	valuesToRender, err := chartutil.ToRenderValues(chart, values, options, caps)
 	if err != nil {
 		linter.RunLinterRule(support.ErrorSev, fpath, err)
-		return
+		return nil
+	}
+	if !linter.RunLinterRule(support.ErrorSev, fpath, chartutil.UnsetRenderValues(valuesToRender, opts.UnsetValues)) {
+		return nil
 	}
 	var e engine.Engine
 	e.LintMode = true
@@ -83,7 +123,7 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 	renderOk := linter.RunLinterRule(support.ErrorSev, fpath, err)
 
 	if !renderOk {
-		return
+		return nil
 	}
 
 	/* Iterate over all the templates to check:
@@ -140,13 +180,14 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 					// NOTE: set to warnings to allow users to support out-of-date kubernetes
 					// Refs https://github.com/helm/helm/issues/8596
 					linter.RunLinterRule(support.WarningSev, fpath, validateMetadataName(yamlStruct))
-					linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct))
+					linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct, targetVersion))
 
 					linter.RunLinterRule(support.ErrorSev, fpath, validateMatchSelector(yamlStruct, renderedContent))
 				}
 			}
 		}
 	}
+	return &Rendered{Chart: chart, Values: valuesToRender, Templates: renderedContentMap}
 }
 
 // validateTopIndentLevel checks that the content does not start with an indent level > 0.
@@ -199,6 +199,59 @@ func TestDeprecatedAPIFails(t *testing.T) {
 	}
 }
 
+func TestRenderTemplatesKubeVersion(t *testing.T) {
+	tests := []struct {
+		kubeVersion string
+		message     string
+	}{
+		{kubeVersion: "1.13"},
+		{kubeVersion: "1.19", message: "extensions/v1beta1 Ingress is deprecated"},
+		{kubeVersion: "v1.22.0", message: "extensions/v1beta1 Ingress was removed in Kubernetes 1.22"},
+	}
+	for _, tt := range tests {
+		linter := support.Linter{ChartDir: "./testdata/deprecated-ingress"}
+		RenderTemplates(&linter, values, RenderOptions{Namespace: namespace, KubeVersion: tt.kubeVersion})
+
+		if tt.message == "" {
+			if len(linter.Messages) != 0 {
+				t.Errorf("%s: expected no lint messages, got %v", tt.kubeVersion, linter.Messages)
+			}
+			continue
+		}
+		if len(linter.Messages) != 1 {
+			t.Errorf("%s: expected 1 lint message, got %v", tt.kubeVersion, linter.Messages)
+			continue
+		}
+		msg := linter.Messages[0]
+		if msg.Severity != support.WarningSev || msg.Path != "templates/ingress.yaml" || !strings.Contains(msg.Err.Error(), tt.message) {
+			t.Errorf("%s: unexpected lint message %v", tt.kubeVersion, msg)
+		}
+	}
+
+	linter := support.Linter{ChartDir: "./testdata/deprecated-ingress"}
+	RenderTemplates(&linter, values, RenderOptions{Namespace: namespace, KubeVersion: "one.twenty"})
+	if len(linter.Messages) != 1 || linter.Messages[0].Severity != support.ErrorSev {
+		t.Errorf("expected an error for an invalid Kubernetes version, got %v", linter.Messages)
+	}
+}
+
+func TestRenderTemplatesUnsetValues(t *testing.T) {
+	linter := support.Linter{ChartDir: "./testdata/container-resources"}
+	rendered := RenderTemplates(&linter, nil, RenderOptions{Namespace: namespace, UnsetValues: []string{"resources.limits"}})
+	if rendered == nil {
+		t.Fatalf("expected the chart to render, got %v", linter.Messages)
+	}
+	if out := rendered.Templates["container-resources/templates/complete.yaml"]; strings.Contains(out, "limits") {
+		t.Errorf("expected the limits to be unset, got %s", out)
+	}
+
+	linter = support.Linter{ChartDir: "./testdata/container-resources"}
+	rendered = RenderTemplates(&linter, nil, RenderOptions{Namespace: namespace, UnsetValues: []string{"resources[0]"}})
+	if rendered != nil || len(linter.Messages) != 1 || linter.Messages[0].Severity != support.ErrorSev {
+		t.Errorf("expected an error unsetting an index of a table, got %v", linter.Messages)
+	}
+}
+
 const manifest = `apiVersion: v1
 kind: ConfigMap
 metadata:
@@ -0,0 +1,3 @@
+{{- define "leakynotes.apiKey" -}}
+API key: {{ .Values.auth.apiKey | quote }}
+{{- end -}}
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/lint/support"
 )
 
@@ -52,6 +53,24 @@ func ValuesWithOverrides(linter *support.Linter, values map[string]interface{})
 	linter.RunLinterRule(support.ErrorSev, file, validateValuesFile(vf, values))
 }
 
+// UnusedValues warns about each value set in the values.yaml file that no
+// template of the rendered chart or of its subcharts references, so that dead
+// configuration can be pruned. See engine.UnusedValues for how references
+// are found. Charts that did not render are left to the Templates rule.
+func UnusedValues(linter *support.Linter, rendered *Rendered) {
+	if rendered == nil {
+		return
+	}
+	unused, err := engine.UnusedValues(rendered.Chart)
+	if err != nil {
+		// template parse errors are reported by the Templates rule
+		return
+	}
+	for _, key := range unused {
+		linter.RunLinterRule(support.WarningSev, "values.yaml", errors.Errorf("value %q is not used by any template", key))
+	}
+}
+
 func validateValuesFileExistence(valuesPath string) error {
 	_, err := os.Stat(valuesPath)
 	if err != nil {
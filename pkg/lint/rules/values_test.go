@@ -25,6 +25,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 var nonExistingValuesFilePath = filepath.Join("/fake/dir", "values.yaml")
@@ -126,3 +128,35 @@ func createTestingSchema(t *testing.T, dir string) string {
 	}
 	return schemafile
 }
+
+func TestUnusedValues(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "unused",
+			Version:    "0.1.0",
+		},
+		Values: map[string]interface{}{
+			"image":    map[string]interface{}{"repository": "nginx", "tag": "latest"},
+			"replicas": 1,
+		},
+		Templates: []*chart.File{
+			{Name: "templates/deployment.yaml", Data: []byte("image: {{ .Values.image.repository }}\nreplicas: {{ .Values.replicas }}\n")},
+		},
+	}
+
+	linter := support.Linter{}
+	UnusedValues(&linter, &Rendered{Chart: &mychart})
+	if len(linter.Messages) != 1 {
+		t.Fatalf("expected 1 lint warning, got %v", linter.Messages)
+	}
+	msg := linter.Messages[0]
+	assert.Equal(t, support.WarningSev, msg.Severity)
+	assert.Equal(t, "values.yaml", msg.Path)
+	assert.Contains(t, msg.Err.Error(), `"image.tag"`)
+
+	// charts that did not render are skipped
+	linter = support.Linter{}
+	UnusedValues(&linter, nil)
+	assert.Empty(t, linter.Messages)
+}
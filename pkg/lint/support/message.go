@@ -49,6 +49,14 @@ type Message struct {
 	Err      error
 }
 
+// SeverityName returns the name of a severity, such as "WARNING".
+func SeverityName(severity int) string {
+	if severity < 0 || severity >= len(sev) {
+		return sev[UnknownSev]
+	}
+	return sev[severity]
+}
+
 func (m Message) Error() string {
 	return fmt.Sprintf("[%s] %s: %s", sev[m.Severity], m.Path, m.Err.Error())
 }
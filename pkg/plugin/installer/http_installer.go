@@ -17,29 +17,58 @@ package installer // import "helm.sh/helm/v3/pkg/plugin/installer"
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/internal/third_party/dep/fs"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/plugin"
 	"helm.sh/helm/v3/pkg/plugin/cache"
+	"helm.sh/helm/v3/pkg/provenance"
 )
 
 // HTTPInstaller installs plugins from an archive served by a web server.
 type HTTPInstaller struct {
 	CacheDir   string
 	PluginName string
+	// Checksum, when set, is the SHA-256 digest the downloaded archive must
+	// match, either as "sha256:<hex>" or as bare hex. It may also be the URL
+	// of a file holding the digest, such as the ".sha256" file published next
+	// to the archive, in the format written by sha256sum.
+	Checksum string
+	// RetryAttempts is the number of times a download is attempted while it
+	// fails with a connection error or a 5xx response.
+	RetryAttempts int
+	// RetryBaseDelay is the delay before the first retry. It doubles after
+	// each attempt.
+	RetryBaseDelay time.Duration
+	// Timeout, when set, bounds how long each download attempt may take.
+	// There is no timeout by default.
+	Timeout time.Duration
+	// Verify, when set, makes the install fail unless the provenance file
+	// published next to the archive, at its URL with a ".prov" suffix, is
+	// signed by a key of Keyring and holds the digest of the archive.
+	Verify bool
+	// Keyring is the path of the public keyring used by Verify.
+	Keyring string
 	base
 	extractor Extractor
 	getter    getter.Getter
@@ -48,6 +77,9 @@ type HTTPInstaller struct {
 // TarGzExtractor extracts gzip compressed tar archives
 type TarGzExtractor struct{}
 
+// ZipExtractor extracts zip archives
+type ZipExtractor struct{}
+
 // Extractor provides an interface for extracting archives
 type Extractor interface {
 	Extract(buffer *bytes.Buffer, targetDir string) error
@@ -57,6 +89,7 @@ type Extractor interface {
 var Extractors = map[string]Extractor{
 	".tar.gz": &TarGzExtractor{},
 	".tgz":    &TarGzExtractor{},
+	".zip":    &ZipExtractor{},
 }
 
 // Convert a media type to an extractor extension.
@@ -66,6 +99,8 @@ func mediaTypeToExtension(mt string) (string, bool) {
 	switch strings.ToLower(mt) {
 	case "application/gzip", "application/x-gzip", "application/x-tgz", "application/x-gtar":
 		return ".tgz", true
+	case "application/zip", "application/x-zip-compressed":
+		return ".zip", true
 	default:
 		return "", false
 	}
@@ -81,6 +116,12 @@ func NewExtractor(source string) (Extractor, error) {
 	return nil, errors.Errorf("no extractor implemented yet for %s", source)
 }
 
+// Default retry settings of the HTTPInstaller downloads.
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = time.Second
+)
+
 // NewHTTPInstaller creates a new HttpInstaller.
 func NewHTTPInstaller(source string) (*HTTPInstaller, error) {
 	key, err := cache.Key(source)
@@ -99,15 +140,21 @@ func NewHTTPInstaller(source string) (*HTTPInstaller, error) {
 	}
 
 	i := &HTTPInstaller{
-		CacheDir:   helmpath.CachePath("plugins", key),
-		PluginName: stripPluginName(filepath.Base(source)),
-		base:       newBase(source),
-		extractor:  extractor,
-		getter:     get,
+		CacheDir:       helmpath.CachePath("plugins", key),
+		PluginName:     stripPluginName(filepath.Base(source)),
+		RetryAttempts:  DefaultRetryAttempts,
+		RetryBaseDelay: DefaultRetryBaseDelay,
+		base:           newBase(source),
+		extractor:      extractor,
+		getter:         get,
 	}
 	return i, nil
 }
 
+// pluginVersionSuffix matches a trailing semantic version, such as "-1.2.3",
+// "-v1.2.3" or "-2.0.0-rc.1", after a plugin name that may contain hyphens.
+var pluginVersionSuffix = regexp.MustCompile(`^(.+?)-v?[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
 // helper that relies on some sort of convention for plugin name (plugin-name-<version>)
 func stripPluginName(name string) string {
 	var strippedName string
@@ -117,8 +164,7 @@ func stripPluginName(name string) string {
 			break
 		}
 	}
-	re := regexp.MustCompile(`(.*)-[0-9]+\..*`)
-	return re.ReplaceAllString(strippedName, `$1`)
+	return pluginVersionSuffix.ReplaceAllString(strippedName, `$1`)
 }
 
 // Install downloads and extracts the tarball into the cache directory
@@ -126,10 +172,16 @@ func stripPluginName(name string) string {
 //
 // Implements Installer.
 func (i *HTTPInstaller) Install() error {
-	pluginData, err := i.getter.Get(i.Source)
+	pluginData, err := i.download(i.Source)
 	if err != nil {
 		return err
 	}
+	if err := i.verifyChecksum(pluginData); err != nil {
+		return err
+	}
+	if err := i.verifyProvenance(pluginData); err != nil {
+		return err
+	}
 
 	if err := i.extractor.Extract(pluginData, i.CacheDir); err != nil {
 		return errors.Wrap(err, "extracting files from archive")
@@ -145,13 +197,223 @@ func (i *HTTPInstaller) Install() error {
 	}
 
 	debug("copying %s to %s", src, i.Path())
-	return fs.CopyDir(src, i.Path())
+	if err := fs.CopyDir(src, i.Path()); err != nil {
+		return err
+	}
+	return i.writeSource(i.Path())
 }
 
-// Update updates a local repository
-// Not implemented for now since tarball most likely will be packaged by version
+// Update downloads the tarball again and replaces the installed plugin with it.
+//
+// The archive is extracted next to the plugin directory and only swapped in
+// once it is known to contain a valid plugin, so that a failed download or
+// extraction leaves the installed plugin untouched.
+//
+// Implements Installer.
 func (i *HTTPInstaller) Update() error {
-	return errors.Errorf("method Update() not implemented for HttpInstaller")
+	pluginData, err := i.download(i.Source)
+	if err != nil {
+		return err
+	}
+	if err := i.verifyChecksum(pluginData); err != nil {
+		return err
+	}
+	if err := i.verifyProvenance(pluginData); err != nil {
+		return err
+	}
+
+	parent := filepath.Dir(i.Path())
+	stage, err := ioutil.TempDir(parent, ".update-"+i.PluginName+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stage)
+
+	dir := filepath.Join(stage, i.PluginName)
+	if err := i.extractor.Extract(pluginData, dir); err != nil {
+		return errors.Wrap(err, "extracting files from archive")
+	}
+	if !isPlugin(dir) {
+		return ErrMissingMetadata
+	}
+	if _, err := plugin.LoadDir(dir); err != nil {
+		return err
+	}
+	if err := i.writeSource(dir); err != nil {
+		return err
+	}
+
+	debug("replacing %s with %s", i.Path(), i.Source)
+	old := filepath.Join(stage, "old")
+	if err := os.Rename(i.Path(), old); err != nil {
+		return err
+	}
+	if err := os.Rename(dir, i.Path()); err != nil {
+		// put the installed plugin back
+		if rerr := os.Rename(old, i.Path()); rerr != nil {
+			return errors.Wrapf(err, "failed to restore %s: %s", i.Path(), rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// SourceFileName is the file recording, in the directory of a plugin
+// installed from an archive served over HTTP, where the archive came from, so
+// that the plugin can be updated from it.
+const SourceFileName = ".helm-plugin-source.yaml"
+
+// pluginSource is the content of SourceFileName.
+type pluginSource struct {
+	URL      string `json:"url"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// writeSource records the source of the plugin in dir.
+func (i *HTTPInstaller) writeSource(dir string) error {
+	data, err := yaml.Marshal(pluginSource{URL: i.Source, Checksum: i.Checksum})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, SourceFileName), data, 0644)
+}
+
+// existingHTTPSource returns an HTTPInstaller for the plugin installed in
+// location from an archive served over HTTP, or nil if the plugin was not
+// installed that way.
+func existingHTTPSource(location string) (*HTTPInstaller, error) {
+	data, err := ioutil.ReadFile(filepath.Join(location, SourceFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var src pluginSource
+	if err := yaml.Unmarshal(data, &src); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", SourceFileName)
+	}
+	if src.URL == "" {
+		return nil, errors.Errorf("%s does not name the plugin source", SourceFileName)
+	}
+	i, err := NewHTTPInstaller(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	i.PluginName = filepath.Base(location)
+	i.Checksum = src.Checksum
+	return i, nil
+}
+
+// download fetches href, retrying transient failures as configured by
+// RetryAttempts and RetryBaseDelay. Each attempt is given up after Timeout, if
+// set; a timed out attempt is not retried.
+func (i *HTTPInstaller) download(href string) (*bytes.Buffer, error) {
+	var options []getter.Option
+	if i.Timeout > 0 {
+		options = append(options, getter.WithTimeout(i.Timeout))
+	}
+	return getter.GetWithRetry(i.RetryAttempts, i.RetryBaseDelay, func() (*bytes.Buffer, error) {
+		return i.getter.Get(href, options...)
+	})
+}
+
+// verifyProvenance checks the downloaded archive against its provenance
+// file, if Verify is set, before anything is extracted from it.
+func (i *HTTPInstaller) verifyProvenance(data *bytes.Buffer) error {
+	if !i.Verify {
+		return nil
+	}
+	sig, err := provenance.NewFromKeyring(i.Keyring, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to load keyring")
+	}
+
+	u, err := url.Parse(i.Source)
+	if err != nil {
+		return err
+	}
+	archiveName := path.Base(u.Path)
+	u.Path += ".prov"
+	prov, err := i.download(u.String())
+	if err != nil {
+		return errors.Wrapf(err, "failed to download the provenance file of %s", i.Source)
+	}
+
+	// the provenance machinery works on files, named like the archive since
+	// the provenance file holds its digest under that name
+	dir, err := ioutil.TempDir("", "helm-plugin-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	archive := filepath.Join(dir, archiveName)
+	if err := ioutil.WriteFile(archive, data.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(archive+".prov", prov.Bytes(), 0644); err != nil {
+		return err
+	}
+	if _, err := sig.Verify(archive, archive+".prov"); err != nil {
+		return errors.Wrapf(err, "failed to verify %s", i.Source)
+	}
+	return nil
+}
+
+// verifyChecksum checks the downloaded archive against the checksum, if
+// any, before anything is extracted from it.
+func (i *HTTPInstaller) verifyChecksum(data *bytes.Buffer) error {
+	if i.Checksum == "" {
+		return nil
+	}
+
+	checksum := i.Checksum
+	if isChecksumURL(checksum) {
+		b, err := i.download(checksum)
+		if err != nil {
+			return errors.Wrapf(err, "failed to download the checksum of %s", i.Source)
+		}
+		// sha256sum writes the digest followed by the file name
+		fields := strings.Fields(b.String())
+		if len(fields) == 0 {
+			return errors.Errorf("checksum file %s is empty", checksum)
+		}
+		checksum = fields[0]
+	}
+	expected, err := parseChecksum(checksum)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data.Bytes())
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return errors.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%s", i.Source, expected, actual)
+	}
+	return nil
+}
+
+// parseChecksum returns the lowercase hex SHA-256 digest of a checksum in
+// the form "sha256:<hex>" or "<hex>".
+func parseChecksum(checksum string) (string, error) {
+	digest := checksum
+	if idx := strings.Index(checksum, ":"); idx != -1 {
+		if algo := checksum[:idx]; !strings.EqualFold(algo, "sha256") {
+			return "", errors.Errorf("unsupported checksum algorithm %q, only sha256 is supported", algo)
+		}
+		digest = checksum[idx+1:]
+	}
+	if digest == "" {
+		return "", errors.Errorf("checksum %q has an empty digest", checksum)
+	}
+	digest = strings.ToLower(digest)
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+		return "", errors.Errorf("checksum %q is not a valid sha256 digest", checksum)
+	}
+	return digest, nil
+}
+
+func isChecksumURL(checksum string) bool {
+	return strings.HasPrefix(checksum, "http://") || strings.HasPrefix(checksum, "https://")
 }
 
 // Path is overridden because we want to join on the plugin name not the file name
@@ -216,27 +478,40 @@ func cleanJoin(root, dest string) (string, error) {
 
 // Extract extracts compressed archives
 //
+// Every entry is checked before anything is written, so that an archive with
+// an entry escaping targetDir, or a symlink pointing outside of it, is
+// rejected as a whole. Symlinks are checked again against the symlinks already
+// extracted before they are created, and targetDir is removed, if Extract
+// created it, when that check fails.
+//
 // Implements Extractor.
 func (g *TarGzExtractor) Extract(buffer *bytes.Buffer, targetDir string) error {
-	uncompressedStream, err := gzip.NewReader(buffer)
+	data := buffer.Bytes()
+
+	err := walkTarGz(data, func(header *tar.Header, _ io.Reader) error {
+		path, err := cleanJoin(targetDir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir, tar.TypeReg, tar.TypeXGlobalHeader, tar.TypeXHeader:
+			return nil
+		case tar.TypeSymlink:
+			return checkSymlink(header.Name, header.Linkname, targetDir, path)
+		default:
+			return errors.Errorf("unknown type: %b in %s", header.Typeflag, header.Name)
+		}
+	})
 	if err != nil {
 		return err
 	}
 
+	_, statErr := os.Stat(targetDir)
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
 		return err
 	}
 
-	tarReader := tar.NewReader(uncompressedStream)
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
+	err = walkTarGz(data, func(header *tar.Header, r io.Reader) error {
 		path, err := cleanJoin(targetDir, header.Name)
 		if err != nil {
 			return err
@@ -252,17 +527,225 @@ func (g *TarGzExtractor) Extract(buffer *bytes.Buffer, targetDir string) error {
 			if err != nil {
 				return err
 			}
-			if _, err := io.Copy(outFile, tarReader); err != nil {
+			if _, err := io.Copy(outFile, r); err != nil {
 				outFile.Close()
 				return err
 			}
 			outFile.Close()
-		// We don't want to process these extension header files.
-		case tar.TypeXGlobalHeader, tar.TypeXHeader:
+		case tar.TypeSymlink:
+			if err := checkSymlinkTarget(header.Name, header.Linkname, targetDir, path); err != nil {
+				return err
+			}
+			return os.Symlink(filepath.FromSlash(header.Linkname), path)
+		}
+		// We don't want to process the extension header files.
+		return nil
+	})
+	if err != nil && os.IsNotExist(statErr) {
+		// do not leave a partly extracted archive behind
+		os.RemoveAll(targetDir)
+	}
+	return err
+}
+
+// walkTarGz calls fn with every entry of the gzip compressed tar archive data.
+func walkTarGz(data []byte, fn func(*tar.Header, io.Reader) error) error {
+	uncompressedStream, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	tarReader := tar.NewReader(uncompressedStream)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(header, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// checkSymlink returns an error unless the symlink name, to be extracted at
+// path, has a relative target, link, inside targetDir.
+func checkSymlink(name, link, targetDir, path string) error {
+	target := filepath.FromSlash(link)
+	if filepath.IsAbs(target) || strings.HasPrefix(link, "/") {
+		return errors.Errorf("symlink %s has an absolute target, which is illegal", name)
+	}
+	resolved := filepath.Join(filepath.Dir(path), target)
+	rel, err := filepath.Rel(filepath.Clean(targetDir), resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.Errorf("symlink %s points outside of the plugin directory, which is illegal", name)
+	}
+	return nil
+}
+
+// checkSymlinkTarget returns an error unless the target, link, of the
+// symlink name about to be created at path resolves inside targetDir once the
+// symlinks already extracted are followed. checkSymlink only reads the text
+// of link: "d/l -> .." is inside targetDir, unless an earlier "d -> ." made
+// it "l -> ..".
+func checkSymlinkTarget(name, link, targetDir, path string) error {
+	dir, err := filepath.Rel(targetDir, filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	var components []string
+	if dir != "." {
+		components = strings.Split(dir, string(filepath.Separator))
+	}
+	if _, err := resolveInside(targetDir, components, link, 0); err != nil {
+		if err == errSymlinkEscape {
+			return errors.Errorf("symlink %s points outside of the plugin directory, which is illegal", name)
+		}
+		return errors.Wrapf(err, "symlink %s", name)
+	}
+	return nil
+}
+
+var errSymlinkEscape = errors.New("symlink escapes the plugin directory")
+
+// maxSymlinkDepth bounds the symlinks followed by resolveInside.
+const maxSymlinkDepth = 40
+
+// resolveInside resolves the relative path p from the directory dir, given as
+// its components under root, following the symlinks on disk, and returns the
+// components of the result. It returns errSymlinkEscape if the path leaves
+// root. A path may not go up with ".." past a component that does not exist
+// yet, as that component could be extracted later as a symlink.
+func resolveInside(root string, dir []string, p string, depth int) ([]string, error) {
+	if depth > maxSymlinkDepth {
+		return nil, errors.New("too many levels of symlinks")
+	}
+	if filepath.IsAbs(filepath.FromSlash(p)) || strings.HasPrefix(p, "/") {
+		return nil, errSymlinkEscape
+	}
+
+	resolved := append([]string(nil), dir...)
+	missing := false
+	for _, c := range strings.Split(filepath.ToSlash(p), "/") {
+		switch c {
+		case "", ".":
+			continue
+		case "..":
+			if missing || len(resolved) == 0 {
+				return nil, errSymlinkEscape
+			}
+			resolved = resolved[:len(resolved)-1]
 			continue
+		}
+		if !missing {
+			current := filepath.Join(root, filepath.Join(resolved...), c)
+			fi, err := os.Lstat(current)
+			switch {
+			case os.IsNotExist(err):
+				missing = true
+			case err != nil:
+				return nil, err
+			case fi.Mode()&os.ModeSymlink != 0:
+				link, err := os.Readlink(current)
+				if err != nil {
+					return nil, err
+				}
+				if resolved, err = resolveInside(root, resolved, link, depth+1); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		resolved = append(resolved, c)
+	}
+	return resolved, nil
+}
+
+// Extract extracts zip archives
+//
+// Implements Extractor.
+func (z *ZipExtractor) Extract(buffer *bytes.Buffer, targetDir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return err
+	}
+
+	for _, f := range zr.File {
+		path, err := cleanJoin(targetDir, f.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case mode&os.ModeSymlink != 0:
+			if err := extractZipSymlink(f, targetDir, path); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			if err := extractZipFile(f, path); err != nil {
+				return err
+			}
 		default:
-			return errors.Errorf("unknown type: %b in %s", header.Typeflag, header.Name)
+			return errors.Errorf("unknown type: %s in %s", mode.Type(), f.Name)
 		}
 	}
 	return nil
 }
+
+func extractZipFile(f *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	outFile, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, f.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(outFile, rc); err != nil {
+		outFile.Close()
+		return err
+	}
+	return outFile.Close()
+}
+
+// extractZipSymlink creates the symlink stored in f at path, provided that it
+// points inside targetDir.
+func extractZipSymlink(f *zip.File, targetDir, path string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	link, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	if err := checkSymlink(f.Name, string(link), targetDir, path); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := checkSymlinkTarget(f.Name, string(link), targetDir, path); err != nil {
+		return err
+	}
+	return os.Symlink(filepath.FromSlash(string(link)), path)
+}
@@ -17,24 +17,34 @@ package installer // import "helm.sh/helm/v3/pkg/plugin/installer"
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
 
 	"helm.sh/helm/v3/internal/test/ensure"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/provenance"
 )
 
 var _ Installer = new(HTTPInstaller)
@@ -43,9 +53,18 @@ var _ Installer = new(HTTPInstaller)
 type TestHTTPGetter struct {
 	MockResponse *bytes.Buffer
 	MockError    error
+	// FailTimes is the number of calls failing with a 503 response before
+	// the mock response is returned.
+	FailTimes int
+	// Calls counts the calls to Get.
+	Calls int
 }
 
 func (t *TestHTTPGetter) Get(href string, _ ...getter.Option) (*bytes.Buffer, error) {
+	t.Calls++
+	if t.Calls <= t.FailTimes {
+		return nil, &getter.StatusError{URL: href, StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+	}
 	return t.MockResponse, t.MockError
 }
 
@@ -65,6 +84,18 @@ func TestStripName(t *testing.T) {
 	if stripPluginName("fake-plugin.tar.gz") != "fake-plugin" {
 		t.Errorf("name does not match expected value")
 	}
+
+	for name, expected := range map[string]string{
+		"helm-foo-bar-1.2.3.tgz":      "helm-foo-bar",
+		"plugin-v2.0.0-rc.1.tar.gz":   "plugin",
+		"no-version.tgz":              "no-version",
+		"helm-foo-v1.2.3+build.5.tgz": "helm-foo",
+		"helm-foo-2.tgz":              "helm-foo-2",
+	} {
+		if got := stripPluginName(name); got != expected {
+			t.Errorf("stripPluginName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
 }
 
 func mockArchiveServer() *httptest.Server {
@@ -128,6 +159,112 @@ func TestHTTPInstaller(t *testing.T) {
 
 }
 
+func TestHTTPInstallerRetry(t *testing.T) {
+	mockTgz, err := base64.StdEncoding.DecodeString(fakePluginB64)
+	if err != nil {
+		t.Fatalf("Could not decode fake tgz plugin: %s", err)
+	}
+
+	tests := []struct {
+		name      string
+		failTimes int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"no failure", 0, 1, false},
+		{"recovers from transient failures", 2, 3, false},
+		{"gives up after the retry attempts", 5, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer ensure.HelmHome(t)()
+			srv := mockArchiveServer()
+			defer srv.Close()
+			if err := os.MkdirAll(helmpath.DataPath("plugins"), 0755); err != nil {
+				t.Fatalf("Could not create %s: %s", helmpath.DataPath("plugins"), err)
+			}
+
+			i, err := NewForSource(srv.URL+"/plugins/fake-plugin-0.0.1.tar.gz", "0.0.1")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			httpInstaller := i.(*HTTPInstaller)
+			httpInstaller.RetryBaseDelay = time.Millisecond
+			mock := &TestHTTPGetter{
+				MockResponse: bytes.NewBuffer(mockTgz),
+				FailTimes:    tt.failTimes,
+			}
+			httpInstaller.getter = mock
+
+			err = Install(i)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatal(err)
+			}
+			if mock.Calls != tt.wantCalls {
+				t.Errorf("expected %d downloads, got %d", tt.wantCalls, mock.Calls)
+			}
+		})
+	}
+}
+
+func TestHTTPInstallerTimeout(t *testing.T) {
+	defer ensure.HelmHome(t)()
+	if err := os.MkdirAll(helmpath.DataPath("plugins"), 0755); err != nil {
+		t.Fatalf("Could not create %s: %s", helmpath.DataPath("plugins"), err)
+	}
+
+	// The server stalls in the middle of the archive until the test ends.
+	stall := make(chan struct{})
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&requests, 1)
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write([]byte{0x1f, 0x8b})
+		w.(http.Flusher).Flush()
+		select {
+		case <-stall:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(stall)
+
+	i, err := NewForSourceWithOptions(srv.URL+"/plugins/fake-plugin-0.0.1.tar.gz", SourceOptions{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	httpInstaller := i.(*HTTPInstaller)
+	if httpInstaller.Timeout != 100*time.Millisecond {
+		t.Fatalf("expected the timeout to be set, got %s", httpInstaller.Timeout)
+	}
+	httpInstaller.RetryAttempts = 3
+	httpInstaller.RetryBaseDelay = time.Millisecond
+
+	done := make(chan error, 1)
+	go func() { done <- Install(i) }()
+	select {
+	case err := <-done:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Fatalf("expected a timeout error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the install to time out")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected a timed out download not to be retried, got %d requests", n)
+	}
+	if _, err := os.Stat(i.Path()); !os.IsNotExist(err) {
+		t.Errorf("expected the plugin not to be installed, got %v", err)
+	}
+}
+
 func TestHTTPInstallerNonExistentVersion(t *testing.T) {
 	defer ensure.HelmHome(t)()
 	srv := mockArchiveServer()
@@ -200,9 +337,358 @@ func TestHTTPInstallerUpdate(t *testing.T) {
 		t.Fatalf("expected path '$XDG_CONFIG_HOME/helm/plugins/fake-plugin', got %q", i.Path())
 	}
 
-	// Update plugin, should fail because it is not implemented
+	// the remote tarball now serves a new version of the plugin
+	httpInstaller.getter = &TestHTTPGetter{
+		MockResponse: pluginTarball(t, "0.0.2"),
+	}
+	if err := Update(i); err != nil {
+		t.Fatal(err)
+	}
+	assertPluginVersion(t, i.Path(), "0.0.2")
+
+	// a broken download leaves the installed plugin untouched
+	httpInstaller.getter = &TestHTTPGetter{
+		MockResponse: bytes.NewBufferString("not a tarball"),
+	}
 	if err := Update(i); err == nil {
-		t.Fatal("update method not implemented for http installer")
+		t.Fatal("expected an error updating from a broken archive")
+	}
+	assertPluginVersion(t, i.Path(), "0.0.2")
+
+	// so does an archive without plugin metadata
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	httpInstaller.getter = &TestHTTPGetter{MockResponse: &buf}
+	if err := Update(i); err != ErrMissingMetadata {
+		t.Fatalf("expected %q, got %v", ErrMissingMetadata, err)
+	}
+	assertPluginVersion(t, i.Path(), "0.0.2")
+
+	entries, err := ioutil.ReadDir(helmpath.DataPath("plugins"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".update-") {
+			t.Errorf("expected the update to clean up after itself, found %s", e.Name())
+		}
+	}
+}
+
+func TestUpdateHTTPInstalledPlugin(t *testing.T) {
+	defer ensure.HelmHome(t)()
+	// keep the extracted archives out of the plugins directory
+	cache := ensure.TempDir(t)
+	defer os.RemoveAll(cache)
+	os.Setenv(helmpath.CacheHomeEnvVar, cache)
+	defer os.Setenv(helmpath.CacheHomeEnvVar, "")
+
+	version := "0.0.1"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/gzip")
+		w.Write(pluginTarball(t, version).Bytes())
+	}))
+	defer srv.Close()
+	source := srv.URL + "/plugins/fake-plugin-0.0.1.tar.gz"
+
+	if err := os.MkdirAll(helmpath.DataPath("plugins"), 0755); err != nil {
+		t.Fatalf("Could not create %s: %s", helmpath.DataPath("plugins"), err)
+	}
+	i, err := NewForSource(source, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := Install(i); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := FindSource(i.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, ok := found.(*HTTPInstaller); !ok || h.Source != source {
+		t.Fatalf("expected a HTTPInstaller for %s, got %#v", source, found)
+	}
+
+	version = "0.0.2"
+	results, err := UpdateAll(helmpath.DataPath("plugins"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != UpdateStatusUpdated {
+		t.Fatalf("expected the plugin to be updated, got %+v", results)
+	}
+	assertPluginVersion(t, i.Path(), "0.0.2")
+
+	// the source is still recorded after the update
+	results, err = UpdateAll(helmpath.DataPath("plugins"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != UpdateStatusUpToDate {
+		t.Fatalf("expected the plugin to be up to date, got %+v", results)
+	}
+}
+
+// pluginTarball returns a gzipped tarball of the fake plugin at the given version.
+func pluginTarball(t *testing.T, version string) *bytes.Buffer {
+	t.Helper()
+	metadata := fmt.Sprintf("name: \"fake-plugin\"\nversion: %q\nusage: \"a fake plugin\"\ncommand: \"$HELM_PLUGIN_DIR/cmd\"\n", version)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "plugin.yaml", Mode: 0644, Size: int64(len(metadata)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(metadata)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func assertPluginVersion(t *testing.T, dir, version string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join(dir, "plugin.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), fmt.Sprintf("version: %q", version)) {
+		t.Errorf("expected the installed plugin to be at version %s, got:\n%s", version, data)
+	}
+}
+
+// Fake http client serving a response per URL
+type checksumHTTPGetter map[string]string
+
+func (g checksumHTTPGetter) Get(href string, _ ...getter.Option) (*bytes.Buffer, error) {
+	body, ok := g[href]
+	if !ok {
+		return nil, errors.Errorf("%s not found", href)
+	}
+	return bytes.NewBufferString(body), nil
+}
+
+func TestHTTPInstallerChecksum(t *testing.T) {
+	srv := mockArchiveServer()
+	defer srv.Close()
+	source := srv.URL + "/plugins/fake-plugin-0.0.1.tar.gz"
+	defer ensure.HelmHome(t)()
+
+	mockTgz, err := base64.StdEncoding.DecodeString(fakePluginB64)
+	if err != nil {
+		t.Fatalf("Could not decode fake tgz plugin: %s", err)
+	}
+	sum := sha256.Sum256(mockTgz)
+	digest := hex.EncodeToString(sum[:])
+
+	for _, checksum := range []string{"sha256:", "md5:" + digest, "sha256:abc", "not-a-digest"} {
+		if _, err := NewForSourceWithOptions(source, SourceOptions{Checksum: checksum}); err == nil {
+			t.Errorf("expected checksum %q to be rejected", checksum)
+		}
+	}
+	if _, err := NewForSourceWithOptions("../testdata/plugdir/good/echo", SourceOptions{Checksum: "sha256:" + digest}); err == nil {
+		t.Error("expected a checksum to be rejected for a local plugin")
+	}
+
+	tests := []struct {
+		name     string
+		checksum string
+		files    checksumHTTPGetter
+		wantErr  string
+	}{
+		{
+			name:     "mismatch",
+			checksum: "sha256:" + strings.Repeat("0", 64),
+			wantErr:  "checksum mismatch",
+		},
+		{
+			name:     "empty checksum file",
+			checksum: source + ".sha256",
+			files:    checksumHTTPGetter{source + ".sha256": "\n"},
+			wantErr:  "is empty",
+		},
+		{
+			name:     "prefixed digest",
+			checksum: "sha256:" + digest,
+		},
+		{
+			name:     "bare uppercase digest",
+			checksum: strings.ToUpper(digest),
+		},
+		{
+			name:     "checksum file",
+			checksum: source + ".sha256",
+			files:    checksumHTTPGetter{source + ".sha256": digest + "  fake-plugin-0.0.1.tar.gz\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.RemoveAll(helmpath.DataPath("plugins")); err != nil {
+				t.Fatal(err)
+			}
+			i, err := NewForSourceWithOptions(source, SourceOptions{Checksum: tt.checksum})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			httpInstaller, ok := i.(*HTTPInstaller)
+			if !ok {
+				t.Fatal("expected a HTTPInstaller")
+			}
+			files := checksumHTTPGetter{source: string(mockTgz)}
+			for href, body := range tt.files {
+				files[href] = body
+			}
+			httpInstaller.getter = files
+
+			err = Install(i)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+			// nothing was written when the verification failed
+			if _, err := os.Stat(i.Path()); !os.IsNotExist(err) {
+				t.Errorf("expected %s not to exist", i.Path())
+			}
+			if _, err := os.Stat(httpInstaller.CacheDir); !os.IsNotExist(err) {
+				t.Errorf("expected %s not to exist", httpInstaller.CacheDir)
+			}
+		})
+	}
+}
+
+// clearSignPlugin returns a provenance file for the archive named name
+// holding data, signed by e.
+func clearSignPlugin(t *testing.T, e *openpgp.Entity, name string, data []byte) string {
+	t.Helper()
+	sum := sha256.Sum256(data)
+	msg := fmt.Sprintf("name: fake-plugin\nversion: 0.0.1\n\n...\nfiles:\n  %s: sha256:%s\n", name, hex.EncodeToString(sum[:]))
+
+	var buf bytes.Buffer
+	w, err := clearsign.Encode(&buf, e.PrivateKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestHTTPInstallerVerify(t *testing.T) {
+	srv := mockArchiveServer()
+	defer srv.Close()
+	source := srv.URL + "/plugins/fake-plugin-0.0.1.tar.gz"
+	defer ensure.HelmHome(t)()
+
+	mockTgz, err := base64.StdEncoding.DecodeString(fakePluginB64)
+	if err != nil {
+		t.Fatalf("Could not decode fake tgz plugin: %s", err)
+	}
+
+	signer, err := provenance.GenerateKey("plugin-signer", "", "signer@example.com", 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := provenance.GenerateKey("stranger", "", "stranger@example.com", 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := filepath.Join(ensure.TempDir(t), "pubring.gpg")
+	f, err := os.Create(keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.Serialize(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, err := NewForSourceWithOptions("../testdata/plugdir/good/echo", SourceOptions{Verify: true, Keyring: keyring}); err == nil {
+		t.Error("expected verification to be rejected for a local plugin")
+	}
+
+	tests := []struct {
+		name    string
+		prov    checksumHTTPGetter
+		wantErr string
+	}{
+		{
+			name: "valid signature",
+			prov: checksumHTTPGetter{source + ".prov": clearSignPlugin(t, signer, "fake-plugin-0.0.1.tar.gz", mockTgz)},
+		},
+		{
+			name:    "missing provenance file",
+			wantErr: "failed to download the provenance file",
+		},
+		{
+			name:    "unknown signer",
+			prov:    checksumHTTPGetter{source + ".prov": clearSignPlugin(t, stranger, "fake-plugin-0.0.1.tar.gz", mockTgz)},
+			wantErr: "failed to verify",
+		},
+		{
+			name:    "digest mismatch",
+			prov:    checksumHTTPGetter{source + ".prov": clearSignPlugin(t, signer, "fake-plugin-0.0.1.tar.gz", []byte("tampered"))},
+			wantErr: "sha256 sum does not match",
+		},
+		{
+			name:    "digest of another file",
+			prov:    checksumHTTPGetter{source + ".prov": clearSignPlugin(t, signer, "other-plugin-0.0.1.tar.gz", mockTgz)},
+			wantErr: "does not contain a SHA",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.RemoveAll(helmpath.DataPath("plugins")); err != nil {
+				t.Fatal(err)
+			}
+			i, err := NewForSourceWithOptions(source, SourceOptions{Verify: true, Keyring: keyring})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			httpInstaller := i.(*HTTPInstaller)
+			files := checksumHTTPGetter{source: string(mockTgz)}
+			for href, body := range tt.prov {
+				files[href] = body
+			}
+			httpInstaller.getter = files
+
+			err = Install(i)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+			// nothing was extracted when the verification failed
+			if _, err := os.Stat(i.Path()); !os.IsNotExist(err) {
+				t.Errorf("expected %s not to exist", i.Path())
+			}
+		})
 	}
 }
 
@@ -301,6 +787,257 @@ func TestExtract(t *testing.T) {
 
 }
 
+func TestExtractRejectsEscapes(t *testing.T) {
+	type entry struct {
+		Name, Linkname, Body string
+		Typeflag             byte
+	}
+	plugin := entry{Name: "plugin.yaml", Body: "plugin metadata", Typeflag: tar.TypeReg}
+
+	for name, entries := range map[string][]entry{
+		"parent directory":          {plugin, {Name: "../../bin/helm", Body: "malicious", Typeflag: tar.TypeReg}},
+		"nested parent directory":   {plugin, {Name: "bin/../../helm", Body: "malicious", Typeflag: tar.TypeReg}},
+		"absolute path":             {plugin, {Name: "/bin/helm", Body: "malicious", Typeflag: tar.TypeReg}},
+		"absolute symlink":          {plugin, {Name: "passwd", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink}},
+		"relative symlink escape":   {plugin, {Name: "bin/passwd", Linkname: "../../etc/passwd", Typeflag: tar.TypeSymlink}},
+		"symlink to the parent dir": {plugin, {Name: "parent", Linkname: "..", Typeflag: tar.TypeSymlink}},
+		"chained symlinks": {plugin,
+			{Name: "d", Linkname: ".", Typeflag: tar.TypeSymlink},
+			{Name: "d/l", Linkname: "..", Typeflag: tar.TypeSymlink}},
+		"symlink through a later symlink": {plugin,
+			{Name: "d", Linkname: ".", Typeflag: tar.TypeSymlink},
+			{Name: "l", Linkname: "x/..", Typeflag: tar.TypeSymlink},
+			{Name: "x", Linkname: "d", Typeflag: tar.TypeSymlink}},
+		"hard link": {plugin, {Name: "passwd", Linkname: "/etc/passwd", Typeflag: tar.TypeLink}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tempDir, err := ioutil.TempDir("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(tempDir)
+			targetDir := filepath.Join(tempDir, "plugin")
+
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gz)
+			for _, e := range entries {
+				hdr := &tar.Header{
+					Name:     e.Name,
+					Linkname: e.Linkname,
+					Typeflag: e.Typeflag,
+					Mode:     0644,
+					Size:     int64(len(e.Body)),
+				}
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := tw.Write([]byte(e.Body)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gz.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := new(TarGzExtractor).Extract(&buf, targetDir); err == nil {
+				t.Fatal("expected an error extracting an entry escaping the target directory")
+			}
+
+			// nothing was written, not even the entries preceding the bad one
+			entries, err := ioutil.ReadDir(tempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("expected nothing to be written, found %s", entries[0].Name())
+			}
+		})
+	}
+}
+
+func TestExtractSymlink(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	body := "#!/bin/sh"
+	if err := tw.WriteHeader(&tar.Header{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "bin/plugin", Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(body))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "bin/latest", Linkname: "plugin", Typeflag: tar.TypeSymlink}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := new(TarGzExtractor).Extract(&buf, tempDir); err != nil {
+		t.Fatalf("Did not expect error but got error: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(tempDir, "bin", "latest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != body {
+		t.Errorf("expected the symlink to resolve to bin/plugin, read %q", data)
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	source := "https://repo.localdomain/plugins/fake-plugin-0.0.1.zip"
+
+	tempDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Set the umask to default open permissions so we can actually test
+	oldmask := syscall.Umask(0000)
+	defer func() {
+		syscall.Umask(oldmask)
+	}()
+
+	// Write a zip archive to a buffer for us to extract
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	var files = []struct {
+		Name, Body string
+		Mode       os.FileMode
+	}{
+		{"plugin.yaml", "plugin metadata", 0600},
+		{"README.md", "some text", 0777},
+		{"bin/", "", os.ModeDir | 0755},
+		{"bin/plugin", "#!/bin/sh", 0755},
+		{"latest", "bin/plugin", os.ModeSymlink | 0777},
+	}
+	for _, file := range files {
+		hdr := &zip.FileHeader{Name: file.Name, Method: zip.Deflate}
+		hdr.SetMode(file.Mode)
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(file.Body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// END zip creation
+
+	extractor, err := NewExtractor(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := extractor.(*ZipExtractor); !ok {
+		t.Fatalf("expected a ZipExtractor, got %T", extractor)
+	}
+
+	if err = extractor.Extract(&buf, tempDir); err != nil {
+		t.Fatalf("Did not expect error but got error: %v", err)
+	}
+
+	for name, mode := range map[string]os.FileMode{
+		"plugin.yaml": 0600,
+		"README.md":   0777,
+		"bin/plugin":  0755,
+	} {
+		fullPath := filepath.Join(tempDir, name)
+		if info, err := os.Stat(fullPath); err != nil {
+			if os.IsNotExist(err) {
+				t.Fatalf("Expected %s to exist but doesn't", fullPath)
+			}
+			t.Fatal(err)
+		} else if info.Mode().Perm() != mode {
+			t.Fatalf("Expected %s to have %o mode it but has %o", fullPath, mode, info.Mode().Perm())
+		}
+	}
+
+	if target, err := os.Readlink(filepath.Join(tempDir, "latest")); err != nil {
+		t.Fatal(err)
+	} else if target != "bin/plugin" {
+		t.Errorf("Expected latest to link to bin/plugin, got %s", target)
+	}
+}
+
+func TestExtractZipRejectsEscapes(t *testing.T) {
+	type entry struct {
+		Name, Body string
+		Mode       os.FileMode
+	}
+	for name, entries := range map[string][]entry{
+		"path traversal":    {{"../../bin/helm", "malicious", 0755}},
+		"absolute symlink":  {{"passwd", "/etc/passwd", os.ModeSymlink | 0777}},
+		"relative symlink":  {{"dir/passwd", "../../../etc/passwd", os.ModeSymlink | 0777}},
+		"symlink to parent": {{"parent", "..", os.ModeSymlink | 0777}},
+		"chained symlinks":  {{"d", ".", os.ModeSymlink | 0777}, {"d/l", "..", os.ModeSymlink | 0777}},
+		"symlink through a later symlink": {
+			{"d", ".", os.ModeSymlink | 0777},
+			{"l", "x/..", os.ModeSymlink | 0777},
+			{"x", "d", os.ModeSymlink | 0777},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tempDir, err := ioutil.TempDir("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(tempDir)
+			targetDir := filepath.Join(tempDir, "plugin")
+
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			for _, entry := range entries {
+				hdr := &zip.FileHeader{Name: entry.Name}
+				hdr.SetMode(entry.Mode)
+				w, err := zw.CreateHeader(hdr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := w.Write([]byte(entry.Body)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := new(ZipExtractor).Extract(&buf, targetDir); err == nil {
+				t.Fatal("expected an error extracting an entry escaping the target directory")
+			}
+			entries, err := ioutil.ReadDir(tempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				if e.Name() != "plugin" {
+					t.Errorf("expected nothing to be written outside of the target directory, found %s", e.Name())
+				}
+			}
+		})
+	}
+}
+
 func TestCleanJoin(t *testing.T) {
 	for i, fixture := range []struct {
 		path        string
@@ -339,6 +1076,7 @@ func TestMediaTypeToExtension(t *testing.T) {
 		"application/x-gzip": true,
 		"application/x-tgz":  true,
 		"application/x-gtar": true,
+		"application/zip":    true,
 		"application/json":   false,
 	} {
 		ext, ok := mediaTypeToExtension(mt)
@@ -21,7 +21,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -31,6 +33,14 @@ import (
 // ErrMissingMetadata indicates that plugin.yaml is missing.
 var ErrMissingMetadata = errors.New("plugin metadata (plugin.yaml) missing")
 
+// ErrValidationNotSupported indicates that an installer cannot check a plugin
+// without installing it.
+var ErrValidationNotSupported = errors.New("validating a plugin without installing it is not supported for this source")
+
+var errChecksumUnsupported = errors.New("checksums are only supported for plugin archives downloaded over HTTP")
+
+var errVerifyUnsupported = errors.New("verification is only supported for plugin archives downloaded over HTTP")
+
 // Debug enables verbose output.
 var Debug bool
 
@@ -44,6 +54,13 @@ type Installer interface {
 	Update() error
 }
 
+// Validator is implemented by the installers that can check a plugin is
+// installable without installing it.
+type Validator interface {
+	// Validate checks the plugin metadata of the source.
+	Validate() error
+}
+
 // Install installs a plugin.
 func Install(i Installer) error {
 	if err := os.MkdirAll(filepath.Dir(i.Path()), 0755); err != nil {
@@ -63,19 +80,180 @@ func Update(i Installer) error {
 	return i.Update()
 }
 
+// Validate checks that the plugin of an installer can be installed, without
+// installing it. It returns ErrValidationNotSupported if the installer does not
+// implement Validator.
+func Validate(i Installer) error {
+	v, ok := i.(Validator)
+	if !ok {
+		return ErrValidationNotSupported
+	}
+	return v.Validate()
+}
+
+// UpdateStatus is the outcome of updating one plugin with UpdateAll.
+type UpdateStatus string
+
+const (
+	// UpdateStatusUpdated means the plugin was updated to a new version.
+	UpdateStatusUpdated UpdateStatus = "updated"
+	// UpdateStatusUpToDate means the plugin was already at its latest version.
+	UpdateStatusUpToDate UpdateStatus = "up-to-date"
+	// UpdateStatusSkipped means the plugin cannot be updated, because it is
+	// linked from a local directory or was not installed from a repository
+	// or an archive.
+	UpdateStatusSkipped UpdateStatus = "skipped"
+	// UpdateStatusFailed means updating the plugin failed.
+	UpdateStatusFailed UpdateStatus = "failed"
+)
+
+// UpdateResult reports the outcome of updating one plugin with UpdateAll.
+type UpdateResult struct {
+	Plugin *plugin.Plugin
+	Status UpdateStatus
+	// Err is the reason the plugin was skipped or failed to update
+	Err error
+}
+
+// UpdateAll updates every plugin installed in the plugin directories, and
+// reports the outcome for each of them, sorted by plugin name.
+//
+// Plugins linked from a local directory and plugins that were not installed
+// from a repository or an archive are skipped. A plugin failing to update does not stop the
+// others from being updated.
+func UpdateAll(pluginsDirs string) ([]*UpdateResult, error) {
+	plugins, err := plugin.FindPlugins(pluginsDirs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Metadata.Name < plugins[j].Metadata.Name
+	})
+
+	results := make([]*UpdateResult, 0, len(plugins))
+	for _, p := range plugins {
+		status, err := updateInstalled(p)
+		results = append(results, &UpdateResult{Plugin: p, Status: status, Err: err})
+	}
+	return results, nil
+}
+
+func updateInstalled(p *plugin.Plugin) (UpdateStatus, error) {
+	fi, err := os.Lstat(p.Dir)
+	if err != nil {
+		return UpdateStatusFailed, err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		return UpdateStatusSkipped, errors.New("plugin is linked from a local directory")
+	}
+
+	i, err := FindSource(p.Dir)
+	if err != nil {
+		return UpdateStatusSkipped, err
+	}
+
+	switch i := i.(type) {
+	case *VCSInstaller:
+		before, _ := i.Repo.Version()
+		if err := Update(i); err != nil {
+			return UpdateStatusFailed, err
+		}
+		after, _ := i.Repo.Version()
+		if before == after {
+			return UpdateStatusUpToDate, nil
+		}
+		return UpdateStatusUpdated, nil
+	case *HTTPInstaller:
+		if err := Update(i); err != nil {
+			return UpdateStatusFailed, err
+		}
+		// archives have no revision, so compare the plugin versions
+		updated, err := plugin.LoadDir(i.Path())
+		if err == nil && updated.Metadata.Version == p.Metadata.Version {
+			return UpdateStatusUpToDate, nil
+		}
+		return UpdateStatusUpdated, nil
+	}
+	return UpdateStatusSkipped, errors.New("plugin was not installed from a repository or an archive")
+}
+
 // NewForSource determines the correct Installer for the given source.
 func NewForSource(source, version string) (Installer, error) {
+	return NewForSourceWithOptions(source, SourceOptions{Version: version})
+}
+
+// SourceOptions configure the Installer created by NewForSourceWithOptions.
+type SourceOptions struct {
+	// Version is the version constraint of the plugin to install.
+	Version string
+	// Checksum is the digest the downloaded archive must match. It is only
+	// supported for plugin archives served over HTTP. See
+	// HTTPInstaller.Checksum for the accepted forms.
+	Checksum string
+	// Timeout bounds each download of a plugin archive served over HTTP.
+	// Zero means no timeout. Other sources ignore it.
+	Timeout time.Duration
+	// Verify checks the provenance file of a plugin archive served over
+	// HTTP against Keyring. See HTTPInstaller.Verify. It is not supported
+	// for other sources.
+	Verify  bool
+	Keyring string
+}
+
+// NewForSourceWithOptions determines the correct Installer for the given
+// source, like NewForSource, and configures it with opts.
+func NewForSourceWithOptions(source string, opts SourceOptions) (Installer, error) {
+	// checksums and signatures are only verified for archives
+	var unsupported error
+	if opts.Checksum != "" {
+		unsupported = errChecksumUnsupported
+	} else if opts.Verify {
+		unsupported = errVerifyUnsupported
+	}
+
 	// Check if source is a local directory
 	if isLocalReference(source) {
+		if unsupported != nil {
+			return nil, unsupported
+		}
 		return NewLocalInstaller(source)
+	} else if isGitReference(source) {
+		if unsupported != nil {
+			return nil, unsupported
+		}
+		return NewGitInstaller(source, opts.Version)
 	} else if isRemoteHTTPArchive(source) {
-		return NewHTTPInstaller(source)
+		i, err := NewHTTPInstaller(source)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Checksum != "" && !isChecksumURL(opts.Checksum) {
+			if _, err := parseChecksum(opts.Checksum); err != nil {
+				return nil, err
+			}
+		}
+		i.Checksum = opts.Checksum
+		i.Timeout = opts.Timeout
+		i.Verify = opts.Verify
+		i.Keyring = opts.Keyring
+		return i, nil
+	}
+	if unsupported != nil {
+		return nil, unsupported
 	}
-	return NewVCSInstaller(source, version)
+	return NewVCSInstaller(source, opts.Version)
 }
 
-// FindSource determines the correct Installer for the given source.
+// FindSource determines the correct Installer for the plugin installed in
+// location: an HTTPInstaller for a plugin installed from an archive served
+// over HTTP, or a VCSInstaller for a plugin installed from a repository.
 func FindSource(location string) (Installer, error) {
+	if i, err := existingHTTPSource(location); err != nil {
+		return nil, err
+	} else if i != nil {
+		return i, nil
+	}
+
 	installer, err := existingVCSRepo(location)
 	if err != nil && err.Error() == "Cannot detect VCS" {
 		return installer, errors.New("cannot get information about plugin source")
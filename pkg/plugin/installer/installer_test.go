@@ -15,7 +15,14 @@ limitations under the License.
 
 package installer
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+)
 
 func TestIsRemoteHTTPArchive(t *testing.T) {
 	srv := mockArchiveServer()
@@ -38,3 +45,51 @@ func TestIsRemoteHTTPArchive(t *testing.T) {
 		t.Error("Expected media type match to fail")
 	}
 }
+
+func TestUpdateAll(t *testing.T) {
+	pluginsDir := ensure.TempDir(t)
+	defer os.RemoveAll(pluginsDir)
+
+	// a plugin copied into the plugins directory, not from a repository
+	copied := filepath.Join(pluginsDir, "copied")
+	if err := os.MkdirAll(copied, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(copied, "plugin.yaml"), []byte("name: copied\nversion: 0.1.0\ncommand: echo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a plugin linked from a local directory, as the local installer does
+	echo, err := filepath.Abs("../testdata/plugdir/good/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(echo, filepath.Join(pluginsDir, "echo")); err != nil {
+		t.Skipf("cannot create symlinks: %s", err)
+	}
+
+	results, err := UpdateAll(pluginsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	expected := []struct {
+		name string
+		err  string
+	}{
+		{"copied", "cannot get information about plugin source"},
+		{"echo", "plugin is linked from a local directory"},
+	}
+	for i, r := range results {
+		if r.Plugin.Metadata.Name != expected[i].name {
+			t.Errorf("expected plugin %s, got %s", expected[i].name, r.Plugin.Metadata.Name)
+		}
+		if r.Status != UpdateStatusSkipped {
+			t.Errorf("expected plugin %s to be skipped, got %s", r.Plugin.Metadata.Name, r.Status)
+		}
+		if r.Err == nil || r.Err.Error() != expected[i].err {
+			t.Errorf("expected plugin %s to be skipped with %q, got %v", r.Plugin.Metadata.Name, expected[i].err, r.Err)
+		}
+	}
+}
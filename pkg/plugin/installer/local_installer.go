@@ -20,6 +20,8 @@ import (
 	"path/filepath"
 
 	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/plugin"
 )
 
 // LocalInstaller installs plugins from the filesystem.
@@ -50,6 +52,27 @@ func (i *LocalInstaller) Install() error {
 	return os.Symlink(i.Source, i.Path())
 }
 
+// Validate checks that the plugin directory has a well-formed plugin.yaml,
+// with a name, a version and a command.
+//
+// Implements Validator.
+func (i *LocalInstaller) Validate() error {
+	if !isPlugin(i.Source) {
+		return ErrMissingMetadata
+	}
+	p, err := plugin.LoadDir(i.Source)
+	if err != nil {
+		return err
+	}
+	if p.Metadata.Version == "" {
+		return errors.Errorf("plugin %q has no version", p.Metadata.Name)
+	}
+	if p.Metadata.Command == "" && len(p.Metadata.PlatformCommand) == 0 && len(p.Metadata.Downloaders) == 0 {
+		return errors.Errorf("plugin %q has no command", p.Metadata.Name)
+	}
+	return nil
+}
+
 // Update updates a local repository
 func (i *LocalInstaller) Update() error {
 	debug("local repository is auto-updated")
@@ -19,8 +19,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"helm.sh/helm/v3/internal/test/ensure"
 	"helm.sh/helm/v3/pkg/helmpath"
 )
 
@@ -52,3 +54,55 @@ func TestLocalInstaller(t *testing.T) {
 	}
 	defer os.RemoveAll(filepath.Dir(helmpath.DataPath())) // helmpath.DataPath is like /tmp/helm013130971/helm
 }
+
+func TestLocalInstallerValidate(t *testing.T) {
+	defer ensure.HelmHome(t)()
+
+	i, err := NewForSource("../testdata/plugdir/good/echo", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := Validate(i); err != nil {
+		t.Fatalf("expected the echo plugin to be valid: %s", err)
+	}
+	if _, err := os.Stat(helmpath.DataPath("plugins")); !os.IsNotExist(err) {
+		t.Error("expected validating a plugin not to install it")
+	}
+
+	tdir, err := ioutil.TempDir("", "helm-installer-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+
+	for metadata, expectedErr := range map[string]string{
+		"":                                 ErrMissingMetadata.Error(),
+		"name: bad name\nversion: 1.0.0\n": "invalid plugin name",
+		"name: echo\ncommand: echo\n":      `plugin "echo" has no version`,
+		"name: echo\nversion: 1.0.0\n":     `plugin "echo" has no command`,
+		"name: echo\nversion: 1.0.0\nunknown: 1\n": "failed to load plugin",
+	} {
+		pluginfile := filepath.Join(tdir, "plugin.yaml")
+		os.Remove(pluginfile)
+		if metadata != "" {
+			if err := ioutil.WriteFile(pluginfile, []byte(metadata), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		i, err := NewLocalInstaller(tdir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Validate(i); err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Errorf("expected an error containing %q for %q, got %v", expectedErr, metadata, err)
+		}
+	}
+
+	httpInstaller, err := NewHTTPInstaller("https://example.com/plugins/fake-plugin-0.0.1.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(httpInstaller); err != ErrValidationNotSupported {
+		t.Errorf("expected %q, got %v", ErrValidationNotSupported, err)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/plugin"
+)
+
+// LockFile records the installed plugins, with the version each one was
+// resolved to and the digest of its content, to reinstall them exactly.
+type LockFile struct {
+	Plugins []*LockedPlugin `json:"plugins"`
+}
+
+// LockedPlugin is a plugin recorded in a LockFile.
+type LockedPlugin struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+	// Source is the repository URL or local directory the plugin is installed from
+	Source string `json:"source"`
+	// Version is the repository revision the plugin was installed at
+	Version string `json:"version,omitempty"`
+	// Digest is the digest of the files of the plugin
+	Digest string `json:"digest"`
+}
+
+// Lock records the plugins installed in the plugin directories, sorted by
+// name. It fails for plugins whose source is unknown, such as the ones
+// installed from an archive, as they could not be reinstalled.
+func Lock(pluginsDirs string) (*LockFile, error) {
+	plugins, err := plugin.FindPlugins(pluginsDirs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Metadata.Name < plugins[j].Metadata.Name
+	})
+
+	lock := &LockFile{Plugins: make([]*LockedPlugin, 0, len(plugins))}
+	for _, p := range plugins {
+		locked, err := lockPlugin(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot lock plugin %s", p.Metadata.Name)
+		}
+		lock.Plugins = append(lock.Plugins, locked)
+	}
+	return lock, nil
+}
+
+func lockPlugin(p *plugin.Plugin) (*LockedPlugin, error) {
+	locked := &LockedPlugin{Name: p.Metadata.Name}
+
+	fi, err := os.Lstat(p.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		if locked.Source, err = filepath.EvalSymlinks(p.Dir); err != nil {
+			return nil, err
+		}
+	} else {
+		i, err := FindSource(p.Dir)
+		if err != nil {
+			return nil, err
+		}
+		vcsInstaller, ok := i.(*VCSInstaller)
+		if !ok {
+			return nil, errors.New("plugin was not installed from a repository")
+		}
+		locked.Source = vcsInstaller.Repo.Remote()
+		if locked.Version, err = vcsInstaller.Repo.Version(); err != nil {
+			return nil, err
+		}
+	}
+
+	if locked.Digest, err = digestPluginDir(p.Dir); err != nil {
+		return nil, err
+	}
+	return locked, nil
+}
+
+// WriteLock writes the lock file to path.
+func WriteLock(path string, lock *LockFile) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// LoadLock reads the lock file at path.
+func LoadLock(path string) (*LockFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lock := &LockFile{}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse plugin lock file %s", path)
+	}
+	return lock, nil
+}
+
+// InstallFromLock installs every plugin of the lock file at path, at the
+// version it records, and returns their installers.
+//
+// Lock digests the plugins as they are once their install hook has run, so
+// installed, when not nil, is called with each plugin once it is installed and
+// before its digest is checked, to run its install hook.
+//
+// A plugin whose content does not match the recorded digest is removed, and
+// the installation stops with an error.
+func InstallFromLock(path string, installed func(Installer) error) ([]Installer, error) {
+	lock, err := LoadLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	installers := make([]Installer, 0, len(lock.Plugins))
+	for _, locked := range lock.Plugins {
+		i, err := NewForSource(locked.Source, locked.Version)
+		if err != nil {
+			return installers, errors.Wrapf(err, "cannot install plugin %s", locked.Name)
+		}
+		if err := Install(i); err != nil {
+			return installers, errors.Wrapf(err, "cannot install plugin %s", locked.Name)
+		}
+		if installed != nil {
+			if err := installed(i); err != nil {
+				return installers, errors.Wrapf(err, "cannot install plugin %s", locked.Name)
+			}
+		}
+		digest, err := digestPluginDir(i.Path())
+		if err != nil {
+			return installers, err
+		}
+		if digest != locked.Digest {
+			if err := os.RemoveAll(i.Path()); err != nil {
+				return installers, errors.Wrapf(err, "digest mismatch for plugin %s, and it could not be removed", locked.Name)
+			}
+			return installers, errors.Errorf("digest mismatch for plugin %s: the lock file records %s, installed %s", locked.Name, locked.Digest, digest)
+		}
+		debug("installed %s from the lock file at %s", locked.Name, i.Path())
+		installers = append(installers, i)
+	}
+	return installers, nil
+}
+
+// digestPluginDir computes the digest of the files of a plugin directory,
+// following a symlinked directory and ignoring VCS metadata.
+func digestPluginDir(dir string) (string, error) {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	err = filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if fi.Name() == ".git" || fi.Name() == ".hg" || fi.Name() == ".svn" || fi.Name() == ".bzr" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), fi.Size())
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/helmpath"
+)
+
+func TestLockRoundTrip(t *testing.T) {
+	defer ensure.HelmHome(t)()
+
+	// lock a plugin linked from a local directory, once its install hook
+	// has written into it
+	pluginsDir := ensure.TempDir(t)
+	defer os.RemoveAll(pluginsDir)
+	echo := filepath.Join(ensure.TempDir(t), "echo")
+	defer os.RemoveAll(filepath.Dir(echo))
+	if err := os.Mkdir(echo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(echo, "plugin.yaml"), []byte("name: echo\nversion: 1.2.3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	installHook := func(i Installer) error {
+		return ioutil.WriteFile(filepath.Join(i.Path(), "echo.bin"), []byte("binary"), 0755)
+	}
+	if err := os.Symlink(echo, filepath.Join(pluginsDir, "echo")); err != nil {
+		t.Skipf("cannot create symlinks: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(echo, "echo.bin"), []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := Lock(pluginsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock.Plugins) != 1 {
+		t.Fatalf("expected 1 locked plugin, got %d", len(lock.Plugins))
+	}
+	locked := lock.Plugins[0]
+	source, _ := filepath.EvalSymlinks(echo)
+	if locked.Name != "echo" || locked.Source != source || !strings.HasPrefix(locked.Digest, "sha256:") {
+		t.Errorf("unexpected locked plugin %+v", locked)
+	}
+
+	lockPath := filepath.Join(pluginsDir, "plugins.lock")
+	if err := WriteLock(lockPath, lock); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadLock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lock, loaded) {
+		t.Errorf("expected the lock to round trip, got %+v", loaded)
+	}
+
+	// install from the lock into the helm home, where the plugin matches
+	// the lock once its install hook has run
+	if err := os.Remove(filepath.Join(echo, "echo.bin")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallFromLock(lockPath, nil); err == nil || !strings.Contains(err.Error(), "digest mismatch for plugin echo") {
+		t.Fatalf("expected a digest mismatch without the install hook, got %v", err)
+	}
+	installers, err := InstallFromLock(lockPath, installHook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(installers) != 1 || installers[0].Path() != helmpath.DataPath("plugins", "echo") {
+		t.Fatalf("unexpected installers %v", installers)
+	}
+	if _, err := os.Stat(filepath.Join(installers[0].Path(), "plugin.yaml")); err != nil {
+		t.Fatalf("expected the plugin to be installed: %s", err)
+	}
+	if err := os.Remove(installers[0].Path()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a digest mismatch fails and leaves nothing installed
+	locked.Digest = "sha256:0000"
+	if err := WriteLock(lockPath, lock); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallFromLock(lockPath, installHook); err == nil || !strings.Contains(err.Error(), "digest mismatch for plugin echo") {
+		t.Fatalf("expected a digest mismatch, got %v", err)
+	}
+	if _, err := os.Lstat(helmpath.DataPath("plugins", "echo")); !os.IsNotExist(err) {
+		t.Errorf("expected the mismatched plugin to be removed, got %v", err)
+	}
+}
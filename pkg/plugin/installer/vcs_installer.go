@@ -18,6 +18,7 @@ package installer // import "helm.sh/helm/v3/pkg/plugin/installer"
 import (
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/Masterminds/vcs"
@@ -66,6 +67,55 @@ func NewVCSInstaller(source, version string) (*VCSInstaller, error) {
 	return i, err
 }
 
+// gitSourcePrefix marks a plugin source as a git repository whatever the
+// scheme of its URL, as in "git+https://github.com/org/helm-plugin".
+const gitSourcePrefix = "git+"
+
+// NewGitInstaller creates a new VCSInstaller for a git repository.
+//
+// The source may carry the "git+" prefix, and a "#ref" fragment pinning a tag,
+// branch or commit, which is used when version is empty.
+func NewGitInstaller(source, version string) (*VCSInstaller, error) {
+	remote, ref := parseGitSource(source)
+	if version == "" {
+		version = ref
+	}
+	key, err := cache.Key(remote)
+	if err != nil {
+		return nil, err
+	}
+	cachedpath := helmpath.CachePath("plugins", key)
+	repo, err := vcs.NewGitRepo(remote, cachedpath)
+	if err != nil {
+		return nil, err
+	}
+	i := &VCSInstaller{
+		Repo:    repo,
+		Version: version,
+		base:    newBase(strings.TrimSuffix(remote, ".git")),
+	}
+	return i, nil
+}
+
+// isGitReference checks if the source explicitly names a git repository,
+// with the "git+" prefix, the git scheme or the ".git" extension.
+func isGitReference(source string) bool {
+	remote, _ := parseGitSource(source)
+	return strings.HasPrefix(source, gitSourcePrefix) ||
+		strings.HasPrefix(remote, "git://") ||
+		strings.HasSuffix(remote, ".git")
+}
+
+// parseGitSource splits a git plugin source into the repository remote and
+// the ref of its fragment.
+func parseGitSource(source string) (remote, ref string) {
+	remote = strings.TrimPrefix(source, gitSourcePrefix)
+	if i := strings.LastIndex(remote, "#"); i >= 0 {
+		remote, ref = remote[:i], remote[i+1:]
+	}
+	return remote, ref
+}
+
 // Install clones a remote repository and installs into the plugin directory.
 //
 // Implements Installer.
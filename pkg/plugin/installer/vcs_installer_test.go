@@ -101,6 +101,61 @@ func TestVCSInstaller(t *testing.T) {
 	}
 }
 
+func TestGitInstaller(t *testing.T) {
+	defer ensure.HelmHome(t)()
+
+	if err := os.MkdirAll(helmpath.DataPath("plugins"), 0755); err != nil {
+		t.Fatalf("Could not create %s: %s", helmpath.DataPath("plugins"), err)
+	}
+
+	source := "git+https://github.com/adamreese/helm-env.git#0.1.0"
+	i, err := NewForSource(source, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// ensure a git VCSInstaller was returned, pinned to the fragment ref
+	gitInstaller, ok := i.(*VCSInstaller)
+	if !ok {
+		t.Fatal("expected a VCSInstaller")
+	}
+	if gitInstaller.Repo.Vcs() != vcs.Git {
+		t.Fatalf("expected a git repository, got %s", gitInstaller.Repo.Vcs())
+	}
+	if remote := gitInstaller.Repo.Remote(); remote != "https://github.com/adamreese/helm-env.git" {
+		t.Fatalf("expected the git+ prefix and fragment to be stripped, got %q", remote)
+	}
+	if gitInstaller.Version != "0.1.0" {
+		t.Fatalf("expected version '0.1.0', got %q", gitInstaller.Version)
+	}
+
+	testRepoPath, _ := filepath.Abs("../testdata/plugdir/good/echo")
+	repo := &testRepo{
+		local: testRepoPath,
+		tags:  []string{"0.1.0", "0.1.1"},
+	}
+	gitInstaller.Repo = repo
+
+	if err := Install(i); err != nil {
+		t.Fatal(err)
+	}
+	if repo.current != "0.1.0" {
+		t.Fatalf("expected version '0.1.0', got %q", repo.current)
+	}
+	if i.Path() != helmpath.DataPath("plugins", "helm-env") {
+		t.Fatalf("expected path '$XDG_CONFIG_HOME/helm/plugins/helm-env', got %q", i.Path())
+	}
+
+	// an explicit version takes precedence over the fragment
+	i, err = NewForSource("git://example.com/helm-env#0.1.0", "0.1.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := i.(*VCSInstaller).Version; v != "0.1.1" {
+		t.Fatalf("expected version '0.1.1', got %q", v)
+	}
+}
+
 func TestVCSInstallerNonExistentVersion(t *testing.T) {
 	defer ensure.HelmHome(t)()
 
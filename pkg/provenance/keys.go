@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+// DefaultKeyBits is the size of the RSA keys generated by GenerateKey.
+const DefaultKeyBits = 3072
+
+// KeyInfo describes a key in a keyring.
+type KeyInfo struct {
+	// ID is the long key ID, as hexadecimal.
+	ID string
+	// Fingerprint is the fingerprint of the primary key, as hexadecimal.
+	Fingerprint string
+	// Identities are the names of the key, typically of the form:
+	//
+	//	USER_NAME (COMMENT) <EMAIL>
+	Identities []string
+	// Created is when the key was generated.
+	Created time.Time
+	// HasPrivateKey is true if the keyring holds the private key, and not
+	// only the public key.
+	HasPrivateKey bool
+}
+
+// GenerateKey generates a new key pair for signing charts, for the identity
+// built from name, comment and email.
+//
+// The key is RSA, of DefaultKeyBits bits when bits is zero. Its private key
+// is only held in memory, unencrypted, until it is written with
+// ExportPrivateKey.
+func GenerateKey(name, comment, email string, bits int) (*openpgp.Entity, error) {
+	if name == "" {
+		return nil, errors.New("a name is required to generate a key")
+	}
+	if bits == 0 {
+		bits = DefaultKeyBits
+	}
+	config := &packet.Config{
+		DefaultHash: crypto.SHA512,
+		RSABits:     bits,
+	}
+	e, err := openpgp.NewEntity(name, comment, email, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate key")
+	}
+	return e, nil
+}
+
+// ExportPublicKey writes the armored public key of e, for distributing to
+// those verifying the charts it signs.
+func ExportPublicKey(w io.Writer, e *openpgp.Entity) error {
+	aw, err := armor.Encode(w, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return err
+	}
+	if err := e.Serialize(aw); err != nil {
+		aw.Close()
+		return errors.Wrap(err, "failed to export public key")
+	}
+	return aw.Close()
+}
+
+// ExportPrivateKey writes the armored private key of e, which must not be
+// encrypted.
+//
+// The key is written straight to w, without any intermediate copy on disk.
+func ExportPrivateKey(w io.Writer, e *openpgp.Entity) error {
+	if e.PrivateKey == nil {
+		return errors.New("private key not found")
+	}
+	if e.PrivateKey.Encrypted {
+		return errors.New("private key is encrypted")
+	}
+	aw, err := armor.Encode(w, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		return err
+	}
+	if err := e.SerializePrivate(aw, &defaultPGPConfig); err != nil {
+		aw.Close()
+		return errors.Wrap(err, "failed to export private key")
+	}
+	return aw.Close()
+}
+
+// ListKeys lists the keys of a keyring file, sorted by ID. The keyring may
+// be binary, as written by gpg, or armored, as written by ExportPublicKey
+// and ExportPrivateKey.
+func ListKeys(keyringfile string) ([]KeyInfo, error) {
+	data, err := ioutil.ReadFile(keyringfile)
+	if err != nil {
+		return nil, err
+	}
+	var ring openpgp.EntityList
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		ring, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		ring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read keyring %s", keyringfile)
+	}
+
+	keys := make([]KeyInfo, 0, len(ring))
+	for _, e := range ring {
+		key := KeyInfo{
+			ID:            e.PrimaryKey.KeyIdString(),
+			Fingerprint:   fmt.Sprintf("%X", e.PrimaryKey.Fingerprint),
+			Created:       e.PrimaryKey.CreationTime,
+			HasPrivateKey: e.PrivateKey != nil,
+		}
+		for name := range e.Identities {
+			key.Identities = append(key.Identities, name)
+		}
+		sort.Strings(key.Identities)
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
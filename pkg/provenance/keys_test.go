@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+func TestGenerateExportListKeys(t *testing.T) {
+	// a small key keeps the test fast
+	e, err := GenerateKey("Chart Publisher", "testing", "publisher@example.com", 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "Chart Publisher (testing) <publisher@example.com>"
+	if _, ok := e.Identities[name]; !ok {
+		t.Fatalf("expected an identity named %q", name)
+	}
+
+	dir, err := ioutil.TempDir("", "helm-keys-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var pub, priv bytes.Buffer
+	if err := ExportPublicKey(&pub, e); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportPrivateKey(&priv, e); err != nil {
+		t.Fatal(err)
+	}
+	pubfile := filepath.Join(dir, "pubring.asc")
+	privfile := filepath.Join(dir, "secring.asc")
+	if err := ioutil.WriteFile(pubfile, pub.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(privfile, priv.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, hasPrivateKey := range map[string]bool{pubfile: false, privfile: true} {
+		keys, err := ListKeys(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) != 1 {
+			t.Fatalf("expected 1 key in %s, got %d", file, len(keys))
+		}
+		key := keys[0]
+		if key.ID != e.PrimaryKey.KeyIdString() {
+			t.Errorf("expected key ID %s, got %s", e.PrimaryKey.KeyIdString(), key.ID)
+		}
+		if len(key.Fingerprint) != 40 {
+			t.Errorf("expected a 40 character fingerprint, got %q", key.Fingerprint)
+		}
+		if len(key.Identities) != 1 || key.Identities[0] != name {
+			t.Errorf("expected identities [%s], got %v", name, key.Identities)
+		}
+		if key.HasPrivateKey != hasPrivateKey {
+			t.Errorf("expected %s to have a private key: %t", file, hasPrivateKey)
+		}
+	}
+
+	// a chart signed with the generated key verifies with the exported public key
+	ring, err := openpgp.ReadArmoredKeyRing(&pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &Signatory{Entity: e, KeyRing: ring}
+	sig, err := signer.ClearSign(testChartfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigfile := filepath.Join(dir, "hashtest-1.2.3.tgz.prov")
+	if err := ioutil.WriteFile(sigfile, []byte(sig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Verify(testChartfile, sigfile); err != nil {
+		t.Errorf("failed to verify a chart signed with the generated key: %s", err)
+	}
+}
+
+func TestListKeysBinaryKeyring(t *testing.T) {
+	keys, err := ListKeys(testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Identities[0] != testKeyName || keys[0].HasPrivateKey {
+		t.Errorf("unexpected keys %+v", keys)
+	}
+}
+
+func TestGenerateKeyRequiresName(t *testing.T) {
+	if _, err := GenerateKey("", "", "publisher@example.com", 1024); err == nil {
+		t.Error("expected an error generating a key without a name")
+	}
+}
@@ -50,6 +50,19 @@ const (
 
 func (x HookDeletePolicy) String() string { return string(x) }
 
+// HookFailurePolicy specifies what happens to the release when a hook fails
+type HookFailurePolicy string
+
+// Hook failure policy types
+const (
+	// HookFailurePolicyFail fails the release operation when the hook fails. This is the default.
+	HookFailurePolicyFail HookFailurePolicy = "fail"
+	// HookFailurePolicyIgnore records the hook failure and carries on with the release operation
+	HookFailurePolicyIgnore HookFailurePolicy = "ignore"
+)
+
+func (x HookFailurePolicy) String() string { return string(x) }
+
 // HookAnnotation is the label name for a hook
 const HookAnnotation = "helm.sh/hook"
 
@@ -59,6 +72,9 @@ const HookWeightAnnotation = "helm.sh/hook-weight"
 // HookDeleteAnnotation is the label name for the delete policy for a hook
 const HookDeleteAnnotation = "helm.sh/hook-delete-policy"
 
+// HookFailureAnnotation is the label name for the failure policy for a hook
+const HookFailureAnnotation = "helm.sh/hook-failure-policy"
+
 // Hook defines a hook object.
 type Hook struct {
 	Name string `json:"name,omitempty"`
@@ -76,6 +92,8 @@ type Hook struct {
 	Weight int `json:"weight,omitempty"`
 	// DeletePolicies are the policies that indicate when to delete the hook
 	DeletePolicies []HookDeletePolicy `json:"delete_policies,omitempty"`
+	// FailurePolicy is the policy that indicates what happens when the hook fails
+	FailurePolicy HookFailurePolicy `json:"failure_policy,omitempty"`
 }
 
 // A HookExecution records the result for the last execution of a hook for a given release.
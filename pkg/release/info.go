@@ -33,4 +33,10 @@ type Info struct {
 	Status Status `json:"status,omitempty"`
 	// Contains the rendered templates/NOTES.txt if available
 	Notes string `json:"notes,omitempty"`
+	// TestResults records the outcome of the last test run, if it was persisted
+	TestResults *TestRun `json:"test_results,omitempty"`
+	// Checkpoint is the number of resources of the manifest created so far
+	// by an install that has not completed. It is kept when the install
+	// fails, so that the install can be resumed from that resource.
+	Checkpoint *int `json:"checkpoint,omitempty"`
 }
@@ -40,6 +40,11 @@ type Release struct {
 	// Labels of the release.
 	// Disabled encoding into Json cause labels are stored in storage driver metadata field.
 	Labels map[string]string `json:"-"`
+	// Annotations are arbitrary key/value pairs stored with the release, such
+	// as the commit or pull request that produced it, for correlation by
+	// external tools. Unlike Labels, they are stored with the release record
+	// itself, and they are never applied to the resources of the release.
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // SetStatus is a helper for setting the status on a release.
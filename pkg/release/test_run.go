@@ -0,0 +1,47 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"helm.sh/helm/v3/pkg/time"
+)
+
+// TestRun records the outcome of a run of the release tests.
+type TestRun struct {
+	// StartedAt indicates the date/time the test run was started
+	StartedAt time.Time `json:"started_at,omitempty"`
+	// CompletedAt indicates the date/time the test run was completed
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	// Passed is true if every test of the run succeeded
+	Passed bool `json:"passed"`
+	// Tests are the results of the individual tests of the run
+	Tests []*TestResult `json:"tests,omitempty"`
+}
+
+// TestResult records the outcome of a single release test.
+type TestResult struct {
+	// Name is the name of the test resource
+	Name string `json:"name"`
+	// Phase indicates whether the test succeeded
+	Phase HookPhase `json:"phase"`
+	// StartedAt indicates the date/time the test was started
+	StartedAt time.Time `json:"started_at,omitempty"`
+	// CompletedAt indicates the date/time the test was completed
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	// Logs is the tail of the logs of the test pod, if they could be fetched
+	Logs string `json:"logs,omitempty"`
+}
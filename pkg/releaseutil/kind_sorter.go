@@ -128,6 +128,45 @@ func sortHooksByKind(hooks []*release.Hook, ordering KindSortOrder) []*release.H
 	return h
 }
 
+// SortManifestsByKindAndName sorts manifests by 'ordering', as the install
+// sort does, and then the manifests of equal kind/priority by name. Unlike
+// the order of the templates they come from, it only depends on the
+// resources themselves.
+func SortManifestsByKindAndName(manifests []Manifest, ordering KindSortOrder) []Manifest {
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return lessByKindAndName(manifests[i].Head.Kind, manifests[j].Head.Kind, manifestName(manifests[i]), manifestName(manifests[j]), ordering)
+	})
+
+	return manifests
+}
+
+// SortHooksByKindAndName sorts hooks as SortManifestsByKindAndName sorts
+// manifests.
+func SortHooksByKindAndName(hooks []*release.Hook, ordering KindSortOrder) []*release.Hook {
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return lessByKindAndName(hooks[i].Kind, hooks[j].Kind, hooks[i].Name, hooks[j].Name, ordering)
+	})
+
+	return hooks
+}
+
+func lessByKindAndName(kindA, kindB, nameA, nameB string, o KindSortOrder) bool {
+	if lessByKind(nil, nil, kindA, kindB, o) {
+		return true
+	}
+	if lessByKind(nil, nil, kindB, kindA, o) {
+		return false
+	}
+	return nameA < nameB
+}
+
+func manifestName(m Manifest) string {
+	if m.Head == nil || m.Head.Metadata == nil {
+		return ""
+	}
+	return m.Head.Metadata.Name
+}
+
 func lessByKind(a interface{}, b interface{}, kindA string, kindB string, o KindSortOrder) bool {
 	ordering := make(map[string]int, len(o))
 	for v, k := range o {
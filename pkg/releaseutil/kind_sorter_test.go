@@ -329,3 +329,34 @@ func TestKindSorterForHooks(t *testing.T) {
 		})
 	}
 }
+
+func TestSortManifestsByKindAndName(t *testing.T) {
+	manifest := func(kind, name string) Manifest {
+		return Manifest{
+			Name: kind + "/" + name,
+			Head: &SimpleHead{Kind: kind, Metadata: &struct {
+				Name        string            `json:"name"`
+				Annotations map[string]string `json:"annotations"`
+			}{Name: name}},
+		}
+	}
+	manifests := []Manifest{
+		manifest("Service", "b"),
+		manifest("ConfigMap", "z"),
+		manifest("Unknown", "a"),
+		manifest("ConfigMap", "a"),
+		manifest("Service", "a"),
+		{Name: "Service/", Head: &SimpleHead{Kind: "Service"}},
+	}
+
+	var got []string
+	for _, m := range SortManifestsByKindAndName(manifests, InstallOrder) {
+		got = append(got, m.Name)
+	}
+	expected := []string{"ConfigMap/a", "ConfigMap/z", "Service/", "Service/a", "Service/b", "Unknown/a"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+}
@@ -198,6 +198,10 @@ func (file *manifestFile) sort(result *result) error {
 		operateAnnotationValues(entry, release.HookDeleteAnnotation, func(value string) {
 			h.DeletePolicies = append(h.DeletePolicies, release.HookDeletePolicy(value))
 		})
+
+		operateAnnotationValues(entry, release.HookFailureAnnotation, func(value string) {
+			h.FailurePolicy = release.HookFailurePolicy(value)
+		})
 	}
 
 	return nil
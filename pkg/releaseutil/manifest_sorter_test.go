@@ -226,3 +226,40 @@ metadata:
 		}
 	}
 }
+
+func TestSortManifestsHookFailurePolicy(t *testing.T) {
+	manifests := map[string]string{
+		"ignored": `kind: Job
+apiVersion: v1
+metadata:
+  name: ignored
+  annotations:
+    "helm.sh/hook": pre-install
+    "helm.sh/hook-failure-policy": " Ignore "
+`,
+		"default": `kind: Job
+apiVersion: v1
+metadata:
+  name: default
+  annotations:
+    "helm.sh/hook": pre-install
+`,
+	}
+
+	hs, _, err := SortManifests(manifests, chartutil.VersionSet{"v1"}, InstallOrder)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(hs) != 2 {
+		t.Fatalf("Expected 2 hooks, got %d", len(hs))
+	}
+	for _, h := range hs {
+		expected := release.HookFailurePolicy("")
+		if h.Name == "ignored" {
+			expected = release.HookFailurePolicyIgnore
+		}
+		if h.FailurePolicy != expected {
+			t.Errorf("Expected failure policy %q for hook %s, got %q", expected, h.Name, h.FailurePolicy)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil
+
+import "testing"
+
+func TestMatchTemplatePath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"templates/deployment.yaml", "templates/deployment.yaml", true},
+		{"templates/deployment.yaml", "templates/service.yaml", false},
+		{"templates/*.yaml", "templates/service.yaml", true},
+		{"templates/*.yaml", "templates/NOTES.txt", false},
+		{"templates/*.yaml", "charts/sub/templates/service.yaml", false},
+		{"**/ingress*.yaml", "templates/ingress.yaml", true},
+		{"**/ingress*.yaml", "charts/sub/templates/ingress-internal.yaml", true},
+		{"**/ingress*.yaml", "ingress.yaml", true},
+		{"**/ingress*.yaml", "templates/service.yaml", false},
+		{"charts/**", "charts/sub/templates/service.yaml", true},
+		{"charts/**/templates/*.yaml", "charts/sub/charts/nested/templates/cm.yaml", true},
+		{"charts/**/templates/*.yaml", "templates/cm.yaml", false},
+		{"templates/[", "templates/[", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchTemplatePath(tt.pattern, tt.name); got != tt.match {
+			t.Errorf("MatchTemplatePath(%q, %q) = %v, expected %v", tt.pattern, tt.name, got, tt.match)
+		}
+	}
+}
@@ -48,6 +48,10 @@ type Entry struct {
 	KeyFile               string `json:"keyFile"`
 	CAFile                string `json:"caFile"`
 	InsecureSkipTLSverify bool   `json:"insecure_skip_tls_verify"`
+	// Proxy is the proxy URL used for this repository instead of the environment's.
+	Proxy string `json:"proxy,omitempty"`
+	// NoProxy is a comma-separated list of hosts that bypass the proxy, as in NO_PROXY.
+	NoProxy string `json:"noProxy,omitempty"`
 }
 
 // ChartRepository represents a chart repository
@@ -129,6 +133,7 @@ func (r *ChartRepository) DownloadIndexFile() (string, error) {
 		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSverify),
 		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
 		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
+		getter.WithProxy(r.Config.Proxy, r.Config.NoProxy),
 	)
 	if err != nil {
 		return "", err
@@ -171,6 +171,40 @@ func TestIndexCustomSchemeDownload(t *testing.T) {
 	}
 }
 
+func TestIndexDownloadWithProxy(t *testing.T) {
+	fileBytes, err := ioutil.ReadFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var proxied []string
+	proxy, err := startLocalServerForTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write(fileBytes)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+
+	repo, err := NewChartRepository(&Entry{
+		Name:  "proxied",
+		URL:   "http://charts.example.com",
+		Proxy: proxy.URL,
+	}, getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.CachePath = ensure.TempDir(t)
+	defer os.RemoveAll(repo.CachePath)
+
+	if _, err := repo.DownloadIndexFile(); err != nil {
+		t.Fatalf("Failed to download index file through proxy: %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://charts.example.com/index.yaml" {
+		t.Errorf("Expected the index to be fetched through the proxy, got %v", proxied)
+	}
+}
+
 func verifyIndex(t *testing.T, actual *IndexFile) {
 	var empty time.Time
 	if actual.Generated.Equal(empty) {
@@ -246,6 +246,10 @@ func (i *IndexFile) Merge(f *IndexFile) {
 	}
 }
 
+// SBOMAnnotation is the chart version annotation holding the URL of the
+// software bill of materials published alongside a chart archive.
+const SBOMAnnotation = "helm.sh/sbom"
+
 // ChartVersion represents a chart entry in the IndexFile
 type ChartVersion struct {
 	*chart.Metadata
@@ -274,7 +278,8 @@ type ChartVersion struct {
 
 // IndexDirectory reads a (flat) directory and generates an index.
 //
-// It indexes only charts that have been packaged (*.tgz).
+// It indexes only charts that have been packaged (*.tgz). Charts with a sibling
+// SBOM file (*.tgz.sbom) are annotated with its URL under SBOMAnnotation.
 //
 // The index returned will be in an unsorted state
 func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
@@ -316,6 +321,14 @@ func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
 		if err := index.MustAdd(c.Metadata, fname, parentURL, hash); err != nil {
 			return index, errors.Wrapf(err, "failed adding to %s to index", fname)
 		}
+		if _, err := os.Stat(arch + ".sbom"); err == nil {
+			versions := index.Entries[c.Metadata.Name]
+			cv := versions[len(versions)-1]
+			if cv.Annotations == nil {
+				cv.Annotations = make(map[string]string)
+			}
+			cv.Annotations[SBOMAnnotation] = cv.URLs[0] + ".sbom"
+		}
 	}
 	return index, nil
 }
@@ -467,6 +467,48 @@ func TestIndexDirectory(t *testing.T) {
 	}
 }
 
+func TestIndexDirectorySBOM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-index-sbom-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"frobnitz-1.2.3.tgz", "sprocket-1.1.0.tgz"} {
+		b, err := ioutil.ReadFile(filepath.Join("testdata/repository", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "frobnitz-1.2.3.tgz.sbom"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := IndexDirectory(dir, "http://localhost:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frob, err := index.Get("frobnitz", "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := frob.Annotations[SBOMAnnotation], "http://localhost:8080/frobnitz-1.2.3.tgz.sbom"; got != want {
+		t.Errorf("expected SBOM annotation %q, got %q", want, got)
+	}
+
+	sprocket, err := index.Get("sprocket", "1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sprocket.Annotations[SBOMAnnotation]; ok {
+		t.Error("expected no SBOM annotation for a chart without an SBOM")
+	}
+}
+
 func TestIndexAdd(t *testing.T) {
 	i := NewIndexFile()
 
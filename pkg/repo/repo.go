@@ -70,13 +70,21 @@ func (r *File) Update(re ...*Entry) {
 }
 
 func (r *File) update(e *Entry) {
+	r.Upsert(e)
+}
+
+// Upsert replaces the repo entry with the same name, updating its URL and
+// credentials, or adds the entry if no such entry exists. It returns true if an
+// existing entry was updated.
+func (r *File) Upsert(e *Entry) bool {
 	for j, repo := range r.Repositories {
 		if repo.Name == e.Name {
 			r.Repositories[j] = e
-			return
+			return true
 		}
 	}
 	r.Add(e)
+	return false
 }
 
 // Has returns true if the given name is already a repository name.
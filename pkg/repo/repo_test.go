@@ -185,6 +185,29 @@ func TestUpdateRepository(t *testing.T) {
 	}
 }
 
+func TestUpsertRepository(t *testing.T) {
+	sampleRepository := NewFile()
+	sampleRepository.Add(&Entry{
+		Name: "stable",
+		URL:  "https://example.com/stable/charts",
+	})
+
+	if sampleRepository.Upsert(&Entry{Name: "sample", URL: "https://example.com/sample"}) {
+		t.Error("expected a new repository to be added, not updated")
+	}
+	if !sampleRepository.Upsert(&Entry{Name: "stable", URL: "https://example.com/charts", Username: "user"}) {
+		t.Error("expected an existing repository to be updated")
+	}
+
+	if len(sampleRepository.Repositories) != 2 {
+		t.Errorf("expected 2 repositories, got %d", len(sampleRepository.Repositories))
+	}
+	stable := sampleRepository.Get("stable")
+	if stable.URL != "https://example.com/charts" || stable.Username != "user" {
+		t.Errorf("expected stable repository to be updated, got %+v", stable)
+	}
+}
+
 func TestWriteFile(t *testing.T) {
 	sampleRepository := NewFile()
 	sampleRepository.Add(
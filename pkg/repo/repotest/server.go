@@ -67,6 +67,7 @@ type OCIServer struct {
 
 type OCIServerRunConfig struct {
 	DependingChart *chart.Chart
+	SBOM           []byte
 }
 
 type OCIServerOpt func(config *OCIServerRunConfig)
@@ -77,6 +78,13 @@ func WithDependingChart(c *chart.Chart) OCIServerOpt {
 	}
 }
 
+// WithSBOM pushes sbom as the software bill of materials of the dependent chart
+func WithSBOM(sbom []byte) OCIServerOpt {
+	return func(config *OCIServerRunConfig) {
+		config.SBOM = sbom
+	}
+}
+
 func NewOCIServer(t *testing.T, dir string) (*OCIServer, error) {
 	testHtpasswdFileBasename := "authtest.htpasswd"
 	testUsername, testPassword := "username", "password"
@@ -195,6 +203,12 @@ func (srv *OCIServer) Run(t *testing.T, opts ...OCIServerOpt) {
 		t.Fatal("error pushing chart")
 	}
 
+	if cfg.SBOM != nil {
+		if err := registryClient.PushSBOM(ref, cfg.SBOM); err != nil {
+			t.Fatal("error pushing SBOM")
+		}
+	}
+
 	if cfg.DependingChart != nil {
 		c := cfg.DependingChart
 		dependingRef, err := ociRegistry.ParseReference(fmt.Sprintf("%s/u/ocitestuser/oci-depending-chart:1.2.3", srv.RegistryURL))
@@ -0,0 +1,221 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v3/pkg/storage/driver"
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+
+	rspb "helm.sh/helm/v3/pkg/release"
+)
+
+var _ Driver = (*CachedDriver)(nil)
+
+// CachedDriver wraps a driver, keeping the releases it reads for a while so
+// that repeated reads, such as those of 'helm list', do not fetch and decode
+// them again. Any Create, Update or Delete drops the cached entries it may
+// affect.
+//
+// Callers are given copies of the cached releases, so they may modify them
+// as they would the releases read from any other driver.
+type CachedDriver struct {
+	Driver
+	// TTL is how long a read is cached.
+	TTL time.Duration
+
+	mu  sync.Mutex
+	now func() time.Time
+	// generation is incremented by every write, so that a read started
+	// before a write does not cache what it read.
+	generation uint64
+	gets       map[string]cachedRelease
+	all        *cachedReleases
+	queries    map[string]cachedReleases
+}
+
+type cachedRelease struct {
+	rls     *rspb.Release
+	expires time.Time
+}
+
+type cachedReleases struct {
+	rls     []*rspb.Release
+	expires time.Time
+}
+
+// NewCachedDriver returns a driver caching the reads of d for ttl.
+func NewCachedDriver(d Driver, ttl time.Duration) *CachedDriver {
+	return &CachedDriver{
+		Driver:  d,
+		TTL:     ttl,
+		now:     time.Now,
+		gets:    map[string]cachedRelease{},
+		queries: map[string]cachedReleases{},
+	}
+}
+
+// Get returns the release named by key, from the cache if it was read less
+// than TTL ago.
+func (c *CachedDriver) Get(key string) (*rspb.Release, error) {
+	c.mu.Lock()
+	r, ok := c.gets[key]
+	generation := c.generation
+	c.mu.Unlock()
+	if ok && c.now().Before(r.expires) {
+		return copyRelease(r.rls)
+	}
+
+	rls, err := c.Driver.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.generation == generation {
+		c.gets[key] = cachedRelease{rls, c.now().Add(c.TTL)}
+	}
+	c.mu.Unlock()
+	return copyRelease(rls)
+}
+
+// List returns the releases that satisfy filter. All the releases are read
+// at once and cached, and filter is applied to the cached releases.
+func (c *CachedDriver) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	c.mu.Lock()
+	all := c.all
+	generation := c.generation
+	c.mu.Unlock()
+
+	if all == nil || !c.now().Before(all.expires) {
+		rls, err := c.Driver.List(func(*rspb.Release) bool { return true })
+		if err != nil {
+			return nil, err
+		}
+		all = &cachedReleases{rls, c.now().Add(c.TTL)}
+		c.mu.Lock()
+		if c.generation == generation {
+			c.all = all
+		}
+		c.mu.Unlock()
+	}
+
+	var results []*rspb.Release
+	for _, rls := range all.rls {
+		if filter(rls) {
+			results = append(results, rls)
+		}
+	}
+	return copyReleases(results)
+}
+
+// Query returns the releases matching labels, from the cache if the same
+// labels were queried less than TTL ago.
+func (c *CachedDriver) Query(labels map[string]string) ([]*rspb.Release, error) {
+	key := labelsKey(labels)
+	c.mu.Lock()
+	r, ok := c.queries[key]
+	generation := c.generation
+	c.mu.Unlock()
+	if ok && c.now().Before(r.expires) {
+		return copyReleases(r.rls)
+	}
+
+	results, err := c.Driver.Query(labels)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.generation == generation {
+		c.queries[key] = cachedReleases{results, c.now().Add(c.TTL)}
+	}
+	c.mu.Unlock()
+	return copyReleases(results)
+}
+
+// Create stores the release and drops the cached entries it affects.
+func (c *CachedDriver) Create(key string, rls *rspb.Release) error {
+	defer c.invalidate(key)
+	return c.Driver.Create(key, rls)
+}
+
+// Update updates the release and drops the cached entries it affects.
+func (c *CachedDriver) Update(key string, rls *rspb.Release) error {
+	defer c.invalidate(key)
+	return c.Driver.Update(key, rls)
+}
+
+// Delete deletes the release and drops the cached entries it affects.
+func (c *CachedDriver) Delete(key string) (*rspb.Release, error) {
+	defer c.invalidate(key)
+	return c.Driver.Delete(key)
+}
+
+// invalidate drops the cached release named by key, and the cached lists and
+// queries, any of which may hold it. Reads in progress are not cached.
+func (c *CachedDriver) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	delete(c.gets, key)
+	c.all = nil
+	c.queries = map[string]cachedReleases{}
+}
+
+// copyRelease returns a deep copy of rls, so that callers modifying it do not
+// modify the cached release.
+func copyRelease(rls *rspb.Release) (*rspb.Release, error) {
+	if rls == nil {
+		return nil, nil
+	}
+	c, err := copystructure.Copy(rls)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to copy release %q", rls.Name)
+	}
+	return c.(*rspb.Release), nil
+}
+
+// copyReleases returns deep copies of releases.
+func copyReleases(releases []*rspb.Release) ([]*rspb.Release, error) {
+	if releases == nil {
+		return nil, nil
+	}
+	copies := make([]*rspb.Release, len(releases))
+	for i, rls := range releases {
+		c, err := copyRelease(rls)
+		if err != nil {
+			return nil, err
+		}
+		copies[i] = c
+	}
+	return copies, nil
+}
+
+// labelsKey returns a string identifying a label set, whatever the order of
+// its labels.
+func labelsKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,266 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chart"
+	rspb "helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+// countingDriver counts the reads reaching the driver it wraps.
+type countingDriver struct {
+	Driver
+	gets, lists, queries int
+}
+
+func (d *countingDriver) Get(key string) (*rspb.Release, error) {
+	d.gets++
+	return d.Driver.Get(key)
+}
+
+func (d *countingDriver) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	d.lists++
+	return d.Driver.List(filter)
+}
+
+func (d *countingDriver) Query(labels map[string]string) ([]*rspb.Release, error) {
+	d.queries++
+	return d.Driver.Query(labels)
+}
+
+func newTestCachedDriver(t *testing.T) (*CachedDriver, *countingDriver, *time.Time) {
+	mem := tsFixtureMemory(t)
+	mem.SetNamespace("default")
+	backing := &countingDriver{Driver: mem}
+	c := NewCachedDriver(backing, time.Minute)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, backing, &now
+}
+
+func TestCachedDriverHits(t *testing.T) {
+	c, backing, now := newTestCachedDriver(t)
+	key := testKey("rls-a", 4)
+
+	for i := 0; i < 3; i++ {
+		rls, err := c.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rls.Name != "rls-a" || rls.Version != 4 {
+			t.Fatalf("Expected rls-a v4, got %s v%d", rls.Name, rls.Version)
+		}
+		deployed, err := c.List(func(r *rspb.Release) bool { return r.Info.Status == rspb.StatusDeployed })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deployed) != 2 {
+			t.Fatalf("Expected 2 deployed releases, got %d", len(deployed))
+		}
+		if _, err := c.Query(map[string]string{"name": "rls-a", "owner": "helm"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backing.gets != 1 || backing.lists != 1 || backing.queries != 1 {
+		t.Errorf("Expected a single read of each kind, got %d gets, %d lists and %d queries", backing.gets, backing.lists, backing.queries)
+	}
+
+	// the reads expire after the TTL
+	*now = now.Add(2 * time.Minute)
+	if _, err := c.Get(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.List(func(*rspb.Release) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	if backing.gets != 2 || backing.lists != 2 {
+		t.Errorf("Expected the expired reads to reach the driver, got %d gets and %d lists", backing.gets, backing.lists)
+	}
+
+	// errors are not cached
+	if _, err := c.Get(testKey("rls-z", 1)); err != ErrReleaseNotFound {
+		t.Fatalf("Expected ErrReleaseNotFound, got %v", err)
+	}
+	if _, err := c.Get(testKey("rls-z", 1)); err != ErrReleaseNotFound {
+		t.Fatalf("Expected ErrReleaseNotFound, got %v", err)
+	}
+	if backing.gets != 4 {
+		t.Errorf("Expected the missing release to be read twice, got %d gets", backing.gets-2)
+	}
+}
+
+func TestCachedDriverInvalidation(t *testing.T) {
+	c, backing, _ := newTestCachedDriver(t)
+	all := func(*rspb.Release) bool { return true }
+
+	key := testKey("rls-a", 4)
+	if _, err := c.Get(key); err != nil {
+		t.Fatal(err)
+	}
+	before, err := c.List(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Update
+	rls := releaseStub("rls-a", 4, "default", rspb.StatusSuperseded)
+	if err := c.Update(key, rls); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Info.Status != rspb.StatusSuperseded {
+		t.Errorf("Expected the updated release, got status %s", got.Info.Status)
+	}
+	if backing.gets != 2 {
+		t.Errorf("Expected the update to drop the cached release, got %d gets", backing.gets)
+	}
+
+	// Create
+	if err := c.Create(testKey("rls-d", 1), releaseStub("rls-d", 1, "default", rspb.StatusDeployed)); err != nil {
+		t.Fatal(err)
+	}
+	after, err := c.List(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before)+1 || backing.lists != 2 {
+		t.Errorf("Expected the created release to be listed, got %d releases after %d lists", len(after), backing.lists)
+	}
+
+	// Delete
+	if _, err := c.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(key); err != ErrReleaseNotFound {
+		t.Errorf("Expected the deleted release to be gone, got %v", err)
+	}
+	if after, err = c.List(all); err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) || backing.lists != 3 {
+		t.Errorf("Expected the deleted release not to be listed, got %d releases after %d lists", len(after), backing.lists)
+	}
+}
+
+func TestCachedDriverReturnsCopies(t *testing.T) {
+	c, _, _ := newTestCachedDriver(t)
+	rls := releaseStub("rls-d", 1, "default", rspb.StatusDeployed)
+	rls.Info.FirstDeployed = helmtime.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	rls.Config = map[string]interface{}{"replicas": 1}
+	rls.Chart = &chart.Chart{Metadata: &chart.Metadata{Name: "web", Version: "1.0.0"}}
+	key := testKey(rls.Name, rls.Version)
+	if err := c.Create(key, rls); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rls) {
+		t.Fatalf("expected %+v, got %+v", rls, got)
+	}
+	got.Info.Status = rspb.StatusFailed
+	got.Config["replicas"] = 3
+	got.Chart.Metadata.Version = "2.0.0"
+
+	listed, err := c.List(func(r *rspb.Release) bool { return r.Name == "rls-d" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed[0].Info.Status = rspb.StatusUninstalled
+
+	for _, read := range []func() (*rspb.Release, error){
+		func() (*rspb.Release, error) { return c.Get(key) },
+		func() (*rspb.Release, error) {
+			results, err := c.List(func(r *rspb.Release) bool { return r.Name == "rls-d" })
+			if err != nil {
+				return nil, err
+			}
+			return results[0], nil
+		},
+	} {
+		again, err := read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.Info.Status != rspb.StatusDeployed || again.Config["replicas"] != 1 || again.Chart.Metadata.Version != "1.0.0" {
+			t.Errorf("expected the cached release to be unchanged, got %+v", again)
+		}
+	}
+}
+
+// blockingDriver blocks the Get of key until release is closed.
+type blockingDriver struct {
+	Driver
+	key     string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (d *blockingDriver) Get(key string) (*rspb.Release, error) {
+	if key == d.key {
+		close(d.started)
+		<-d.release
+	}
+	return d.Driver.Get(key)
+}
+
+func TestCachedDriverDoesNotHoldLockWhileFetching(t *testing.T) {
+	mem := tsFixtureMemory(t)
+	mem.SetNamespace("default")
+	backing := &blockingDriver{Driver: mem, key: testKey("rls-b", 4), started: make(chan struct{}), release: make(chan struct{})}
+	c := NewCachedDriver(backing, time.Minute)
+
+	if _, err := c.Get(testKey("rls-a", 4)); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Get(testKey("rls-b", 4))
+		done <- err
+	}()
+	<-backing.started
+
+	cached := make(chan error, 1)
+	go func() {
+		_, err := c.Get(testKey("rls-a", 4))
+		cached <- err
+	}()
+	select {
+	case err := <-cached:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a cached read waited for a read in progress")
+	}
+
+	close(backing.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
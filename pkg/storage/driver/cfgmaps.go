@@ -33,7 +33,10 @@ import (
 	rspb "helm.sh/helm/v3/pkg/release"
 )
 
-var _ Driver = (*ConfigMaps)(nil)
+var (
+	_ Driver      = (*ConfigMaps)(nil)
+	_ PagedLister = (*ConfigMaps)(nil)
+)
 
 // ConfigMapsDriverName is the string name of the driver.
 const ConfigMapsDriverName = "ConfigMap"
@@ -43,6 +46,9 @@ const ConfigMapsDriverName = "ConfigMap"
 type ConfigMaps struct {
 	impl corev1.ConfigMapInterface
 	Log  func(string, ...interface{})
+	// Codec compresses the stored releases. When nil, they are gzipped
+	// without a codec marker, readable by any Helm 3 version.
+	Codec Codec
 }
 
 // NewConfigMaps initializes a new ConfigMaps wrapping an implementation of
@@ -115,6 +121,37 @@ func (cfgmaps *ConfigMaps) List(filter func(*rspb.Release) bool) ([]*rspb.Releas
 	return results, nil
 }
 
+// ListPaged fetches at most limit releases, using the limit and continue
+// options of the Kubernetes list API, and returns the continue token of the
+// next page. The releases are in the order the API server returns the
+// configmaps in.
+func (cfgmaps *ConfigMaps) ListPaged(continueToken string, limit int) ([]*rspb.Release, string, error) {
+	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
+	opts := metav1.ListOptions{LabelSelector: lsel.String(), Continue: continueToken}
+	if limit > 0 {
+		opts.Limit = int64(limit)
+	}
+
+	list, err := cfgmaps.impl.List(context.Background(), opts)
+	if err != nil {
+		cfgmaps.Log("list: failed to list: %s", err)
+		return nil, "", err
+	}
+
+	results := make([]*rspb.Release, 0, len(list.Items))
+	for _, item := range list.Items {
+		rls, err := decodeRelease(item.Data["release"])
+		if err != nil {
+			cfgmaps.Log("list: failed to decode release: %v: %s", item, err)
+			continue
+		}
+
+		rls.Labels = item.ObjectMeta.Labels
+		results = append(results, rls)
+	}
+	return results, list.Continue, nil
+}
+
 // Query fetches all releases that match the provided map of labels.
 // An error is returned if the configmap fails to retrieve the releases.
 func (cfgmaps *ConfigMaps) Query(labels map[string]string) ([]*rspb.Release, error) {
@@ -160,7 +197,7 @@ func (cfgmaps *ConfigMaps) Create(key string, rls *rspb.Release) error {
 	lbs.set("createdAt", strconv.Itoa(int(time.Now().Unix())))
 
 	// create a new configmap to hold the release
-	obj, err := newConfigMapsObject(key, rls, lbs)
+	obj, err := newConfigMapsObject(key, rls, lbs, cfgmaps.Codec)
 	if err != nil {
 		cfgmaps.Log("create: failed to encode release %q: %s", rls.Name, err)
 		return err
@@ -187,7 +224,7 @@ func (cfgmaps *ConfigMaps) Update(key string, rls *rspb.Release) error {
 	lbs.set("modifiedAt", strconv.Itoa(int(time.Now().Unix())))
 
 	// create a new configmap object to hold the release
-	obj, err := newConfigMapsObject(key, rls, lbs)
+	obj, err := newConfigMapsObject(key, rls, lbs, cfgmaps.Codec)
 	if err != nil {
 		cfgmaps.Log("update: failed to encode release %q: %s", rls.Name, err)
 		return err
@@ -216,7 +253,7 @@ func (cfgmaps *ConfigMaps) Delete(key string) (rls *rspb.Release, err error) {
 
 // newConfigMapsObject constructs a kubernetes ConfigMap object
 // to store a release. Each configmap data entry is the base64
-// encoded gzipped string of a release.
+// encoded string of a release, compressed by the codec.
 //
 // The following labels are used within each configmap:
 //
@@ -227,11 +264,11 @@ func (cfgmaps *ConfigMaps) Delete(key string) (rls *rspb.Release, err error) {
 //    "owner"          - owner of the configmap, currently "helm".
 //    "name"           - name of the release.
 //
-func newConfigMapsObject(key string, rls *rspb.Release, lbs labels) (*v1.ConfigMap, error) {
+func newConfigMapsObject(key string, rls *rspb.Release, lbs labels, codec Codec) (*v1.ConfigMap, error) {
 	const owner = "helm"
 
 	// encode the release
-	s, err := encodeRelease(rls)
+	s, err := encodeReleaseWithCodec(rls, codec)
 	if err != nil {
 		return nil, err
 	}
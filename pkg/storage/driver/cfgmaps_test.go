@@ -59,7 +59,7 @@ func TestUncompressedConfigMapGet(t *testing.T) {
 	rel := releaseStub(name, vers, namespace, rspb.StatusDeployed)
 
 	// Create a test fixture which contains an uncompressed release
-	cfgmap, err := newConfigMapsObject(key, rel, nil)
+	cfgmap, err := newConfigMapsObject(key, rel, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to create configmap: %s", err)
 	}
@@ -130,6 +130,45 @@ func TestConfigMapList(t *testing.T) {
 	}
 }
 
+func TestConfigMapListPaged(t *testing.T) {
+	cfgmaps := newTestFixtureCfgMaps(t, []*rspb.Release{
+		releaseStub("key-1", 1, "default", rspb.StatusSuperseded),
+		releaseStub("key-1", 2, "default", rspb.StatusDeployed),
+		releaseStub("key-2", 1, "default", rspb.StatusDeployed),
+		releaseStub("key-3", 1, "default", rspb.StatusUninstalled),
+		releaseStub("key-4", 1, "default", rspb.StatusDeployed),
+	}...)
+
+	var (
+		pages [][]string
+		token string
+	)
+	for {
+		page, next, err := cfgmaps.ListPaged(token, 2)
+		if err != nil {
+			t.Fatalf("Failed to list page %d: %s", len(pages), err)
+		}
+		var keys []string
+		for _, rls := range page {
+			keys = append(keys, testKey(rls.Name, rls.Version))
+		}
+		pages = append(pages, keys)
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	expect := [][]string{
+		{"key-1.v1", "key-1.v2"},
+		{"key-2.v1", "key-3.v1"},
+		{"key-4.v1"},
+	}
+	if !reflect.DeepEqual(pages, expect) {
+		t.Errorf("Expected pages %v, got %v", expect, pages)
+	}
+}
+
 func TestConfigMapQuery(t *testing.T) {
 	cfgmaps := newTestFixtureCfgMaps(t, []*rspb.Release{
 		releaseStub("key-1", 1, "default", rspb.StatusUninstalled),
@@ -239,3 +278,25 @@ func TestConfigMapDelete(t *testing.T) {
 		t.Errorf("Expected {%v}, got {%v}", ErrReleaseNotFound, err)
 	}
 }
+
+func TestConfigMapAnnotations(t *testing.T) {
+	vers := 1
+	name := "smug-pigeon"
+	namespace := "default"
+	key := testKey(name, vers)
+	rel := releaseStub(name, vers, namespace, rspb.StatusDeployed)
+	rel.Annotations = map[string]string{"commit": "1a2b3c4", "pull-request": "42"}
+
+	cfgmaps := newTestFixtureCfgMaps(t)
+	if err := cfgmaps.Create(key, rel); err != nil {
+		t.Fatalf("Failed to create release with key %q: %s", key, err)
+	}
+
+	got, err := cfgmaps.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release: %s", err)
+	}
+	if !reflect.DeepEqual(rel.Annotations, got.Annotations) {
+		t.Errorf("Expected annotations %v, got %v", rel.Annotations, got.Annotations)
+	}
+}
@@ -92,6 +92,17 @@ type Queryor interface {
 	Query(labels map[string]string) ([]*rspb.Release, error)
 }
 
+// PagedLister is the interface that wraps the ListPaged method.
+//
+// ListPaged returns at most limit releases, starting from the position
+// described by continueToken, along with the token to pass to read the next
+// page. An empty continueToken starts from the first release and an empty
+// returned token means there are no more releases. A limit of 0 or less
+// returns all the remaining releases.
+type PagedLister interface {
+	ListPaged(continueToken string, limit int) ([]*rspb.Release, string, error)
+}
+
 // Driver is the interface composed of Creator, Updator, Deletor, and Queryor
 // interfaces. It defines the behavior for storing, updating, deleted,
 // and retrieving Helm releases from some underlying storage mechanism,
@@ -19,6 +19,7 @@ package driver // import "helm.sh/helm/v3/pkg/storage/driver"
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
@@ -99,7 +100,7 @@ func (mock *MockConfigMapsInterface) Init(t *testing.T, releases ...*rspb.Releas
 	for _, rls := range releases {
 		objkey := testKey(rls.Name, rls.Version)
 
-		cfgmap, err := newConfigMapsObject(objkey, rls, nil)
+		cfgmap, err := newConfigMapsObject(objkey, rls, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to create configmap: %s", err)
 		}
@@ -125,11 +126,16 @@ func (mock *MockConfigMapsInterface) List(_ context.Context, opts metav1.ListOpt
 		return nil, err
 	}
 
-	for _, cfgmap := range mock.objects {
+	var names []string
+	for name, cfgmap := range mock.objects {
 		if labelSelector.Matches(kblabels.Set(cfgmap.ObjectMeta.Labels)) {
-			list.Items = append(list.Items, *cfgmap)
+			names = append(names, name)
 		}
 	}
+	names, list.Continue = pageNames(names, opts)
+	for _, name := range names {
+		list.Items = append(list.Items, *mock.objects[name])
+	}
 	return &list, nil
 }
 
@@ -185,7 +191,7 @@ func (mock *MockSecretsInterface) Init(t *testing.T, releases ...*rspb.Release)
 	for _, rls := range releases {
 		objkey := testKey(rls.Name, rls.Version)
 
-		secret, err := newSecretsObject(objkey, rls, nil)
+		secret, err := newSecretsObject(objkey, rls, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to create secret: %s", err)
 		}
@@ -211,11 +217,16 @@ func (mock *MockSecretsInterface) List(_ context.Context, opts metav1.ListOption
 		return nil, err
 	}
 
-	for _, secret := range mock.objects {
+	var names []string
+	for name, secret := range mock.objects {
 		if labelSelector.Matches(kblabels.Set(secret.ObjectMeta.Labels)) {
-			list.Items = append(list.Items, *secret)
+			names = append(names, name)
 		}
 	}
+	names, list.Continue = pageNames(names, opts)
+	for _, name := range names {
+		list.Items = append(list.Items, *mock.objects[name])
+	}
 	return &list, nil
 }
 
@@ -248,6 +259,22 @@ func (mock *MockSecretsInterface) Delete(_ context.Context, name string, _ metav
 	return nil
 }
 
+// pageNames sorts names and returns the page of them selected by the limit
+// and continue options, as the Kubernetes API server would, along with the
+// continue token of the next page.
+func pageNames(names []string, opts metav1.ListOptions) ([]string, string) {
+	sort.Strings(names)
+	start := 0
+	if opts.Continue != "" {
+		start = sort.SearchStrings(names, opts.Continue)
+	}
+	names = names[start:]
+	if opts.Limit > 0 && int64(len(names)) > opts.Limit {
+		return names[:opts.Limit], names[opts.Limit]
+	}
+	return names, ""
+}
+
 // newTestFixtureSQL mocks the SQL database (for testing purposes)
 func newTestFixtureSQL(t *testing.T, releases ...*rspb.Release) (*SQL, sqlmock.Sqlmock) {
 	sqlDB, mock, err := sqlmock.New()
@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v3/pkg/storage/driver"
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	rspb "helm.sh/helm/v3/pkg/release"
+)
+
+// ListPaged reads a page of releases from d. Drivers implementing PagedLister
+// page the releases themselves; the releases of the other drivers are all
+// listed, ordered by namespace, name and version, and the page is cut out of
+// them, using the offset of the next page as token.
+func ListPaged(d Driver, continueToken string, limit int) ([]*rspb.Release, string, error) {
+	if p, ok := d.(PagedLister); ok {
+		return p.ListPaged(continueToken, limit)
+	}
+	all, err := d.List(func(*rspb.Release) bool { return true })
+	if err != nil {
+		return nil, "", err
+	}
+	return pageReleases(all, continueToken, limit)
+}
+
+// pageReleases returns the page of rls starting at the offset continueToken.
+func pageReleases(rls []*rspb.Release, continueToken string, limit int) ([]*rspb.Release, string, error) {
+	offset := 0
+	if continueToken != "" {
+		var err error
+		if offset, err = strconv.Atoi(continueToken); err != nil || offset < 0 {
+			return nil, "", errors.Errorf("invalid continue token %q", continueToken)
+		}
+	}
+
+	sorted := make([]*rspb.Release, len(rls))
+	copy(sorted, rls)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+
+	if offset >= len(sorted) {
+		return []*rspb.Release{}, "", nil
+	}
+	last := len(sorted)
+	if limit > 0 && offset+limit < last {
+		last = offset + limit
+	}
+	next := ""
+	if last < len(sorted) {
+		next = strconv.Itoa(last)
+	}
+	return sorted[offset:last], next, nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v3/pkg/storage/driver"
+
+import (
+	"testing"
+
+	rspb "helm.sh/helm/v3/pkg/release"
+)
+
+func TestListPagedFallback(t *testing.T) {
+	mem := tsFixtureMemory(t)
+	mem.SetNamespace("default")
+
+	page, next, err := ListPaged(mem, "", 3)
+	if err != nil {
+		t.Fatalf("Failed to list first page: %s", err)
+	}
+	assertPage(t, page, "rls-a.v1", "rls-a.v2", "rls-a.v3")
+	if next != "3" {
+		t.Errorf("Expected next token %q, got %q", "3", next)
+	}
+
+	page, next, err = ListPaged(mem, next, 10)
+	if err != nil {
+		t.Fatalf("Failed to list last page: %s", err)
+	}
+	assertPage(t, page, "rls-a.v4", "rls-b.v1", "rls-b.v2", "rls-b.v3", "rls-b.v4")
+	if next != "" {
+		t.Errorf("Expected no next token, got %q", next)
+	}
+
+	if _, _, err := ListPaged(mem, "bogus", 3); err == nil {
+		t.Error("Expected an error for an invalid continue token")
+	}
+}
+
+func assertPage(t *testing.T, page []*rspb.Release, keys ...string) {
+	t.Helper()
+	if len(page) != len(keys) {
+		t.Fatalf("Expected %d releases, got %d", len(keys), len(page))
+	}
+	for i, rls := range page {
+		if key := testKey(rls.Name, rls.Version); key != keys[i] {
+			t.Errorf("Expected release %d to be %q, got %q", i, keys[i], key)
+		}
+	}
+}
@@ -33,7 +33,10 @@ import (
 	rspb "helm.sh/helm/v3/pkg/release"
 )
 
-var _ Driver = (*Secrets)(nil)
+var (
+	_ Driver      = (*Secrets)(nil)
+	_ PagedLister = (*Secrets)(nil)
+)
 
 // SecretsDriverName is the string name of the driver.
 const SecretsDriverName = "Secret"
@@ -43,6 +46,9 @@ const SecretsDriverName = "Secret"
 type Secrets struct {
 	impl corev1.SecretInterface
 	Log  func(string, ...interface{})
+	// Codec compresses the stored releases. When nil, they are gzipped
+	// without a codec marker, readable by any Helm 3 version.
+	Codec Codec
 }
 
 // NewSecrets initializes a new Secrets wrapping an implementation of
@@ -107,6 +113,36 @@ func (secrets *Secrets) List(filter func(*rspb.Release) bool) ([]*rspb.Release,
 	return results, nil
 }
 
+// ListPaged fetches at most limit releases, using the limit and continue
+// options of the Kubernetes list API, and returns the continue token of the
+// next page. The releases are in the order the API server returns the
+// secrets in.
+func (secrets *Secrets) ListPaged(continueToken string, limit int) ([]*rspb.Release, string, error) {
+	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
+	opts := metav1.ListOptions{LabelSelector: lsel.String(), Continue: continueToken}
+	if limit > 0 {
+		opts.Limit = int64(limit)
+	}
+
+	list, err := secrets.impl.List(context.Background(), opts)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "list: failed to list")
+	}
+
+	results := make([]*rspb.Release, 0, len(list.Items))
+	for _, item := range list.Items {
+		rls, err := decodeRelease(string(item.Data["release"]))
+		if err != nil {
+			secrets.Log("list: failed to decode release: %v: %s", item, err)
+			continue
+		}
+
+		rls.Labels = item.ObjectMeta.Labels
+		results = append(results, rls)
+	}
+	return results, list.Continue, nil
+}
+
 // Query fetches all releases that match the provided map of labels.
 // An error is returned if the secret fails to retrieve the releases.
 func (secrets *Secrets) Query(labels map[string]string) ([]*rspb.Release, error) {
@@ -151,7 +187,7 @@ func (secrets *Secrets) Create(key string, rls *rspb.Release) error {
 	lbs.set("createdAt", strconv.Itoa(int(time.Now().Unix())))
 
 	// create a new secret to hold the release
-	obj, err := newSecretsObject(key, rls, lbs)
+	obj, err := newSecretsObject(key, rls, lbs, secrets.Codec)
 	if err != nil {
 		return errors.Wrapf(err, "create: failed to encode release %q", rls.Name)
 	}
@@ -176,7 +212,7 @@ func (secrets *Secrets) Update(key string, rls *rspb.Release) error {
 	lbs.set("modifiedAt", strconv.Itoa(int(time.Now().Unix())))
 
 	// create a new secret object to hold the release
-	obj, err := newSecretsObject(key, rls, lbs)
+	obj, err := newSecretsObject(key, rls, lbs, secrets.Codec)
 	if err != nil {
 		return errors.Wrapf(err, "update: failed to encode release %q", rls.Name)
 	}
@@ -198,7 +234,7 @@ func (secrets *Secrets) Delete(key string) (rls *rspb.Release, err error) {
 
 // newSecretsObject constructs a kubernetes Secret object
 // to store a release. Each secret data entry is the base64
-// encoded gzipped string of a release.
+// encoded string of a release, compressed by the codec.
 //
 // The following labels are used within each secret:
 //
@@ -209,11 +245,11 @@ func (secrets *Secrets) Delete(key string) (rls *rspb.Release, err error) {
 //    "owner"          - owner of the secret, currently "helm".
 //    "name"           - name of the release.
 //
-func newSecretsObject(key string, rls *rspb.Release, lbs labels) (*v1.Secret, error) {
+func newSecretsObject(key string, rls *rspb.Release, lbs labels, codec Codec) (*v1.Secret, error) {
 	const owner = "helm"
 
 	// encode the release
-	s, err := encodeRelease(rls)
+	s, err := encodeReleaseWithCodec(rls, codec)
 	if err != nil {
 		return nil, err
 	}
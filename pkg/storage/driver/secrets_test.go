@@ -59,7 +59,7 @@ func TestUNcompressedSecretGet(t *testing.T) {
 	rel := releaseStub(name, vers, namespace, rspb.StatusDeployed)
 
 	// Create a test fixture which contains an uncompressed release
-	secret, err := newSecretsObject(key, rel, nil)
+	secret, err := newSecretsObject(key, rel, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to create secret: %s", err)
 	}
@@ -130,6 +130,45 @@ func TestSecretList(t *testing.T) {
 	}
 }
 
+func TestSecretListPaged(t *testing.T) {
+	secrets := newTestFixtureSecrets(t, []*rspb.Release{
+		releaseStub("key-1", 1, "default", rspb.StatusSuperseded),
+		releaseStub("key-1", 2, "default", rspb.StatusDeployed),
+		releaseStub("key-2", 1, "default", rspb.StatusDeployed),
+		releaseStub("key-3", 1, "default", rspb.StatusUninstalled),
+		releaseStub("key-4", 1, "default", rspb.StatusDeployed),
+	}...)
+
+	var (
+		pages [][]string
+		token string
+	)
+	for {
+		page, next, err := secrets.ListPaged(token, 2)
+		if err != nil {
+			t.Fatalf("Failed to list page %d: %s", len(pages), err)
+		}
+		var keys []string
+		for _, rls := range page {
+			keys = append(keys, testKey(rls.Name, rls.Version))
+		}
+		pages = append(pages, keys)
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	expect := [][]string{
+		{"key-1.v1", "key-1.v2"},
+		{"key-2.v1", "key-3.v1"},
+		{"key-4.v1"},
+	}
+	if !reflect.DeepEqual(pages, expect) {
+		t.Errorf("Expected pages %v, got %v", expect, pages)
+	}
+}
+
 func TestSecretQuery(t *testing.T) {
 	secrets := newTestFixtureSecrets(t, []*rspb.Release{
 		releaseStub("key-1", 1, "default", rspb.StatusUninstalled),
@@ -239,3 +278,25 @@ func TestSecretDelete(t *testing.T) {
 		t.Errorf("Expected {%v}, got {%v}", ErrReleaseNotFound, err)
 	}
 }
+
+func TestSecretAnnotations(t *testing.T) {
+	vers := 1
+	name := "smug-pigeon"
+	namespace := "default"
+	key := testKey(name, vers)
+	rel := releaseStub(name, vers, namespace, rspb.StatusDeployed)
+	rel.Annotations = map[string]string{"commit": "1a2b3c4", "pull-request": "42"}
+
+	secrets := newTestFixtureSecrets(t)
+	if err := secrets.Create(key, rel); err != nil {
+		t.Fatalf("Failed to create release with key %q: %s", key, err)
+	}
+
+	got, err := secrets.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release: %s", err)
+	}
+	if !reflect.DeepEqual(rel.Annotations, got.Annotations) {
+		t.Errorf("Expected annotations %v, got %v", rel.Annotations, got.Annotations)
+	}
+}
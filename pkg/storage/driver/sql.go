@@ -180,12 +180,49 @@ type SQLReleaseWrapper struct {
 	ModifiedAt int    `db:"modifiedAt"`
 }
 
+// SQLPoolOptions tunes the pool of connections the SQL driver keeps to the
+// database. Zero fields keep the defaults of database/sql.
+type SQLPoolOptions struct {
+	// MaxOpenConns limits the connections open at once.
+	MaxOpenConns int
+	// MaxIdleConns limits the connections kept open while unused.
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections once they have been open this long.
+	ConnMaxLifetime time.Duration
+}
+
+// sqlPool is the part of *sql.DB that SQLPoolOptions configures.
+type sqlPool interface {
+	SetMaxOpenConns(n int)
+	SetMaxIdleConns(n int)
+	SetConnMaxLifetime(d time.Duration)
+}
+
+func (o SQLPoolOptions) apply(db sqlPool) {
+	if o.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(o.MaxOpenConns)
+	}
+	if o.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(o.MaxIdleConns)
+	}
+	if o.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(o.ConnMaxLifetime)
+	}
+}
+
 // NewSQL initializes a new sql driver.
 func NewSQL(connectionString string, logger func(string, ...interface{}), namespace string) (*SQL, error) {
+	return NewSQLWithPool(connectionString, logger, namespace, SQLPoolOptions{})
+}
+
+// NewSQLWithPool initializes a new sql driver whose connection pool is
+// configured by pool.
+func NewSQLWithPool(connectionString string, logger func(string, ...interface{}), namespace string, pool SQLPoolOptions) (*SQL, error) {
 	db, err := sqlx.Connect(postgreSQLDialect, connectionString)
 	if err != nil {
 		return nil, err
 	}
+	pool.apply(db)
 
 	driver := &SQL{
 		db:               db,
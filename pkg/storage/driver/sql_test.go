@@ -76,6 +76,51 @@ func TestSQLGet(t *testing.T) {
 	}
 }
 
+func TestSQLGetAnnotations(t *testing.T) {
+	vers := int(1)
+	name := "smug-pigeon"
+	namespace := "default"
+	key := testKey(name, vers)
+	rel := releaseStub(name, vers, namespace, rspb.StatusDeployed)
+	rel.Annotations = map[string]string{"commit": "1a2b3c4", "pull-request": "42"}
+
+	body, _ := encodeRelease(rel)
+
+	sqlDriver, mock := newTestFixtureSQL(t)
+
+	query := fmt.Sprintf(
+		regexp.QuoteMeta("SELECT %s FROM %s WHERE %s = $1 AND %s = $2"),
+		sqlReleaseTableBodyColumn,
+		sqlReleaseTableName,
+		sqlReleaseTableKeyColumn,
+		sqlReleaseTableNamespaceColumn,
+	)
+
+	mock.
+		ExpectQuery(query).
+		WithArgs(key, namespace).
+		WillReturnRows(
+			mock.NewRows([]string{
+				sqlReleaseTableBodyColumn,
+			}).AddRow(
+				body,
+			),
+		).RowsWillBeClosed()
+
+	got, err := sqlDriver.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release: %v", err)
+	}
+
+	if !reflect.DeepEqual(rel.Annotations, got.Annotations) {
+		t.Errorf("Expected annotations %v, got %v", rel.Annotations, got.Annotations)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("sql expectations weren't met: %v", err)
+	}
+}
+
 func TestSQLList(t *testing.T) {
 	body1, _ := encodeRelease(releaseStub("key-1", 1, "default", rspb.StatusUninstalled))
 	body2, _ := encodeRelease(releaseStub("key-2", 1, "default", rspb.StatusUninstalled))
@@ -440,3 +485,42 @@ func TestSqlDelete(t *testing.T) {
 		t.Errorf("Expected release {%v}, got {%v}", rel, deletedRelease)
 	}
 }
+
+// recordingPool records the connection pool settings applied to it.
+type recordingPool struct {
+	calls []string
+}
+
+func (p *recordingPool) SetMaxOpenConns(n int) {
+	p.calls = append(p.calls, fmt.Sprintf("max open %d", n))
+}
+
+func (p *recordingPool) SetMaxIdleConns(n int) {
+	p.calls = append(p.calls, fmt.Sprintf("max idle %d", n))
+}
+
+func (p *recordingPool) SetConnMaxLifetime(d time.Duration) {
+	p.calls = append(p.calls, fmt.Sprintf("max lifetime %s", d))
+}
+
+func TestSQLPoolOptions(t *testing.T) {
+	pool := &recordingPool{}
+	SQLPoolOptions{MaxOpenConns: 20, MaxIdleConns: 5, ConnMaxLifetime: 30 * time.Minute}.apply(pool)
+	expect := []string{"max open 20", "max idle 5", "max lifetime 30m0s"}
+	if !reflect.DeepEqual(pool.calls, expect) {
+		t.Errorf("Expected %v, got %v", expect, pool.calls)
+	}
+
+	// zero options keep the database/sql defaults
+	pool = &recordingPool{}
+	SQLPoolOptions{}.apply(pool)
+	if len(pool.calls) != 0 {
+		t.Errorf("Expected no settings, got %v", pool.calls)
+	}
+
+	sqlDriver, _ := newTestFixtureSQL(t)
+	SQLPoolOptions{MaxOpenConns: 7}.apply(sqlDriver.db)
+	if got := sqlDriver.db.Stats().MaxOpenConnections; got != 7 {
+		t.Errorf("Expected 7 max open connections on the database, got %d", got)
+	}
+}
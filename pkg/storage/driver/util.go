@@ -22,6 +22,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
 
 	rspb "helm.sh/helm/v3/pkg/release"
 )
@@ -30,6 +34,125 @@ var b64 = base64.StdEncoding
 
 var magicGzip = []byte{0x1f, 0x8b, 0x08}
 
+// Codec compresses the releases stored by the Secrets and ConfigMaps drivers
+// whose Codec it is. The compressed payload starts with the marker byte ID,
+// from which any driver finds the codec decompressing it.
+//
+// GzipCodec and ZstdCodec are built in; other codecs must be registered with
+// RegisterCodec to read the releases they compress.
+type Codec interface {
+	// ID is the marker byte tagging the payloads the codec compresses.
+	ID() byte
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCodec is the gzip Codec. Unlike the untagged gzip payloads written
+// when no Codec is set, its payloads cannot be read by Helm versions
+// predating codecs.
+var GzipCodec Codec = gzipCodec{}
+
+// ZstdCodec is the zstd Codec. It compresses large releases better and faster
+// than gzip, but its payloads cannot be read by Helm versions predating
+// codecs.
+var ZstdCodec Codec = zstdCodec{}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[byte]Codec{
+		GzipCodec.ID(): GzipCodec,
+		ZstdCodec.ID(): ZstdCodec,
+	}
+)
+
+// RegisterCodec makes the payloads compressed by c readable. Its ID must not
+// be that of another codec, nor 0x1f or '{', which start the untagged gzip
+// and JSON payloads written before codecs.
+func RegisterCodec(c Codec) error {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	id := c.ID()
+	if id == magicGzip[0] || id == '{' {
+		return errors.Errorf("codec ID %#x is reserved for untagged payloads", id)
+	}
+	if _, ok := codecs[id]; ok {
+		return errors.Errorf("codec ID %#x is already registered", id)
+	}
+	codecs[id] = c
+	return nil
+}
+
+func codecByID(id byte) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[id]
+	return c, ok
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) ID() byte { return 0x01 }
+
+func (gzipCodec) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	w.Close()
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// zstd encoders and decoders are safe for concurrent use, and expensive
+// enough to create to be shared.
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+type zstdCodec struct{}
+
+func (zstdCodec) ID() byte { return 0x02 }
+
+func (zstdCodec) init() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression)); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+func (c zstdCodec) Compress(data []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+func (c zstdCodec) Decompress(data []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return zstdDecoder.DecodeAll(data, nil)
+}
+
 // encodeRelease encodes a release returning a base64 encoded
 // gzipped string representation, or error.
 func encodeRelease(rls *rspb.Release) (string, error) {
@@ -37,22 +160,37 @@ func encodeRelease(rls *rspb.Release) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	var buf bytes.Buffer
-	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	b, err = GzipCodec.Compress(b)
 	if err != nil {
 		return "", err
 	}
-	if _, err = w.Write(b); err != nil {
+
+	return b64.EncodeToString(b), nil
+}
+
+// encodeReleaseWithCodec encodes a release returning a base64 encoded string
+// of the payload compressed by c and tagged with its ID. A nil c writes the
+// untagged gzip payload of encodeRelease.
+func encodeReleaseWithCodec(rls *rspb.Release, c Codec) (string, error) {
+	if c == nil {
+		return encodeRelease(rls)
+	}
+	b, err := json.Marshal(rls)
+	if err != nil {
+		return "", err
+	}
+	b, err = c.Compress(b)
+	if err != nil {
 		return "", err
 	}
-	w.Close()
 
-	return b64.EncodeToString(buf.Bytes()), nil
+	return b64.EncodeToString(append([]byte{c.ID()}, b...)), nil
 }
 
 // decodeRelease decodes the bytes of data into a release
-// type. Data must contain a base64 encoded gzipped string of a
-// valid release, otherwise an error is returned.
+// type. Data must contain a base64 encoded string of a valid
+// release, gzipped or tagged by the codec compressing it,
+// otherwise an error is returned.
 func decodeRelease(data string) (*rspb.Release, error) {
 	// base64 decode string
 	b, err := b64.DecodeString(data)
@@ -63,17 +201,19 @@ func decodeRelease(data string) (*rspb.Release, error) {
 	// For backwards compatibility with releases that were stored before
 	// compression was introduced we skip decompression if the
 	// gzip magic header is not found
-	if bytes.Equal(b[0:3], magicGzip) {
-		r, err := gzip.NewReader(bytes.NewReader(b))
-		if err != nil {
+	switch {
+	case bytes.HasPrefix(b, magicGzip):
+		if b, err = GzipCodec.Decompress(b); err != nil {
 			return nil, err
 		}
-		defer r.Close()
-		b2, err := ioutil.ReadAll(r)
-		if err != nil {
+	case len(b) > 0 && b[0] != '{':
+		c, ok := codecByID(b[0])
+		if !ok {
+			return nil, errors.Errorf("release is compressed by an unknown codec %#x", b[0])
+		}
+		if b, err = c.Decompress(b[1:]); err != nil {
 			return nil, err
 		}
-		b = b2
 	}
 
 	var rls rspb.Release
@@ -0,0 +1,168 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	rspb "helm.sh/helm/v3/pkg/release"
+)
+
+// flateCodec is registered as an embedder would register a codec Helm does
+// not build in.
+type flateCodec struct{}
+
+func (flateCodec) ID() byte { return 0x7a }
+
+func (flateCodec) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	w.Close()
+	return buf.Bytes(), nil
+}
+
+func (flateCodec) Decompress(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func init() {
+	if err := RegisterCodec(flateCodec{}); err != nil {
+		panic(err)
+	}
+}
+
+func TestEncodeReleaseWithCodec(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+
+	for _, c := range []Codec{nil, GzipCodec, ZstdCodec, flateCodec{}} {
+		data, err := encodeReleaseWithCodec(rel, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := b64.DecodeString(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c == nil && !bytes.Equal(b[:3], magicGzip) {
+			t.Errorf("Expected an untagged gzip payload without a codec, got %x", b[:3])
+		}
+		if c != nil && b[0] != c.ID() {
+			t.Errorf("Expected the payload to be tagged with %#x, got %#x", c.ID(), b[0])
+		}
+
+		got, err := decodeRelease(data)
+		if err != nil {
+			t.Fatalf("Failed to decode the %T payload: %s", c, err)
+		}
+		if !reflect.DeepEqual(rel, got) {
+			t.Errorf("Expected {%v}, got {%v}", rel, got)
+		}
+	}
+}
+
+func TestDecodeLegacyRelease(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+	b, err := json.Marshal(rel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a payload gzipped as it was before codecs
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(b)
+	w.Close()
+
+	for name, payload := range map[string][]byte{"gzip": buf.Bytes(), "uncompressed": b} {
+		got, err := decodeRelease(b64.EncodeToString(payload))
+		if err != nil {
+			t.Fatalf("Failed to decode the legacy %s payload: %s", name, err)
+		}
+		if !reflect.DeepEqual(rel, got) {
+			t.Errorf("Expected {%v}, got {%v}", rel, got)
+		}
+	}
+
+	if _, err := decodeRelease(b64.EncodeToString(append([]byte{0x42}, b...))); err == nil {
+		t.Error("Expected an error decoding the payload of an unknown codec")
+	}
+
+	// payloads shorter than the gzip header must not panic
+	for _, payload := range [][]byte{nil, {0x1f}, {0x1f, 0x8b}, {GzipCodec.ID()}} {
+		if _, err := decodeRelease(b64.EncodeToString(payload)); err == nil {
+			t.Errorf("Expected an error decoding the payload %v", payload)
+		}
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	if err := RegisterCodec(flateCodec{}); err == nil {
+		t.Error("Expected an error registering a codec twice")
+	}
+	if err := RegisterCodec(builtinCodec{}); err == nil {
+		t.Error("Expected an error registering a codec with the ID of a built in codec")
+	}
+	if err := RegisterCodec(reservedCodec{}); err == nil {
+		t.Error("Expected an error registering a codec with a reserved ID")
+	}
+}
+
+type reservedCodec struct{ flateCodec }
+
+func (reservedCodec) ID() byte { return '{' }
+
+type builtinCodec struct{ flateCodec }
+
+func (builtinCodec) ID() byte { return ZstdCodec.ID() }
+
+func TestSecretsCodec(t *testing.T) {
+	secrets := newTestFixtureSecrets(t)
+	secrets.Codec = flateCodec{}
+
+	key := testKey("smug-pigeon", 1)
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+	if err := secrets.Create(key, rel); err != nil {
+		t.Fatalf("Failed to create release with key %q: %s", key, err)
+	}
+
+	// any driver reads the release, whatever its codec
+	secrets.Codec = nil
+	got, err := secrets.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release with key %q: %s", key, err)
+	}
+	if !reflect.DeepEqual(rel, got) {
+		t.Errorf("Expected {%v}, got {%v}", rel, got)
+	}
+}
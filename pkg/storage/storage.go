@@ -19,6 +19,7 @@ package storage // import "helm.sh/helm/v3/pkg/storage"
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -92,6 +93,16 @@ func (s *Storage) ListReleases() ([]*rspb.Release, error) {
 	return s.Driver.List(func(_ *rspb.Release) bool { return true })
 }
 
+// ListPaged returns at most limit releases from storage, starting from the
+// position described by continueToken, along with the token of the next page.
+// An empty continueToken starts from the first release and an empty returned
+// token means there are no more releases. The Kubernetes drivers page the
+// releases with the limit and continue options of the list API.
+func (s *Storage) ListPaged(continueToken string, limit int) ([]*rspb.Release, string, error) {
+	s.Log("listing releases in storage (limit %d)", limit)
+	return driver.ListPaged(s.Driver, continueToken, limit)
+}
+
 // ListUninstalled returns all releases with Status == UNINSTALLED. An error is returned
 // if the storage backend fails to retrieve the releases.
 func (s *Storage) ListUninstalled() ([]*rspb.Release, error) {
@@ -252,13 +263,35 @@ func makeKey(rlsname string, version int) string {
 	return fmt.Sprintf("%s.%s.v%d", HelmStorageType, rlsname, version)
 }
 
+// InitOption configures the storage created by Init.
+type InitOption func(*initOptions)
+
+type initOptions struct {
+	cacheTTL time.Duration
+}
+
+// WithCache caches the releases read from the driver for ttl, wrapping it in
+// a driver.CachedDriver.
+func WithCache(ttl time.Duration) InitOption {
+	return func(o *initOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
 // Init initializes a new storage backend with the driver d.
 // If d is nil, the default in-memory driver is used.
-func Init(d driver.Driver) *Storage {
+func Init(d driver.Driver, opts ...InitOption) *Storage {
 	// default driver is in memory
 	if d == nil {
 		d = driver.NewMemory()
 	}
+	var o initOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.cacheTTL > 0 {
+		d = driver.NewCachedDriver(d, o.cacheTTL)
+	}
 	return &Storage{
 		Driver: d,
 		Log:    func(_ string, _ ...interface{}) {},
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -467,3 +468,26 @@ func assertErrNil(eh func(args ...interface{}), err error, message string) {
 		eh(fmt.Sprintf("%s: %q", message, err))
 	}
 }
+
+func TestStorageInitWithCache(t *testing.T) {
+	if _, ok := Init(driver.NewMemory()).Driver.(*driver.Memory); !ok {
+		t.Error("Expected the driver to be used as is by default")
+	}
+
+	storage := Init(driver.NewMemory(), WithCache(time.Minute))
+	cached, ok := storage.Driver.(*driver.CachedDriver)
+	if !ok {
+		t.Fatalf("Expected a cached driver, got %T", storage.Driver)
+	}
+	if cached.TTL != time.Minute {
+		t.Errorf("Expected a TTL of 1m, got %s", cached.TTL)
+	}
+
+	rls := ReleaseTestData{Name: "angry-beaver", Version: 1}.ToRelease()
+	assertErrNil(t.Fatal, storage.Create(rls), "StoreRelease")
+	res, err := storage.Get(rls.Name, rls.Version)
+	assertErrNil(t.Fatal, err, "QueryRelease")
+	if !reflect.DeepEqual(rls, res) {
+		t.Fatalf("Expected %v, got %v", rls, res)
+	}
+}
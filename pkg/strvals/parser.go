@@ -17,10 +17,12 @@ package strvals
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
@@ -94,6 +96,19 @@ func ParseIntoString(s string, dest map[string]interface{}) error {
 	return t.parse()
 }
 
+// ParseJSON parses a set line whose values are JSON and merges the result
+// into dest.
+//
+// A set line is of the form name1=jsonval1,name2=jsonval2, such as
+// servers=[{"port":80},{"port":443}]. JSON numbers are parsed as float64, as
+// are numbers in values files, and a JSON null is kept as nil.
+func ParseJSON(s string, dest map[string]interface{}) error {
+	scanner := bytes.NewBufferString(s)
+	t := newParser(scanner, dest, false)
+	t.isjsonval = true
+	return t.parse()
+}
+
 // ParseIntoFile parses a filevals line and merges the result into dest.
 //
 // This method always returns a string as the value.
@@ -116,6 +131,8 @@ type parser struct {
 	sc     *bytes.Buffer
 	data   map[string]interface{}
 	reader RunesValueReader
+	// isjsonval parses the values as JSON
+	isjsonval bool
 }
 
 func newParser(sc *bytes.Buffer, data map[string]interface{}, stringBool bool) *parser {
@@ -184,6 +201,14 @@ func (t *parser) key(data map[string]interface{}) (reterr error) {
 			set(data, kk, list)
 			return err
 		case last == '=':
+			if t.isjsonval {
+				v, e := t.jsonVal()
+				if e != nil {
+					return e
+				}
+				set(data, string(k), v)
+				return nil
+			}
 			//End of key. Consume =, Get value.
 			// FIXME: Get value list first
 			vl, e := t.valList()
@@ -280,6 +305,13 @@ func (t *parser) listItem(list []interface{}, i int) ([]interface{}, error) {
 	case err != nil:
 		return list, err
 	case last == '=':
+		if t.isjsonval {
+			v, e := t.jsonVal()
+			if e != nil {
+				return list, e
+			}
+			return setIndex(list, i, v)
+		}
 		vl, e := t.valList()
 		switch e {
 		case nil:
@@ -349,6 +381,29 @@ func (t *parser) val() ([]rune, error) {
 	return v, err
 }
 
+// jsonVal decodes the JSON value at the start of the remaining input and
+// consumes it along with the comma that may follow it.
+func (t *parser) jsonVal() (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(t.sc.Bytes()))
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON value")
+	}
+	t.sc.Next(int(dec.InputOffset()))
+
+	for {
+		r, _, e := t.sc.ReadRune()
+		switch {
+		case e == io.EOF || r == ',':
+			return v, nil
+		case e != nil:
+			return v, e
+		case !unicode.IsSpace(r):
+			return v, errors.Errorf("unexpected data after JSON value: %q", r)
+		}
+	}
+}
+
 func (t *parser) valList() ([]interface{}, error) {
 	r, _, e := t.sc.ReadRune()
 	if e != nil {
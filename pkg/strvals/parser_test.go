@@ -633,6 +633,88 @@ func TestParseIntoFile(t *testing.T) {
 	}
 }
 
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		input  string
+		got    map[string]interface{}
+		expect map[string]interface{}
+		err    bool
+	}{
+		{ // object
+			input:  `outer={"inner":{"port":80,"enabled":true}}`,
+			got:    map[string]interface{}{},
+			expect: map[string]interface{}{"outer": map[string]interface{}{"inner": map[string]interface{}{"port": float64(80), "enabled": true}}},
+		},
+		{ // array of objects
+			input: `servers=[{"port":80},{"port":443}]`,
+			got:   map[string]interface{}{},
+			expect: map[string]interface{}{"servers": []interface{}{
+				map[string]interface{}{"port": float64(80)},
+				map[string]interface{}{"port": float64(443)},
+			}},
+		},
+		{ // nested keys, list indexes and several values with scalars
+			input: `a.b=["x", 1.5, null],list[1].name="two" ,count=3,flag=false`,
+			got:   map[string]interface{}{"a": map[string]interface{}{"c": "kept"}},
+			expect: map[string]interface{}{
+				"a":     map[string]interface{}{"b": []interface{}{"x", 1.5, nil}, "c": "kept"},
+				"list":  []interface{}{nil, map[string]interface{}{"name": "two"}},
+				"count": float64(3),
+				"flag":  false,
+			},
+		},
+		{ // the value replaces the existing value of the key
+			input:  `outer={"new":1}`,
+			got:    map[string]interface{}{"outer": map[string]interface{}{"old": 1}},
+			expect: map[string]interface{}{"outer": map[string]interface{}{"new": float64(1)}},
+		},
+		{ // malformed JSON
+			input: `outer={"inner":}`,
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+		{ // unquoted string
+			input: `name=value`,
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+		{ // data after the value
+			input: `outer={"inner":1}x`,
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+		{ // missing value
+			input: `outer=`,
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		if err := ParseJSON(tt.input, tt.got); err != nil {
+			if tt.err {
+				continue
+			}
+			t.Fatalf("%s: %s", tt.input, err)
+		}
+		if tt.err {
+			t.Fatalf("%s: Expected error. Got nil", tt.input)
+		}
+
+		y1, err := yaml.Marshal(tt.expect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y2, err := yaml.Marshal(tt.got)
+		if err != nil {
+			t.Fatalf("Error serializing parsed value: %s", err)
+		}
+
+		if string(y1) != string(y2) {
+			t.Errorf("%s: Expected:\n%s\nGot:\n%s", tt.input, y1, y2)
+		}
+	}
+}
+
 func TestToYAML(t *testing.T) {
 	// The TestParse does the hard part. We just verify that YAML formatting is
 	// happening.